@@ -0,0 +1,169 @@
+package lockout
+
+import (
+	"strings"
+	"time"
+
+	"github.com/reearth/reearthx/account/accountdomain"
+)
+
+// Key identifies what failed login attempts are counted for.
+type Key string
+
+func UserKey(id accountdomain.UserID) Key {
+	return Key("user:" + id.String())
+}
+
+func IPKey(ip string) Key {
+	return Key("ip:" + strings.ToLower(ip))
+}
+
+func (k Key) String() string {
+	return string(k)
+}
+
+// Policy decides how long attempts are delayed and locked out after failures.
+type Policy struct {
+	// MaxFailures is the number of consecutive failures that locks out the key.
+	MaxFailures int
+	// BaseDelay is the wait after the first failure. It doubles on every further failure up to MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// LockoutDuration is how long the key is locked out.
+	LockoutDuration time.Duration
+	// ResetAfter forgets failures that are older than it.
+	ResetAfter time.Duration
+}
+
+var (
+	DefaultUserPolicy = Policy{
+		MaxFailures:     5,
+		BaseDelay:       time.Second,
+		MaxDelay:        time.Minute,
+		LockoutDuration: 30 * time.Minute,
+		ResetAfter:      24 * time.Hour,
+	}
+	DefaultIPPolicy = Policy{
+		MaxFailures:     50,
+		BaseDelay:       0,
+		MaxDelay:        time.Minute,
+		LockoutDuration: time.Hour,
+		ResetAfter:      time.Hour,
+	}
+)
+
+func (p Policy) delay(failures int) time.Duration {
+	if failures <= 0 || p.BaseDelay <= 0 {
+		return 0
+	}
+	d := p.BaseDelay
+	for i := 1; i < failures; i++ {
+		d *= 2
+		if p.MaxDelay > 0 && d >= p.MaxDelay {
+			return p.MaxDelay
+		}
+	}
+	return d
+}
+
+// Counter counts consecutive failed login attempts of a key.
+type Counter struct {
+	key          Key
+	failures     int
+	lastFailedAt time.Time
+	lockedUntil  *time.Time
+}
+
+func New(key Key) *Counter {
+	return &Counter{key: key}
+}
+
+func From(key Key, failures int, lastFailedAt time.Time, lockedUntil *time.Time) *Counter {
+	return &Counter{
+		key:          key,
+		failures:     failures,
+		lastFailedAt: lastFailedAt,
+		lockedUntil:  lockedUntil,
+	}
+}
+
+func (c *Counter) Key() Key {
+	return c.key
+}
+
+func (c *Counter) Failures() int {
+	if c == nil {
+		return 0
+	}
+	return c.failures
+}
+
+func (c *Counter) LastFailedAt() time.Time {
+	if c == nil {
+		return time.Time{}
+	}
+	return c.lastFailedAt
+}
+
+func (c *Counter) LockedUntil() *time.Time {
+	if c == nil {
+		return nil
+	}
+	return c.lockedUntil
+}
+
+func (c *Counter) Locked(now time.Time) bool {
+	return c != nil && c.lockedUntil != nil && now.Before(*c.lockedUntil)
+}
+
+// RetryAt returns when the next attempt is allowed.
+func (c *Counter) RetryAt(p Policy) time.Time {
+	if c == nil {
+		return time.Time{}
+	}
+	if c.lockedUntil != nil {
+		return *c.lockedUntil
+	}
+	if c.failures == 0 {
+		return time.Time{}
+	}
+	return c.lastFailedAt.Add(p.delay(c.failures))
+}
+
+// Blocked reports whether an attempt is not allowed now because of the backoff or the lockout.
+func (c *Counter) Blocked(now time.Time, p Policy) bool {
+	return now.Before(c.RetryAt(p))
+}
+
+// Fail records a failed attempt. It reports true if the key has been locked out by this failure.
+func (c *Counter) Fail(now time.Time, p Policy) bool {
+	if c.expired(now, p) {
+		c.Reset()
+	}
+	c.failures++
+	c.lastFailedAt = now
+	if p.MaxFailures > 0 && c.failures >= p.MaxFailures && c.lockedUntil == nil {
+		lockedUntil := now.Add(p.LockoutDuration)
+		c.lockedUntil = &lockedUntil
+		return true
+	}
+	return false
+}
+
+// Expired reports whether the counter can be forgotten.
+func (c *Counter) Expired(now time.Time, p Policy) bool {
+	return c == nil || c.expired(now, p)
+}
+
+func (c *Counter) expired(now time.Time, p Policy) bool {
+	if c.lockedUntil != nil {
+		return !now.Before(*c.lockedUntil)
+	}
+	return p.ResetAfter > 0 && !now.Before(c.lastFailedAt.Add(p.ResetAfter))
+}
+
+func (c *Counter) Reset() {
+	c.failures = 0
+	c.lastFailedAt = time.Time{}
+	c.lockedUntil = nil
+}
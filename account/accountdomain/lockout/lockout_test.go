@@ -0,0 +1,85 @@
+package lockout
+
+import (
+	"testing"
+	"time"
+
+	"github.com/reearth/reearthx/account/accountdomain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKey(t *testing.T) {
+	uid := accountdomain.NewUserID()
+	assert.Equal(t, Key("user:"+uid.String()), UserKey(uid))
+	assert.Equal(t, Key("ip:2001:db8::1"), IPKey("2001:DB8::1"))
+}
+
+func TestPolicy_delay(t *testing.T) {
+	p := Policy{BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+	assert.Equal(t, time.Duration(0), p.delay(0))
+	assert.Equal(t, time.Second, p.delay(1))
+	assert.Equal(t, 2*time.Second, p.delay(2))
+	assert.Equal(t, 4*time.Second, p.delay(3))
+	assert.Equal(t, 5*time.Second, p.delay(4))
+	assert.Equal(t, 5*time.Second, p.delay(100))
+	assert.Equal(t, time.Duration(0), Policy{}.delay(3))
+}
+
+func TestCounter_Fail(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	p := Policy{
+		MaxFailures:     3,
+		BaseDelay:       time.Second,
+		MaxDelay:        time.Minute,
+		LockoutDuration: time.Hour,
+		ResetAfter:      24 * time.Hour,
+	}
+	c := New("k")
+	assert.False(t, c.Blocked(now, p))
+
+	assert.False(t, c.Fail(now, p))
+	assert.Equal(t, 1, c.Failures())
+	assert.True(t, c.Blocked(now, p))
+	assert.False(t, c.Blocked(now.Add(time.Second), p))
+
+	now = now.Add(time.Second)
+	assert.False(t, c.Fail(now, p))
+	assert.Equal(t, now.Add(2*time.Second), c.RetryAt(p))
+
+	now = now.Add(2 * time.Second)
+	assert.True(t, c.Fail(now, p))
+	assert.True(t, c.Locked(now))
+	assert.Equal(t, now.Add(time.Hour), *c.LockedUntil())
+	assert.True(t, c.Blocked(now.Add(59*time.Minute), p))
+
+	// failures while locked do not extend the lockout
+	assert.False(t, c.Fail(now.Add(time.Minute), p))
+	assert.Equal(t, now.Add(time.Hour), *c.LockedUntil())
+
+	// the counter starts over after the lockout
+	now = now.Add(time.Hour)
+	assert.False(t, c.Locked(now))
+	assert.True(t, c.Expired(now, p))
+	assert.False(t, c.Fail(now, p))
+	assert.Equal(t, 1, c.Failures())
+	assert.Nil(t, c.LockedUntil())
+
+	// old failures are forgotten
+	assert.False(t, c.Fail(now.Add(25*time.Hour), p))
+	assert.Equal(t, 1, c.Failures())
+}
+
+func TestCounter_Reset(t *testing.T) {
+	now := time.Now()
+	c := From("k", 10, now, &now)
+	c.Reset()
+	assert.Equal(t, New("k"), c)
+}
+
+func TestCounter_Nil(t *testing.T) {
+	var c *Counter
+	assert.Equal(t, 0, c.Failures())
+	assert.False(t, c.Locked(time.Now()))
+	assert.False(t, c.Blocked(time.Now(), DefaultUserPolicy))
+	assert.True(t, c.Expired(time.Now(), DefaultUserPolicy))
+}
@@ -0,0 +1,221 @@
+package user
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/reearth/reearthx/i18n"
+	"github.com/reearth/reearthx/rerror"
+)
+
+const (
+	totpPeriod        = 30
+	totpDigits        = 6
+	totpSkew          = 1
+	recoveryCodeCount = 10
+)
+
+var (
+	ErrMFAAlreadyEnabled = rerror.NewE(i18n.T("mfa already enabled"))
+	ErrMFANotEnabled     = rerror.NewE(i18n.T("mfa not enabled"))
+	ErrMFANotEnrolled    = rerror.NewE(i18n.T("mfa not enrolled"))
+	ErrInvalidMFACode    = rerror.NewE(i18n.T("invalid mfa code"))
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// MFA is a TOTP (RFC 6238) second factor of a user. It is pending until the first code is confirmed.
+type MFA struct {
+	secret        string
+	enabledAt     *time.Time
+	recoveryCodes []string
+	lastUsedStep  int64
+}
+
+func NewMFA() (*MFA, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	return &MFA{secret: totpEncoding.EncodeToString(b)}, nil
+}
+
+// MFAFrom restores MFA. recoveryCodes must be hashed ones returned by RecoveryCodes.
+func MFAFrom(secret string, enabledAt *time.Time, recoveryCodes []string, lastUsedStep int64) *MFA {
+	return &MFA{
+		secret:        secret,
+		enabledAt:     enabledAt,
+		recoveryCodes: slices.Clone(recoveryCodes),
+		lastUsedStep:  lastUsedStep,
+	}
+}
+
+func (m *MFA) Secret() string {
+	if m == nil {
+		return ""
+	}
+	return m.secret
+}
+
+func (m *MFA) EnabledAt() *time.Time {
+	if m == nil {
+		return nil
+	}
+	return m.enabledAt
+}
+
+func (m *MFA) Enabled() bool {
+	return m != nil && m.enabledAt != nil
+}
+
+// RecoveryCodes returns the hashes of the unused recovery codes.
+func (m *MFA) RecoveryCodes() []string {
+	if m == nil {
+		return nil
+	}
+	return slices.Clone(m.recoveryCodes)
+}
+
+func (m *MFA) LastUsedStep() int64 {
+	if m == nil {
+		return 0
+	}
+	return m.lastUsedStep
+}
+
+// URI returns the otpauth URI to be shown as a QR code by authenticator apps.
+func (m *MFA) URI(issuer, account string) string {
+	q := url.Values{}
+	q.Set("secret", m.Secret())
+	q.Set("issuer", issuer)
+	q.Set("algorithm", "SHA1")
+	q.Set("digits", fmt.Sprint(totpDigits))
+	q.Set("period", fmt.Sprint(totpPeriod))
+	u := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + issuer + ":" + account,
+		RawQuery: q.Encode(),
+	}
+	return u.String()
+}
+
+// Enable confirms the enrollment with a code from the authenticator app and returns new recovery codes.
+func (m *MFA) Enable(code string, now time.Time) ([]string, error) {
+	if m == nil {
+		return nil, ErrMFANotEnrolled
+	}
+	if m.Enabled() {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	if !m.Verify(code, now) {
+		return nil, ErrInvalidMFACode
+	}
+	m.enabledAt = &now
+	return m.RegenerateRecoveryCodes()
+}
+
+// Verify checks a TOTP code. A code accepted once cannot be used again.
+func (m *MFA) Verify(code string, now time.Time) bool {
+	if m == nil || len(code) != totpDigits {
+		return false
+	}
+	step := now.Unix() / totpPeriod
+	for i := int64(-totpSkew); i <= totpSkew; i++ {
+		s := step + i
+		if s <= m.lastUsedStep {
+			continue
+		}
+		c, err := totpCode(m.secret, s)
+		if err != nil {
+			return false
+		}
+		if subtle.ConstantTimeCompare([]byte(c), []byte(code)) == 1 {
+			m.lastUsedStep = s
+			return true
+		}
+	}
+	return false
+}
+
+// UseRecoveryCode consumes a recovery code. Each code can be used only once.
+func (m *MFA) UseRecoveryCode(code string) bool {
+	if !m.Enabled() {
+		return false
+	}
+	h := hashRecoveryCode(code)
+	for i, c := range m.recoveryCodes {
+		if subtle.ConstantTimeCompare([]byte(c), []byte(h)) == 1 {
+			m.recoveryCodes = slices.Delete(m.recoveryCodes, i, i+1)
+			return true
+		}
+	}
+	return false
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes and returns the new ones in plain text.
+func (m *MFA) RegenerateRecoveryCodes() ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, 5)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		c := strings.ToLower(totpEncoding.EncodeToString(b))
+		c = c[:4] + "-" + c[4:]
+		codes = append(codes, c)
+		hashes = append(hashes, hashRecoveryCode(c))
+	}
+	m.recoveryCodes = hashes
+	return codes, nil
+}
+
+func (m *MFA) Clone() *MFA {
+	if m == nil {
+		return nil
+	}
+	var enabledAt *time.Time
+	if m.enabledAt != nil {
+		t := *m.enabledAt
+		enabledAt = &t
+	}
+	return MFAFrom(m.secret, enabledAt, m.recoveryCodes, m.lastUsedStep)
+}
+
+// TOTPCode returns the TOTP code of the secret at the time.
+func TOTPCode(secret string, t time.Time) (string, error) {
+	return totpCode(secret, t.Unix()/totpPeriod)
+}
+
+func totpCode(secret string, step int64) (string, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", err
+	}
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	_, _ = mac.Write(msg[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	v := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, v%1000000), nil
+}
+
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	h := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(h[:])
+}
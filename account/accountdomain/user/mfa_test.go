@@ -0,0 +1,116 @@
+package user
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTOTPCode(t *testing.T) {
+	// RFC 6238 test vectors (SHA1, truncated to 6 digits)
+	secret := totpEncoding.EncodeToString([]byte("12345678901234567890"))
+	tests := []struct {
+		time int64
+		want string
+	}{
+		{time: 59, want: "287082"},
+		{time: 1111111109, want: "081804"},
+		{time: 1234567890, want: "005924"},
+		{time: 2000000000, want: "279037"},
+	}
+	for _, tt := range tests {
+		got, err := TOTPCode(secret, time.Unix(tt.time, 0))
+		assert.NoError(t, err)
+		assert.Equal(t, tt.want, got)
+	}
+
+	_, err := TOTPCode("!", time.Now())
+	assert.Error(t, err)
+}
+
+func TestNewMFA(t *testing.T) {
+	m, err := NewMFA()
+	assert.NoError(t, err)
+	assert.Len(t, m.Secret(), 32)
+	assert.False(t, m.Enabled())
+	assert.Empty(t, m.RecoveryCodes())
+	assert.Equal(t, "otpauth://totp/Re:Earth:user@example.com?algorithm=SHA1&digits=6&issuer=Re%3AEarth&period=30&secret="+m.Secret(), m.URI("Re:Earth", "user@example.com"))
+}
+
+func TestMFA_Enable(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	m, _ := NewMFA()
+	code, _ := TOTPCode(m.Secret(), now)
+
+	_, err := (*MFA)(nil).Enable(code, now)
+	assert.Same(t, ErrMFANotEnrolled, err)
+
+	_, err = m.Enable("000000", now.Add(time.Hour))
+	assert.Same(t, ErrInvalidMFACode, err)
+	assert.False(t, m.Enabled())
+
+	codes, err := m.Enable(code, now)
+	assert.NoError(t, err)
+	assert.Len(t, codes, recoveryCodeCount)
+	assert.Len(t, m.RecoveryCodes(), recoveryCodeCount)
+	assert.Equal(t, &now, m.EnabledAt())
+	assert.True(t, m.Enabled())
+
+	_, err = m.Enable(code, now)
+	assert.Same(t, ErrMFAAlreadyEnabled, err)
+}
+
+func TestMFA_Verify(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	m, _ := NewMFA()
+
+	prev, _ := TOTPCode(m.Secret(), now.Add(-totpPeriod*time.Second))
+	code, _ := TOTPCode(m.Secret(), now)
+	old, _ := TOTPCode(m.Secret(), now.Add(-3*totpPeriod*time.Second))
+
+	assert.False(t, m.Verify(old, now))
+	assert.False(t, m.Verify("12345", now))
+	assert.True(t, m.Verify(code, now))
+	// codes cannot be reused, and older codes are rejected once a newer one is used
+	assert.False(t, m.Verify(code, now))
+	assert.False(t, m.Verify(prev, now))
+	assert.Equal(t, now.Unix()/totpPeriod, m.LastUsedStep())
+}
+
+func TestMFA_UseRecoveryCode(t *testing.T) {
+	now := time.Now()
+	m, _ := NewMFA()
+	code, _ := TOTPCode(m.Secret(), now)
+	codes, _ := m.Enable(code, now)
+
+	assert.False(t, m.UseRecoveryCode("xxxx-xxxx"))
+	assert.True(t, m.UseRecoveryCode(codes[0]))
+	assert.False(t, m.UseRecoveryCode(codes[0]))
+	assert.True(t, m.UseRecoveryCode(" "+codes[1][:4]+codes[1][5:]+" "))
+	assert.Len(t, m.RecoveryCodes(), recoveryCodeCount-2)
+
+	m2 := MFAFrom(m.Secret(), nil, m.RecoveryCodes(), 0)
+	assert.False(t, m2.UseRecoveryCode(codes[2]))
+}
+
+func TestMFA_Clone(t *testing.T) {
+	now := time.Now()
+	m := MFAFrom("AAAA", &now, []string{"a"}, 1)
+	got := m.Clone()
+	assert.Equal(t, m, got)
+	assert.NotSame(t, m, got)
+	assert.NotSame(t, m.EnabledAt(), got.EnabledAt())
+	assert.Nil(t, (*MFA)(nil).Clone())
+}
+
+func TestUser_MFAEnabled(t *testing.T) {
+	now := time.Now()
+	u := &User{}
+	assert.False(t, u.MFAEnabled())
+	u.SetMFA(MFAFrom("AAAA", nil, nil, 0))
+	assert.False(t, u.MFAEnabled())
+	u.SetMFA(MFAFrom("AAAA", &now, nil, 0))
+	assert.True(t, u.MFAEnabled())
+	assert.False(t, (*User)(nil).MFAEnabled())
+}
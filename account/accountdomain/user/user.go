@@ -23,6 +23,7 @@ type User struct {
 	auths         []Auth
 	verification  *Verification
 	passwordReset *PasswordReset
+	mfa           *MFA
 	host          string
 }
 
@@ -186,6 +187,18 @@ func (u *User) SetPasswordReset(pr *PasswordReset) {
 	u.passwordReset = pr.Clone()
 }
 
+func (u *User) MFA() *MFA {
+	return u.mfa
+}
+
+func (u *User) MFAEnabled() bool {
+	return u != nil && u.mfa.Enabled()
+}
+
+func (u *User) SetMFA(m *MFA) {
+	u.mfa = m
+}
+
 func (u *User) SetVerification(v *Verification) {
 	u.verification = v
 }
@@ -210,6 +223,7 @@ func (u *User) Clone() *User {
 		metadata:      util.CloneRef(u.metadata),
 		verification:  util.CloneRef(u.verification),
 		passwordReset: util.CloneRef(u.passwordReset),
+		mfa:           u.mfa.Clone(),
 	}
 }
 
@@ -119,3 +119,8 @@ func (b *Builder) Metadata(m *Metadata) *Builder {
 	b.u.metadata = m
 	return b
 }
+
+func (b *Builder) MFA(m *MFA) *Builder {
+	b.u.mfa = m
+	return b
+}
@@ -11,6 +11,7 @@ func New() *accountrepo.Container {
 		Workspace:   NewWorkspace(),
 		Role:        NewRole(),        // TODO: Delete this once the permission check migration is complete.
 		Permittable: NewPermittable(), // TODO: Delete this once the permission check migration is complete.
+		Lockout:     NewLockout(),
 		Transaction: &usecasex.NopTransaction{},
 	}
 }
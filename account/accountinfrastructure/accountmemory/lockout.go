@@ -0,0 +1,40 @@
+package accountmemory
+
+import (
+	"context"
+
+	"github.com/reearth/reearthx/account/accountdomain/lockout"
+	"github.com/reearth/reearthx/account/accountusecase/accountrepo"
+	"github.com/reearth/reearthx/rerror"
+	"github.com/reearth/reearthx/util"
+)
+
+type Lockout struct {
+	data *util.SyncMap[lockout.Key, *lockout.Counter]
+}
+
+var _ accountrepo.Lockout = (*Lockout)(nil)
+
+func NewLockout() *Lockout {
+	return &Lockout{
+		data: &util.SyncMap[lockout.Key, *lockout.Counter]{},
+	}
+}
+
+func (r *Lockout) FindByKey(_ context.Context, key lockout.Key) (*lockout.Counter, error) {
+	c, ok := r.data.Load(key)
+	if !ok {
+		return nil, rerror.ErrNotFound
+	}
+	return c, nil
+}
+
+func (r *Lockout) Save(_ context.Context, c *lockout.Counter) error {
+	r.data.Store(c.Key(), c)
+	return nil
+}
+
+func (r *Lockout) Remove(_ context.Context, key lockout.Key) error {
+	r.data.Delete(key)
+	return nil
+}
@@ -33,6 +33,7 @@ func New(ctx context.Context, mc *mongo.Client, databaseName string, useTransact
 		Users:       users,
 		Role:        NewRole(client),
 		Permittable: NewPermittable(client),
+		Lockout:     NewLockout(client),
 	}
 
 	// init
@@ -57,6 +58,7 @@ func Init(r *accountrepo.Container) error {
 		r.User.(*User).Init,
 		r.Role.(*Role).Init,
 		r.Permittable.(*Permittable).Init,
+		r.Lockout.(*Lockout).Init,
 	)
 }
 
@@ -0,0 +1,47 @@
+package accountmongo
+
+import (
+	"context"
+
+	"github.com/reearth/reearthx/account/accountdomain/lockout"
+	"github.com/reearth/reearthx/account/accountinfrastructure/accountmongo/mongodoc"
+	"github.com/reearth/reearthx/account/accountusecase/accountrepo"
+	"github.com/reearth/reearthx/mongox"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var (
+	lockoutIndexes       = []string{}
+	lockoutUniqueIndexes = []string{"id"}
+)
+
+type Lockout struct {
+	client *mongox.Collection
+}
+
+func NewLockout(client *mongox.Client) accountrepo.Lockout {
+	return &Lockout{
+		client: client.WithCollection("lockout"),
+	}
+}
+
+func (r *Lockout) Init() error {
+	return createIndexes(context.Background(), r.client, lockoutIndexes, lockoutUniqueIndexes)
+}
+
+func (r *Lockout) FindByKey(ctx context.Context, key lockout.Key) (*lockout.Counter, error) {
+	c := mongodoc.NewLockoutConsumer()
+	if err := r.client.FindOne(ctx, bson.M{"id": key.String()}, c); err != nil {
+		return nil, err
+	}
+	return c.Result[0], nil
+}
+
+func (r *Lockout) Save(ctx context.Context, c *lockout.Counter) error {
+	doc, id := mongodoc.NewLockout(c)
+	return r.client.SaveOne(ctx, id, doc)
+}
+
+func (r *Lockout) Remove(ctx context.Context, key lockout.Key) error {
+	return r.client.RemoveOne(ctx, bson.M{"id": key.String()})
+}
@@ -0,0 +1,37 @@
+package accountmongo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/reearth/reearthx/account/accountdomain/lockout"
+	"github.com/reearth/reearthx/mongox"
+	"github.com/reearth/reearthx/mongox/mongotest"
+	"github.com/reearth/reearthx/rerror"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLockout(t *testing.T) {
+	client := mongox.NewClientWithDatabase(mongotest.Connect(t)(t))
+	repo := NewLockout(client)
+	ctx := context.Background()
+	assert.NoError(t, repo.(*Lockout).Init())
+
+	key := lockout.IPKey("127.0.0.1")
+	got, err := repo.FindByKey(ctx, key)
+	assert.Nil(t, got)
+	assert.Equal(t, rerror.ErrNotFound, err)
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	c := lockout.From(key, 3, now, &now)
+	assert.NoError(t, repo.Save(ctx, c))
+
+	got, err = repo.FindByKey(ctx, key)
+	assert.NoError(t, err)
+	assert.Equal(t, c, got)
+
+	assert.NoError(t, repo.Remove(ctx, key))
+	_, err = repo.FindByKey(ctx, key)
+	assert.Equal(t, rerror.ErrNotFound, err)
+}
@@ -0,0 +1,38 @@
+package mongodoc
+
+import (
+	"time"
+
+	"github.com/reearth/reearthx/account/accountdomain/lockout"
+	"github.com/reearth/reearthx/mongox"
+)
+
+type LockoutDocument struct {
+	ID           string
+	Failures     int
+	LastFailedAt time.Time
+	LockedUntil  *time.Time
+}
+
+type LockoutConsumer = mongox.SliceFuncConsumer[*LockoutDocument, *lockout.Counter]
+
+func NewLockoutConsumer() *LockoutConsumer {
+	return NewConsumer[*LockoutDocument, *lockout.Counter]()
+}
+
+func NewLockout(c *lockout.Counter) (*LockoutDocument, string) {
+	id := c.Key().String()
+	return &LockoutDocument{
+		ID:           id,
+		Failures:     c.Failures(),
+		LastFailedAt: c.LastFailedAt(),
+		LockedUntil:  c.LockedUntil(),
+	}, id
+}
+
+func (d *LockoutDocument) Model() (*lockout.Counter, error) {
+	if d == nil {
+		return nil, nil
+	}
+	return lockout.From(lockout.Key(d.ID), d.Failures, d.LastFailedAt, d.LockedUntil), nil
+}
@@ -27,6 +27,7 @@ type UserDocument struct {
 	PasswordReset *PasswordResetDocument
 	Verification  *UserVerificationDoc
 	Metadata      *UserMetadataDoc
+	MFA           *UserMFADoc `bson:",omitempty"`
 }
 
 type UserVerificationDoc struct {
@@ -35,6 +36,13 @@ type UserVerificationDoc struct {
 	Verified   bool
 }
 
+type UserMFADoc struct {
+	Secret        string
+	EnabledAt     *time.Time
+	RecoveryCodes []string
+	LastUsedStep  int64
+}
+
 type UserMetadataDoc struct {
 	Description string
 	Website     string
@@ -79,6 +87,16 @@ func NewUser(user *user.User) (*UserDocument, string) {
 		}
 	}
 
+	var mfaDoc *UserMFADoc
+	if m := user.MFA(); m != nil {
+		mfaDoc = &UserMFADoc{
+			Secret:        m.Secret(),
+			EnabledAt:     m.EnabledAt(),
+			RecoveryCodes: m.RecoveryCodes(),
+			LastUsedStep:  m.LastUsedStep(),
+		}
+	}
+
 	return &UserDocument{
 		ID:            id,
 		Name:          user.Name(),
@@ -90,6 +108,7 @@ func NewUser(user *user.User) (*UserDocument, string) {
 		Password:      user.Password(),
 		PasswordReset: pwdResetDoc,
 		Metadata:      metadataDoc,
+		MFA:           mfaDoc,
 	}, id
 }
 
@@ -140,6 +159,7 @@ func (d *UserDocument) Model() (*user.User, error) {
 		Verification(v).
 		EncodedPassword(d.Password).
 		PasswordReset(d.PasswordReset.Model()).
+		MFA(d.MFA.Model()).
 		Build()
 
 	if err != nil {
@@ -158,6 +178,13 @@ func (d *PasswordResetDocument) Model() *user.PasswordReset {
 	}
 }
 
+func (d *UserMFADoc) Model() *user.MFA {
+	if d == nil {
+		return nil
+	}
+	return user.MFAFrom(d.Secret, d.EnabledAt, d.RecoveryCodes, d.LastUsedStep)
+}
+
 type UserConsumer = mongox.SliceFuncConsumer[*UserDocument, *user.User]
 
 func NewUserConsumer(host string) *UserConsumer {
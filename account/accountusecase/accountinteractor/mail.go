@@ -0,0 +1,62 @@
+package accountinteractor
+
+import (
+	"context"
+	"embed"
+	htmlTmpl "html/template"
+	"io/fs"
+
+	"github.com/reearth/reearthx/account/accountdomain/user"
+	"github.com/reearth/reearthx/i18n"
+	"github.com/reearth/reearthx/mailer"
+	"golang.org/x/text/language"
+)
+
+const (
+	passwordResetMail = "password_reset"
+	verificationMail  = "verification"
+	lockoutMail       = "lockout"
+)
+
+type mailContent struct {
+	UserName  string
+	ActionURL htmlTmpl.URL
+}
+
+var (
+	//go:embed emails/*.tmpl emails/locales/*.yml
+	emailsFS embed.FS
+
+	mailTemplates = newMailTemplates()
+)
+
+func newMailTemplates() *mailer.Templates {
+	fsys, err := fs.Sub(emailsFS, "emails")
+	if err != nil {
+		panic(err)
+	}
+
+	bundle := i18n.NewBundle(language.English)
+	bundle.MustLoadFS(fsys, "locales/en.yml", "locales/ja.yml")
+	return mailer.MustNewTemplates(fsys, bundle)
+}
+
+// sendMail renders the mail in the language of the user and sends it to the user.
+func (i *User) sendMail(ctx context.Context, u *user.User, name string, content mailContent) error {
+	lang := language.Und
+	if m := u.Metadata(); m != nil {
+		lang = m.Lang()
+	}
+
+	msg, err := mailTemplates.Render(name, lang, content)
+	if err != nil {
+		return err
+	}
+	msg.To = []mailer.Contact{
+		{
+			Email: u.Email(),
+			Name:  u.Name(),
+		},
+	}
+	return i.gateways.Mailer.Send(ctx, msg)
+}
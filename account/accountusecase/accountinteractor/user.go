@@ -1,12 +1,11 @@
 package accountinteractor
 
 import (
-	"bytes"
 	"context"
-	_ "embed"
 	"errors"
 	htmlTmpl "html/template"
 
+	"github.com/reearth/reearthx/account/accountdomain/lockout"
 	"github.com/reearth/reearthx/account/accountdomain/user"
 	"github.com/reearth/reearthx/account/accountdomain/workspace"
 	"github.com/reearth/reearthx/account/accountusecase"
@@ -14,8 +13,10 @@ import (
 	"github.com/reearth/reearthx/account/accountusecase/accountinterfaces"
 	"github.com/reearth/reearthx/account/accountusecase/accountrepo"
 	"github.com/reearth/reearthx/i18n"
+	"github.com/reearth/reearthx/log"
 	"github.com/reearth/reearthx/mailer"
 	"github.com/reearth/reearthx/rerror"
+	"github.com/reearth/reearthx/util"
 	"github.com/samber/lo"
 )
 
@@ -27,14 +28,6 @@ type User struct {
 	query           accountinterfaces.UserQuery
 }
 
-var (
-	passwordResetMailContent = mailContent{
-		Message:     "Thank you for using Re:Earth. We've received a request to reset your password. If this was you, please click the link below to confirm and change your password.",
-		Suffix:      "If you did not mean to reset your password, then you can ignore this email.",
-		ActionLabel: "Confirm to reset your password",
-	}
-)
-
 func NewUser(r *accountrepo.Container, g *accountgateway.Container, signupSecret, authSrcUIDomain string) accountinterfaces.User {
 	var repos []accountrepo.User
 	if r != nil {
@@ -80,13 +73,30 @@ func (i *User) SearchUser(ctx context.Context, keyword string) (user.SimpleList,
 }
 
 func (i *User) GetUserByCredentials(ctx context.Context, inp accountinterfaces.GetUserByCredentials) (u *user.User, err error) {
-	return Run1(ctx, nil, i.repos, Usecase().Transaction(), func(ctx context.Context) (*user.User, error) {
+	now := util.Now()
+	if inp.IP != "" {
+		if blocked, err := i.loginBlocked(ctx, lockout.IPKey(inp.IP), lockout.DefaultIPPolicy, now); err != nil {
+			return nil, err
+		} else if blocked {
+			return nil, accountinterfaces.ErrTooManyLoginAttempts
+		}
+	}
+
+	// failures are recorded outside of the transaction so that they are not rolled back
+	var target *user.User
+	u, err = Run1(ctx, nil, i.repos, Usecase().Transaction(), func(ctx context.Context) (*user.User, error) {
 		u, err = i.repos.User.FindByNameOrEmail(ctx, inp.Email)
 		if err != nil && !errors.Is(err, rerror.ErrNotFound) {
 			return nil, err
 		} else if u == nil {
 			return nil, accountinterfaces.ErrInvalidUserEmail
 		}
+		if blocked, err := i.loginBlocked(ctx, lockout.UserKey(u.ID()), lockout.DefaultUserPolicy, now); err != nil {
+			return nil, err
+		} else if blocked {
+			return nil, accountinterfaces.ErrTooManyLoginAttempts
+		}
+		target = u
 		matched, err := u.MatchPassword(inp.Password)
 		if err != nil {
 			return nil, err
@@ -99,6 +109,16 @@ func (i *User) GetUserByCredentials(ctx context.Context, inp accountinterfaces.G
 		}
 		return u, nil
 	})
+
+	if errors.Is(err, accountinterfaces.ErrInvalidUserEmail) || errors.Is(err, accountinterfaces.ErrInvalidEmailOrPassword) {
+		if err2 := i.recordLoginFailure(ctx, target, inp.IP, now); err2 != nil {
+			log.Errorfc(ctx, "user: failed to record a login failure: %v", err2)
+		}
+	} else if err == nil && !u.MFAEnabled() {
+		// users with MFA are reset by VerifyMFACode after the second factor
+		i.resetLoginFailures(ctx, u.ID())
+	}
+	return u, err
 }
 
 func (i *User) GetUserBySubject(ctx context.Context, sub string) (u *user.User, err error) {
@@ -316,29 +336,11 @@ func (i *User) StartPasswordReset(ctx context.Context, email string) error {
 			return err
 		}
 
-		var TextOut, HTMLOut bytes.Buffer
-		link := i.authSrvUIDomain + "/?pwd-reset-token=" + pr.Token
-		passwordResetMailContent.UserName = u.Name()
-		passwordResetMailContent.ActionURL = htmlTmpl.URL(link)
-
-		if err := authTextTMPL.Execute(&TextOut, passwordResetMailContent); err != nil {
-			return err
-		}
-		if err := authHTMLTMPL.Execute(&HTMLOut, passwordResetMailContent); err != nil {
-			return err
-		}
-
-		err = i.gateways.Mailer.SendMail(ctx, []mailer.Contact{
-			{
-				Email: u.Email(),
-				Name:  u.Name(),
-			},
-		}, "Password reset", TextOut.String(), HTMLOut.String())
-		if err != nil {
-			return err
-		}
-
-		return nil
+		mctx := mailer.ContextWithIdempotencyKey(ctx, "password-reset:"+pr.Token)
+		return i.sendMail(mctx, u, passwordResetMail, mailContent{
+			UserName:  u.Name(),
+			ActionURL: htmlTmpl.URL(i.authSrvUIDomain + "/?pwd-reset-token=" + pr.Token),
+		})
 	})
 }
 
@@ -0,0 +1,90 @@
+package accountinteractor
+
+import (
+	"context"
+	"errors"
+	htmlTmpl "html/template"
+	"strconv"
+	"time"
+
+	"github.com/reearth/reearthx/account/accountdomain/lockout"
+	"github.com/reearth/reearthx/account/accountdomain/user"
+	"github.com/reearth/reearthx/log"
+	"github.com/reearth/reearthx/mailer"
+	"github.com/reearth/reearthx/rerror"
+)
+
+func (i *User) loginBlocked(ctx context.Context, key lockout.Key, p lockout.Policy, now time.Time) (bool, error) {
+	if i.repos.Lockout == nil {
+		return false, nil
+	}
+	c, err := i.repos.Lockout.FindByKey(ctx, key)
+	if errors.Is(err, rerror.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return c.Blocked(now, p), nil
+}
+
+// recordLoginFailure counts a failed login for the IP and, if the user exists, for the user.
+// The user is notified by email when their account gets locked out.
+func (i *User) recordLoginFailure(ctx context.Context, u *user.User, ip string, now time.Time) error {
+	if i.repos.Lockout == nil {
+		return nil
+	}
+
+	if ip != "" {
+		if _, _, err := i.fail(ctx, lockout.IPKey(ip), lockout.DefaultIPPolicy, now); err != nil {
+			return err
+		}
+	}
+
+	if u == nil {
+		return nil
+	}
+	c, locked, err := i.fail(ctx, lockout.UserKey(u.ID()), lockout.DefaultUserPolicy, now)
+	if err != nil || !locked {
+		return err
+	}
+	return i.sendLockoutMail(ctx, u, *c.LockedUntil())
+}
+
+func (i *User) resetLoginFailures(ctx context.Context, uid user.ID) {
+	if i.repos.Lockout == nil {
+		return
+	}
+	if err := i.repos.Lockout.Remove(ctx, lockout.UserKey(uid)); err != nil {
+		log.Errorfc(ctx, "user: failed to reset login failures: %v", err)
+	}
+}
+
+func (i *User) fail(ctx context.Context, key lockout.Key, p lockout.Policy, now time.Time) (*lockout.Counter, bool, error) {
+	c, err := i.repos.Lockout.FindByKey(ctx, key)
+	if errors.Is(err, rerror.ErrNotFound) {
+		c, err = lockout.New(key), nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	locked := c.Fail(now, p)
+	return c, locked, i.repos.Lockout.Save(ctx, c)
+}
+
+func (i *User) sendLockoutMail(ctx context.Context, u *user.User, lockedUntil time.Time) error {
+	if i.gateways == nil || i.gateways.Mailer == nil {
+		return nil
+	}
+
+	// the mail links to the auth UI, where the user can request a password reset to secure the account.
+	// A reset is not issued here so that locking out the account does not replace a reset the user has requested.
+	actionURL := i.authSrvUIDomain
+
+	// a lockout is notified only once even if the login is retried
+	mctx := mailer.ContextWithIdempotencyKey(ctx, "lockout:"+u.ID().String()+":"+strconv.FormatInt(lockedUntil.Unix(), 10))
+	return i.sendMail(mctx, u, lockoutMail, mailContent{
+		UserName:  u.Name(),
+		ActionURL: htmlTmpl.URL(actionURL),
+	})
+}
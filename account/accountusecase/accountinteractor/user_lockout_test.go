@@ -0,0 +1,187 @@
+package accountinteractor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/reearth/reearthx/account/accountdomain"
+	"github.com/reearth/reearthx/account/accountdomain/lockout"
+	"github.com/reearth/reearthx/account/accountdomain/user"
+	"github.com/reearth/reearthx/account/accountdomain/workspace"
+	"github.com/reearth/reearthx/account/accountinfrastructure/accountmemory"
+	"github.com/reearth/reearthx/account/accountusecase"
+	"github.com/reearth/reearthx/account/accountusecase/accountgateway"
+	"github.com/reearth/reearthx/account/accountusecase/accountinterfaces"
+	"github.com/reearth/reearthx/mailer"
+	"github.com/reearth/reearthx/util"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUser_GetUserByCredentials_Lockout(t *testing.T) {
+	user.DefaultPasswordEncoder = &user.NoopPasswordEncoder{}
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	defer util.MockNow(now)()
+
+	ctx := context.Background()
+	u := user.New().NewID().Workspace(accountdomain.NewWorkspaceID()).
+		Name("NAME").Email("aaa@bbb.com").PasswordPlainText("PAss00!!").
+		Verification(user.VerificationFrom("", now, true)).
+		Auths([]user.Auth{{Provider: user.ProviderReearth, Sub: "reearth|aaa"}}).
+		MustBuild()
+	pr := user.NewPasswordReset()
+	u.SetPasswordReset(pr)
+	r := accountmemory.New()
+	lo.Must0(r.User.Save(ctx, u))
+	m := mailer.NewMock()
+	uc := NewUser(r, &accountgateway.Container{Mailer: m}, "", "https://reearth.io").(*User)
+
+	login := func(password string) error {
+		_, err := uc.GetUserByCredentials(ctx, accountinterfaces.GetUserByCredentials{
+			Email:    "aaa@bbb.com",
+			Password: password,
+			IP:       "127.0.0.1",
+		})
+		return err
+	}
+
+	p := lockout.DefaultUserPolicy
+	for i := 0; i < p.MaxFailures; i++ {
+		assert.Same(t, accountinterfaces.ErrInvalidEmailOrPassword, login("wrong"))
+		// attempts during the backoff are rejected even with the right password
+		assert.Same(t, accountinterfaces.ErrTooManyLoginAttempts, login("PAss00!!"))
+		now = now.Add(p.MaxDelay)
+		defer util.MockNow(now)()
+	}
+
+	c := lo.Must(r.Lockout.FindByKey(ctx, lockout.UserKey(u.ID())))
+	assert.True(t, c.Locked(now))
+	assert.Equal(t, p.MaxFailures, lo.Must(r.Lockout.FindByKey(ctx, lockout.IPKey("127.0.0.1"))).Failures())
+
+	mails := m.Mails()
+	assert.Len(t, mails, 1)
+	assert.Equal(t, "Your account has been locked", mails[0].Subject)
+	assert.Equal(t, []mailer.Contact{{Email: "aaa@bbb.com", Name: "NAME"}}, mails[0].To)
+	assert.Contains(t, mails[0].PlainContent, "https://reearth.io")
+	assert.NotContains(t, mails[0].PlainContent, "pwd-reset-token")
+	// the reset the user requested before the lockout is kept
+	assert.Equal(t, pr, lo.Must(r.User.FindByID(ctx, u.ID())).PasswordReset())
+
+	assert.Same(t, accountinterfaces.ErrTooManyLoginAttempts, login("PAss00!!"))
+
+	// the lockout ends
+	now = now.Add(p.LockoutDuration)
+	defer util.MockNow(now)()
+	assert.NoError(t, login("PAss00!!"))
+	_, err := r.Lockout.FindByKey(ctx, lockout.UserKey(u.ID()))
+	assert.Error(t, err)
+
+	// unknown users are counted per IP
+	_, err = uc.GetUserByCredentials(ctx, accountinterfaces.GetUserByCredentials{
+		Email:    "xxx@bbb.com",
+		Password: "wrong",
+		IP:       "127.0.0.2",
+	})
+	assert.Same(t, accountinterfaces.ErrInvalidUserEmail, err)
+	assert.Equal(t, 1, lo.Must(r.Lockout.FindByKey(ctx, lockout.IPKey("127.0.0.2"))).Failures())
+}
+
+func TestUser_VerifyMFACode_Lockout(t *testing.T) {
+	user.DefaultPasswordEncoder = &user.NoopPasswordEncoder{}
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	defer util.MockNow(now)()
+
+	ctx := context.Background()
+	u := user.New().NewID().Workspace(accountdomain.NewWorkspaceID()).
+		Name("NAME").Email("aaa@bbb.com").PasswordPlainText("PAss00!!").
+		Verification(user.VerificationFrom("", now, true)).
+		MustBuild()
+	r := accountmemory.New()
+	lo.Must0(r.User.Save(ctx, u))
+	uc := NewUser(r, nil, "", "").(*User)
+	op := &accountusecase.Operator{User: lo.ToPtr(u.ID())}
+	e := lo.Must(uc.EnrollMFA(ctx, op))
+	lo.Must(uc.VerifyMFA(ctx, lo.Must(user.TOTPCode(e.Secret, now)), op))
+
+	login := func(password string) error {
+		_, err := uc.GetUserByCredentials(ctx, accountinterfaces.GetUserByCredentials{
+			Email:    "aaa@bbb.com",
+			Password: password,
+		})
+		return err
+	}
+	failures := func() int {
+		c, err := r.Lockout.FindByKey(ctx, lockout.UserKey(u.ID()))
+		if err != nil {
+			return 0
+		}
+		return c.Failures()
+	}
+
+	p := lockout.DefaultUserPolicy
+	assert.Same(t, accountinterfaces.ErrInvalidEmailOrPassword, login("wrong"))
+	now = now.Add(p.MaxDelay)
+	defer util.MockNow(now)()
+
+	// the right password does not reset the failures until the second factor is passed
+	assert.NoError(t, login("PAss00!!"))
+	assert.Equal(t, 1, failures())
+
+	// wrong codes are counted as login failures
+	_, err := uc.VerifyMFACode(ctx, u.ID(), "000000")
+	assert.Same(t, accountinterfaces.ErrInvalidMFACode, err)
+	assert.Equal(t, 2, failures())
+	_, err = uc.VerifyMFACode(ctx, u.ID(), lo.Must(user.TOTPCode(e.Secret, now)))
+	assert.Same(t, accountinterfaces.ErrTooManyLoginAttempts, err, "attempts during the backoff are rejected")
+
+	now = now.Add(p.MaxDelay)
+	defer util.MockNow(now)()
+	_, err = uc.VerifyMFACode(ctx, u.ID(), lo.Must(user.TOTPCode(e.Secret, now)))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, failures())
+
+	// the account gets locked by wrong codes
+	for i := 0; i < p.MaxFailures; i++ {
+		_, err = uc.VerifyMFACode(ctx, u.ID(), "000000")
+		assert.Same(t, accountinterfaces.ErrInvalidMFACode, err)
+		now = now.Add(p.MaxDelay)
+		defer util.MockNow(now)()
+	}
+	assert.True(t, lo.Must(r.Lockout.FindByKey(ctx, lockout.UserKey(u.ID()))).Locked(now))
+	assert.Same(t, accountinterfaces.ErrTooManyLoginAttempts, login("PAss00!!"))
+}
+
+func TestWorkspace_UnlockUserMember(t *testing.T) {
+	ctx := context.Background()
+	owner := accountdomain.NewUserID()
+	member := accountdomain.NewUserID()
+	other := accountdomain.NewUserID()
+	ws := workspace.New().NewID().Name("W").Members(map[user.ID]workspace.Member{
+		owner:  {Role: workspace.RoleOwner},
+		member: {Role: workspace.RoleReader},
+	}).MustBuild()
+
+	r := accountmemory.New()
+	lo.Must0(r.Workspace.Save(ctx, ws))
+	now := time.Now()
+	lo.Must0(r.Lockout.Save(ctx, lockout.From(lockout.UserKey(member), 5, now, &now)))
+	uc := NewWorkspace(r, nil)
+
+	err := uc.UnlockUserMember(ctx, ws.ID(), member, &accountusecase.Operator{
+		User:               &member,
+		ReadableWorkspaces: workspace.IDList{ws.ID()},
+	})
+	assert.Same(t, accountinterfaces.ErrOperationDenied, err)
+
+	ownerOp := &accountusecase.Operator{
+		User:             &owner,
+		OwningWorkspaces: workspace.IDList{ws.ID()},
+	}
+	err = uc.UnlockUserMember(ctx, ws.ID(), other, ownerOp)
+	assert.Same(t, workspace.ErrTargetUserNotInTheWorkspace, err)
+
+	assert.NoError(t, uc.UnlockUserMember(ctx, ws.ID(), member, ownerOp))
+	_, err = r.Lockout.FindByKey(ctx, lockout.UserKey(member))
+	assert.Error(t, err)
+}
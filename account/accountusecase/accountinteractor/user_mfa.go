@@ -0,0 +1,149 @@
+package accountinteractor
+
+import (
+	"context"
+	"errors"
+
+	"github.com/reearth/reearthx/account/accountdomain/lockout"
+	"github.com/reearth/reearthx/account/accountdomain/user"
+	"github.com/reearth/reearthx/account/accountusecase"
+	"github.com/reearth/reearthx/account/accountusecase/accountinterfaces"
+	"github.com/reearth/reearthx/log"
+	"github.com/reearth/reearthx/util"
+)
+
+const mfaIssuer = "Re:Earth"
+
+func (i *User) EnrollMFA(ctx context.Context, operator *accountusecase.Operator) (*accountinterfaces.MFAEnrollment, error) {
+	if operator.User == nil {
+		return nil, accountinterfaces.ErrInvalidOperator
+	}
+
+	return Run1(ctx, operator, i.repos, Usecase().Transaction(), func(ctx context.Context) (*accountinterfaces.MFAEnrollment, error) {
+		u, err := i.repos.User.FindByID(ctx, *operator.User)
+		if err != nil {
+			return nil, err
+		}
+		if u.MFAEnabled() {
+			return nil, user.ErrMFAAlreadyEnabled
+		}
+
+		// a pending enrollment is replaced with a new secret
+		m, err := user.NewMFA()
+		if err != nil {
+			return nil, err
+		}
+		u.SetMFA(m)
+
+		if err := i.repos.User.Save(ctx, u); err != nil {
+			return nil, err
+		}
+
+		return &accountinterfaces.MFAEnrollment{
+			Secret: m.Secret(),
+			URI:    m.URI(mfaIssuer, u.Email()),
+		}, nil
+	})
+}
+
+func (i *User) VerifyMFA(ctx context.Context, code string, operator *accountusecase.Operator) ([]string, error) {
+	if operator.User == nil {
+		return nil, accountinterfaces.ErrInvalidOperator
+	}
+
+	return Run1(ctx, operator, i.repos, Usecase().Transaction(), func(ctx context.Context) ([]string, error) {
+		u, err := i.repos.User.FindByID(ctx, *operator.User)
+		if err != nil {
+			return nil, err
+		}
+
+		codes, err := u.MFA().Enable(code, util.Now())
+		if err != nil {
+			if errors.Is(err, user.ErrInvalidMFACode) {
+				return nil, accountinterfaces.ErrInvalidMFACode
+			}
+			return nil, err
+		}
+
+		if err := i.repos.User.Save(ctx, u); err != nil {
+			return nil, err
+		}
+
+		return codes, nil
+	})
+}
+
+func (i *User) DisableMFA(ctx context.Context, code string, operator *accountusecase.Operator) (*user.User, error) {
+	if operator.User == nil {
+		return nil, accountinterfaces.ErrInvalidOperator
+	}
+
+	return Run1(ctx, operator, i.repos, Usecase().Transaction(), func(ctx context.Context) (*user.User, error) {
+		u, err := i.repos.User.FindByID(ctx, *operator.User)
+		if err != nil {
+			return nil, err
+		}
+		if !u.MFAEnabled() {
+			return nil, user.ErrMFANotEnabled
+		}
+		if m := u.MFA(); !m.Verify(code, util.Now()) && !m.UseRecoveryCode(code) {
+			return nil, accountinterfaces.ErrInvalidMFACode
+		}
+
+		u.SetMFA(nil)
+
+		if err := i.repos.User.Save(ctx, u); err != nil {
+			return nil, err
+		}
+
+		return u, nil
+	})
+}
+
+func (i *User) VerifyMFACode(ctx context.Context, id user.ID, code string) (bool, error) {
+	now := util.Now()
+	if blocked, err := i.loginBlocked(ctx, lockout.UserKey(id), lockout.DefaultUserPolicy, now); err != nil {
+		return false, err
+	} else if blocked {
+		return false, accountinterfaces.ErrTooManyLoginAttempts
+	}
+
+	// failures are recorded outside of the transaction so that they are not rolled back
+	var target *user.User
+	recovery, err := Run1(ctx, nil, i.repos, Usecase().Transaction(), func(ctx context.Context) (bool, error) {
+		u, err := i.repos.User.FindByID(ctx, id)
+		if err != nil {
+			return false, err
+		}
+		if !u.MFAEnabled() {
+			return false, user.ErrMFANotEnabled
+		}
+		target = u
+
+		m := u.MFA()
+		recovery := false
+		if !m.Verify(code, now) {
+			if !m.UseRecoveryCode(code) {
+				return false, accountinterfaces.ErrInvalidMFACode
+			}
+			recovery = true
+		}
+
+		// the last used step and the recovery codes are updated to prevent replays
+		if err := i.repos.User.Save(ctx, u); err != nil {
+			return false, err
+		}
+
+		return recovery, nil
+	})
+
+	// the failures of the password step are kept until the second factor is also passed
+	if errors.Is(err, accountinterfaces.ErrInvalidMFACode) {
+		if err2 := i.recordLoginFailure(ctx, target, "", now); err2 != nil {
+			log.Errorfc(ctx, "user: failed to record a login failure: %v", err2)
+		}
+	} else if err == nil {
+		i.resetLoginFailures(ctx, id)
+	}
+	return recovery, err
+}
@@ -0,0 +1,80 @@
+package accountinteractor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/reearth/reearthx/account/accountdomain"
+	"github.com/reearth/reearthx/account/accountdomain/user"
+	"github.com/reearth/reearthx/account/accountinfrastructure/accountmemory"
+	"github.com/reearth/reearthx/account/accountusecase"
+	"github.com/reearth/reearthx/account/accountusecase/accountinterfaces"
+	"github.com/reearth/reearthx/util"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUser_MFA(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	defer util.MockNow(now)()
+
+	ctx := context.Background()
+	u := user.New().NewID().Workspace(accountdomain.NewWorkspaceID()).Name("NAME").Email("aaa@bbb.com").MustBuild()
+	r := accountmemory.New()
+	lo.Must0(r.User.Save(ctx, u))
+	uc := NewUser(r, nil, "", "")
+	op := &accountusecase.Operator{User: lo.ToPtr(u.ID())}
+
+	_, err := uc.EnrollMFA(ctx, &accountusecase.Operator{})
+	assert.Same(t, accountinterfaces.ErrInvalidOperator, err)
+
+	_, err = uc.VerifyMFA(ctx, "000000", op)
+	assert.Same(t, user.ErrMFANotEnrolled, err)
+
+	// enroll
+	e, err := uc.EnrollMFA(ctx, op)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, e.Secret)
+	assert.Contains(t, e.URI, "otpauth://totp/Re:Earth:aaa@bbb.com?")
+
+	_, err = uc.VerifyMFACode(ctx, u.ID(), "000000")
+	assert.Same(t, user.ErrMFANotEnabled, err)
+
+	_, err = uc.VerifyMFA(ctx, "000000", op)
+	assert.Same(t, accountinterfaces.ErrInvalidMFACode, err)
+
+	code := lo.Must(user.TOTPCode(e.Secret, now))
+	recoveryCodes, err := uc.VerifyMFA(ctx, code, op)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, recoveryCodes)
+
+	_, err = uc.EnrollMFA(ctx, op)
+	assert.Same(t, user.ErrMFAAlreadyEnabled, err)
+
+	// login
+	_, err = uc.VerifyMFACode(ctx, u.ID(), code)
+	assert.Same(t, accountinterfaces.ErrInvalidMFACode, err, "the code is already used")
+
+	next := now.Add(30 * time.Second)
+	defer util.MockNow(next)()
+	recovery, err := uc.VerifyMFACode(ctx, u.ID(), lo.Must(user.TOTPCode(e.Secret, next)))
+	assert.NoError(t, err)
+	assert.False(t, recovery)
+
+	recovery, err = uc.VerifyMFACode(ctx, u.ID(), recoveryCodes[0])
+	assert.NoError(t, err)
+	assert.True(t, recovery)
+
+	_, err = uc.VerifyMFACode(ctx, u.ID(), recoveryCodes[0])
+	assert.Same(t, accountinterfaces.ErrInvalidMFACode, err)
+
+	// disable
+	_, err = uc.DisableMFA(ctx, "000000", op)
+	assert.Same(t, accountinterfaces.ErrInvalidMFACode, err)
+
+	got, err := uc.DisableMFA(ctx, recoveryCodes[1], op)
+	assert.NoError(t, err)
+	assert.False(t, got.MFAEnabled())
+	assert.False(t, lo.Must(r.User.FindByID(ctx, u.ID())).MFAEnabled())
+}
@@ -1,9 +1,7 @@
 package accountinteractor
 
 import (
-	"bytes"
 	"context"
-	_ "embed"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -12,7 +10,6 @@ import (
 	"net/url"
 	"path"
 	"strings"
-	textTmpl "text/template"
 	"time"
 
 	"github.com/reearth/reearthx/account/accountdomain/user"
@@ -20,20 +17,11 @@ import (
 	"github.com/reearth/reearthx/account/accountusecase/accountinterfaces"
 	"github.com/reearth/reearthx/account/accountusecase/accountrepo"
 	"github.com/reearth/reearthx/i18n"
-	"github.com/reearth/reearthx/log"
 	"github.com/reearth/reearthx/mailer"
 	"github.com/reearth/reearthx/rerror"
 	"github.com/samber/lo"
 )
 
-type mailContent struct {
-	UserName    string
-	Message     string
-	Suffix      string
-	ActionLabel string
-	ActionURL   htmlTmpl.URL
-}
-
 type OpenIDConfiguration struct {
 	UserinfoEndpoint string `json:"userinfo_endpoint"`
 }
@@ -46,29 +34,7 @@ type UserInfo struct {
 	Error    string `json:"error"`
 }
 
-var (
-	//go:embed emails/auth_html.tmpl
-	autHTMLTMPLStr string
-	//go:embed emails/auth_text.tmpl
-	authTextTMPLStr string
-
-	authTextTMPL *textTmpl.Template
-	authHTMLTMPL *htmlTmpl.Template
-
-	httpClient = &http.Client{Timeout: 30 * time.Second}
-)
-
-func init() {
-	var err error
-	authTextTMPL, err = textTmpl.New("passwordReset").Parse(authTextTMPLStr)
-	if err != nil {
-		log.Panicf("password reset email template parse error: %s\n", err)
-	}
-	authHTMLTMPL, err = htmlTmpl.New("passwordReset").Parse(autHTMLTMPLStr)
-	if err != nil {
-		log.Panicf("password reset email template parse error: %s\n", err)
-	}
-}
+var httpClient = &http.Client{Timeout: 30 * time.Second}
 
 func (i *User) Signup(ctx context.Context, param accountinterfaces.SignupParam) (u *user.User, err error) {
 	if err := i.verifySignupSecret(param.Secret); err != nil {
@@ -219,38 +185,15 @@ func (i *User) FindOrCreate(ctx context.Context, param accountinterfaces.UserFin
 }
 
 func (i *User) sendVerificationMail(ctx context.Context, u *user.User, vr *user.Verification) error {
-	var text, html bytes.Buffer
-	link := i.authSrvUIDomain + "/?user-verification-token=" + vr.Code()
-	signupMailContent := mailContent{
-		Message:     "Thank you for signing up to Re:Earth. Please verify your email address by clicking the button below.",
-		Suffix:      "You can use this email address to log in to Re:Earth account anytime.",
-		ActionLabel: "Activate your account and log in",
-		UserName:    u.Email(),
-		ActionURL:   htmlTmpl.URL(link),
-	}
-	if err := authTextTMPL.Execute(&text, signupMailContent); err != nil {
-		return err
-	}
-	if err := authHTMLTMPL.Execute(&html, signupMailContent); err != nil {
-		return err
-	}
-
-	if err := i.gateways.Mailer.SendMail(
-		ctx,
-		[]mailer.Contact{
-			{
-				Email: u.Email(),
-				Name:  u.Name(),
-			},
+	return i.sendMail(
+		mailer.ContextWithIdempotencyKey(ctx, "verification:"+vr.Code()),
+		u,
+		verificationMail,
+		mailContent{
+			UserName:  u.Email(),
+			ActionURL: htmlTmpl.URL(i.authSrvUIDomain + "/?user-verification-token=" + vr.Code()),
 		},
-		"email verification",
-		text.String(),
-		html.String(),
-	); err != nil {
-		return err
-	}
-
-	return nil
+	)
 }
 
 func getUserInfoFromISS(ctx context.Context, iss, accessToken string) (UserInfo, error) {
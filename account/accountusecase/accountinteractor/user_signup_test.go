@@ -194,7 +194,7 @@ func TestUser_Signup(t *testing.T) {
 				Metadata(workspace.NewMetadata()).
 				MustBuild(),
 			wantMailTo:      []mailer.Contact{{Email: "aaa@bbb.com", Name: "NAME"}},
-			wantMailSubject: "email verification",
+			wantMailSubject: "メールアドレスの確認",
 			wantMailContent: "/?user-verification-token=CODECODE",
 			wantError:       nil,
 		},
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/reearth/reearthx/account/accountdomain"
+	"github.com/reearth/reearthx/account/accountdomain/lockout"
 	"github.com/reearth/reearthx/account/accountdomain/permittable"
 	"github.com/reearth/reearthx/account/accountdomain/role"
 	"github.com/reearth/reearthx/account/accountdomain/user"
@@ -412,6 +413,28 @@ func (i *Workspace) Remove(ctx context.Context, id workspace.ID, operator *accou
 	})
 }
 
+func (i *Workspace) UnlockUserMember(ctx context.Context, id workspace.ID, u workspace.UserID, operator *accountusecase.Operator) error {
+	if operator.User == nil {
+		return accountinterfaces.ErrInvalidOperator
+	}
+
+	return Run0(ctx, operator, i.repos, Usecase().WithOwnableWorkspaces(id), func(ctx context.Context) error {
+		ws, err := i.repos.Workspace.FindByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if !ws.Members().HasUser(u) {
+			return workspace.ErrTargetUserNotInTheWorkspace
+		}
+
+		if i.repos.Lockout == nil {
+			return nil
+		}
+		return i.repos.Lockout.Remove(ctx, lockout.UserKey(u))
+	})
+}
+
 func (i *Workspace) applyDefaultPolicy(ws *workspace.Workspace, o *accountusecase.Operator) {
 	if ws.Policy() == nil && o.DefaultPolicy != nil {
 		ws.SetPolicy(o.DefaultPolicy)
@@ -20,6 +20,8 @@ var (
 	ErrNotVerifiedUser                 = rerror.NewE(i18n.T("not verified user"))
 	ErrInvalidEmailOrPassword          = rerror.NewE(i18n.T("invalid email or password"))
 	ErrUserAlreadyExists               = rerror.NewE(i18n.T("user already exists"))
+	ErrInvalidMFACode                  = rerror.NewE(i18n.T("invalid mfa code"))
+	ErrTooManyLoginAttempts            = rerror.NewE(i18n.T("too many login attempts"))
 )
 
 type SignupOIDCParam struct {
@@ -60,6 +62,8 @@ type UserFindOrCreateParam struct {
 type GetUserByCredentials struct {
 	Email    string
 	Password string
+	// IP is the client IP address used to limit failed attempts per IP. It is optional.
+	IP string
 }
 
 type UpdateMeParam struct {
@@ -71,6 +75,12 @@ type UpdateMeParam struct {
 	PasswordConfirmation *string
 }
 
+type MFAEnrollment struct {
+	Secret string
+	// URI is the otpauth URI to be shown as a QR code.
+	URI string
+}
+
 type UserQuery interface {
 	FetchByID(context.Context, user.IDList) (user.List, error)
 	FetchBySub(context.Context, string) (*user.User, error)
@@ -90,9 +100,19 @@ type User interface {
 	RemoveMyAuth(context.Context, string, *accountusecase.Operator) (*user.User, error)
 	DeleteMe(context.Context, user.ID, *accountusecase.Operator) error
 
+	// multi-factor authentication
+	EnrollMFA(context.Context, *accountusecase.Operator) (*MFAEnrollment, error)
+	// VerifyMFA confirms the enrollment with a TOTP code and returns recovery codes.
+	VerifyMFA(context.Context, string, *accountusecase.Operator) ([]string, error)
+	// DisableMFA disables MFA with a TOTP code or a recovery code.
+	DisableMFA(context.Context, string, *accountusecase.Operator) (*user.User, error)
+
 	// built-in auth server
 	CreateVerification(context.Context, string) error
 	VerifyUser(context.Context, string) (*user.User, error)
 	StartPasswordReset(context.Context, string) error
 	PasswordReset(context.Context, string, string) error
+	// VerifyMFACode checks a TOTP code or a recovery code of the user as the second login step.
+	// It returns true if a recovery code was used.
+	VerifyMFACode(context.Context, user.ID, string) (bool, error)
 }
@@ -31,4 +31,6 @@ type Workspace interface {
 	RemoveIntegration(context.Context, workspace.ID, workspace.IntegrationID, *accountusecase.Operator) (*workspace.Workspace, error)
 	RemoveIntegrations(context.Context, workspace.ID, workspace.IntegrationIDList, *accountusecase.Operator) (*workspace.Workspace, error)
 	Remove(context.Context, workspace.ID, *accountusecase.Operator) error
+	// UnlockUserMember clears the login lockout of a member. Only owners of the workspace can do it.
+	UnlockUserMember(context.Context, workspace.ID, user.ID, *accountusecase.Operator) error
 }
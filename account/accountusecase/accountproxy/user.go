@@ -12,6 +12,7 @@ import (
 	"github.com/Khan/genqlient/graphql"
 	"github.com/reearth/reearthx/account/accountusecase"
 	"github.com/reearth/reearthx/account/accountusecase/accountinterfaces"
+	"github.com/reearth/reearthx/rerror"
 )
 
 type User struct {
@@ -173,3 +174,19 @@ func (u *User) PasswordReset(ctx context.Context, password string, token string)
 	}
 	return nil
 }
+
+func (u *User) EnrollMFA(ctx context.Context, operator *accountusecase.Operator) (*accountinterfaces.MFAEnrollment, error) {
+	return nil, rerror.ErrNotImplemented
+}
+
+func (u *User) VerifyMFA(ctx context.Context, code string, operator *accountusecase.Operator) ([]string, error) {
+	return nil, rerror.ErrNotImplemented
+}
+
+func (u *User) DisableMFA(ctx context.Context, code string, operator *accountusecase.Operator) (*user.User, error) {
+	return nil, rerror.ErrNotImplemented
+}
+
+func (u *User) VerifyMFACode(ctx context.Context, id user.ID, code string) (bool, error) {
+	return false, rerror.ErrNotImplemented
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/reearth/reearthx/account/accountdomain/workspace"
 	"github.com/reearth/reearthx/account/accountusecase"
 	"github.com/reearth/reearthx/account/accountusecase/accountinterfaces"
+	"github.com/reearth/reearthx/rerror"
 	"github.com/samber/lo"
 )
 
@@ -135,3 +136,7 @@ func (w *Workspace) Remove(ctx context.Context, id workspace.ID, op *accountusec
 	}
 	return nil
 }
+
+func (w *Workspace) UnlockUserMember(ctx context.Context, id workspace.ID, userID accountdomain.UserID, op *accountusecase.Operator) error {
+	return rerror.ErrNotImplemented
+}
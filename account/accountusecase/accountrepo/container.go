@@ -14,6 +14,7 @@ type Container struct {
 	Workspace   Workspace
 	Role        Role        // TODO: Delete this once the permission check migration is complete.
 	Permittable Permittable // TODO: Delete this once the permission check migration is complete.
+	Lockout     Lockout
 	Transaction usecasex.Transaction
 	Users       []User
 }
@@ -32,6 +33,7 @@ func (c *Container) Filtered(workspace WorkspaceFilter) *Container {
 		Users:       c.Users,
 		Role:        c.Role,        // TODO: Delete this once the permission check migration is complete.
 		Permittable: c.Permittable, // TODO: Delete this once the permission check migration is complete.
+		Lockout:     c.Lockout,
 		Transaction: c.Transaction,
 	}
 }
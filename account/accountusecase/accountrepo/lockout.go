@@ -0,0 +1,13 @@
+package accountrepo
+
+import (
+	"context"
+
+	"github.com/reearth/reearthx/account/accountdomain/lockout"
+)
+
+type Lockout interface {
+	FindByKey(context.Context, lockout.Key) (*lockout.Counter, error)
+	Save(context.Context, *lockout.Counter) error
+	Remove(context.Context, lockout.Key) error
+}
@@ -0,0 +1,356 @@
+package appx
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/reearth/reearthx/log"
+	"github.com/reearth/reearthx/rerror"
+	"github.com/reearth/reearthx/util"
+)
+
+const (
+	DefaultAPITokenPrefix = "pat_"
+	apiTokenHeader        = "X-API-Key"
+	// lastUsedInterval throttles updates of APIToken.LastUsedAt not to write to the store on every request.
+	lastUsedInterval = time.Minute
+)
+
+var (
+	ErrInvalidAPIToken   = errors.New("invalid api token")
+	ErrAPITokenRevoked   = errors.New("api token revoked")
+	ErrAPITokenExpired   = errors.New("api token expired")
+	ErrInsufficientScope = errors.New("insufficient scope")
+)
+
+// APIToken is a long-lived personal access token. Only the hash of the token is stored.
+type APIToken struct {
+	ID         string
+	UserID     string
+	Name       string
+	Hash       string
+	Scopes     []string
+	CreatedAt  time.Time
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+}
+
+// HasScope returns true if the token allows the scope. A token without scopes allows all scopes.
+func (t *APIToken) HasScope(scope string) bool {
+	return t != nil && (len(t.Scopes) == 0 || slices.Contains(t.Scopes, scope))
+}
+
+func (t *APIToken) IsRevoked() bool {
+	return t != nil && t.RevokedAt != nil
+}
+
+func (t *APIToken) IsExpired(now time.Time) bool {
+	return t != nil && t.ExpiresAt != nil && !now.Before(*t.ExpiresAt)
+}
+
+// APITokenStore stores APITokens. Find methods return rerror.ErrNotFound if the token does not exist.
+type APITokenStore interface {
+	FindByID(ctx context.Context, id string) (*APIToken, error)
+	FindByHash(ctx context.Context, hash string) (*APIToken, error)
+	FindByUser(ctx context.Context, userID string) ([]*APIToken, error)
+	Save(ctx context.Context, token *APIToken) error
+	UpdateLastUsed(ctx context.Context, id string, t time.Time) error
+}
+
+// HashAPIToken returns the hash of the raw token that is stored in APITokenStore.
+// Tokens are random with enough entropy, so a fast hash is sufficient.
+func HashAPIToken(token string) string {
+	h := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(h[:])
+}
+
+// APITokens issues, revokes and authenticates APITokens.
+type APITokens struct {
+	Store APITokenStore
+	// Prefix is prepended to raw tokens to tell them from JWTs. It defaults to DefaultAPITokenPrefix.
+	Prefix string
+}
+
+func NewAPITokens(store APITokenStore) *APITokens {
+	return &APITokens{Store: store}
+}
+
+type IssueAPITokenParam struct {
+	UserID    string
+	Name      string
+	Scopes    []string
+	ExpiresAt *time.Time
+}
+
+// Issue creates a new token and returns it with the raw token. The raw token cannot be retrieved later.
+func (a *APITokens) Issue(ctx context.Context, param IssueAPITokenParam) (*APIToken, string, error) {
+	if param.UserID == "" {
+		return nil, "", rerror.ErrInvalidParams
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, "", rerror.ErrInternalByWithContext(ctx, err)
+	}
+	raw := a.prefix() + base64.RawURLEncoding.EncodeToString(b)
+
+	t := &APIToken{
+		ID:        uuid.NewString(),
+		UserID:    param.UserID,
+		Name:      param.Name,
+		Hash:      HashAPIToken(raw),
+		Scopes:    slices.Clone(param.Scopes),
+		CreatedAt: util.Now(),
+		ExpiresAt: param.ExpiresAt,
+	}
+	if err := a.Store.Save(ctx, t); err != nil {
+		return nil, "", err
+	}
+	return t, raw, nil
+}
+
+// Revoke revokes the token of the user. Revoking a revoked token does nothing.
+func (a *APITokens) Revoke(ctx context.Context, id, userID string) error {
+	t, err := a.Store.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if t.UserID != userID {
+		return rerror.ErrNotFound
+	}
+	if t.IsRevoked() {
+		return nil
+	}
+	now := util.Now()
+	t.RevokedAt = &now
+	return a.Store.Save(ctx, t)
+}
+
+// List returns the tokens of the user.
+func (a *APITokens) List(ctx context.Context, userID string) ([]*APIToken, error) {
+	return a.Store.FindByUser(ctx, userID)
+}
+
+// IsAPIToken returns true if the raw token looks like a token issued by Issue.
+func (a *APITokens) IsAPIToken(raw string) bool {
+	return strings.HasPrefix(raw, a.prefix())
+}
+
+// Authenticate finds the token by the raw token and records that it is used.
+func (a *APITokens) Authenticate(ctx context.Context, raw string) (*APIToken, error) {
+	if !a.IsAPIToken(raw) {
+		return nil, ErrInvalidAPIToken
+	}
+
+	t, err := a.Store.FindByHash(ctx, HashAPIToken(raw))
+	if err != nil {
+		if errors.Is(err, rerror.ErrNotFound) {
+			return nil, ErrInvalidAPIToken
+		}
+		return nil, err
+	}
+
+	now := util.Now()
+	if t.IsRevoked() {
+		return nil, ErrAPITokenRevoked
+	}
+	if t.IsExpired(now) {
+		return nil, ErrAPITokenExpired
+	}
+
+	if t.LastUsedAt == nil || now.Sub(*t.LastUsedAt) >= lastUsedInterval {
+		if err := a.Store.UpdateLastUsed(ctx, t.ID, now); err != nil {
+			// authentication should not fail only because of tracking
+			log.Errorfc(ctx, "auth: failed to update last used time of api token %s: %v", t.ID, err)
+		} else {
+			t.LastUsedAt = &now
+		}
+	}
+	return t, nil
+}
+
+func (a *APITokens) prefix() string {
+	if a.Prefix == "" {
+		return DefaultAPITokenPrefix
+	}
+	return a.Prefix
+}
+
+// APITokenMiddleware authenticates requests with API tokens in the Authorization header as a bearer token or the X-API-Key header,
+// and attaches AuthInfo to the context. Requests with other tokens are passed to the next handler as they are.
+func APITokenMiddleware(a *APITokens, key any) func(http.Handler) http.Handler {
+	if key == nil {
+		key = authInfoKey{}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			raw := apiTokenFromRequest(r)
+			if raw == "" || !a.IsAPIToken(raw) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			t, err := a.Authenticate(r.Context(), raw)
+			if err != nil {
+				log.Debugfc(r.Context(), "auth: invalid api token: %v", err)
+				http.Error(w, "invalid api token", http.StatusUnauthorized)
+				return
+			}
+
+			authInfo := AuthInfo{
+				Token:      raw,
+				Sub:        t.UserID,
+				APITokenID: t.ID,
+			}
+			if len(t.Scopes) > 0 {
+				authInfo.Scopes = slices.Clone(t.Scopes)
+			}
+			ctx := context.WithValue(r.Context(), key, authInfo)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// AuthMiddlewareWithAPITokens authenticates requests with either API tokens or JWTs of the providers.
+func AuthMiddlewareWithAPITokens(providers []JWTProvider, a *APITokens, key any, optional bool) (func(http.Handler) http.Handler, error) {
+	jwtm, err := AuthMiddleware(providers, key, optional)
+	if err != nil {
+		return nil, err
+	}
+	tm := APITokenMiddleware(a, key)
+
+	return func(next http.Handler) http.Handler {
+		jwtNext := jwtm(next)
+		return tm(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if raw := apiTokenFromRequest(r); raw != "" && a.IsAPIToken(raw) {
+				// already authenticated by the api token
+				next.ServeHTTP(w, r)
+				return
+			}
+			jwtNext.ServeHTTP(w, r)
+		}))
+	}, nil
+}
+
+// RequireScopeMiddleware rejects requests whose AuthInfo does not have the scope.
+func RequireScopeMiddleware(scope string, key any) func(http.Handler) http.Handler {
+	if key == nil {
+		key = authInfoKey{}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			a, ok := r.Context().Value(key).(AuthInfo)
+			if !ok {
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+			if !a.HasScope(scope) {
+				http.Error(w, ErrInsufficientScope.Error(), http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func apiTokenFromRequest(r *http.Request) string {
+	if t := r.Header.Get(apiTokenHeader); t != "" {
+		return t
+	}
+	if t, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return t
+	}
+	return ""
+}
+
+// MemoryAPITokenStore is an in-memory APITokenStore for tests and development.
+type MemoryAPITokenStore struct {
+	lock   sync.Mutex
+	tokens map[string]*APIToken
+}
+
+var _ APITokenStore = (*MemoryAPITokenStore)(nil)
+
+func NewMemoryAPITokenStore() *MemoryAPITokenStore {
+	return &MemoryAPITokenStore{tokens: map[string]*APIToken{}}
+}
+
+func (s *MemoryAPITokenStore) FindByID(_ context.Context, id string) (*APIToken, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if t, ok := s.tokens[id]; ok {
+		return copyAPIToken(t), nil
+	}
+	return nil, rerror.ErrNotFound
+}
+
+func (s *MemoryAPITokenStore) FindByHash(_ context.Context, hash string) (*APIToken, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, t := range s.tokens {
+		if t.Hash == hash {
+			return copyAPIToken(t), nil
+		}
+	}
+	return nil, rerror.ErrNotFound
+}
+
+func (s *MemoryAPITokenStore) FindByUser(_ context.Context, userID string) ([]*APIToken, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var res []*APIToken
+	for _, t := range s.tokens {
+		if t.UserID == userID {
+			res = append(res, copyAPIToken(t))
+		}
+	}
+	slices.SortFunc(res, func(a, b *APIToken) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return res, nil
+}
+
+func (s *MemoryAPITokenStore) Save(_ context.Context, t *APIToken) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.tokens[t.ID] = copyAPIToken(t)
+	return nil
+}
+
+func (s *MemoryAPITokenStore) UpdateLastUsed(_ context.Context, id string, at time.Time) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	t, ok := s.tokens[id]
+	if !ok {
+		return rerror.ErrNotFound
+	}
+	t.LastUsedAt = &at
+	return nil
+}
+
+func copyAPIToken(t *APIToken) *APIToken {
+	c := *t
+	c.Scopes = slices.Clone(t.Scopes)
+	return &c
+}
@@ -0,0 +1,183 @@
+package appx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/reearth/reearthx/rerror"
+	"github.com/reearth/reearthx/util"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPITokens(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	defer util.MockNow(now)()
+
+	store := NewMemoryAPITokenStore()
+	a := NewAPITokens(store)
+
+	_, _, err := a.Issue(ctx, IssueAPITokenParam{})
+	assert.Same(t, rerror.ErrInvalidParams, err)
+
+	token, raw, err := a.Issue(ctx, IssueAPITokenParam{UserID: "user1", Name: "ci", Scopes: []string{"read"}})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(raw, DefaultAPITokenPrefix))
+	assert.True(t, a.IsAPIToken(raw))
+	assert.Equal(t, HashAPIToken(raw), token.Hash)
+	assert.NotContains(t, token.Hash, raw)
+	assert.Equal(t, now, token.CreatedAt)
+	assert.True(t, token.HasScope("read"))
+	assert.False(t, token.HasScope("write"))
+
+	// authenticate and track the last used time
+	got, err := a.Authenticate(ctx, raw)
+	require.NoError(t, err)
+	assert.Equal(t, token.ID, got.ID)
+	assert.Equal(t, &now, got.LastUsedAt)
+
+	_, err = a.Authenticate(ctx, raw+"x")
+	assert.Same(t, ErrInvalidAPIToken, err)
+	_, err = a.Authenticate(ctx, "jwt")
+	assert.Same(t, ErrInvalidAPIToken, err)
+
+	tokens, err := a.List(ctx, "user1")
+	require.NoError(t, err)
+	assert.Len(t, tokens, 1)
+	assert.Equal(t, &now, tokens[0].LastUsedAt)
+
+	// revoke
+	assert.Same(t, rerror.ErrNotFound, a.Revoke(ctx, token.ID, "user2"))
+	assert.NoError(t, a.Revoke(ctx, token.ID, "user1"))
+	assert.NoError(t, a.Revoke(ctx, token.ID, "user1"))
+	_, err = a.Authenticate(ctx, raw)
+	assert.Same(t, ErrAPITokenRevoked, err)
+
+	// expiration
+	expiresAt := now.Add(time.Hour)
+	_, raw2, err := a.Issue(ctx, IssueAPITokenParam{UserID: "user1", ExpiresAt: &expiresAt})
+	require.NoError(t, err)
+	_, err = a.Authenticate(ctx, raw2)
+	assert.NoError(t, err)
+	util.MockNow(expiresAt)
+	_, err = a.Authenticate(ctx, raw2)
+	assert.Same(t, ErrAPITokenExpired, err)
+}
+
+type failingLastUsedStore struct {
+	*MemoryAPITokenStore
+}
+
+func (failingLastUsedStore) UpdateLastUsed(context.Context, string, time.Time) error {
+	return errors.New("failed")
+}
+
+func TestAPITokens_Authenticate_LastUsedFailure(t *testing.T) {
+	ctx := context.Background()
+	a := NewAPITokens(failingLastUsedStore{NewMemoryAPITokenStore()})
+	_, raw, err := a.Issue(ctx, IssueAPITokenParam{UserID: "user1"})
+	require.NoError(t, err)
+
+	got, err := a.Authenticate(ctx, raw)
+	assert.NoError(t, err)
+	assert.Nil(t, got.LastUsedAt)
+}
+
+func TestAPITokenMiddleware(t *testing.T) {
+	ctx := context.Background()
+	a := NewAPITokens(NewMemoryAPITokenStore())
+	_, raw, err := a.Issue(ctx, IssueAPITokenParam{UserID: "user1", Scopes: []string{"read"}})
+	require.NoError(t, err)
+
+	h := APITokenMiddleware(a, nil)(RequireScopeMiddleware("read", nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ai := GetAuthInfoFromContext(r.Context())
+		_, _ = w.Write([]byte(ai.Sub + " " + strings.Join(ai.Scopes, ",")))
+	})))
+	ts := httptest.NewServer(h)
+	defer ts.Close()
+
+	do := func(header, value string) (int, string) {
+		req := lo.Must(http.NewRequest(http.MethodGet, ts.URL, nil))
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		res := lo.Must(http.DefaultClient.Do(req))
+		defer func() { _ = res.Body.Close() }()
+		return res.StatusCode, string(lo.Must(io.ReadAll(res.Body)))
+	}
+
+	status, body := do("Authorization", "Bearer "+raw)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "user1 read", body)
+
+	status, body = do("X-API-Key", raw)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "user1 read", body)
+
+	status, _ = do("Authorization", "Bearer "+DefaultAPITokenPrefix+"invalid")
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	// not an api token and not authenticated
+	status, _ = do("Authorization", "Bearer jwt")
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	_, raw2, err := a.Issue(ctx, IssueAPITokenParam{UserID: "user2", Scopes: []string{"write"}})
+	require.NoError(t, err)
+	status, body = do("Authorization", "Bearer "+raw2)
+	assert.Equal(t, http.StatusForbidden, status)
+	assert.Equal(t, "insufficient scope\n", body)
+}
+
+func TestAuthInfo_HasScope(t *testing.T) {
+	assert.True(t, AuthInfo{}.HasScope("read"))
+	assert.True(t, AuthInfo{Scopes: []string{"read"}}.HasScope("read"))
+	assert.False(t, AuthInfo{Scopes: []string{"read"}}.HasScope("write"))
+	assert.False(t, AuthInfo{Scopes: []string{}}.HasScope("read"))
+}
+
+func TestAuthMiddlewareWithAPITokens(t *testing.T) {
+	a := NewAPITokens(NewMemoryAPITokenStore())
+	_, raw, err := a.Issue(context.Background(), IssueAPITokenParam{UserID: "user1"})
+	require.NoError(t, err)
+
+	m, err := AuthMiddlewareWithAPITokens([]JWTProvider{
+		{ISS: "https://example.com/", AUD: []string{"a"}},
+	}, a, nil, true)
+	require.NoError(t, err)
+	ts := httptest.NewServer(m(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ai := GetAuthInfoFromContext(r.Context()); ai != nil {
+			_, _ = w.Write([]byte(ai.Sub))
+		}
+	})))
+	defer ts.Close()
+
+	do := func(token string) (int, string) {
+		req := lo.Must(http.NewRequest(http.MethodGet, ts.URL, nil))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		res := lo.Must(http.DefaultClient.Do(req))
+		defer func() { _ = res.Body.Close() }()
+		return res.StatusCode, string(lo.Must(io.ReadAll(res.Body)))
+	}
+
+	status, body := do(raw)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "user1", body)
+
+	status, body = do("")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "", body)
+
+	// falls back to JWT
+	status, _ = do("invalid.token")
+	assert.Equal(t, http.StatusUnauthorized, status)
+}
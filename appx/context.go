@@ -6,6 +6,7 @@ import (
 	"os"
 
 	"github.com/google/uuid"
+	"github.com/reearth/reearthx/i18n"
 	"github.com/reearth/reearthx/log"
 )
 
@@ -84,3 +85,29 @@ func GetRequestIDFromContext(ctx context.Context) string {
 	}
 	return ""
 }
+
+type localizerKey struct{}
+
+func ContextWithLocalizer(ctx context.Context, l *i18n.Localizer) context.Context {
+	if ctx == nil {
+		return nil
+	}
+	return context.WithValue(ctx, localizerKey{}, l)
+}
+
+func GetLocalizerFromContext(ctx context.Context) *i18n.Localizer {
+	if ctx == nil {
+		return nil
+	}
+	if l, ok := ctx.Value(localizerKey{}).(*i18n.Localizer); ok {
+		return l
+	}
+	return nil
+}
+
+// LocalizerMiddleware sets a localizer for the languages of the Accept-Language header to the context.
+func LocalizerMiddleware(bundle *i18n.Bundle) func(http.Handler) http.Handler {
+	return ContextMiddlewareBy(func(w http.ResponseWriter, r *http.Request) context.Context {
+		return ContextWithLocalizer(r.Context(), i18n.NewLocalizer(bundle, r.Header.Get("Accept-Language")))
+	})
+}
@@ -7,8 +7,10 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/reearth/reearthx/i18n"
 	"github.com/samber/lo"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/language"
 )
 
 func TestContextMiddleware(t *testing.T) {
@@ -66,3 +68,26 @@ func TestRequestIDMiddleware(t *testing.T) {
 	body = string(lo.Must(io.ReadAll(res.Body)))
 	assert.Equal(t, "xxx", body)
 }
+
+func TestLocalizerMiddleware(t *testing.T) {
+	b := i18n.NewBundle(language.English)
+	b.MustAddMessages(language.English, &i18n.Message{ID: "hello", Other: "hello"})
+	b.MustAddMessages(language.Japanese, &i18n.Message{ID: "hello", Other: "こんにちは"})
+
+	ts := httptest.NewServer(LocalizerMiddleware(b)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(lo.Must(GetLocalizerFromContext(r.Context()).LocalizeMessage(i18n.T("hello")))))
+	})))
+	defer ts.Close()
+
+	req := lo.Must(http.NewRequest(http.MethodGet, ts.URL, nil))
+	req.Header.Set("Accept-Language", "ja,en;q=0.9")
+	res := lo.Must(http.DefaultClient.Do(req))
+	body := string(lo.Must(io.ReadAll(res.Body)))
+	assert.Equal(t, "こんにちは", body)
+
+	res = lo.Must(http.Get(ts.URL))
+	body = string(lo.Must(io.ReadAll(res.Body)))
+	assert.Equal(t, "hello", body)
+
+	assert.Nil(t, GetLocalizerFromContext(context.Background()))
+}
@@ -3,13 +3,15 @@ package appx
 import (
 	"context"
 	"net/http"
+	"time"
 
 	"github.com/99designs/gqlgen/graphql"
 	"github.com/99designs/gqlgen/graphql/handler"
 	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/lru"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
 	"github.com/ravilushqa/otelgqlgen"
-	"github.com/reearth/reearthx/log"
-	"github.com/vektah/gqlparser/v2/gqlerror"
+	"github.com/vektah/gqlparser/v2/ast"
 )
 
 type GraphQLHandlerConfig struct {
@@ -17,32 +19,71 @@ type GraphQLHandlerConfig struct {
 	Dev             bool
 	Context         func(r *http.Request) context.Context
 	ComplexityLimit int
+	// ErrorCodes maps errors to the codes set to extensions.code in addition to the default codes.
+	// They are matched in order.
+	ErrorCodes []ErrorCodeMapping
+	// PersistedQueryCache stores queries of automatic persisted queries. It defaults to an in-memory LRU cache.
+	// Use a shared store such as Redis to share persisted queries across multiple instances.
+	PersistedQueryCache graphql.Cache[string]
+	DepthLimit          int
+	RateLimit           *GraphQLRateLimit
+	// SlowOperationThreshold enables logging of operations that take the duration or longer.
+	SlowOperationThreshold time.Duration
 }
 
 func GraphQLHandler(c GraphQLHandlerConfig) http.Handler {
-	//nolint:staticcheck
-	srv := handler.NewDefaultServer(c.Schema)
+	srv := newGraphQLServer(c.Schema, c.PersistedQueryCache)
 	srv.Use(otelgqlgen.Middleware())
 
 	if c.ComplexityLimit > 0 {
 		srv.Use(extension.FixedComplexityLimit(c.ComplexityLimit))
 	}
 
+	if c.DepthLimit > 0 {
+		srv.Use(DepthLimit{Limit: c.DepthLimit})
+	}
+
+	if c.RateLimit != nil {
+		srv.Use(c.RateLimit)
+	}
+
+	if c.SlowOperationThreshold > 0 {
+		srv.Use(SlowOperationLogger{Threshold: c.SlowOperationThreshold})
+	}
+
 	if c.Dev {
 		srv.Use(extension.Introspection{})
 	}
 
-	srv.SetErrorPresenter(
-		// show more detailed error messgage in debug mode
-		func(ctx context.Context, e error) *gqlerror.Error {
-			path := graphql.GetFieldContext(ctx).Path()
-			log.Debugfc(ctx, "gql error: %v: %v", path, e)
-			if c.Dev {
-				return gqlerror.ErrorPathf(path, "%v", e)
-			}
-			return graphql.DefaultErrorPresenter(ctx, e)
-		},
-	)
+	// show more detailed error messgage in debug mode
+	srv.SetErrorPresenter(GraphQLErrorPresenter(c.Dev, c.ErrorCodes))
+
+	if c.RateLimit != nil {
+		return remoteIPMiddleware(srv)
+	}
+	return srv
+}
+
+// newGraphQLServer is the same as handler.NewDefaultServer except that the cache of persisted queries can be replaced.
+func newGraphQLServer(es graphql.ExecutableSchema, apqCache graphql.Cache[string]) *handler.Server {
+	if apqCache == nil {
+		apqCache = lru.New[string](100)
+	}
+
+	srv := handler.New(es)
+	srv.AddTransport(transport.Websocket{
+		KeepAlivePingInterval: 10 * time.Second,
+	})
+	srv.AddTransport(transport.Options{})
+	srv.AddTransport(transport.GET{})
+	srv.AddTransport(transport.POST{})
+	srv.AddTransport(transport.MultipartForm{})
+
+	srv.SetQueryCache(lru.New[*ast.QueryDocument](1000))
 
+	srv.Use(extension.Introspection{})
+	srv.Use(extension.AutomaticPersistedQuery{
+		Cache: apqCache,
+	})
 	return srv
 }
@@ -0,0 +1,154 @@
+package appx
+
+import (
+	"context"
+	"errors"
+	"maps"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/reearth/reearthx/i18n"
+	"github.com/reearth/reearthx/log"
+	"github.com/reearth/reearthx/rerror"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// Error codes set to extensions.code of GraphQL errors.
+const (
+	ErrCodeInternal        = "INTERNAL_SERVER_ERROR"
+	ErrCodeNotFound        = "NOT_FOUND"
+	ErrCodeAlreadyExists   = "ALREADY_EXISTS"
+	ErrCodeBadUserInput    = "BAD_USER_INPUT"
+	ErrCodeNotImplemented  = "NOT_IMPLEMENTED"
+	ErrCodeTooManyRequests = "TOO_MANY_REQUESTS"
+	ErrCodeUnauthenticated = "UNAUTHENTICATED"
+	ErrCodeCanceled        = "CANCELED"
+	ErrCodeTimeout         = "TIMEOUT"
+)
+
+// ErrorCodeMapping maps errors that match Err with errors.Is to Code.
+type ErrorCodeMapping struct {
+	Err  error
+	Code string
+}
+
+var defaultErrorCodes = []ErrorCodeMapping{
+	{rerror.ErrNotFoundRaw, ErrCodeNotFound},
+	{rerror.ErrAlreadyExistsRaw, ErrCodeAlreadyExists},
+	{rerror.ErrInvalidParamsRaw, ErrCodeBadUserInput},
+	{rerror.ErrNotImplementedRaw, ErrCodeNotImplemented},
+	{rerror.ErrTooManyRequestsRaw, ErrCodeTooManyRequests},
+	{context.Canceled, ErrCodeCanceled},
+	{context.DeadlineExceeded, ErrCodeTimeout},
+}
+
+var errInternalMessage = rerror.NewE(&i18n.Message{ID: rerror.IDErrInternal})
+
+// ErrorCode returns the error code of the error. codes takes precedence over the default codes,
+// and the first mapping that matches is used so that the code is stable.
+// An empty string is returned for errors that are not known.
+func ErrorCode(err error, codes []ErrorCodeMapping) string {
+	if err == nil {
+		return ""
+	}
+	if code := matchErrorCode(err, codes); code != "" {
+		return code
+	}
+	if isHiddenError(err) {
+		return ErrCodeInternal
+	}
+	return matchErrorCode(err, defaultErrorCodes)
+}
+
+func matchErrorCode(err error, codes []ErrorCodeMapping) string {
+	for _, c := range codes {
+		if errors.Is(err, c.Err) {
+			return c.Code
+		}
+	}
+	return ""
+}
+
+// GraphQLErrorPresenter returns an error presenter that sets the error code and the request ID to the extensions
+// and localizes the message with the localizer in the context. Messages of internal errors are hidden unless dev is true.
+func GraphQLErrorPresenter(dev bool, codes []ErrorCodeMapping) graphql.ErrorPresenterFunc {
+	return func(ctx context.Context, e error) *gqlerror.Error {
+		if e == nil {
+			return nil
+		}
+
+		path := graphql.GetPath(ctx)
+		log.Debugfc(ctx, "gql error: %v: %v", path, e)
+
+		var res *gqlerror.Error
+		var gqlErr *gqlerror.Error
+		if errors.As(e, &gqlErr) && gqlErr.Err == nil {
+			// errors built by gqlgen such as validation errors and errors with their own extensions
+			res = gqlErr
+		} else {
+			if gqlErr != nil {
+				// keep the path and the extensions of errors wrapped by graphql.ErrorOnPath etc.
+				c := *gqlErr
+				c.Extensions = maps.Clone(gqlErr.Extensions)
+				if c.Path == nil {
+					c.Path = path
+				}
+				res = &c
+				e = gqlErr.Err
+			} else {
+				res = gqlerror.WrapPath(path, e)
+			}
+			res.Message = errorMessage(ctx, e, dev)
+		}
+
+		if _, ok := res.Extensions["code"]; !ok {
+			if code := ErrorCode(e, codes); code != "" {
+				errcode.Set(res, code)
+			}
+		}
+		if reqid := GetRequestIDFromContext(ctx); reqid != "" {
+			if res.Extensions == nil {
+				res.Extensions = map[string]any{}
+			}
+			res.Extensions["requestId"] = reqid
+		}
+		return res
+	}
+}
+
+func errorMessage(ctx context.Context, err error, dev bool) string {
+	l := GetLocalizerFromContext(ctx)
+	if isHiddenError(err) {
+		if dev {
+			// show the wrapped error to help debugging
+			if e := rerror.Get(err); e != nil && e.Err != nil {
+				return e.Label.Error() + ": " + e.Err.Error()
+			}
+			return err.Error()
+		}
+		if l == nil {
+			return errInternalMessage.Error()
+		}
+		return errInternalMessage.LocalizeError(l).Error()
+	}
+	if l == nil {
+		return err.Error()
+	}
+	return rerror.Localize(l, err).Error()
+}
+
+// isHiddenError returns true if the error is an internal error whose message should not be exposed.
+func isHiddenError(err error) bool {
+	if rerror.IsInternal(err) {
+		return true
+	}
+	e := err
+	var target *rerror.Error
+	for errors.As(e, &target) {
+		if target.Hidden {
+			return true
+		}
+		e = target.Unwrap()
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+package appx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/reearth/reearthx/i18n"
+	"github.com/reearth/reearthx/rerror"
+	"github.com/stretchr/testify/assert"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"golang.org/x/text/language"
+)
+
+func TestErrorCode(t *testing.T) {
+	errCustom := errors.New("custom")
+
+	assert.Equal(t, "", ErrorCode(nil, nil))
+	assert.Equal(t, "", ErrorCode(errors.New("unknown"), nil))
+	assert.Equal(t, ErrCodeNotFound, ErrorCode(rerror.ErrNotFound, nil))
+	assert.Equal(t, ErrCodeNotFound, ErrorCode(fmt.Errorf("item: %w", rerror.ErrNotFound), nil))
+	assert.Equal(t, ErrCodeAlreadyExists, ErrorCode(rerror.ErrAlreadyExists, nil))
+	assert.Equal(t, ErrCodeBadUserInput, ErrorCode(rerror.ErrInvalidParams, nil))
+	assert.Equal(t, ErrCodeNotImplemented, ErrorCode(rerror.ErrNotImplemented, nil))
+	assert.Equal(t, ErrCodeTooManyRequests, ErrorCode(rerror.ErrTooManyRequests, nil))
+	assert.Equal(t, ErrCodeInternal, ErrorCode(rerror.ErrInternalBy(errors.New("db")), nil))
+	assert.Equal(t, ErrCodeInternal, ErrorCode(rerror.ErrInternalByWith("label", errors.New("db")), nil))
+	assert.Equal(t, ErrCodeTimeout, ErrorCode(context.DeadlineExceeded, nil))
+	assert.Equal(t, "CUSTOM", ErrorCode(errCustom, []ErrorCodeMapping{{Err: errCustom, Code: "CUSTOM"}}))
+	assert.Equal(t, "GONE", ErrorCode(rerror.ErrNotFound, []ErrorCodeMapping{{Err: rerror.ErrNotFoundRaw, Code: "GONE"}}))
+
+	// the first matching code is used
+	wrapped := fmt.Errorf("%w: %w", errCustom, rerror.ErrNotFoundRaw)
+	codes := []ErrorCodeMapping{{Err: rerror.ErrNotFoundRaw, Code: "GONE"}, {Err: errCustom, Code: "CUSTOM"}}
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, "GONE", ErrorCode(wrapped, codes))
+	}
+}
+
+func TestGraphQLErrorPresenter(t *testing.T) {
+	b := i18n.NewBundle(language.Japanese)
+	b.MustAddMessages(
+		language.Japanese,
+		&i18n.Message{ID: rerror.IDErrInternal, Other: "内部エラー"},
+		&i18n.Message{ID: rerror.IDErrNotFound, Other: "見つかりませんでした"},
+	)
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "reqid")
+	lctx := ContextWithLocalizer(ctx, i18n.NewLocalizer(b, "ja"))
+	internal := rerror.ErrInternalBy(errors.New("db is down"))
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		dev     bool
+		err     error
+		message string
+		ext     map[string]any
+	}{
+		{
+			name:    "not found",
+			ctx:     ctx,
+			err:     rerror.ErrNotFound,
+			message: "not found",
+			ext:     map[string]any{"code": ErrCodeNotFound, "requestId": "reqid"},
+		},
+		{
+			name:    "localized",
+			ctx:     lctx,
+			err:     rerror.ErrNotFound,
+			message: "見つかりませんでした",
+			ext:     map[string]any{"code": ErrCodeNotFound, "requestId": "reqid"},
+		},
+		{
+			name:    "internal",
+			ctx:     ctx,
+			err:     rerror.ErrInternalByWith("mongo", errors.New("db is down")),
+			message: "internal",
+			ext:     map[string]any{"code": ErrCodeInternal, "requestId": "reqid"},
+		},
+		{
+			name:    "localized internal",
+			ctx:     lctx,
+			err:     fmt.Errorf("failed to find: %w", internal),
+			message: "内部エラー",
+			ext:     map[string]any{"code": ErrCodeInternal, "requestId": "reqid"},
+		},
+		{
+			name:    "internal in dev",
+			ctx:     lctx,
+			dev:     true,
+			err:     internal,
+			message: "internal: db is down",
+			ext:     map[string]any{"code": ErrCodeInternal, "requestId": "reqid"},
+		},
+		{
+			name:    "unknown without request id",
+			ctx:     context.Background(),
+			err:     errors.New("unknown"),
+			message: "unknown",
+		},
+		{
+			name:    "wrapped by gqlerror",
+			ctx:     ctx,
+			err:     gqlerror.WrapPath(ast.Path{ast.PathName("item")}, rerror.ErrNotFound),
+			message: "not found",
+			ext:     map[string]any{"code": ErrCodeNotFound, "requestId": "reqid"},
+		},
+		{
+			name:    "gqlerror",
+			ctx:     ctx,
+			err:     &gqlerror.Error{Message: "invalid", Extensions: map[string]any{"code": "GRAPHQL_VALIDATION_FAILED"}},
+			message: "invalid",
+			ext:     map[string]any{"code": "GRAPHQL_VALIDATION_FAILED", "requestId": "reqid"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := GraphQLErrorPresenter(tt.dev, nil)(tt.ctx, tt.err)
+			assert.Equal(t, tt.message, got.Message)
+			assert.Equal(t, tt.ext, got.Extensions)
+		})
+	}
+
+	got := GraphQLErrorPresenter(false, nil)(ctx, gqlerror.WrapPath(ast.Path{ast.PathName("item")}, rerror.ErrNotFound))
+	assert.Equal(t, ast.Path{ast.PathName("item")}, got.Path)
+	assert.Nil(t, GraphQLErrorPresenter(false, nil)(ctx, nil))
+}
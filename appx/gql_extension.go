@@ -0,0 +1,219 @@
+package appx
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/reearth/reearthx/log"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+const ErrCodeDepthLimitExceeded = "DEPTH_LIMIT_EXCEEDED"
+
+// DepthLimit rejects operations whose selections are nested deeper than Limit.
+// Introspection fields are not counted.
+type DepthLimit struct {
+	Limit int
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationContextMutator
+} = DepthLimit{}
+
+func (DepthLimit) ExtensionName() string {
+	return "DepthLimit"
+}
+
+func (d DepthLimit) Validate(graphql.ExecutableSchema) error {
+	if d.Limit <= 0 {
+		return errors.New("DepthLimit limit must be positive")
+	}
+	return nil
+}
+
+func (d DepthLimit) MutateOperationContext(_ context.Context, opCtx *graphql.OperationContext) *gqlerror.Error {
+	if opCtx.Operation == nil {
+		return nil
+	}
+	if depth := selectionDepth(opCtx.Operation.SelectionSet, map[string]struct{}{}); depth > d.Limit {
+		err := gqlerror.Errorf("operation has depth %d, which exceeds the limit of %d", depth, d.Limit)
+		errcode.Set(err, ErrCodeDepthLimitExceeded)
+		return err
+	}
+	return nil
+}
+
+func selectionDepth(set ast.SelectionSet, visited map[string]struct{}) int {
+	depth := 0
+	for _, s := range set {
+		var d int
+		switch s := s.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(s.Name, "__") {
+				continue
+			}
+			d = 1 + selectionDepth(s.SelectionSet, visited)
+		case *ast.InlineFragment:
+			d = selectionDepth(s.SelectionSet, visited)
+		case *ast.FragmentSpread:
+			if s.Definition == nil {
+				continue
+			}
+			// fragment cycles are rejected by validation, but guard against them anyway
+			if _, ok := visited[s.Name]; ok {
+				continue
+			}
+			visited[s.Name] = struct{}{}
+			d = selectionDepth(s.Definition.SelectionSet, visited)
+			delete(visited, s.Name)
+		}
+		depth = max(depth, d)
+	}
+	return depth
+}
+
+// GraphQLRateLimit limits the number of operations each user can execute per window.
+// Requests are counted for each pair of a user and an operation name.
+type GraphQLRateLimit struct {
+	// Limit is the number of operations allowed in Window for each user across the operations not in Operations.
+	Limit  int
+	Window time.Duration
+	// Operations gives the operations of the names their own limits. Zero disables the limit of the operation.
+	Operations map[string]int
+	// Limiter defaults to a MemoryRateLimiter.
+	Limiter RateLimiter
+	// Key returns the key of the user. It defaults to the subject of AuthInfo or the remote IP address for anonymous users.
+	Key func(ctx context.Context) string
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationContextMutator
+} = &GraphQLRateLimit{}
+
+func (*GraphQLRateLimit) ExtensionName() string {
+	return "RateLimit"
+}
+
+func (r *GraphQLRateLimit) Validate(graphql.ExecutableSchema) error {
+	if r.Window <= 0 {
+		return errors.New("RateLimit window must be positive")
+	}
+	if r.Limiter == nil {
+		r.Limiter = NewMemoryRateLimiter()
+	}
+	return nil
+}
+
+func (r *GraphQLRateLimit) MutateOperationContext(ctx context.Context, opCtx *graphql.OperationContext) *gqlerror.Error {
+	key := rateLimitKey(ctx)
+	if r.Key != nil {
+		key = r.Key(ctx)
+	}
+
+	// operation names are chosen by clients, so only the listed ones get their own buckets
+	// and the others share the bucket of the user
+	name := operationName(opCtx)
+	limit := r.Limit
+	if l, ok := r.Operations[name]; ok {
+		limit = l
+		key += ":" + name
+	}
+	if limit <= 0 {
+		return nil
+	}
+
+	ok, err := r.Limiter.Allow(ctx, key, limit, r.Window)
+	if err != nil {
+		// do not block requests when the limiter is unavailable
+		log.Errorfc(ctx, "gql: failed to check rate limit: %v", err)
+		return nil
+	}
+	if !ok {
+		err := gqlerror.Errorf("too many requests for operation %s", name)
+		errcode.Set(err, ErrCodeTooManyRequests)
+		return err
+	}
+	return nil
+}
+
+func rateLimitKey(ctx context.Context) string {
+	if a := GetAuthInfoFromContext(ctx); a != nil && a.Sub != "" {
+		return "user:" + a.Sub
+	}
+	if ip, ok := ctx.Value(remoteIPKey{}).(string); ok && ip != "" {
+		return "ip:" + ip
+	}
+	return "anonymous"
+}
+
+type remoteIPKey struct{}
+
+// remoteIPMiddleware sets the remote IP address to the context for the rate limit of anonymous users.
+func remoteIPMiddleware(next http.Handler) http.Handler {
+	return ContextMiddlewareBy(func(w http.ResponseWriter, r *http.Request) context.Context {
+		ip, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			ip = r.RemoteAddr
+		}
+		return context.WithValue(r.Context(), remoteIPKey{}, ip)
+	})(next)
+}
+
+// SlowOperationLogger logs operations that take Threshold or longer.
+type SlowOperationLogger struct {
+	Threshold time.Duration
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.ResponseInterceptor
+} = SlowOperationLogger{}
+
+func (SlowOperationLogger) ExtensionName() string {
+	return "SlowOperationLogger"
+}
+
+func (SlowOperationLogger) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (s SlowOperationLogger) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	res := next(ctx)
+	if res == nil || !graphql.HasOperationContext(ctx) {
+		return res
+	}
+
+	opCtx := graphql.GetOperationContext(ctx)
+	// subscriptions are long-lived and responses are written many times
+	if opCtx.Operation == nil || opCtx.Operation.Operation == ast.Subscription {
+		return res
+	}
+
+	if d := time.Since(opCtx.Stats.OperationStart); d >= s.Threshold {
+		user := ""
+		if a := GetAuthInfoFromContext(ctx); a != nil {
+			user = a.Sub
+		}
+		log.Warnfc(ctx, "gql: slow operation %s %s took %s (user: %s, errors: %d)", opCtx.Operation.Operation, operationName(opCtx), d, user, len(res.Errors))
+	}
+	return res
+}
+
+func operationName(opCtx *graphql.OperationContext) string {
+	if opCtx.Operation != nil && opCtx.Operation.Name != "" {
+		return opCtx.Operation.Name
+	}
+	if opCtx.OperationName != "" {
+		return opCtx.OperationName
+	}
+	return "anonymous"
+}
@@ -0,0 +1,132 @@
+package appx
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/reearth/reearthx/log"
+	"github.com/reearth/reearthx/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+var testGQLSchema = gqlparser.MustLoadSchema(&ast.Source{Input: `
+type Query {
+	node: Node
+	nodes: [Node!]!
+}
+
+type Node {
+	id: ID!
+	child: Node
+}
+`})
+
+func testOperationContext(t *testing.T, query string) *graphql.OperationContext {
+	t.Helper()
+	doc := gqlparser.MustLoadQuery(testGQLSchema, query)
+	return &graphql.OperationContext{
+		RawQuery:  query,
+		Doc:       doc,
+		Operation: doc.Operations[0],
+	}
+}
+
+func TestDepthLimit(t *testing.T) {
+	d := DepthLimit{Limit: 3}
+	assert.NoError(t, d.Validate(nil))
+	assert.Error(t, DepthLimit{}.Validate(nil))
+
+	assert.Nil(t, d.MutateOperationContext(context.Background(), testOperationContext(t, `{ node { child { id } } }`)))
+	assert.Nil(t, d.MutateOperationContext(context.Background(), testOperationContext(t, `{ __schema { types { fields { type { ofType { name } } } } } }`)))
+
+	err := d.MutateOperationContext(context.Background(), testOperationContext(t, `{ node { child { child { id } } } }`))
+	assert.Equal(t, "operation has depth 4, which exceeds the limit of 3", err.Message)
+	assert.Equal(t, ErrCodeDepthLimitExceeded, err.Extensions["code"])
+
+	err = d.MutateOperationContext(context.Background(), testOperationContext(t, `
+		query { nodes { ...F } }
+		fragment F on Node { child { ... on Node { child { id } } } }
+	`))
+	assert.Equal(t, "operation has depth 4, which exceeds the limit of 3", err.Message)
+}
+
+type errRateLimiter struct{}
+
+func (errRateLimiter) Allow(context.Context, string, int, time.Duration) (bool, error) {
+	return false, errors.New("unavailable")
+}
+
+func TestGraphQLRateLimit(t *testing.T) {
+	defer util.MockNow(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))()
+
+	r := &GraphQLRateLimit{
+		Limit:      2,
+		Window:     time.Minute,
+		Operations: map[string]int{"Nodes": 1, "Free": 0},
+	}
+	assert.NoError(t, r.Validate(nil))
+	assert.NotNil(t, r.Limiter)
+	assert.Error(t, (&GraphQLRateLimit{}).Validate(nil))
+
+	user1 := ContextWithAuthInfo(context.Background(), AuthInfo{Sub: "user1"})
+	user2 := ContextWithAuthInfo(context.Background(), AuthInfo{Sub: "user2"})
+	node := testOperationContext(t, `query Node { node { id } }`)
+	nodes := testOperationContext(t, `query Nodes { nodes { id } }`)
+	free := testOperationContext(t, `query Free { node { id } }`)
+
+	assert.Nil(t, r.MutateOperationContext(user1, node))
+	assert.Nil(t, r.MutateOperationContext(user1, node))
+	err := r.MutateOperationContext(user1, node)
+	assert.Equal(t, "too many requests for operation Node", err.Message)
+	assert.Equal(t, ErrCodeTooManyRequests, err.Extensions["code"])
+
+	// operation names that are not listed share the limit of the user
+	random := testOperationContext(t, `query Random123 { node { id } }`)
+	err = r.MutateOperationContext(user1, random)
+	assert.Equal(t, "too many requests for operation Random123", err.Message)
+
+	// limits are counted for each user and listed operation
+	assert.Nil(t, r.MutateOperationContext(user2, node))
+	assert.Nil(t, r.MutateOperationContext(user1, nodes))
+	assert.NotNil(t, r.MutateOperationContext(user1, nodes))
+	for range 3 {
+		assert.Nil(t, r.MutateOperationContext(user1, free))
+	}
+
+	// anonymous users are limited by IP addresses
+	ip1 := context.WithValue(context.Background(), remoteIPKey{}, "192.0.2.1")
+	ip2 := context.WithValue(context.Background(), remoteIPKey{}, "192.0.2.2")
+	assert.Nil(t, r.MutateOperationContext(ip1, nodes))
+	assert.NotNil(t, r.MutateOperationContext(ip1, nodes))
+	assert.Nil(t, r.MutateOperationContext(ip2, nodes))
+
+	// requests are allowed when the limiter fails
+	r2 := &GraphQLRateLimit{Limit: 1, Window: time.Minute, Limiter: errRateLimiter{}}
+	assert.Nil(t, r2.MutateOperationContext(user1, node))
+}
+
+func TestSlowOperationLogger(t *testing.T) {
+	logWriter := &testLogWriter{}
+	log.SetOutput(logWriter)
+	defer log.SetOutput(nil)
+
+	s := SlowOperationLogger{Threshold: 100 * time.Millisecond}
+	next := func(context.Context) *graphql.Response { return &graphql.Response{} }
+
+	opCtx := testOperationContext(t, `query Fast { node { id } }`)
+	opCtx.Stats.OperationStart = time.Now()
+	ctx := ContextWithAuthInfo(context.Background(), AuthInfo{Sub: "user1"})
+	assert.NotNil(t, s.InterceptResponse(graphql.WithOperationContext(ctx, opCtx), next))
+	assert.NotContains(t, logWriter.String(), "slow operation")
+
+	opCtx = testOperationContext(t, `query Slow { node { id } }`)
+	opCtx.Stats.OperationStart = time.Now().Add(-time.Second)
+	assert.NotNil(t, s.InterceptResponse(graphql.WithOperationContext(ctx, opCtx), next))
+	assert.Contains(t, logWriter.String(), "gql: slow operation query Slow took")
+	assert.Contains(t, logWriter.String(), "(user: user1, errors: 0)")
+}
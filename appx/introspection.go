@@ -0,0 +1,236 @@
+package appx
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/auth0/go-jwt-middleware/v2/validator"
+	"github.com/golang-jwt/jwt"
+	"github.com/reearth/reearthx/util"
+)
+
+var (
+	ErrInactiveToken = errors.New("inactive token")
+	errSkipToken     = errors.New("token is issued by another issuer")
+)
+
+// IntrospectionValidator validates opaque access tokens with an OAuth 2.0 token introspection endpoint (RFC 7662).
+// Responses of active tokens are cached until the token expires or the TTL elapses, whichever comes first.
+// It returns the same claims as JWTValidatorWithError so that AuthInfoMiddleware can handle both kinds of tokens.
+type IntrospectionValidator struct {
+	endpoint     string
+	clientID     string
+	clientSecret string
+	iss          string
+	aud          []string
+	ttl          time.Duration
+	client       *http.Client
+	// cache is keyed by the hash of the token
+	cache util.ExpiringMap[string, *validator.ValidatedClaims]
+}
+
+var _ JWTValidator = (*IntrospectionValidator)(nil)
+
+func NewIntrospectionValidator(endpoint, clientID, clientSecret, issuer string, audience []string, ttl time.Duration) (*IntrospectionValidator, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the introspection url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("failed to parse the introspection url")
+	}
+	return &IntrospectionValidator{
+		endpoint:     u.String(),
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		iss:          issuer,
+		aud:          slices.Clone(audience),
+		ttl:          ttl,
+		client:       http.DefaultClient,
+	}, nil
+}
+
+type introspectionResponse struct {
+	Active        bool            `json:"active"`
+	Scope         string          `json:"scope"`
+	ClientID      string          `json:"client_id"`
+	Username      string          `json:"username"`
+	TokenType     string          `json:"token_type"`
+	Exp           int64           `json:"exp"`
+	Iat           int64           `json:"iat"`
+	Nbf           int64           `json:"nbf"`
+	Sub           string          `json:"sub"`
+	Aud           json.RawMessage `json:"aud"`
+	Iss           string          `json:"iss"`
+	Jti           string          `json:"jti"`
+	Name          string          `json:"name"`
+	Nickname      string          `json:"nickname"`
+	Email         string          `json:"email"`
+	EmailVerified *bool           `json:"email_verified"`
+}
+
+func (v *IntrospectionValidator) ValidateToken(ctx context.Context, token string) (interface{}, error) {
+	res, err := v.validateToken(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid token: introspection=%s iss=%s aud=%v err=%w", v.endpoint, v.iss, v.aud, err)
+	}
+	return res, nil
+}
+
+func (v *IntrospectionValidator) validateToken(ctx context.Context, token string) (*validator.ValidatedClaims, error) {
+	if v.isJWTOfAnotherIssuer(token) {
+		// do not send tokens to the endpoint of an issuer that did not issue them
+		return nil, errSkipToken
+	}
+
+	key := introspectionCacheKey(token)
+	now := util.Now()
+	if claims, ok := v.cache.Load(key); ok {
+		return claims, nil
+	}
+
+	res, err := v.introspect(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	claims, err := v.claims(res, now)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := now.Add(v.ttl)
+	if res.Exp > 0 {
+		if exp := time.Unix(res.Exp, 0); exp.Before(expiresAt) {
+			expiresAt = exp
+		}
+	}
+	if now.Before(expiresAt) {
+		v.cache.Store(key, claims, expiresAt)
+	}
+	return claims, nil
+}
+
+func (v *IntrospectionValidator) introspect(ctx context.Context, token string) (*introspectionResponse, error) {
+	form := url.Values{}
+	form.Set("token", token)
+	form.Set("token_type_hint", "access_token")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if v.clientID != "" {
+		req.SetBasicAuth(url.QueryEscape(v.clientID), url.QueryEscape(v.clientSecret))
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to introspect the token: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to introspect the token: status code %d", resp.StatusCode)
+	}
+
+	var res introspectionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return nil, fmt.Errorf("failed to decode the introspection response: %w", err)
+	}
+	return &res, nil
+}
+
+func (v *IntrospectionValidator) claims(res *introspectionResponse, now time.Time) (*validator.ValidatedClaims, error) {
+	if !res.Active {
+		return nil, ErrInactiveToken
+	}
+	if res.Exp > 0 && !now.Before(time.Unix(res.Exp, 0)) {
+		return nil, errors.New("token is expired")
+	}
+	if res.Nbf > 0 && now.Before(time.Unix(res.Nbf, 0)) {
+		return nil, errors.New("token is not valid yet")
+	}
+	if v.iss != "" && res.Iss != "" && res.Iss != v.iss {
+		return nil, fmt.Errorf("invalid issuer: %s", res.Iss)
+	}
+
+	aud, err := parseAudience(res.Aud)
+	if err != nil {
+		return nil, err
+	}
+	if len(v.aud) > 0 && !slices.ContainsFunc(aud, func(a string) bool { return slices.Contains(v.aud, a) }) {
+		return nil, fmt.Errorf("invalid audience: %v", aud)
+	}
+
+	iss := res.Iss
+	if iss == "" {
+		iss = v.iss
+	}
+	name := res.Name
+	if name == "" {
+		name = res.Username
+	}
+	return &validator.ValidatedClaims{
+		RegisteredClaims: validator.RegisteredClaims{
+			Issuer:    iss,
+			Subject:   res.Sub,
+			Audience:  aud,
+			Expiry:    res.Exp,
+			NotBefore: res.Nbf,
+			IssuedAt:  res.Iat,
+			ID:        res.Jti,
+		},
+		CustomClaims: &customClaims{
+			Name:          name,
+			Nickname:      res.Nickname,
+			Email:         res.Email,
+			EmailVerified: res.EmailVerified,
+		},
+	}, nil
+}
+
+// isJWTOfAnotherIssuer returns true if the token is a JWT whose iss claim is not the issuer of the validator.
+func (v *IntrospectionValidator) isJWTOfAnotherIssuer(token string) bool {
+	if v.iss == "" || strings.Count(token, ".") != 2 {
+		return false
+	}
+	claims := jwt.MapClaims{}
+	if _, _, err := new(jwt.Parser).ParseUnverified(token, claims); err != nil {
+		return false
+	}
+	iss, ok := claims["iss"].(string)
+	return ok && iss != v.iss
+}
+
+// introspectionCacheKey hashes the token not to keep raw tokens in memory.
+func introspectionCacheKey(token string) string {
+	h := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(h[:])
+}
+
+// parseAudience parses aud, which is either a string or an array of strings.
+func parseAudience(raw json.RawMessage) ([]string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return []string{s}, nil
+	}
+	var a []string
+	if err := json.Unmarshal(raw, &a); err != nil {
+		return nil, fmt.Errorf("invalid aud: %s", raw)
+	}
+	return a, nil
+}
@@ -0,0 +1,177 @@
+package appx
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/auth0/go-jwt-middleware/v2/validator"
+	"github.com/golang-jwt/jwt"
+	"github.com/reearth/reearthx/util"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newIntrospectionServer(t *testing.T, responses map[string]map[string]any) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	calls := &atomic.Int32{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		id, secret, ok := r.BasicAuth()
+		if !ok || id != "client" || secret != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		assert.Equal(t, "access_token", r.FormValue("token_type_hint"))
+		res, ok := responses[r.FormValue("token")]
+		if !ok {
+			res = map[string]any{"active": false}
+		}
+		_ = json.NewEncoder(w).Encode(res)
+	}))
+	t.Cleanup(ts.Close)
+	return ts, calls
+}
+
+func TestIntrospectionValidator(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	defer util.MockNow(now)()
+
+	ts, calls := newIntrospectionServer(t, map[string]map[string]any{
+		"token1": {
+			"active":   true,
+			"sub":      "user1",
+			"iss":      "https://example.com/",
+			"aud":      "a",
+			"exp":      now.Add(time.Minute).Unix(),
+			"username": "name1",
+			"email":    "user1@example.com",
+		},
+		"token2": {
+			"active": true,
+			"sub":    "user2",
+			"aud":    []string{"b", "c"},
+		},
+		"expired": {"active": true, "sub": "user3", "exp": now.Unix()},
+		"other":   {"active": true, "sub": "user4", "iss": "https://example2.com/"},
+	})
+
+	v, err := NewIntrospectionValidator(ts.URL, "client", "secret", "https://example.com/", []string{"a", "b"}, 5*time.Minute)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	res, err := v.ValidateToken(ctx, "token1")
+	require.NoError(t, err)
+	claims := res.(*validator.ValidatedClaims)
+	assert.Equal(t, validator.RegisteredClaims{
+		Issuer:   "https://example.com/",
+		Subject:  "user1",
+		Audience: []string{"a"},
+		Expiry:   now.Add(time.Minute).Unix(),
+	}, claims.RegisteredClaims)
+	assert.Equal(t, &customClaims{Name: "name1", Email: "user1@example.com"}, claims.CustomClaims)
+	assert.Equal(t, int32(1), calls.Load())
+
+	// cached until exp
+	_, err = v.ValidateToken(ctx, "token1")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), calls.Load())
+	util.MockNow(now.Add(time.Minute))
+	_, err = v.ValidateToken(ctx, "token1")
+	assert.ErrorContains(t, err, "token is expired")
+	assert.Equal(t, int32(2), calls.Load())
+
+	// cached until ttl without exp
+	util.MockNow(now)
+	res, err = v.ValidateToken(ctx, "token2")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/", res.(*validator.ValidatedClaims).RegisteredClaims.Issuer)
+	util.MockNow(now.Add(4 * time.Minute))
+	_, err = v.ValidateToken(ctx, "token2")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), calls.Load())
+	util.MockNow(now.Add(5 * time.Minute))
+	_, err = v.ValidateToken(ctx, "token2")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(4), calls.Load())
+
+	util.MockNow(now)
+	_, err = v.ValidateToken(ctx, "unknown")
+	assert.ErrorIs(t, err, ErrInactiveToken)
+	_, err = v.ValidateToken(ctx, "expired")
+	assert.ErrorContains(t, err, "token is expired")
+	_, err = v.ValidateToken(ctx, "other")
+	assert.ErrorContains(t, err, "invalid issuer")
+
+	// JWTs of other issuers are not sent to the endpoint
+	c := calls.Load()
+	jwtOfOther := lo.Must(jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"iss": "https://example2.com/"}).SignedString([]byte("key")))
+	_, err = v.ValidateToken(ctx, jwtOfOther)
+	assert.ErrorIs(t, err, errSkipToken)
+	assert.Equal(t, c, calls.Load())
+
+	// invalid client credentials
+	v2, err := NewIntrospectionValidator(ts.URL, "client", "wrong", "https://example.com/", nil, time.Minute)
+	require.NoError(t, err)
+	_, err = v2.ValidateToken(ctx, "token1")
+	assert.ErrorContains(t, err, "status code 401")
+
+	// audience
+	v3, err := NewIntrospectionValidator(ts.URL, "client", "secret", "https://example.com/", []string{"x"}, time.Minute)
+	require.NoError(t, err)
+	_, err = v3.ValidateToken(ctx, "token2")
+	assert.ErrorContains(t, err, "invalid audience")
+
+	_, err = NewIntrospectionValidator("example.com", "", "", "", nil, time.Minute)
+	assert.Error(t, err)
+}
+
+func TestAuthMiddleware_Introspection(t *testing.T) {
+	ts, _ := newIntrospectionServer(t, map[string]map[string]any{
+		"opaque": {"active": true, "sub": "user1", "iss": "https://example.com/", "name": "aaa", "email": "bbb"},
+	})
+
+	m, err := AuthMiddleware([]JWTProvider{
+		{
+			ISS:              "https://example.com/",
+			IntrospectionURL: lo.ToPtr(ts.URL),
+			ClientID:         lo.ToPtr("client"),
+			ClientSecret:     lo.ToPtr("secret"),
+		},
+	}, nil, false)
+	require.NoError(t, err)
+
+	var got *AuthInfo
+	h := m(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = GetAuthInfoFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer opaque")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, &AuthInfo{
+		Token: "opaque",
+		Sub:   "user1",
+		Iss:   "https://example.com/",
+		Name:  "aaa",
+		Email: "bbb",
+	}, got)
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer invalid")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestJWTProvider_IsEmpty_Introspection(t *testing.T) {
+	assert.True(t, JWTProvider{}.IsEmpty())
+	assert.False(t, JWTProvider{IntrospectionURL: lo.ToPtr("https://example.com/introspect")}.IsEmpty())
+}
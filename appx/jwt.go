@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"slices"
 	"strings"
 	"time"
 
@@ -24,6 +25,15 @@ type AuthInfo struct {
 	Name          string
 	Email         string
 	EmailVerified *bool
+	// APITokenID is the ID of the APIToken if the request is authenticated with an API token.
+	APITokenID string
+	// Scopes restricts what the request can do. Nil means no restriction, e.g. for JWTs.
+	Scopes []string
+}
+
+// HasScope returns true if the scopes are not restricted or contain the scope.
+func (a AuthInfo) HasScope(scope string) bool {
+	return a.Scopes == nil || slices.Contains(a.Scopes, scope)
 }
 
 type JWTProvider struct {
@@ -32,10 +42,16 @@ type JWTProvider struct {
 	AUD     []string
 	ALG     *string
 	TTL     *int
+	// IntrospectionURL enables validation of opaque tokens by the token introspection endpoint (RFC 7662) instead of JWKS.
+	// Introspection responses are cached for TTL minutes at most.
+	IntrospectionURL *string
+	ClientID         *string
+	ClientSecret     *string
 }
 
 func (p JWTProvider) IsEmpty() bool {
-	return p.ISS == "" && p.JWKSURI == nil && p.AUD == nil && p.ALG == nil && p.TTL == nil
+	return p.ISS == "" && p.JWKSURI == nil && p.AUD == nil && p.ALG == nil && p.TTL == nil &&
+		p.IntrospectionURL == nil && p.ClientID == nil && p.ClientSecret == nil
 }
 
 func (p JWTProvider) validator() (JWTValidator, error) {
@@ -47,6 +63,18 @@ func (p JWTProvider) validator() (JWTValidator, error) {
 		return nil, fmt.Errorf("failed to parse the issuer url")
 	}
 
+	ttl := time.Duration(lo.FromPtrOr(p.TTL, defaultJWTTTL)) * time.Minute
+	if p.IntrospectionURL != nil && *p.IntrospectionURL != "" {
+		return NewIntrospectionValidator(
+			*p.IntrospectionURL,
+			lo.FromPtr(p.ClientID),
+			lo.FromPtr(p.ClientSecret),
+			issuerURL.String(),
+			p.AUD,
+			ttl,
+		)
+	}
+
 	opts := []any{}
 	if p.JWKSURI != nil && *p.JWKSURI != "" {
 		u, err := url.Parse(*p.JWKSURI)
@@ -55,7 +83,6 @@ func (p JWTProvider) validator() (JWTValidator, error) {
 		}
 		opts = append(opts, jwks.WithCustomJWKSURI(u))
 	}
-	ttl := time.Duration(lo.FromPtrOr(p.TTL, defaultJWTTTL)) * time.Minute
 	provider := jwks.NewCachingProvider(issuerURL, ttl, opts...)
 	algorithm := validator.SignatureAlgorithm(lo.FromPtrOr(p.ALG, jwt.SigningMethodRS256.Name))
 
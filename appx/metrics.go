@@ -0,0 +1,112 @@
+package appx
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/felixge/httpsnoop"
+	"github.com/reearth/reearthx/log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+const (
+	meterName              = "github.com/reearth/reearthx/appx"
+	defaultMetricsInterval = time.Minute
+)
+
+type MetricsConfig struct {
+	Name string
+	OTLP OTLPConfig
+	// ResourceAttributes are attached to all metrics. OTEL_RESOURCE_ATTRIBUTES is also respected.
+	ResourceAttributes map[string]string
+	// Interval is the interval of exports. It defaults to one minute.
+	Interval time.Duration
+}
+
+// InitMetrics sets the global meter provider that exports metrics with OTLP.
+// Close the returned closer on shutdown to export the remaining metrics.
+func InitMetrics(ctx context.Context, conf *MetricsConfig) io.Closer {
+	exporter, err := newOTLPMetricExporter(ctx, conf.OTLP)
+	if err != nil {
+		log.Fatalc(ctx, err)
+	}
+
+	res, err := newResource(ctx, conf.Name, conf.ResourceAttributes)
+	if err != nil {
+		log.Fatalc(ctx, err)
+	}
+
+	interval := conf.Interval
+	if interval <= 0 {
+		interval = defaultMetricsInterval
+	}
+
+	mp := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter, sdkmetric.WithInterval(interval))),
+		sdkmetric.WithResource(res),
+	)
+	otel.SetMeterProvider(mp)
+
+	log.Infofc(ctx, "metrics: initialized otlp metrics (%s) with interval: %s", conf.OTLP.protocol(), interval)
+	return shutdownCloser(mp.Shutdown)
+}
+
+// HTTPMetricsMiddleware records the duration of requests and the number of active requests
+// as http.server.request.duration and http.server.active_requests of the OpenTelemetry semantic conventions.
+// If mp is nil, the global meter provider is used.
+func HTTPMetricsMiddleware(mp metric.MeterProvider) func(http.Handler) http.Handler {
+	if mp == nil {
+		mp = otel.GetMeterProvider()
+	}
+	meter := mp.Meter(meterName)
+
+	duration, err := meter.Float64Histogram(
+		"http.server.request.duration",
+		metric.WithUnit("s"),
+		metric.WithDescription("Duration of HTTP server requests."),
+		metric.WithExplicitBucketBoundaries(0.005, 0.01, 0.025, 0.05, 0.075, 0.1, 0.25, 0.5, 0.75, 1, 2.5, 5, 7.5, 10),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+	active, err := meter.Int64UpDownCounter(
+		"http.server.active_requests",
+		metric.WithUnit("{request}"),
+		metric.WithDescription("Number of active HTTP server requests."),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			attrs := []attribute.KeyValue{
+				attribute.String("http.request.method", r.Method),
+				attribute.String("url.scheme", scheme(r)),
+			}
+
+			active.Add(ctx, 1, metric.WithAttributes(attrs...))
+			defer active.Add(ctx, -1, metric.WithAttributes(attrs...))
+
+			// httpsnoop keeps optional interfaces of the writer such as http.Flusher and http.Hijacker
+			m := httpsnoop.CaptureMetrics(next, w, r)
+
+			duration.Record(ctx, m.Duration.Seconds(), metric.WithAttributes(
+				append(attrs, attribute.Int("http.response.status_code", m.Code))...,
+			))
+		})
+	}
+}
+
+func scheme(r *http.Request) string {
+	if r.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
@@ -0,0 +1,66 @@
+package appx
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestHTTPMetricsMiddleware(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	handler := HTTPMetricsMiddleware(mp)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, ok := w.(http.Flusher)
+		assert.True(t, ok)
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+
+	for _, path := range []string{"/", "/", "/missing"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	var rm metricdata.ResourceMetrics
+	assert.NoError(t, reader.Collect(context.Background(), &rm))
+	assert.Len(t, rm.ScopeMetrics, 1)
+	assert.Equal(t, meterName, rm.ScopeMetrics[0].Scope.Name)
+
+	metrics := map[string]metricdata.Metrics{}
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		metrics[m.Name] = m
+	}
+
+	duration, ok := metrics["http.server.request.duration"].Data.(metricdata.Histogram[float64])
+	assert.True(t, ok)
+	counts := map[int64]uint64{}
+	for _, dp := range duration.DataPoints {
+		status, _ := dp.Attributes.Value(attribute.Key("http.response.status_code"))
+		method, _ := dp.Attributes.Value(attribute.Key("http.request.method"))
+		assert.Equal(t, http.MethodGet, method.AsString())
+		counts[status.AsInt64()] = dp.Count
+	}
+	assert.Equal(t, map[int64]uint64{200: 2, 404: 1}, counts)
+
+	active, ok := metrics["http.server.active_requests"].Data.(metricdata.Sum[int64])
+	assert.True(t, ok)
+	assert.Len(t, active.DataPoints, 1)
+	assert.Equal(t, int64(0), active.DataPoints[0].Value)
+}
+
+func TestInitMetrics(t *testing.T) {
+	closer := InitMetrics(context.Background(), &MetricsConfig{
+		Name: "test",
+		OTLP: OTLPConfig{Protocol: OTLP_HTTP, Endpoint: "http://localhost:4318", Insecure: true},
+	})
+	assert.NotNil(t, closer)
+}
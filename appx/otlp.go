@@ -0,0 +1,148 @@
+package appx
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+type OTLPProtocol string
+
+const (
+	OTLP_GRPC = OTLPProtocol("grpc")
+	OTLP_HTTP = OTLPProtocol("http")
+)
+
+const otlpShutdownTimeout = 10 * time.Second
+
+// OTLPConfig configures OTLP exporters. Zero values fall back to the standard OTEL_EXPORTER_OTLP_* environment variables.
+type OTLPConfig struct {
+	// Protocol defaults to gRPC.
+	Protocol OTLPProtocol
+	// Endpoint is a host and port such as "localhost:4317", or a URL such as "https://collector:4318".
+	Endpoint string
+	// Insecure disables TLS.
+	Insecure bool
+	// Headers are sent with every export request, e.g. for authentication.
+	Headers map[string]string
+}
+
+func (c OTLPConfig) protocol() OTLPProtocol {
+	if c.Protocol == "" {
+		return OTLP_GRPC
+	}
+	return c.Protocol
+}
+
+func (c OTLPConfig) isURL() bool {
+	return strings.Contains(c.Endpoint, "://")
+}
+
+func newOTLPTraceExporter(ctx context.Context, c OTLPConfig) (*otlptrace.Exporter, error) {
+	switch c.protocol() {
+	case OTLP_GRPC:
+		var opts []otlptracegrpc.Option
+		if c.Endpoint != "" && c.isURL() {
+			opts = append(opts, otlptracegrpc.WithEndpointURL(c.Endpoint))
+		} else if c.Endpoint != "" {
+			opts = append(opts, otlptracegrpc.WithEndpoint(c.Endpoint))
+		}
+		if c.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		if len(c.Headers) > 0 {
+			opts = append(opts, otlptracegrpc.WithHeaders(c.Headers))
+		}
+		return otlptracegrpc.New(ctx, opts...)
+	case OTLP_HTTP:
+		var opts []otlptracehttp.Option
+		if c.Endpoint != "" && c.isURL() {
+			opts = append(opts, otlptracehttp.WithEndpointURL(c.Endpoint))
+		} else if c.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(c.Endpoint))
+		}
+		if c.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		if len(c.Headers) > 0 {
+			opts = append(opts, otlptracehttp.WithHeaders(c.Headers))
+		}
+		return otlptracehttp.New(ctx, opts...)
+	}
+	return nil, fmt.Errorf("unsupported otlp protocol: %s", c.Protocol)
+}
+
+func newOTLPMetricExporter(ctx context.Context, c OTLPConfig) (sdkmetric.Exporter, error) {
+	switch c.protocol() {
+	case OTLP_GRPC:
+		var opts []otlpmetricgrpc.Option
+		if c.Endpoint != "" && c.isURL() {
+			opts = append(opts, otlpmetricgrpc.WithEndpointURL(c.Endpoint))
+		} else if c.Endpoint != "" {
+			opts = append(opts, otlpmetricgrpc.WithEndpoint(c.Endpoint))
+		}
+		if c.Insecure {
+			opts = append(opts, otlpmetricgrpc.WithInsecure())
+		}
+		if len(c.Headers) > 0 {
+			opts = append(opts, otlpmetricgrpc.WithHeaders(c.Headers))
+		}
+		return otlpmetricgrpc.New(ctx, opts...)
+	case OTLP_HTTP:
+		var opts []otlpmetrichttp.Option
+		if c.Endpoint != "" && c.isURL() {
+			opts = append(opts, otlpmetrichttp.WithEndpointURL(c.Endpoint))
+		} else if c.Endpoint != "" {
+			opts = append(opts, otlpmetrichttp.WithEndpoint(c.Endpoint))
+		}
+		if c.Insecure {
+			opts = append(opts, otlpmetrichttp.WithInsecure())
+		}
+		if len(c.Headers) > 0 {
+			opts = append(opts, otlpmetrichttp.WithHeaders(c.Headers))
+		}
+		return otlpmetrichttp.New(ctx, opts...)
+	}
+	return nil, fmt.Errorf("unsupported otlp protocol: %s", c.Protocol)
+}
+
+// newResource describes the service. Attributes override ones from the environment.
+func newResource(ctx context.Context, name string, attrs map[string]string) (*resource.Resource, error) {
+	kvs := make([]attribute.KeyValue, 0, len(attrs)+1)
+	if name != "" {
+		kvs = append(kvs, attribute.String("service.name", name))
+	}
+	for _, k := range slices.Sorted(maps.Keys(attrs)) {
+		kvs = append(kvs, attribute.String(k, attrs[k]))
+	}
+	return resource.New(
+		ctx,
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(kvs...),
+	)
+}
+
+// shutdownCloser flushes and stops a provider on Close.
+type shutdownCloser func(context.Context) error
+
+var _ io.Closer = shutdownCloser(nil)
+
+func (f shutdownCloser) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), otlpShutdownTimeout)
+	defer cancel()
+	return f(ctx)
+}
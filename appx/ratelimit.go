@@ -0,0 +1,54 @@
+package appx
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/reearth/reearthx/util"
+)
+
+// RateLimiter counts requests for each key. Implementations backed by a shared store such as Redis
+// can be used to limit requests across multiple instances.
+type RateLimiter interface {
+	// Allow records a request for the key and returns true if the number of requests in the current window does not exceed the limit.
+	Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, error)
+}
+
+// MemoryRateLimiter is a fixed window RateLimiter that keeps counters in memory.
+type MemoryRateLimiter struct {
+	// lock makes reading and incrementing a counter atomic
+	lock sync.Mutex
+	// counters expire at the end of their windows
+	counters util.ExpiringMap[string, *rateCounter]
+}
+
+type rateCounter struct {
+	count int
+}
+
+var _ RateLimiter = (*MemoryRateLimiter)(nil)
+
+func NewMemoryRateLimiter() *MemoryRateLimiter {
+	return &MemoryRateLimiter{}
+}
+
+func (l *MemoryRateLimiter) Allow(_ context.Context, key string, limit int, window time.Duration) (bool, error) {
+	if limit <= 0 || window <= 0 {
+		return true, nil
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	c, ok := l.counters.Load(key)
+	if !ok {
+		c = &rateCounter{}
+		l.counters.Store(key, c, util.Now().Truncate(window).Add(window))
+	}
+	if c.count >= limit {
+		return false, nil
+	}
+	c.count++
+	return true, nil
+}
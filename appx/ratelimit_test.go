@@ -0,0 +1,30 @@
+package appx
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/reearth/reearthx/util"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryRateLimiter(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	defer util.MockNow(now)()
+
+	l := NewMemoryRateLimiter()
+	assert.True(t, lo.Must(l.Allow(ctx, "a", 2, time.Minute)))
+	assert.True(t, lo.Must(l.Allow(ctx, "a", 2, time.Minute)))
+	assert.False(t, lo.Must(l.Allow(ctx, "a", 2, time.Minute)))
+	assert.True(t, lo.Must(l.Allow(ctx, "b", 2, time.Minute)))
+	assert.True(t, lo.Must(l.Allow(ctx, "c", 0, time.Minute)))
+
+	// the counter is reset in the next window
+	util.MockNow(now.Add(time.Minute))
+	assert.True(t, lo.Must(l.Allow(ctx, "a", 2, time.Minute)))
+	_, ok := l.counters.Load("b")
+	assert.False(t, ok)
+}
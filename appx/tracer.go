@@ -11,6 +11,7 @@ import (
 	jaegerlog "github.com/uber/jaeger-client-go/log"
 	"github.com/uber/jaeger-lib/metrics"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 )
 
@@ -18,11 +19,17 @@ type Tracer string
 
 const TRACER_GCP = Tracer("gcp")
 const TRACER_JAEGER = Tracer("jaeger")
+const TRACER_OTLP = Tracer("otlp")
 
 type TracerConfig struct {
 	Name         string
 	Tracer       Tracer
 	TracerSample float64
+	// OTLP configures the exporter of TRACER_OTLP.
+	OTLP OTLPConfig
+	// ResourceAttributes are attached to all spans of TRACER_OTLP, e.g. "deployment.environment".
+	// OTEL_RESOURCE_ATTRIBUTES is also respected.
+	ResourceAttributes map[string]string
 }
 
 func InitTracer(ctx context.Context, conf *TracerConfig) io.Closer {
@@ -31,6 +38,8 @@ func InitTracer(ctx context.Context, conf *TracerConfig) io.Closer {
 		initGCPTracer(ctx, conf)
 	case TRACER_JAEGER:
 		return initJaegerTracer(conf)
+	case TRACER_OTLP:
+		return initOTLPTracer(ctx, conf)
 	}
 	return nil
 }
@@ -78,3 +87,28 @@ func initJaegerTracer(conf *TracerConfig) io.Closer {
 	log.Infof("tracer: initialized jaeger tracer with sample fraction: %g\n", conf.TracerSample)
 	return closer
 }
+
+func initOTLPTracer(ctx context.Context, conf *TracerConfig) io.Closer {
+	exporter, err := newOTLPTraceExporter(ctx, conf.OTLP)
+	if err != nil {
+		log.Fatalc(ctx, err)
+	}
+
+	res, err := newResource(ctx, conf.Name, conf.ResourceAttributes)
+	if err != nil {
+		log.Fatalc(ctx, err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		// follow the decision of the caller so that traces across services are not broken
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(conf.TracerSample))),
+	)
+
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	log.Infofc(ctx, "tracer: initialized otlp tracer (%s) with sample fraction: %g", conf.OTLP.protocol(), conf.TracerSample)
+	return shutdownCloser(tp.Shutdown)
+}
@@ -147,3 +147,49 @@ func TestInitTracer(t *testing.T) {
 		})
 	}
 }
+
+func TestInitTracer_OTLP(t *testing.T) {
+	for _, protocol := range []OTLPProtocol{OTLP_GRPC, OTLP_HTTP} {
+		t.Run(string(protocol), func(t *testing.T) {
+			logWriter := &testLogWriter{}
+			log.SetOutput(logWriter)
+			defer log.SetOutput(nil)
+
+			closer := InitTracer(context.Background(), &TracerConfig{
+				Name:         "test-otlp",
+				Tracer:       TRACER_OTLP,
+				TracerSample: 0.5,
+				OTLP: OTLPConfig{
+					Protocol: protocol,
+					Endpoint: "localhost:4317",
+					Insecure: true,
+				},
+				ResourceAttributes: map[string]string{"deployment.environment": "test"},
+			})
+			assert.NotNil(t, closer)
+			assert.IsType(t, &sdktrace.TracerProvider{}, otel.GetTracerProvider())
+			assert.NoError(t, closer.Close())
+			assert.Contains(t, logWriter.String(), "tracer: initialized otlp tracer ("+string(protocol)+") with sample fraction: 0.5")
+		})
+	}
+}
+
+func TestNewResource(t *testing.T) {
+	res, err := newResource(context.Background(), "svc", map[string]string{"deployment.environment": "test"})
+	assert.NoError(t, err)
+
+	attrs := map[string]string{}
+	for _, kv := range res.Attributes() {
+		attrs[string(kv.Key)] = kv.Value.Emit()
+	}
+	assert.Equal(t, "svc", attrs["service.name"])
+	assert.Equal(t, "test", attrs["deployment.environment"])
+	assert.Equal(t, "opentelemetry", attrs["telemetry.sdk.name"])
+}
+
+func TestNewOTLPTraceExporter_UnsupportedProtocol(t *testing.T) {
+	_, err := newOTLPTraceExporter(context.Background(), OTLPConfig{Protocol: "unknown"})
+	assert.EqualError(t, err, "unsupported otlp protocol: unknown")
+	_, err = newOTLPMetricExporter(context.Background(), OTLPConfig{Protocol: "unknown"})
+	assert.EqualError(t, err, "unsupported otlp protocol: unknown")
+}
@@ -278,6 +278,23 @@ var (
 	WebhookIDListFrom = idx.ListFrom[Webhook]
 )
 
+type WebhookDelivery struct{}
+
+func (WebhookDelivery) Type() string { return "webhook_delivery" }
+
+type (
+	WebhookDeliveryID     = idx.ID[WebhookDelivery]
+	WebhookDeliveryIDList = idx.List[WebhookDelivery]
+)
+
+var (
+	MustWebhookDeliveryID     = idx.Must[WebhookDelivery]
+	NewWebhookDeliveryID      = idx.New[WebhookDelivery]
+	WebhookDeliveryIDFrom     = idx.From[WebhookDelivery]
+	WebhookDeliveryIDFromRef  = idx.FromRef[WebhookDelivery]
+	WebhookDeliveryIDListFrom = idx.ListFrom[WebhookDelivery]
+)
+
 type Task struct{}
 
 func (Task) Type() string { return "task" }
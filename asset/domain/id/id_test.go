@@ -49,6 +49,9 @@ func TestAsset_Type(t *testing.T) {
 	we := Webhook{}
 	assert.Equal(t, "webhook", we.Type())
 
+	wd := WebhookDelivery{}
+	assert.Equal(t, "webhook_delivery", wd.Type())
+
 	wo := Workspace{}
 	assert.Equal(t, "workspace", wo.Type())
 
@@ -0,0 +1,199 @@
+package integration
+
+import (
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/reearth/reearthx/asset/domain/event"
+	"github.com/reearth/reearthx/util"
+)
+
+// MaxDeliveryHistory is the number of attempts kept in a delivery.
+const MaxDeliveryHistory = 10
+
+type DeliveryStatus string
+
+const (
+	DeliveryStatusPending   DeliveryStatus = "pending"
+	DeliveryStatusRetrying  DeliveryStatus = "retrying"
+	DeliveryStatusSucceeded DeliveryStatus = "succeeded"
+	DeliveryStatusFailed    DeliveryStatus = "failed"
+)
+
+// Delivery is a log of sending an event to a webhook.
+// The payload is kept as it was sent so that the delivery can be sent again later.
+type Delivery struct {
+	updatedAt   time.Time
+	eventType   event.Type
+	url         string
+	status      DeliveryStatus
+	error       string
+	payload     []byte
+	history     []*DeliveryAttempt
+	attempts    int
+	statusCode  int
+	id          DeliveryID
+	integration ID
+	webhook     WebhookID
+	event       EventID
+	replayOf    *DeliveryID
+}
+
+type DeliveryList []*Delivery
+
+func (d *Delivery) ID() DeliveryID {
+	return d.id
+}
+
+func (d *Delivery) Integration() ID {
+	return d.integration
+}
+
+func (d *Delivery) Webhook() WebhookID {
+	return d.webhook
+}
+
+func (d *Delivery) Event() EventID {
+	return d.event
+}
+
+func (d *Delivery) EventType() event.Type {
+	return d.eventType
+}
+
+func (d *Delivery) URL() string {
+	return d.url
+}
+
+// SetURL changes the URL where the payload is sent, such as when the URL of the webhook is updated before redelivery.
+func (d *Delivery) SetURL(url string) {
+	d.url = url
+}
+
+func (d *Delivery) Payload() []byte {
+	return slices.Clone(d.payload)
+}
+
+func (d *Delivery) Status() DeliveryStatus {
+	return d.status
+}
+
+// InProgress reports whether the delivery is waiting for the first attempt or a retry.
+func (d *Delivery) InProgress() bool {
+	return d.status == DeliveryStatusPending || d.status == DeliveryStatusRetrying
+}
+
+// Attempts returns how many times the payload has been sent.
+func (d *Delivery) Attempts() int {
+	return d.attempts
+}
+
+// StatusCode returns the response status of the last attempt, or 0 when no response was received.
+func (d *Delivery) StatusCode() int {
+	return d.statusCode
+}
+
+// Error returns the reason why the last attempt failed.
+func (d *Delivery) Error() string {
+	return d.error
+}
+
+// History returns the latest attempts from the oldest one.
+func (d *Delivery) History() []*DeliveryAttempt {
+	return slices.Clone(d.history)
+}
+
+// ReplayOf returns the ID of the delivery that this delivery replays.
+func (d *Delivery) ReplayOf() *DeliveryID {
+	return d.replayOf.CloneRef()
+}
+
+func (d *Delivery) CreatedAt() time.Time {
+	return d.id.Timestamp()
+}
+
+func (d *Delivery) UpdatedAt() time.Time {
+	if d.updatedAt.IsZero() {
+		return d.id.Timestamp()
+	}
+	return d.updatedAt
+}
+
+// AddAttempt records the result of sending the payload. The delivery succeeds when a 2xx response is received.
+// Only the latest attempts up to MaxDeliveryHistory are kept in the history.
+func (d *Delivery) AddAttempt(a *DeliveryAttempt) {
+	if a == nil {
+		return
+	}
+	d.attempts++
+	d.statusCode = a.ResponseStatus()
+	d.updatedAt = a.AttemptedAt()
+	d.error = a.Error()
+	if a.Succeeded() {
+		d.status = DeliveryStatusSucceeded
+	} else {
+		d.status = DeliveryStatusFailed
+	}
+	d.history = append(d.history, a)
+	if len(d.history) > MaxDeliveryHistory {
+		d.history = slices.Clone(d.history[len(d.history)-MaxDeliveryHistory:])
+	}
+}
+
+// SetRetrying marks the delivery as waiting for a retry. Deliveries that have not been attempted stay pending.
+func (d *Delivery) SetRetrying() {
+	if d.status != DeliveryStatusPending {
+		d.status = DeliveryStatusRetrying
+	}
+}
+
+// Replay returns a new delivery that sends the same payload again. The new delivery has its own attempts.
+func (d *Delivery) Replay() *Delivery {
+	return &Delivery{
+		id:          NewDeliveryID(),
+		integration: d.integration,
+		webhook:     d.webhook,
+		event:       d.event,
+		eventType:   d.eventType,
+		url:         d.url,
+		payload:     slices.Clone(d.payload),
+		status:      DeliveryStatusPending,
+		replayOf:    d.id.Ref(),
+	}
+}
+
+func (d *Delivery) Clone() *Delivery {
+	if d == nil {
+		return nil
+	}
+	return &Delivery{
+		id:          d.id.Clone(),
+		integration: d.integration.Clone(),
+		webhook:     d.webhook.Clone(),
+		event:       d.event.Clone(),
+		eventType:   d.eventType,
+		url:         d.url,
+		payload:     slices.Clone(d.payload),
+		status:      d.status,
+		attempts:    d.attempts,
+		statusCode:  d.statusCode,
+		error:       d.error,
+		updatedAt:   d.updatedAt,
+		history:     util.Map(d.history, func(a *DeliveryAttempt) *DeliveryAttempt { return a.Clone() }),
+		replayOf:    d.replayOf.CloneRef(),
+	}
+}
+
+// StatusError is the error of an attempt whose response status is not 2xx.
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return "unexpected response status: " + strconv.Itoa(e.StatusCode)
+}
+
+func (l DeliveryList) Clone() DeliveryList {
+	return util.Map(l, func(d *Delivery) *Delivery { return d.Clone() })
+}
@@ -0,0 +1,102 @@
+package integration
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// DeliveryAttempt is the result of sending a payload to a webhook once.
+// The payload is stored once in the delivery, so attempts only record the size and the SHA-256 hash of the request body.
+type DeliveryAttempt struct {
+	attemptedAt    time.Time
+	requestSize    int
+	requestHash    string
+	responseStatus int
+	latency        time.Duration
+	error          string
+}
+
+// NewDeliveryAttempt returns an attempt that sent the request body. A response status that is not 2xx is recorded as an error.
+func NewDeliveryAttempt(
+	attemptedAt time.Time,
+	requestBody []byte,
+	responseStatus int,
+	latency time.Duration,
+	err error,
+) *DeliveryAttempt {
+	h := sha256.Sum256(requestBody)
+	return NewDeliveryAttemptWith(attemptedAt, len(requestBody), hex.EncodeToString(h[:]), responseStatus, latency, err)
+}
+
+// NewDeliveryAttemptWith returns an attempt with the size and the hash of the request body that were recorded.
+func NewDeliveryAttemptWith(
+	attemptedAt time.Time,
+	requestSize int,
+	requestHash string,
+	responseStatus int,
+	latency time.Duration,
+	err error,
+) *DeliveryAttempt {
+	if err == nil && (responseStatus < 200 || responseStatus >= 300) {
+		err = &StatusError{StatusCode: responseStatus}
+	}
+	var msg string
+	if err != nil {
+		msg = err.Error()
+	}
+	return &DeliveryAttempt{
+		attemptedAt:    attemptedAt,
+		requestSize:    requestSize,
+		requestHash:    requestHash,
+		responseStatus: responseStatus,
+		latency:        latency,
+		error:          msg,
+	}
+}
+
+func (a *DeliveryAttempt) AttemptedAt() time.Time {
+	return a.attemptedAt
+}
+
+// RequestSize returns the size of the request body in bytes.
+func (a *DeliveryAttempt) RequestSize() int {
+	return a.requestSize
+}
+
+// RequestHash returns the hex-encoded SHA-256 hash of the request body.
+func (a *DeliveryAttempt) RequestHash() string {
+	return a.requestHash
+}
+
+// ResponseStatus returns the status of the response, or 0 when no response was received.
+func (a *DeliveryAttempt) ResponseStatus() int {
+	return a.responseStatus
+}
+
+// Latency returns the time from sending the request until the response or the error.
+func (a *DeliveryAttempt) Latency() time.Duration {
+	return a.latency
+}
+
+func (a *DeliveryAttempt) Error() string {
+	return a.error
+}
+
+func (a *DeliveryAttempt) Succeeded() bool {
+	return a.error == ""
+}
+
+func (a *DeliveryAttempt) Clone() *DeliveryAttempt {
+	if a == nil {
+		return nil
+	}
+	return &DeliveryAttempt{
+		attemptedAt:    a.attemptedAt,
+		requestSize:    a.requestSize,
+		requestHash:    a.requestHash,
+		responseStatus: a.responseStatus,
+		latency:        a.latency,
+		error:          a.error,
+	}
+}
@@ -0,0 +1,46 @@
+package integration
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDeliveryAttempt(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	a := NewDeliveryAttempt(now, []byte(`{"a":1}`), 200, 150*time.Millisecond, nil)
+	assert.Equal(t, now, a.AttemptedAt())
+	assert.Equal(t, 7, a.RequestSize())
+	assert.Equal(t, "015abd7f5cc57a2dd94b7590f04ad8084273905ee33ec5cebeae62276a97f862", a.RequestHash())
+	assert.Equal(t, 200, a.ResponseStatus())
+	assert.Equal(t, 150*time.Millisecond, a.Latency())
+	assert.Empty(t, a.Error())
+	assert.True(t, a.Succeeded())
+
+	a = NewDeliveryAttempt(now, nil, 404, 0, nil)
+	assert.Equal(t, "unexpected response status: 404", a.Error())
+	assert.False(t, a.Succeeded())
+	assert.Equal(t, 0, a.RequestSize())
+
+	a = NewDeliveryAttempt(now, nil, 0, time.Second, errors.New("timeout"))
+	assert.Equal(t, "timeout", a.Error())
+	assert.Equal(t, 0, a.ResponseStatus())
+	assert.False(t, a.Succeeded())
+}
+
+func TestDeliveryAttempt_Clone(t *testing.T) {
+	a := NewDeliveryAttempt(time.Now(), []byte(`{}`), 200, time.Second, nil)
+	c := a.Clone()
+	assert.Equal(t, a, c)
+	assert.NotSame(t, a, c)
+	assert.Nil(t, (*DeliveryAttempt)(nil).Clone())
+}
+
+func TestNewDeliveryAttemptWith(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	a := NewDeliveryAttempt(now, []byte(`{}`), 502, time.Second, nil)
+	assert.Equal(t, a, NewDeliveryAttemptWith(now, a.RequestSize(), a.RequestHash(), 502, time.Second, nil))
+}
@@ -0,0 +1,111 @@
+package integration
+
+import (
+	"time"
+
+	"github.com/reearth/reearthx/asset/domain/event"
+)
+
+type DeliveryBuilder struct {
+	d *Delivery
+}
+
+func NewDeliveryBuilder() *DeliveryBuilder {
+	return &DeliveryBuilder{d: &Delivery{}}
+}
+
+func (b *DeliveryBuilder) Build() (*Delivery, error) {
+	if b.d.id.IsNil() || b.d.integration.IsNil() || b.d.webhook.IsNil() {
+		return nil, ErrInvalidID
+	}
+	if b.d.status == "" {
+		b.d.status = DeliveryStatusPending
+	}
+	if b.d.updatedAt.IsZero() {
+		b.d.updatedAt = b.d.CreatedAt()
+	}
+	return b.d, nil
+}
+
+func (b *DeliveryBuilder) MustBuild() *Delivery {
+	r, err := b.Build()
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+func (b *DeliveryBuilder) NewID() *DeliveryBuilder {
+	b.d.id = NewDeliveryID()
+	return b
+}
+
+func (b *DeliveryBuilder) ID(id DeliveryID) *DeliveryBuilder {
+	b.d.id = id
+	return b
+}
+
+func (b *DeliveryBuilder) Integration(integration ID) *DeliveryBuilder {
+	b.d.integration = integration
+	return b
+}
+
+func (b *DeliveryBuilder) Webhook(webhook WebhookID) *DeliveryBuilder {
+	b.d.webhook = webhook
+	return b
+}
+
+func (b *DeliveryBuilder) Event(event EventID) *DeliveryBuilder {
+	b.d.event = event
+	return b
+}
+
+func (b *DeliveryBuilder) EventType(eventType event.Type) *DeliveryBuilder {
+	b.d.eventType = eventType
+	return b
+}
+
+func (b *DeliveryBuilder) URL(url string) *DeliveryBuilder {
+	b.d.url = url
+	return b
+}
+
+func (b *DeliveryBuilder) Payload(payload []byte) *DeliveryBuilder {
+	b.d.payload = payload
+	return b
+}
+
+func (b *DeliveryBuilder) Status(status DeliveryStatus) *DeliveryBuilder {
+	b.d.status = status
+	return b
+}
+
+func (b *DeliveryBuilder) Attempts(attempts int) *DeliveryBuilder {
+	b.d.attempts = attempts
+	return b
+}
+
+func (b *DeliveryBuilder) StatusCode(statusCode int) *DeliveryBuilder {
+	b.d.statusCode = statusCode
+	return b
+}
+
+func (b *DeliveryBuilder) Error(err string) *DeliveryBuilder {
+	b.d.error = err
+	return b
+}
+
+func (b *DeliveryBuilder) UpdatedAt(updatedAt time.Time) *DeliveryBuilder {
+	b.d.updatedAt = updatedAt
+	return b
+}
+
+func (b *DeliveryBuilder) History(history []*DeliveryAttempt) *DeliveryBuilder {
+	b.d.history = history
+	return b
+}
+
+func (b *DeliveryBuilder) ReplayOf(replayOf *DeliveryID) *DeliveryBuilder {
+	b.d.replayOf = replayOf
+	return b
+}
@@ -0,0 +1,67 @@
+package integration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/reearth/reearthx/asset/domain/event"
+	"github.com/reearth/reearthx/asset/domain/id"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeliveryBuilder_Build(t *testing.T) {
+	did := id.NewWebhookDeliveryID()
+	iid := id.NewIntegrationID()
+	wid := id.NewWebhookID()
+	eid := id.NewEventID()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	history := []*DeliveryAttempt{NewDeliveryAttempt(now, []byte(`{"a":1}`), 503, time.Second, nil)}
+	replayOf := id.NewWebhookDeliveryID().Ref()
+
+	d, err := NewDeliveryBuilder().
+		ID(did).
+		Integration(iid).
+		Webhook(wid).
+		Event(eid).
+		EventType(event.AssetCreate).
+		URL("https://example.com/hook").
+		Payload([]byte(`{"a":1}`)).
+		Status(DeliveryStatusFailed).
+		Attempts(2).
+		StatusCode(503).
+		Error("unexpected response status: 503").
+		UpdatedAt(now).
+		History(history).
+		ReplayOf(replayOf).
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, &Delivery{
+		id:          did,
+		integration: iid,
+		webhook:     wid,
+		event:       eid,
+		eventType:   event.AssetCreate,
+		url:         "https://example.com/hook",
+		payload:     []byte(`{"a":1}`),
+		status:      DeliveryStatusFailed,
+		attempts:    2,
+		statusCode:  503,
+		error:       "unexpected response status: 503",
+		updatedAt:   now,
+		history:     history,
+		replayOf:    replayOf,
+	}, d)
+
+	d, err = NewDeliveryBuilder().ID(did).Integration(iid).Webhook(wid).Build()
+	assert.NoError(t, err)
+	assert.Equal(t, DeliveryStatusPending, d.Status())
+	assert.Equal(t, did.Timestamp(), d.UpdatedAt())
+
+	_, err = NewDeliveryBuilder().NewID().Integration(iid).Build()
+	assert.ErrorIs(t, err, ErrInvalidID)
+	_, err = NewDeliveryBuilder().Integration(iid).Webhook(wid).Build()
+	assert.ErrorIs(t, err, ErrInvalidID)
+	_, err = NewDeliveryBuilder().NewID().Webhook(wid).Build()
+	assert.ErrorIs(t, err, ErrInvalidID)
+	assert.Panics(t, func() { NewDeliveryBuilder().MustBuild() })
+}
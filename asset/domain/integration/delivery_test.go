@@ -0,0 +1,127 @@
+package integration
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/reearth/reearthx/asset/domain/event"
+	"github.com/reearth/reearthx/asset/domain/id"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDelivery_AddAttempt(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	d := NewDeliveryBuilder().NewID().Integration(id.NewIntegrationID()).Webhook(id.NewWebhookID()).MustBuild()
+	assert.Equal(t, DeliveryStatusPending, d.Status())
+	assert.Equal(t, 0, d.Attempts())
+
+	a1 := NewDeliveryAttempt(now, nil, 0, time.Second, errors.New("connection refused"))
+	d.AddAttempt(a1)
+	assert.Equal(t, DeliveryStatusFailed, d.Status())
+	assert.Equal(t, "connection refused", d.Error())
+	assert.Equal(t, 0, d.StatusCode())
+	assert.Equal(t, now, d.UpdatedAt())
+
+	a2 := NewDeliveryAttempt(now.Add(time.Second), nil, 500, time.Millisecond, nil)
+	d.AddAttempt(a2)
+	assert.Equal(t, DeliveryStatusFailed, d.Status())
+	assert.Equal(t, "unexpected response status: 500", d.Error())
+	assert.Equal(t, 500, d.StatusCode())
+
+	a3 := NewDeliveryAttempt(now.Add(2*time.Second), nil, 204, time.Millisecond, nil)
+	d.AddAttempt(a3)
+	d.AddAttempt(nil)
+	assert.Equal(t, DeliveryStatusSucceeded, d.Status())
+	assert.Empty(t, d.Error())
+	assert.Equal(t, 204, d.StatusCode())
+	assert.Equal(t, 3, d.Attempts())
+	assert.Equal(t, now.Add(2*time.Second), d.UpdatedAt())
+	assert.Equal(t, []*DeliveryAttempt{a1, a2, a3}, d.History())
+
+	// only the latest attempts are kept
+	for i := 0; i < MaxDeliveryHistory; i++ {
+		d.AddAttempt(NewDeliveryAttempt(now.Add(time.Minute), nil, 200, 0, nil))
+	}
+	assert.Equal(t, MaxDeliveryHistory+3, d.Attempts())
+	assert.Len(t, d.History(), MaxDeliveryHistory)
+	assert.NotContains(t, d.History(), a3)
+}
+
+func TestDelivery_SetRetrying(t *testing.T) {
+	d := NewDeliveryBuilder().NewID().Integration(id.NewIntegrationID()).Webhook(id.NewWebhookID()).MustBuild()
+	assert.True(t, d.InProgress())
+
+	// deliveries that have not been attempted stay pending
+	d.SetRetrying()
+	assert.Equal(t, DeliveryStatusPending, d.Status())
+
+	d.AddAttempt(NewDeliveryAttempt(time.Now(), nil, 500, 0, nil))
+	assert.False(t, d.InProgress())
+	d.SetRetrying()
+	assert.Equal(t, DeliveryStatusRetrying, d.Status())
+	assert.True(t, d.InProgress())
+
+	d.AddAttempt(NewDeliveryAttempt(time.Now(), nil, 200, 0, nil))
+	assert.Equal(t, DeliveryStatusSucceeded, d.Status())
+	assert.False(t, d.InProgress())
+
+	// succeeded deliveries can be retried
+	d.SetRetrying()
+	assert.Equal(t, DeliveryStatusRetrying, d.Status())
+}
+
+func TestDelivery_Replay(t *testing.T) {
+	d := NewDeliveryBuilder().
+		NewID().
+		Integration(id.NewIntegrationID()).
+		Webhook(id.NewWebhookID()).
+		Event(id.NewEventID()).
+		EventType(event.ItemUpdate).
+		URL("https://example.com").
+		Payload([]byte(`{}`)).
+		MustBuild()
+	d.AddAttempt(NewDeliveryAttempt(time.Now(), []byte(`{}`), 200, 0, nil))
+
+	r := d.Replay()
+	assert.NotEqual(t, d.ID(), r.ID())
+	assert.Equal(t, d.ID().Ref(), r.ReplayOf())
+	assert.Nil(t, d.ReplayOf())
+	assert.Equal(t, d.Integration(), r.Integration())
+	assert.Equal(t, d.Webhook(), r.Webhook())
+	assert.Equal(t, d.Event(), r.Event())
+	assert.Equal(t, d.EventType(), r.EventType())
+	assert.Equal(t, d.URL(), r.URL())
+	assert.Equal(t, d.Payload(), r.Payload())
+	assert.Equal(t, DeliveryStatusPending, r.Status())
+	assert.Equal(t, 0, r.Attempts())
+	assert.Empty(t, r.History())
+}
+
+func TestDelivery_Clone(t *testing.T) {
+	d := NewDeliveryBuilder().
+		NewID().
+		Integration(id.NewIntegrationID()).
+		Webhook(id.NewWebhookID()).
+		Event(id.NewEventID()).
+		EventType(event.ItemCreate).
+		URL("https://example.com").
+		Payload([]byte(`{}`)).
+		Attempts(1).
+		StatusCode(200).
+		Status(DeliveryStatusSucceeded).
+		History([]*DeliveryAttempt{NewDeliveryAttempt(time.Now(), []byte(`{}`), 200, time.Second, nil)}).
+		ReplayOf(id.NewWebhookDeliveryID().Ref()).
+		MustBuild()
+
+	c := d.Clone()
+	assert.Equal(t, d, c)
+	assert.NotSame(t, d, c)
+	assert.NotSame(t, d.History()[0], c.History()[0])
+	assert.NotSame(t, d.ReplayOf(), c.ReplayOf())
+	assert.Nil(t, (*Delivery)(nil).Clone())
+
+	p := d.Payload()
+	p[0] = '['
+	assert.Equal(t, []byte(`{}`), d.Payload())
+}
@@ -6,17 +6,20 @@ import (
 )
 
 type (
-	ID        = id.IntegrationID
-	WebhookID = id.WebhookID
-	UserID    = accountdomain.UserID
-	ModelID   = id.ModelID
+	ID         = id.IntegrationID
+	WebhookID  = id.WebhookID
+	DeliveryID = id.WebhookDeliveryID
+	EventID    = id.EventID
+	UserID     = accountdomain.UserID
+	ModelID    = id.ModelID
 )
 
 var (
-	NewID        = id.NewIntegrationID
-	NewWebhookID = id.NewWebhookID
-	MustID       = id.MustIntegrationID
-	IDFrom       = id.IntegrationIDFrom
-	IDFromRef    = id.IntegrationIDFromRef
-	ErrInvalidID = id.ErrInvalidID
+	NewID         = id.NewIntegrationID
+	NewWebhookID  = id.NewWebhookID
+	NewDeliveryID = id.NewWebhookDeliveryID
+	MustID        = id.MustIntegrationID
+	IDFrom        = id.IntegrationIDFrom
+	IDFromRef     = id.IntegrationIDFromRef
+	ErrInvalidID  = id.ErrInvalidID
 )
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/reearth/reearthx/asset/domain/event"
 	"github.com/reearth/reearthx/asset/domain/id"
 
 	"github.com/reearth/reearthx/account/accountdomain"
@@ -1317,3 +1318,14 @@ func TestIntegration_RandomToken(t *testing.T) {
 	assert.True(t, strings.HasPrefix(i.token, "secret_"))
 	assert.Equal(t, 50, len(i.token))
 }
+
+func TestIntegration_ActiveWebhooks(t *testing.T) {
+	w1 := NewWebhookBuilder().NewID().Active(true).Trigger(WebhookTrigger{event.ItemCreate: true}).MustBuild()
+	w2 := NewWebhookBuilder().NewID().Active(false).Trigger(WebhookTrigger{event.ItemCreate: true}).MustBuild()
+	w3 := NewWebhookBuilder().NewID().Active(true).Trigger(WebhookTrigger{event.ItemUpdate: true}).MustBuild()
+	i := New().NewID().Webhook([]*Webhook{w1, w2, w3}).MustBuild()
+
+	assert.Equal(t, []*Webhook{w1}, i.ActiveWebhooks(event.ItemCreate))
+	assert.Equal(t, []*Webhook{w3}, i.ActiveWebhooks(event.ItemUpdate))
+	assert.Empty(t, i.ActiveWebhooks(event.AssetCreate))
+}
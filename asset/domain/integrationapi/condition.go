@@ -1,6 +1,9 @@
 package integrationapi
 
 import (
+	"encoding/json"
+
+	geojson "github.com/paulmach/go.geojson"
 	"github.com/reearth/reearthx/asset/domain/item/view"
 	"github.com/samber/lo"
 )
@@ -108,6 +111,20 @@ func (i *Condition) Into() *view.Condition {
 			},
 		}
 	}
+	if i.Geo != nil {
+		return &view.Condition{
+			ConditionType: view.ConditionTypeGeo,
+			GeoCondition: &view.GeoCondition{
+				Field:    i.Geo.FieldId.Into(),
+				Op:       i.Geo.Operator.Into(),
+				BBox:     lo.FromPtr(i.Geo.Bbox),
+				Polygon:  lo.FromPtr(i.Geo.Polygon),
+				Geometry: toGeometry(i.Geo.Geometry),
+				Point:    lo.FromPtr(i.Geo.Point),
+				Distance: lo.FromPtr(i.Geo.Distance),
+			},
+		}
+	}
 	if i.And != nil {
 		return &view.Condition{
 			ConditionType: view.ConditionTypeAnd,
@@ -220,6 +237,36 @@ func (e ConditionNullableOperator) Into() view.NullableOperator {
 	}
 }
 
+func (e ConditionGeoOperator) Into() view.GeoOperator {
+	switch e {
+	case WithinBbox:
+		return view.GeoOperatorWithinBBox
+	case WithinPolygon:
+		return view.GeoOperatorWithinPolygon
+	case Intersects:
+		return view.GeoOperatorIntersects
+	case Near:
+		return view.GeoOperatorNear
+	default:
+		return ""
+	}
+}
+
+func toGeometry(g *map[string]any) *geojson.Geometry {
+	if g == nil {
+		return nil
+	}
+	b, err := json.Marshal(g)
+	if err != nil {
+		return nil
+	}
+	res, err := geojson.UnmarshalGeometry(b)
+	if err != nil {
+		return nil
+	}
+	return res
+}
+
 func (e ConditionMultipleOperator) Into() view.MultipleOperator {
 	switch e {
 	case IncludesAny:
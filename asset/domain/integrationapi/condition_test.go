@@ -1,9 +1,11 @@
 package integrationapi
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
+	geojson "github.com/paulmach/go.geojson"
 	"github.com/reearth/reearthx/asset/domain/id"
 	"github.com/reearth/reearthx/asset/domain/item/view"
 
@@ -330,6 +332,76 @@ func TestConditionMultipleOperator_Into(t *testing.T) {
 	}
 }
 
+func TestConditionGeoOperator_Into(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    ConditionGeoOperator
+		expected view.GeoOperator
+	}{
+		{"success WithinBbox", WithinBbox, view.GeoOperatorWithinBBox},
+		{"success WithinPolygon", WithinPolygon, view.GeoOperatorWithinPolygon},
+		{"success Intersects", Intersects, view.GeoOperatorIntersects},
+		{"success Near", Near, view.GeoOperatorNear},
+		{"success default case", ConditionGeoOperator("99"), ""},
+	}
+
+	for _, test := range tests {
+		t.Run(string(test.name), func(t *testing.T) {
+			t.Parallel()
+			result := test.input.Into()
+			assert.Equal(t, test.expected, result)
+		})
+	}
+}
+
+func TestConditionInto_Geo(t *testing.T) {
+	fid := id.NewFieldID()
+	field := view.FieldSelector{Type: view.FieldTypeField, ID: &fid}
+	tests := []struct {
+		name string
+		json string
+		want *view.GeoCondition
+	}{
+		{
+			name: "bbox",
+			json: `{"geo":{"fieldId":{"type":"field","fieldId":"` + fid.String() + `"},"operator":"withinBbox","bbox":[1,2,3,4]}}`,
+			want: &view.GeoCondition{Field: field, Op: view.GeoOperatorWithinBBox, BBox: []float64{1, 2, 3, 4}},
+		},
+		{
+			name: "polygon",
+			json: `{"geo":{"fieldId":{"type":"field","fieldId":"` + fid.String() + `"},"operator":"withinPolygon","polygon":[[[0,0],[1,0],[1,1],[0,0]]]}}`,
+			want: &view.GeoCondition{Field: field, Op: view.GeoOperatorWithinPolygon, Polygon: [][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}},
+		},
+		{
+			name: "intersects",
+			json: `{"geo":{"fieldId":{"type":"field","fieldId":"` + fid.String() + `"},"operator":"intersects","geometry":{"type":"Point","coordinates":[1,2]}}}`,
+			want: &view.GeoCondition{Field: field, Op: view.GeoOperatorIntersects, Geometry: geojson.NewPointGeometry([]float64{1, 2})},
+		},
+		{
+			name: "intersects invalid geometry",
+			json: `{"geo":{"fieldId":{"type":"field","fieldId":"` + fid.String() + `"},"operator":"intersects","geometry":{"type":"Point","coordinates":"x"}}}`,
+			want: &view.GeoCondition{Field: field, Op: view.GeoOperatorIntersects},
+		},
+		{
+			name: "near",
+			json: `{"geo":{"fieldId":{"type":"field","fieldId":"` + fid.String() + `"},"operator":"near","point":[139.7,35.6],"distance":1000}}`,
+			want: &view.GeoCondition{Field: field, Op: view.GeoOperatorNear, Point: []float64{139.7, 35.6}, Distance: 1000},
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			var c Condition
+			assert.NoError(t, json.Unmarshal([]byte(tc.json), &c))
+			assert.Equal(t, &view.Condition{
+				ConditionType: view.ConditionTypeGeo,
+				GeoCondition:  tc.want,
+			}, c.Into())
+		})
+	}
+}
+
 func TestFieldSelector_Into(t *testing.T) {
 	fieldType := FieldSelector{
 		FieldId: id.NewFieldID().Ref(),
@@ -93,6 +93,14 @@ const (
 	ConditionBoolOperatorNotEquals ConditionBoolOperator = "notEquals"
 )
 
+// Defines values for ConditionGeoOperator.
+const (
+	Intersects    ConditionGeoOperator = "intersects"
+	Near          ConditionGeoOperator = "near"
+	WithinBbox    ConditionGeoOperator = "withinBbox"
+	WithinPolygon ConditionGeoOperator = "withinPolygon"
+)
+
 // Defines values for ConditionMultipleOperator.
 const (
 	IncludesAll    ConditionMultipleOperator = "includesAll"
@@ -427,6 +435,15 @@ type Condition struct {
 		Operator ConditionBoolOperator `json:"operator"`
 		Value    bool                  `json:"value"`
 	} `json:"bool,omitempty"`
+	Geo *struct {
+		Bbox     *[]float64              `json:"bbox,omitempty"`
+		Distance *float64                `json:"distance,omitempty"`
+		FieldId  FieldSelector           `json:"fieldId"`
+		Geometry *map[string]interface{} `json:"geometry,omitempty"`
+		Operator ConditionGeoOperator    `json:"operator"`
+		Point    *[]float64              `json:"point,omitempty"`
+		Polygon  *[][][]float64          `json:"polygon,omitempty"`
+	} `json:"geo,omitempty"`
 	Multiple *struct {
 		FieldId  FieldSelector             `json:"fieldId"`
 		Operator ConditionMultipleOperator `json:"operator"`
@@ -460,6 +477,9 @@ type ConditionBasicOperator string
 // ConditionBoolOperator defines model for Condition.Bool.Operator.
 type ConditionBoolOperator string
 
+// ConditionGeoOperator defines model for Condition.Geo.Operator.
+type ConditionGeoOperator string
+
 // ConditionMultipleOperator defines model for Condition.Multiple.Operator.
 type ConditionMultipleOperator string
 
@@ -221,6 +221,29 @@ func (i *Item) AssetIDs() AssetIDList {
 	})
 }
 
+// TextValues returns the values of text-like fields, which are the targets of full-text search.
+func (i *Item) TextValues() []string {
+	var res []string
+	for _, f := range i.fields {
+		if f == nil || !textTypes[f.Type()] {
+			continue
+		}
+		vv, _ := f.Value().ValuesString()
+		res = append(res, lo.FilterMap(vv, func(v value.String, _ int) (string, bool) {
+			return v, v != ""
+		})...)
+	}
+	return res
+}
+
+var textTypes = map[value.Type]bool{
+	value.TypeText:     true,
+	value.TypeTextArea: true,
+	value.TypeRichText: true,
+	value.TypeMarkdown: true,
+	value.TypeSelect:   true,
+}
+
 func (i *Item) GetTitle(s *schema.Schema) *string {
 	if s == nil || s.TitleField() == nil {
 		return nil
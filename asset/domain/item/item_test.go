@@ -215,6 +215,20 @@ func TestItem_AssetIDs(t *testing.T) {
 	}).AssetIDs())
 }
 
+func TestItem_TextValues(t *testing.T) {
+	assert.Equal(t, []string{"aa", "bb", "cc", "# dd"}, (&Item{
+		fields: []*Field{
+			{value: value.New(value.TypeAsset, id.NewAssetID()).AsMultiple()},
+			{value: value.NewMultiple(value.TypeText, []any{"aa", "bb"})},
+			{value: value.New(value.TypeInteger, 1).AsMultiple()},
+			{value: value.New(value.TypeTextArea, "cc").AsMultiple()},
+			{value: value.New(value.TypeGeometryObject, `{"type":"Point","coordinates":[1,2]}`).AsMultiple()},
+			{value: value.New(value.TypeMarkdown, "# dd").AsMultiple()},
+			nil,
+		},
+	}).TextValues())
+}
+
 func TestItem_User(t *testing.T) {
 	f1 := NewField(id.NewFieldID(), value.TypeText.Value("foo").AsMultiple(), nil)
 	uid := accountdomain.NewUserID()
@@ -11,11 +11,12 @@ type Query struct {
 	schema *id.SchemaID
 	ref    *version.Ref
 
-	sort    *view.Sort
-	filter  *view.Condition
-	keyword string
-	project id.ProjectID
-	model   id.ModelID
+	sort     *view.Sort
+	filter   *view.Condition
+	keyword  string
+	language string
+	project  id.ProjectID
+	model    id.ModelID
 }
 
 func NewQuery(
@@ -44,10 +45,39 @@ func (q *Query) WithFilter(filter *view.Condition) *Query {
 	return q
 }
 
+// WithGeoFilter adds a geospatial condition to the filter, combining it with the existing filter by AND.
+func (q *Query) WithGeoFilter(geo view.GeoCondition) *Query {
+	c := view.Condition{
+		ConditionType: view.ConditionTypeGeo,
+		GeoCondition:  &geo,
+	}
+	if q.filter == nil {
+		q.filter = &c
+		return q
+	}
+	q.filter = &view.Condition{
+		ConditionType: view.ConditionTypeAnd,
+		AndCondition: &view.AndCondition{
+			Conditions: []view.Condition{*q.filter, c},
+		},
+	}
+	return q
+}
+
+// WithLanguage sets the language of the keyword, which full-text search uses to choose an analyzer.
+func (q *Query) WithLanguage(lang string) *Query {
+	q.language = lang
+	return q
+}
+
 func (q *Query) Keyword() string {
 	return q.keyword
 }
 
+func (q *Query) Language() string {
+	return q.language
+}
+
 func (q *Query) Project() id.ProjectID {
 	return q.project
 }
@@ -61,6 +61,44 @@ func TestQuery_WithFilter(t *testing.T) {
 	assert.Equal(t, f, q.Filter())
 }
 
+func TestQuery_WithGeoFilter(t *testing.T) {
+	fid := id.NewFieldID()
+	geo := view.GeoCondition{
+		Field: view.FieldSelector{Type: view.FieldTypeField, ID: &fid},
+		Op:    view.GeoOperatorNear,
+		Point: []float64{139.7, 35.6},
+	}
+
+	q := &Query{}
+	assert.Equal(t, q, q.WithGeoFilter(geo))
+	assert.Equal(t, &view.Condition{
+		ConditionType: view.ConditionTypeGeo,
+		GeoCondition:  &geo,
+	}, q.Filter())
+	assert.Equal(t, view.FieldSelectorList{geo.Field}, q.ItemFields())
+
+	f := view.Condition{
+		ConditionType: view.ConditionTypeBool,
+		BoolCondition: &view.BoolCondition{Field: view.FieldSelector{Type: view.FieldTypeId}},
+	}
+	q = (&Query{}).WithFilter(&f).WithGeoFilter(geo)
+	assert.Equal(t, &view.Condition{
+		ConditionType: view.ConditionTypeAnd,
+		AndCondition: &view.AndCondition{
+			Conditions: []view.Condition{
+				f,
+				{ConditionType: view.ConditionTypeGeo, GeoCondition: &geo},
+			},
+		},
+	}, q.Filter())
+}
+
+func TestQuery_WithLanguage(t *testing.T) {
+	q := &Query{}
+	assert.Equal(t, q, q.WithLanguage("ja"))
+	assert.Equal(t, "ja", q.Language())
+}
+
 func TestQuery_Project(t *testing.T) {
 	pid := id.NewProjectID()
 	q := &Query{
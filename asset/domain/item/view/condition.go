@@ -14,6 +14,7 @@ const (
 	ConditionTypeString   ConditionType = "STRING"
 	ConditionTypeNumber   ConditionType = "NUMBER"
 	ConditionTypeTime     ConditionType = "TIME"
+	ConditionTypeGeo      ConditionType = "GEO"
 )
 
 type Condition struct {
@@ -26,6 +27,7 @@ type Condition struct {
 	StringCondition   *StringCondition
 	NumberCondition   *NumberCondition
 	TimeCondition     *TimeCondition
+	GeoCondition      *GeoCondition
 	ConditionType     ConditionType
 }
 
@@ -81,6 +83,10 @@ func (c Condition) FieldsByType(t FieldType) FieldSelectorList {
 		if c.TimeCondition.Field.Type == t {
 			res = append(res, c.TimeCondition.Field)
 		}
+	case ConditionTypeGeo:
+		if c.GeoCondition.Field.Type == t {
+			res = append(res, c.GeoCondition.Field)
+		}
 	}
 	return res
 }
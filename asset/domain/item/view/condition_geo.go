@@ -0,0 +1,50 @@
+package view
+
+import (
+	geojson "github.com/paulmach/go.geojson"
+)
+
+type GeoOperator string
+
+const (
+	GeoOperatorWithinBBox    GeoOperator = "WITHIN_BBOX"
+	GeoOperatorWithinPolygon GeoOperator = "WITHIN_POLYGON"
+	GeoOperatorIntersects    GeoOperator = "INTERSECTS"
+	GeoOperatorNear          GeoOperator = "NEAR"
+)
+
+// GeoCondition filters items by the GeoJSON values of a geometry field.
+// BBox ([minLng, minLat, maxLng, maxLat]) is used by WITHIN_BBOX, Polygon by WITHIN_POLYGON,
+// Geometry by INTERSECTS, and Point ([lng, lat]) with Distance in meters by NEAR.
+type GeoCondition struct {
+	Field    FieldSelector
+	Op       GeoOperator
+	BBox     []float64
+	Polygon  [][][]float64
+	Geometry *geojson.Geometry
+	Point    []float64
+	Distance float64
+}
+
+// Region returns the geometry the field values are compared with,
+// or nil when the operator is NEAR or the condition is incomplete.
+func (c GeoCondition) Region() *geojson.Geometry {
+	switch c.Op {
+	case GeoOperatorWithinBBox:
+		if len(c.BBox) != 4 {
+			return nil
+		}
+		minX, minY, maxX, maxY := c.BBox[0], c.BBox[1], c.BBox[2], c.BBox[3]
+		return geojson.NewPolygonGeometry([][][]float64{{
+			{minX, minY}, {maxX, minY}, {maxX, maxY}, {minX, maxY}, {minX, minY},
+		}})
+	case GeoOperatorWithinPolygon:
+		if len(c.Polygon) == 0 {
+			return nil
+		}
+		return geojson.NewPolygonGeometry(c.Polygon)
+	case GeoOperatorIntersects:
+		return c.Geometry
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package view
+
+import (
+	"testing"
+
+	geojson "github.com/paulmach/go.geojson"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeoCondition_Region(t *testing.T) {
+	assert.Equal(t, geojson.NewPolygonGeometry([][][]float64{{
+		{1, 2}, {3, 2}, {3, 4}, {1, 4}, {1, 2},
+	}}), GeoCondition{Op: GeoOperatorWithinBBox, BBox: []float64{1, 2, 3, 4}}.Region())
+	assert.Nil(t, GeoCondition{Op: GeoOperatorWithinBBox, BBox: []float64{1, 2}}.Region())
+
+	p := [][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}
+	assert.Equal(t, geojson.NewPolygonGeometry(p), GeoCondition{Op: GeoOperatorWithinPolygon, Polygon: p}.Region())
+	assert.Nil(t, GeoCondition{Op: GeoOperatorWithinPolygon}.Region())
+
+	g := geojson.NewPointGeometry([]float64{1, 1})
+	assert.Equal(t, g, GeoCondition{Op: GeoOperatorIntersects, Geometry: g}.Region())
+	assert.Nil(t, GeoCondition{Op: GeoOperatorNear, Point: []float64{1, 1}}.Region())
+}
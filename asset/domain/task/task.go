@@ -37,9 +37,10 @@ func (t *CompressAssetPayload) Payload() Payload {
 }
 
 type WebhookPayload struct {
-	Webhook  *integration.Webhook
-	Event    *event.Event[any]
-	Override any
+	Webhook     *integration.Webhook
+	Event       *event.Event[any]
+	Override    any
+	Integration integration.ID
 }
 
 func (t WebhookPayload) Payload() Payload {
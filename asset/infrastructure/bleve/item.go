@@ -0,0 +1,219 @@
+package bleve
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	blevesearch "github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/keyword"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/standard"
+	"github.com/blevesearch/bleve/v2/analysis/lang/cjk"
+	"github.com/blevesearch/bleve/v2/analysis/lang/de"
+	"github.com/blevesearch/bleve/v2/analysis/lang/en"
+	"github.com/blevesearch/bleve/v2/analysis/lang/es"
+	"github.com/blevesearch/bleve/v2/analysis/lang/fr"
+	"github.com/blevesearch/bleve/v2/analysis/lang/it"
+	"github.com/blevesearch/bleve/v2/analysis/lang/pt"
+	"github.com/blevesearch/bleve/v2/analysis/lang/ru"
+	"github.com/blevesearch/bleve/v2/mapping"
+	"github.com/blevesearch/bleve/v2/search"
+	"github.com/blevesearch/bleve/v2/search/query"
+	"github.com/reearth/reearthx/asset/domain/id"
+	"github.com/reearth/reearthx/asset/domain/item"
+	"github.com/reearth/reearthx/asset/usecase/repo"
+	"github.com/samber/lo"
+)
+
+const (
+	defaultLimit = 1000
+	textField    = "text"
+	// prefixBoost keeps prefix matches below whole word matches
+	prefixBoost = 0.5
+)
+
+var analyzers = map[string]string{
+	"de": de.AnalyzerName,
+	"en": en.AnalyzerName,
+	"es": es.AnalyzerName,
+	"fr": fr.AnalyzerName,
+	"it": it.AnalyzerName,
+	"ja": cjk.AnalyzerName,
+	"ko": cjk.AnalyzerName,
+	"pt": pt.AnalyzerName,
+	"ru": ru.AnalyzerName,
+	"zh": cjk.AnalyzerName,
+}
+
+var ErrUnsupportedLanguage = errors.New("unsupported language")
+
+var _ repo.ItemSearchIndex = &ItemIndex{}
+
+// ItemIndex is an embedded full-text index of items.
+// Texts are indexed with the standard analyzer and with the analyzer of each language given to NewItemIndex.
+type ItemIndex struct {
+	index     blevesearch.Index
+	languages []string
+	limit     int
+}
+
+// NewItemIndex opens the index at the path or creates it when it does not exist. An empty path creates an in-memory index.
+// languages are codes such as "en" and "ja" whose analyzers are used when a query has the same language,
+// and they must be the same as the ones the index was created with.
+func NewItemIndex(path string, languages ...string) (*ItemIndex, error) {
+	languages = lo.Uniq(lo.Map(languages, func(l string, _ int) string { return strings.ToLower(l) }))
+	for _, l := range languages {
+		if _, ok := analyzers[l]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedLanguage, l)
+		}
+	}
+
+	var index blevesearch.Index
+	var err error
+	if path == "" {
+		index, err = blevesearch.NewMemOnly(indexMapping(languages))
+	} else if _, serr := os.Stat(path); serr == nil {
+		index, err = blevesearch.Open(path)
+	} else {
+		index, err = blevesearch.New(path, indexMapping(languages))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &ItemIndex{
+		index:     index,
+		languages: languages,
+		limit:     defaultLimit,
+	}, nil
+}
+
+func indexMapping(languages []string) mapping.IndexMapping {
+	keywordField := mapping.NewKeywordFieldMapping()
+	keywordField.Store = false
+	textFieldMapping := func(analyzer string) *mapping.FieldMapping {
+		f := mapping.NewTextFieldMapping()
+		f.Analyzer = analyzer
+		f.Store = false
+		f.IncludeInAll = false
+		return f
+	}
+
+	doc := mapping.NewDocumentStaticMapping()
+	doc.AddFieldMappingsAt("project", keywordField)
+	doc.AddFieldMappingsAt("model", keywordField)
+	doc.AddFieldMappingsAt("schema", keywordField)
+	doc.AddFieldMappingsAt(textField, textFieldMapping(standard.Name))
+	for _, l := range languages {
+		doc.AddFieldMappingsAt(languageField(l), textFieldMapping(analyzers[l]))
+	}
+
+	m := mapping.NewIndexMapping()
+	m.DefaultMapping = doc
+	m.DefaultAnalyzer = keyword.Name
+	return m
+}
+
+func (i *ItemIndex) Close() error {
+	return i.index.Close()
+}
+
+func (i *ItemIndex) Index(ctx context.Context, items item.List) error {
+	b := i.index.NewBatch()
+	for _, it := range items {
+		if it == nil {
+			continue
+		}
+		text := strings.Join(it.TextValues(), "\n")
+		doc := map[string]any{
+			"project": it.Project().String(),
+			"model":   it.Model().String(),
+			"schema":  it.Schema().String(),
+			textField: text,
+		}
+		for _, l := range i.languages {
+			doc[languageField(l)] = text
+		}
+		if err := b.Index(it.ID().String(), doc); err != nil {
+			return err
+		}
+	}
+	return i.index.Batch(b)
+}
+
+func (i *ItemIndex) Delete(ctx context.Context, ids id.ItemIDList) error {
+	b := i.index.NewBatch()
+	for _, iid := range ids {
+		b.Delete(iid.String())
+	}
+	return i.index.Batch(b)
+}
+
+func (i *ItemIndex) Search(ctx context.Context, q *item.Query) (id.ItemIDList, error) {
+	terms := strings.Fields(strings.ToLower(q.Keyword()))
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	match := blevesearch.NewMatchQuery(q.Keyword())
+	match.SetField(i.textField(q.Language()))
+	// the standard analyzer only lower-cases words, so the last word can be compared with terms as it is
+	prefix := blevesearch.NewPrefixQuery(terms[len(terms)-1])
+	prefix.SetField(textField)
+	prefix.SetBoost(prefixBoost)
+
+	conds := []query.Query{
+		term("project", q.Project().String()),
+		term("model", q.Model().String()),
+		blevesearch.NewDisjunctionQuery(match, prefix),
+	}
+	if q.Schema() != nil {
+		conds = append(conds, term("schema", q.Schema().String()))
+	}
+
+	res, err := i.index.SearchInContext(ctx, blevesearch.NewSearchRequestOptions(
+		blevesearch.NewConjunctionQuery(conds...),
+		i.limit,
+		0,
+		false,
+	))
+	if err != nil {
+		return nil, err
+	}
+	if res.Total > uint64(i.limit) {
+		return nil, repo.ErrTooManySearchResults
+	}
+
+	return id.ItemIDListFrom(lo.Map(res.Hits, func(h *search.DocumentMatch, _ int) string {
+		return h.ID
+	}))
+}
+
+func (i *ItemIndex) Count(ctx context.Context, m id.ModelID) (int64, error) {
+	res, err := i.index.SearchInContext(ctx, blevesearch.NewSearchRequestOptions(term("model", m.String()), 0, 0, false))
+	if err != nil {
+		return 0, err
+	}
+	return int64(res.Total), nil
+}
+
+// textField returns the field analyzed for the language, or the standard field when the language is not indexed.
+func (i *ItemIndex) textField(lang string) string {
+	lang = strings.ToLower(lang)
+	if lo.Contains(i.languages, lang) {
+		return languageField(lang)
+	}
+	return textField
+}
+
+func languageField(lang string) string {
+	return textField + "_" + lang
+}
+
+func term(field, v string) query.Query {
+	q := blevesearch.NewTermQuery(v)
+	q.SetField(field)
+	return q
+}
@@ -0,0 +1,143 @@
+package bleve
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/reearth/reearthx/account/accountdomain"
+	"github.com/reearth/reearthx/asset/domain/id"
+	"github.com/reearth/reearthx/asset/domain/item"
+	"github.com/reearth/reearthx/asset/domain/schema"
+	"github.com/reearth/reearthx/asset/domain/value"
+	"github.com/reearth/reearthx/asset/infrastructure/memory"
+	"github.com/reearth/reearthx/asset/usecase/repo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestItemIndex(t *testing.T) {
+	ctx := context.Background()
+	pid := id.NewProjectID()
+	mid := id.NewModelID()
+	sid := id.NewSchemaID()
+	fid := id.NewFieldID()
+	newItem := func(project id.ProjectID, texts ...any) *item.Item {
+		return item.New().NewID().Schema(sid).Model(mid).Project(project).Thread(id.NewThreadID().Ref()).
+			Fields([]*item.Field{item.NewField(fid, value.NewMultiple(value.TypeText, texts), nil)}).
+			MustBuild()
+	}
+	i1 := newItem(pid, "Running shoes", "for marathon")
+	i2 := newItem(pid, "running running running")
+	i3 := newItem(pid, "Runner's guide")
+	i4 := newItem(pid, "東京タワーの展望台")
+	i5 := newItem(id.NewProjectID(), "running")
+
+	index, err := NewItemIndex("", "en", "ja")
+	require.NoError(t, err)
+	defer func() { _ = index.Close() }()
+	require.NoError(t, index.Index(ctx, item.List{i1, i2, i3, i4, i5}))
+
+	search := func(keyword, lang string) id.ItemIDList {
+		t.Helper()
+		res, err := index.Search(ctx, item.NewQuery(pid, mid, nil, keyword, nil).WithLanguage(lang))
+		require.NoError(t, err)
+		return res
+	}
+
+	// ranked by relevance, and prefixes of words also match
+	assert.Equal(t, id.ItemIDList{i2.ID(), i1.ID()}, search("running", ""))
+	assert.ElementsMatch(t, id.ItemIDList{i1.ID(), i2.ID(), i3.ID()}, search("run", ""))
+	// english analyzer stems words
+	assert.ElementsMatch(t, id.ItemIDList{i1.ID(), i2.ID()}, search("runs", "en"))
+	assert.Empty(t, search("runs", ""))
+	// cjk analyzer splits words into bigrams
+	assert.Equal(t, id.ItemIDList{i4.ID()}, search("タワー", "ja"))
+	assert.Empty(t, search("", ""))
+
+	res, err := index.Search(ctx, item.NewQuery(pid, mid, id.NewSchemaID().Ref(), "running", nil))
+	require.NoError(t, err)
+	assert.Empty(t, res)
+
+	// results are not truncated silently
+	index.limit = 2
+	_, err = index.Search(ctx, item.NewQuery(pid, mid, nil, "run", nil))
+	assert.ErrorIs(t, err, repo.ErrTooManySearchResults)
+	assert.Equal(t, id.ItemIDList{i2.ID(), i1.ID()}, search("running", ""))
+	index.limit = defaultLimit
+
+	count, err := index.Count(ctx, mid)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), count)
+
+	require.NoError(t, index.Delete(ctx, id.ItemIDList{i2.ID()}))
+	assert.Equal(t, id.ItemIDList{i1.ID()}, search("running", ""))
+	count, err = index.Count(ctx, mid)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), count)
+
+	_, err = NewItemIndex("", "xx")
+	assert.ErrorIs(t, err, ErrUnsupportedLanguage)
+}
+
+func TestItemIndex_Persistent(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "items.bleve")
+	i := item.New().NewID().Schema(id.NewSchemaID()).Model(id.NewModelID()).Project(id.NewProjectID()).
+		Thread(id.NewThreadID().Ref()).
+		Fields([]*item.Field{item.NewField(id.NewFieldID(), value.TypeText.Value("persisted").AsMultiple(), nil)}).
+		MustBuild()
+
+	index, err := NewItemIndex(path, "en")
+	require.NoError(t, err)
+	require.NoError(t, index.Index(ctx, item.List{i}))
+	require.NoError(t, index.Close())
+
+	index, err = NewItemIndex(path, "en")
+	require.NoError(t, err)
+	defer func() { _ = index.Close() }()
+	res, err := index.Search(ctx, item.NewQuery(i.Project(), i.Model(), nil, "persist", nil))
+	require.NoError(t, err)
+	assert.Equal(t, id.ItemIDList{i.ID()}, res)
+}
+
+func TestItemIndex_Repo(t *testing.T) {
+	ctx := context.Background()
+	pid := id.NewProjectID()
+	mid := id.NewModelID()
+	sf := schema.NewField(schema.NewText(nil).TypeProperty()).NewID().Key(id.RandomKey()).MustBuild()
+	s := schema.New().NewID().Workspace(accountdomain.NewWorkspaceID()).Project(pid).Fields(schema.FieldList{sf}).MustBuild()
+	newItem := func(text string) *item.Item {
+		return item.New().NewID().Schema(s.ID()).Model(mid).Project(pid).Thread(id.NewThreadID().Ref()).
+			Fields([]*item.Field{item.NewField(sf.ID(), value.TypeText.Value(text).AsMultiple(), nil)}).
+			MustBuild()
+	}
+	i1 := newItem("apple pie")
+	i2 := newItem("apple apple")
+	i3 := newItem("banana")
+
+	index, err := NewItemIndex("")
+	require.NoError(t, err)
+	defer func() { _ = index.Close() }()
+
+	r := memory.NewItemWithSearchIndex(index)
+	for _, i := range (item.List{i1, i2, i3}) {
+		require.NoError(t, r.Save(ctx, i))
+	}
+
+	res, _, err := r.Search(ctx, *schema.NewPackage(s, nil, nil, nil), item.NewQuery(pid, mid, nil, "apple", nil), nil)
+	require.NoError(t, err)
+	assert.Equal(t, item.List{i2, i1}, res.Unwrap())
+
+	// when the index has too many results, field values are scanned
+	index.limit = 1
+	res, _, err = r.Search(ctx, *schema.NewPackage(s, nil, nil, nil), item.NewQuery(pid, mid, nil, "apple", nil), nil)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, item.List{i1, i2}, res.Unwrap())
+	index.limit = defaultLimit
+
+	require.NoError(t, r.Remove(ctx, i2.ID()))
+	res, _, err = r.Search(ctx, *schema.NewPackage(s, nil, nil, nil), item.NewQuery(pid, mid, nil, "appl", nil), nil)
+	require.NoError(t, err)
+	assert.Equal(t, item.List{i1}, res.Unwrap())
+}
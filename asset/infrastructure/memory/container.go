@@ -26,6 +26,7 @@ func New() *repo.Container {
 		Event:             NewEvent(),
 		Group:             NewGroup(),
 		WorkspaceSettings: NewWorkspaceSettings(),
+		WebhookDelivery:   NewWebhookDelivery(),
 		Transaction:       &usecasex.NopTransaction{},
 	}
 }
@@ -3,17 +3,15 @@ package memory
 import (
 	"context"
 	"encoding/json"
-	"strings"
 	"time"
 
 	"github.com/reearth/reearthx/asset/domain/id"
 	"github.com/reearth/reearthx/asset/domain/item"
-	"github.com/reearth/reearthx/asset/domain/schema"
 	"github.com/reearth/reearthx/asset/domain/task"
-	"github.com/reearth/reearthx/asset/domain/value"
 	"github.com/reearth/reearthx/asset/domain/version"
 	"github.com/reearth/reearthx/asset/infrastructure/memory/memorygit"
 	"github.com/reearth/reearthx/asset/usecase/repo"
+	"github.com/reearth/reearthx/log"
 	"github.com/reearth/reearthx/rerror"
 	"github.com/reearth/reearthx/usecasex"
 	"github.com/samber/lo"
@@ -21,9 +19,10 @@ import (
 )
 
 type Item struct {
-	err  error
-	data *memorygit.VersionedSyncMap[item.ID, *item.Item]
-	f    repo.ProjectFilter
+	err    error
+	data   *memorygit.VersionedSyncMap[item.ID, *item.Item]
+	search repo.ItemSearchIndex
+	f      repo.ProjectFilter
 }
 
 func NewItem() repo.Item {
@@ -32,6 +31,14 @@ func NewItem() repo.Item {
 	}
 }
 
+// NewItemWithSearchIndex returns an item repository that looks up keywords of Search in the search index.
+func NewItemWithSearchIndex(search repo.ItemSearchIndex) repo.Item {
+	return &Item{
+		data:   memorygit.NewVersionedSyncMap[item.ID, *item.Item](),
+		search: search,
+	}
+}
+
 func (r *Item) FindByAssets(
 	_ context.Context,
 	list id.AssetIDList,
@@ -55,8 +62,9 @@ func (r *Item) FindByAssets(
 
 func (r *Item) Filtered(filter repo.ProjectFilter) repo.Item {
 	return &Item{
-		data: r.data,
-		f:    r.f.Merge(filter),
+		data:   r.data,
+		search: r.search,
+		f:      r.f.Merge(filter),
 	}
 }
 
@@ -96,7 +104,7 @@ func (r *Item) FindBySchema(
 		}
 		return true
 	})
-	return res, nil, nil
+	return paginateItems(res, sort, pagination)
 }
 
 func (r *Item) FindByModel(
@@ -120,7 +128,7 @@ func (r *Item) FindByModel(
 		return true
 	})
 
-	return res, nil, nil
+	return paginateItems(res, sort, pagination)
 }
 
 func (r *Item) FindByIDs(
@@ -195,7 +203,7 @@ func (r *Item) LastModifiedByModel(_ context.Context, modelID id.ModelID) (time.
 	return res.Latest().Time(), nil
 }
 
-func (r *Item) Save(_ context.Context, t *item.Item) error {
+func (r *Item) Save(ctx context.Context, t *item.Item) error {
 	if r.err != nil {
 		return r.err
 	}
@@ -205,10 +213,11 @@ func (r *Item) Save(_ context.Context, t *item.Item) error {
 	}
 
 	r.data.SaveOne(t.ID(), t, nil)
+	r.index(ctx, item.List{t})
 	return nil
 }
 
-func (r *Item) SaveAll(_ context.Context, il item.List) error {
+func (r *Item) SaveAll(ctx context.Context, il item.List) error {
 	if r.err != nil {
 		return r.err
 	}
@@ -219,9 +228,44 @@ func (r *Item) SaveAll(_ context.Context, il item.List) error {
 		}
 	}
 	r.data.SaveAll(il.IDs(), il, nil)
+	r.index(ctx, il)
 	return nil
 }
 
+// index updates the search index. Failures are only logged because the items have been saved already,
+// and Search scans field values until Reindex adds the missing items.
+func (r *Item) index(ctx context.Context, il item.List) {
+	if r.search == nil {
+		return
+	}
+	if err := r.search.Index(ctx, il); err != nil {
+		log.Errorfc(ctx, "memory: item: failed to index items: %v", err)
+	}
+}
+
+func (r *Item) Reindex(ctx context.Context, modelID id.ModelID) error {
+	if r.err != nil {
+		return r.err
+	}
+	if r.search == nil {
+		return nil
+	}
+	return r.search.Index(ctx, r.latestOfModel(modelID))
+}
+
+// latestOfModel returns the latest items of the model which can be read.
+func (r *Item) latestOfModel(modelID id.ModelID) item.List {
+	var items item.List
+	r.data.Range(func(_ item.ID, v *version.Values[*item.Item]) bool {
+		it := v.Get(version.Latest.OrVersion())
+		if it != nil && it.Value().Model() == modelID && r.f.CanRead(it.Value().Project()) {
+			items = append(items, it.Value())
+		}
+		return true
+	})
+	return items
+}
+
 func (r *Item) UpdateRef(
 	_ context.Context,
 	item id.ItemID,
@@ -236,7 +280,7 @@ func (r *Item) UpdateRef(
 	return nil
 }
 
-func (r *Item) Remove(_ context.Context, itemID id.ItemID) error {
+func (r *Item) Remove(ctx context.Context, itemID id.ItemID) error {
 	if r.err != nil {
 		return r.err
 	}
@@ -250,6 +294,11 @@ func (r *Item) Remove(_ context.Context, itemID id.ItemID) error {
 	}
 
 	r.data.Delete(itemID)
+	if r.search != nil {
+		if err := r.search.Delete(ctx, id.ItemIDList{itemID}); err != nil {
+			log.Errorfc(ctx, "memory: item: failed to delete item from search index: %v", err)
+		}
+	}
 	return nil
 }
 
@@ -304,41 +353,35 @@ func sortItems(items []*version.Value[*item.Item]) {
 	})
 }
 
-func (r *Item) Search(
-	_ context.Context,
-	sp schema.Package,
-	q *item.Query,
+// paginateItems orders items by the sort keys and then by their IDs, which keeps pages stable across requests.
+func paginateItems(
+	items item.VersionedList,
+	sort *usecasex.Sort,
 	pagination *usecasex.Pagination,
 ) (item.VersionedList, *usecasex.PageInfo, error) {
-	// TODO: support filters, sort, and pagination
-	if r.err != nil {
-		return nil, nil, r.err
-	}
-
-	var res item.VersionedList
-	qq := q.Keyword()
-
-	r.data.Range(func(k item.ID, v *version.Values[*item.Item]) bool {
-		it := v.Get(version.Latest.OrVersion())
-		itv := it.Value()
-		_, searchMatched := lo.Find(itv.Fields(), func(f *item.Field) bool {
-			return lo.SomeBy(f.Value().Values(), func(v *value.Value) bool {
-				if s, ok := v.ValueString(); ok {
-					if strings.Contains(s, qq) {
-						return true
-					}
-				}
-				return false
-			})
-		})
-		schemaMatched := q.Schema() == nil || itv.Schema() == *q.Schema()
-		modelMatched := itv.Model() == q.Model()
-		if searchMatched && schemaMatched && modelMatched && r.f.CanRead(itv.Project()) {
-			res = append(res, it)
-		}
-		return true
+	slices.SortFunc(items, usecasex.SortFunc(sort, itemSortValue, func(v item.Versioned) string {
+		return v.Value().ID().String()
+	}))
+	return usecasex.PaginateSlice(items, pagination, func(v item.Versioned) usecasex.Cursor {
+		return usecasex.Cursor(v.Value().ID().String())
 	})
-	return res, nil, nil
+}
+
+func itemSortValue(v item.Versioned, key string) any {
+	it := v.Value()
+	switch key {
+	case "id":
+		return it.ID().String()
+	case "timestamp":
+		return it.Timestamp()
+	case "modelid":
+		return it.Model().String()
+	case "schema":
+		return it.Schema().String()
+	case "project":
+		return it.Project().String()
+	}
+	return nil
 }
 
 func (r *Item) FindByModelAndValue(
@@ -0,0 +1,454 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/reearth/reearthx/asset/domain/item"
+	"github.com/reearth/reearthx/asset/domain/item/view"
+	"github.com/reearth/reearthx/asset/domain/schema"
+	"github.com/reearth/reearthx/asset/domain/value"
+	"github.com/reearth/reearthx/asset/domain/version"
+	"github.com/reearth/reearthx/asset/usecase/repo"
+	"github.com/reearth/reearthx/usecasex"
+	"github.com/reearth/reearthx/util"
+	"github.com/samber/lo"
+)
+
+// Search evaluates the conditions, the sort and the pagination of the query on the items in memory
+// with the semantics of the search pipeline, so that interactors can be tested without a database.
+// Asset and reference fields are matched by IDs because assets and referenced items are not looked up.
+func (r *Item) Search(
+	ctx context.Context,
+	sp schema.Package,
+	q *item.Query,
+	pagination *usecasex.Pagination,
+) (item.VersionedList, *usecasex.PageInfo, error) {
+	if r.err != nil {
+		return nil, nil, r.err
+	}
+
+	// positions of items in the result of the search index, which are used as relevance
+	var rank map[item.ID]int
+	ids, err := r.searchIndex(ctx, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ids != nil {
+		rank = make(map[item.ID]int, len(ids))
+		for i, iid := range ids {
+			rank[iid] = i
+		}
+	}
+
+	ref := q.Ref().OrLatest().OrVersion()
+	var items []searchItem
+	r.data.Range(func(k item.ID, v *version.Values[*item.Item]) bool {
+		itv := v.Get(ref)
+		if itv == nil {
+			return true
+		}
+		it := itv.Value()
+		if it.Project() != q.Project() || it.Model() != q.Model() ||
+			(q.Schema() != nil && it.Schema() != *q.Schema()) || !r.f.CanRead(it.Project()) {
+			return true
+		}
+
+		si := searchItem{v: itv, item: it}
+		if mid := it.MetadataItem(); mid != nil {
+			if meta, ok := r.data.Load(*mid, version.Latest.OrVersion()); ok {
+				si.meta = meta.Value()
+			}
+		}
+
+		if rank != nil {
+			if _, ok := rank[it.ID()]; !ok {
+				return true
+			}
+		} else if q.Keyword() != "" && !si.matchKeyword(q.Keyword(), &sp) {
+			return true
+		}
+		if q.Filter() != nil && !si.match(*q.Filter()) {
+			return true
+		}
+		items = append(items, si)
+		return true
+	})
+
+	var s *usecasex.Sort
+	if q.Sort() != nil {
+		s = &usecasex.Sort{Key: "field", Reverted: q.Sort().Direction == view.DirectionDesc}
+	} else if rank != nil {
+		s = &usecasex.Sort{Key: "score"}
+	}
+	slices.SortFunc(items, usecasex.SortFunc(s, func(si searchItem, _ string) any {
+		if q.Sort() == nil {
+			return rank[si.item.ID()]
+		}
+		v := si.first(q.Sort().Field)
+		// integers and numbers are compared with each other
+		if f, ok := toFloat(v); ok {
+			return f
+		}
+		return v
+	}, searchItem.id))
+
+	items, pageInfo, err := usecasex.PaginateSlice(items, pagination, func(si searchItem) usecasex.Cursor {
+		return usecasex.Cursor(si.id())
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return lo.Map(items, func(si searchItem, _ int) item.Versioned { return si.v }), pageInfo, nil
+}
+
+// searchIndex looks up the keyword of the query in the search index. It returns nil when field values should be
+// scanned instead: when the repository has no index, when too many items match to filter them by IDs,
+// or when the index has fewer items of the model than the repository, e.g. until a copied model is reindexed.
+func (r *Item) searchIndex(ctx context.Context, q *item.Query) (item.IDList, error) {
+	if r.search == nil || q.Keyword() == "" {
+		return nil, nil
+	}
+
+	indexed, err := r.search.Count(ctx, q.Model())
+	if err != nil {
+		return nil, err
+	}
+	if indexed < int64(len(r.latestOfModel(q.Model()))) {
+		return nil, nil
+	}
+
+	ids, err := r.search.Search(ctx, q)
+	if errors.Is(err, repo.ErrTooManySearchResults) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return lo.Ternary(ids == nil, item.IDList{}, ids), nil
+}
+
+// searchItem is an item with its metadata item, which provides the values of meta fields.
+type searchItem struct {
+	v    item.Versioned
+	item *item.Item
+	meta *item.Item
+}
+
+func (si searchItem) id() string {
+	return si.item.ID().String()
+}
+
+// values returns the values of the field. Dates are truncated to days because the search pipeline resets their time before filtering and sorting.
+func (si searchItem) values(f view.FieldSelector) []any {
+	switch f.Type {
+	case view.FieldTypeField, view.FieldTypeMetaField:
+		if f.ID == nil {
+			return nil
+		}
+		field := si.item.Field(*f.ID)
+		if field == nil && si.meta != nil {
+			field = si.meta.Field(*f.ID)
+		}
+		if field == nil {
+			return nil
+		}
+		return lo.FilterMap(field.Value().Values(), func(v *value.Value, _ int) (any, bool) {
+			res := searchValue(v)
+			return res, res != nil
+		})
+	case view.FieldTypeId:
+		return []any{si.item.ID().String()}
+	case view.FieldTypeCreationDate, view.FieldTypeModificationDate:
+		return []any{truncateDay(si.item.Timestamp())}
+	case view.FieldTypeCreationUser:
+		return nonEmpty(idString(si.item.User(), si.item.Integration()))
+	case view.FieldTypeModificationUser:
+		return nonEmpty(idString(si.item.UpdatedByUser(), si.item.UpdatedByIntegration()))
+	}
+	// status is not stored in items
+	return nil
+}
+
+func (si searchItem) first(f view.FieldSelector) any {
+	if v := si.values(f); len(v) > 0 {
+		return v[0]
+	}
+	return nil
+}
+
+func (si searchItem) matchKeyword(keyword string, sp *schema.Package) bool {
+	keyword = strings.ToLower(keyword)
+	contains := func(v any) bool {
+		s, ok := v.(string)
+		return ok && strings.Contains(strings.ToLower(s), keyword)
+	}
+
+	if s := sp.Schema(); s != nil {
+		for _, f := range s.Fields() {
+			values := si.values(view.FieldSelector{ID: f.ID().Ref(), Type: view.FieldTypeField})
+			if !f.Multiple() && len(values) > 0 {
+				values = values[:1]
+			}
+			if slices.ContainsFunc(values, contains) {
+				return true
+			}
+		}
+	} else {
+		// search all fields of the item when the schema is not given
+		for _, f := range si.item.Fields() {
+			if slices.ContainsFunc(f.Value().Values(), func(v *value.Value) bool { return contains(searchValue(v)) }) {
+				return true
+			}
+		}
+	}
+
+	return slices.ContainsFunc([]view.FieldType{
+		view.FieldTypeId,
+		view.FieldTypeCreationUser,
+		view.FieldTypeModificationUser,
+	}, func(t view.FieldType) bool {
+		return contains(si.first(view.FieldSelector{Type: t}))
+	})
+}
+
+func (si searchItem) match(c view.Condition) bool {
+	switch c.ConditionType {
+	case view.ConditionTypeAnd:
+		return lo.EveryBy(c.AndCondition.Conditions, si.match)
+	case view.ConditionTypeOr:
+		return lo.SomeBy(c.OrCondition.Conditions, si.match)
+	case view.ConditionTypeBasic:
+		return si.matchBasic(c.BasicCondition)
+	case view.ConditionTypeNullable:
+		return si.matchNullable(c.NullableCondition)
+	case view.ConditionTypeMultiple:
+		return si.matchMultiple(c.MultipleCondition)
+	case view.ConditionTypeBool:
+		return si.matchBool(c.BoolCondition)
+	case view.ConditionTypeString:
+		return si.matchString(c.StringCondition)
+	case view.ConditionTypeNumber:
+		return si.matchNumber(c.NumberCondition)
+	case view.ConditionTypeTime:
+		return si.matchTime(c.TimeCondition)
+	case view.ConditionTypeGeo:
+		return si.matchGeo(c.GeoCondition)
+	}
+	// conditions of unknown types produce no filter stage, so they match every item
+	return true
+}
+
+func (si searchItem) matchBasic(c *view.BasicCondition) bool {
+	v := si.first(c.Field)
+	equal := v != nil && equalValues(v, conditionValue(c.Field, c.Value))
+	switch c.Op {
+	case view.BasicOperatorEquals:
+		return equal
+	case view.BasicOperatorNotEquals:
+		return !equal
+	}
+	return true
+}
+
+func (si searchItem) matchNullable(c *view.NullableCondition) bool {
+	empty := si.first(c.Field) == nil
+	switch c.Op {
+	case view.NullableOperatorEmpty:
+		return empty
+	case view.NullableOperatorNotEmpty:
+		return !empty
+	}
+	return true
+}
+
+func (si searchItem) matchMultiple(c *view.MultipleCondition) bool {
+	values := si.values(c.Field)
+	has := func(cv any) bool {
+		return slices.ContainsFunc(values, func(v any) bool { return equalValues(v, cv) })
+	}
+	switch c.Op {
+	case view.MultipleOperatorIncludesAny:
+		return lo.SomeBy(c.Value, has)
+	case view.MultipleOperatorNotIncludesAny:
+		return !lo.SomeBy(c.Value, has)
+	case view.MultipleOperatorIncludesAll:
+		return len(c.Value) > 0 && lo.EveryBy(c.Value, has)
+	case view.MultipleOperatorNotIncludesAll:
+		return len(c.Value) == 0 || !lo.EveryBy(c.Value, has)
+	}
+	return true
+}
+
+func (si searchItem) matchBool(c *view.BoolCondition) bool {
+	v, ok := si.first(c.Field).(bool)
+	equal := ok && v == c.Value
+	switch c.Op {
+	case view.BoolOperatorEquals:
+		return equal
+	case view.BoolOperatorNotEquals:
+		return !equal
+	}
+	return true
+}
+
+func (si searchItem) matchString(c *view.StringCondition) bool {
+	s, ok := si.first(c.Field).(string)
+	switch c.Op {
+	case view.StringOperatorContains:
+		return ok && strings.Contains(s, c.Value)
+	case view.StringOperatorNotContains:
+		return !ok || !strings.Contains(s, c.Value)
+	case view.StringOperatorStartsWith:
+		return ok && strings.HasPrefix(s, c.Value)
+	case view.StringOperatorNotStartsWith:
+		return !ok || !strings.HasPrefix(s, c.Value)
+	case view.StringOperatorEndsWith:
+		return ok && strings.HasSuffix(s, c.Value)
+	case view.StringOperatorNotEndsWith:
+		return !ok || !strings.HasSuffix(s, c.Value)
+	}
+	return true
+}
+
+func (si searchItem) matchNumber(c *view.NumberCondition) bool {
+	n, ok := toFloat(si.first(c.Field))
+	if !ok {
+		return false
+	}
+	switch c.Op {
+	case view.NumberOperatorGreaterThan:
+		return n > c.Value
+	case view.NumberOperatorGreaterThanOrEqualTo:
+		return n >= c.Value
+	case view.NumberOperatorLessThan:
+		return n < c.Value
+	case view.NumberOperatorLessThanOrEqualTo:
+		return n <= c.Value
+	}
+	return true
+}
+
+func (si searchItem) matchTime(c *view.TimeCondition) bool {
+	t, ok := si.first(c.Field).(time.Time)
+	if !ok {
+		return false
+	}
+	v := c.Value.Truncate(24 * time.Hour)
+	switch c.Op {
+	case view.TimeOperatorAfter:
+		return t.After(v)
+	case view.TimeOperatorAfterOrOn:
+		return !t.Before(v)
+	case view.TimeOperatorBefore:
+		return t.Before(v)
+	case view.TimeOperatorBeforeOrOn:
+		return !t.After(v)
+	case view.TimeOperatorOfThisWeek:
+		return !t.Before(startDayOfWeek(util.Now()))
+	case view.TimeOperatorOfThisMonth:
+		return !t.Before(startDayOfMonth(util.Now()))
+	case view.TimeOperatorOfThisYear:
+		return !t.Before(startDayOfYear(util.Now()))
+	}
+	return true
+}
+
+// searchValue converts a value into what conditions and sort keys compare: dates without the time of day, and other values as they are.
+func searchValue(v *value.Value) any {
+	if v == nil || v.IsEmpty() {
+		return nil
+	}
+	if t, ok := v.Value().(value.DateTime); ok {
+		return truncateDay(t)
+	}
+	return v.Interface()
+}
+
+// conditionValue converts the value of a condition into the representation of values of the field.
+func conditionValue(f view.FieldSelector, v any) any {
+	s, ok := v.(string)
+	if !ok {
+		return v
+	}
+	switch f.Type {
+	case view.FieldTypeCreationDate, view.FieldTypeModificationDate:
+		if t, err := time.Parse(time.RFC3339, s); err == nil {
+			return t.Truncate(24 * time.Hour)
+		}
+	case view.FieldTypeField, view.FieldTypeMetaField:
+		if t, err := time.Parse(time.RFC3339, s); err == nil {
+			return t
+		}
+	}
+	return v
+}
+
+func equalValues(a, b any) bool {
+	if fa, ok := toFloat(a); ok {
+		fb, ok := toFloat(b)
+		return ok && fa == fb
+	}
+	if ta, ok := a.(time.Time); ok {
+		tb, ok := b.(time.Time)
+		return ok && ta.Equal(tb)
+	}
+	return a == b
+}
+
+func toFloat(v any) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+func truncateDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func idString(u *item.UserID, i *item.IntegrationID) string {
+	if u != nil {
+		return u.String()
+	}
+	if i != nil {
+		return i.String()
+	}
+	return ""
+}
+
+func nonEmpty(s string) []any {
+	if s == "" {
+		return nil
+	}
+	return []any{s}
+}
+
+func startDayOfWeek(t time.Time) time.Time {
+	weekday := time.Duration(t.Weekday())
+	if weekday == 0 {
+		weekday = 7
+	}
+	return t.Truncate(24 * time.Hour).Add(-1 * (weekday - 1) * 24 * time.Hour)
+}
+
+func startDayOfMonth(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
+
+func startDayOfYear(t time.Time) time.Time {
+	return time.Date(t.Year(), 1, 1, 0, 0, 0, 0, t.Location())
+}
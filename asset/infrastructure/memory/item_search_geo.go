@@ -0,0 +1,216 @@
+package memory
+
+import (
+	"math"
+
+	geojson "github.com/paulmach/go.geojson"
+	"github.com/reearth/reearthx/asset/domain/item/view"
+	"github.com/samber/lo"
+)
+
+// earthRadius is the radius in meters that mongo uses for $centerSphere
+const earthRadius = 6378100.0
+
+// matchGeo evaluates the condition with planar geometry on longitude and latitude,
+// which is close to the spherical geometry of the mongo repository for small regions.
+func (si searchItem) matchGeo(c *view.GeoCondition) bool {
+	shapes := lo.FilterMap(si.values(c.Field), func(v any, _ int) (geoShape, bool) {
+		s, ok := v.(string)
+		if !ok {
+			return geoShape{}, false
+		}
+		g, err := geojson.UnmarshalGeometry([]byte(s))
+		if err != nil {
+			return geoShape{}, false
+		}
+		return newGeoShape(g), true
+	})
+
+	switch c.Op {
+	case view.GeoOperatorWithinBBox, view.GeoOperatorWithinPolygon:
+		r := c.Region()
+		if r == nil {
+			return false
+		}
+		return lo.SomeBy(shapes, func(s geoShape) bool { return s.within(r.Polygon) })
+	case view.GeoOperatorIntersects:
+		r := c.Region()
+		if r == nil {
+			return false
+		}
+		region := newGeoShape(r)
+		return lo.SomeBy(shapes, func(s geoShape) bool { return s.intersects(region) })
+	case view.GeoOperatorNear:
+		if len(c.Point) < 2 {
+			return false
+		}
+		return lo.SomeBy(shapes, func(s geoShape) bool {
+			return len(s.positions) > 0 && lo.EveryBy(s.positions, func(p []float64) bool {
+				return geoDistance(c.Point, p) <= c.Distance
+			})
+		})
+	}
+	return true
+}
+
+// geoShape is a geometry flattened into its vertices, segments and polygons.
+// Points are represented as segments whose both ends are the same position.
+type geoShape struct {
+	positions [][]float64
+	segments  [][2][]float64
+	polygons  [][][][]float64
+}
+
+func newGeoShape(g *geojson.Geometry) geoShape {
+	s := geoShape{}
+	s.add(g)
+	return s
+}
+
+func (s *geoShape) add(g *geojson.Geometry) {
+	if g == nil {
+		return
+	}
+	switch g.Type {
+	case geojson.GeometryPoint:
+		s.addLine([][]float64{g.Point})
+	case geojson.GeometryMultiPoint:
+		for _, p := range g.MultiPoint {
+			s.addLine([][]float64{p})
+		}
+	case geojson.GeometryLineString:
+		s.addLine(g.LineString)
+	case geojson.GeometryMultiLineString:
+		for _, l := range g.MultiLineString {
+			s.addLine(l)
+		}
+	case geojson.GeometryPolygon:
+		s.addPolygon(g.Polygon)
+	case geojson.GeometryMultiPolygon:
+		for _, p := range g.MultiPolygon {
+			s.addPolygon(p)
+		}
+	case geojson.GeometryCollection:
+		for _, c := range g.Geometries {
+			s.add(c)
+		}
+	}
+}
+
+func (s *geoShape) addLine(l [][]float64) {
+	l = lo.Filter(l, func(p []float64, _ int) bool { return len(p) >= 2 })
+	s.positions = append(s.positions, l...)
+	if len(l) == 1 {
+		s.segments = append(s.segments, [2][]float64{l[0], l[0]})
+	}
+	for i := 1; i < len(l); i++ {
+		s.segments = append(s.segments, [2][]float64{l[i-1], l[i]})
+	}
+}
+
+func (s *geoShape) addPolygon(p [][][]float64) {
+	if len(p) == 0 {
+		return
+	}
+	for _, r := range p {
+		s.addLine(r)
+	}
+	s.polygons = append(s.polygons, p)
+}
+
+// within reports whether the shape lies inside the polygon.
+func (s geoShape) within(polygon [][][]float64) bool {
+	if len(s.positions) == 0 || len(polygon) == 0 {
+		return false
+	}
+	if !lo.EveryBy(s.positions, func(p []float64) bool { return inPolygon(p, polygon) }) {
+		return false
+	}
+	// vertices inside a concave polygon do not guarantee that the edges are inside
+	edges := newGeoShape(geojson.NewPolygonGeometry(polygon)).segments
+	return !lo.SomeBy(s.segments, func(a [2][]float64) bool {
+		return lo.SomeBy(edges, func(b [2][]float64) bool { return segmentsCross(a, b) })
+	})
+}
+
+// intersects reports whether the shapes share at least one position.
+func (s geoShape) intersects(t geoShape) bool {
+	if lo.SomeBy(s.segments, func(a [2][]float64) bool {
+		return lo.SomeBy(t.segments, func(b [2][]float64) bool { return segmentsIntersect(a, b) })
+	}) {
+		return true
+	}
+	return s.hasPositionIn(t) || t.hasPositionIn(s)
+}
+
+func (s geoShape) hasPositionIn(t geoShape) bool {
+	return lo.SomeBy(s.positions, func(p []float64) bool {
+		return lo.SomeBy(t.polygons, func(polygon [][][]float64) bool { return inPolygon(p, polygon) })
+	})
+}
+
+// inPolygon reports whether the position is inside the outer ring and outside of the holes.
+func inPolygon(p []float64, polygon [][][]float64) bool {
+	if len(polygon) == 0 || !inRing(p, polygon[0]) {
+		return false
+	}
+	return !lo.SomeBy(polygon[1:], func(r [][]float64) bool { return inRing(p, r) })
+}
+
+func inRing(p []float64, ring [][]float64) bool {
+	in := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		a, b := ring[i], ring[j]
+		if len(a) < 2 || len(b) < 2 {
+			continue
+		}
+		if onSegment(p, [2][]float64{a, b}) {
+			return true
+		}
+		if (a[1] > p[1]) != (b[1] > p[1]) && p[0] < (b[0]-a[0])*(p[1]-a[1])/(b[1]-a[1])+a[0] {
+			in = !in
+		}
+	}
+	return in
+}
+
+// segmentsCross reports whether the segments cross each other at a point that is not an end of them.
+func segmentsCross(a, b [2][]float64) bool {
+	d1, d2 := orientation(b[0], b[1], a[0]), orientation(b[0], b[1], a[1])
+	d3, d4 := orientation(a[0], a[1], b[0]), orientation(a[0], a[1], b[1])
+	return d1*d2 < 0 && d3*d4 < 0
+}
+
+func segmentsIntersect(a, b [2][]float64) bool {
+	if segmentsCross(a, b) {
+		return true
+	}
+	return onSegment(a[0], b) || onSegment(a[1], b) || onSegment(b[0], a) || onSegment(b[1], a)
+}
+
+func onSegment(p []float64, s [2][]float64) bool {
+	return orientation(s[0], s[1], p) == 0 &&
+		p[0] >= math.Min(s[0][0], s[1][0]) && p[0] <= math.Max(s[0][0], s[1][0]) &&
+		p[1] >= math.Min(s[0][1], s[1][1]) && p[1] <= math.Max(s[0][1], s[1][1])
+}
+
+// orientation returns the sign of the cross product of (b - a) and (c - a).
+func orientation(a, b, c []float64) float64 {
+	v := (b[0]-a[0])*(c[1]-a[1]) - (b[1]-a[1])*(c[0]-a[0])
+	switch {
+	case v > 0:
+		return 1
+	case v < 0:
+		return -1
+	}
+	return 0
+}
+
+// geoDistance returns the great-circle distance in meters between the positions.
+func geoDistance(a, b []float64) float64 {
+	rad := math.Pi / 180
+	lat1, lat2 := a[1]*rad, b[1]*rad
+	dLat, dLng := (b[1]-a[1])*rad, (b[0]-a[0])*rad
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
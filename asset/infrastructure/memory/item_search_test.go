@@ -0,0 +1,401 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	geojson "github.com/paulmach/go.geojson"
+	"github.com/reearth/reearthx/account/accountdomain"
+	"github.com/reearth/reearthx/asset/domain/id"
+	"github.com/reearth/reearthx/asset/domain/item"
+	"github.com/reearth/reearthx/asset/domain/item/view"
+	"github.com/reearth/reearthx/asset/domain/schema"
+	"github.com/reearth/reearthx/asset/domain/value"
+	"github.com/reearth/reearthx/usecasex"
+	"github.com/reearth/reearthx/util"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestItem_Search(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 3, 14, 10, 0, 0, 0, time.UTC)
+	defer util.MockNow(now)()
+
+	pid := id.NewProjectID()
+	mid := id.NewModelID()
+	sid := id.NewSchemaID()
+	msid := id.NewSchemaID()
+	fText := id.NewFieldID()
+	fNum := id.NewFieldID()
+	fBool := id.NewFieldID()
+	fDate := id.NewFieldID()
+	fTags := id.NewFieldID()
+	fMeta := id.NewFieldID()
+	u1 := accountdomain.NewUserID()
+	u2 := accountdomain.NewUserID()
+
+	s := schema.New().ID(sid).Workspace(accountdomain.NewWorkspaceID()).Project(pid).Fields(schema.FieldList{
+		schema.NewField(schema.NewText(nil).TypeProperty()).ID(fText).Key(id.RandomKey()).MustBuild(),
+		schema.NewField(schema.NewText(nil).TypeProperty()).ID(fTags).Key(id.RandomKey()).Multiple(true).MustBuild(),
+	}).MustBuild()
+	sp := schema.NewPackage(s, nil, nil, nil)
+
+	newItem := func(ts time.Time, user item.UserID, meta *item.ID, fields ...*item.Field) *item.Item {
+		return item.New().
+			NewID().
+			Schema(sid).
+			Model(mid).
+			Project(pid).
+			User(user).
+			Thread(id.NewThreadID().Ref()).
+			Timestamp(ts).
+			MetadataItem(meta).
+			Fields(fields).
+			MustBuild()
+	}
+	text := func(f id.FieldID, v ...string) *item.Field {
+		return item.NewField(f, value.NewMultiple(value.TypeText, lo.ToAnySlice(v)), nil)
+	}
+
+	m1 := item.New().NewID().Schema(msid).Model(mid).Project(id.NewProjectID()).
+		Thread(id.NewThreadID().Ref()).IsMetadata(true).
+		Fields([]*item.Field{item.NewField(fMeta, value.TypeBool.Value(true).AsMultiple(), nil)}).
+		MustBuild()
+	i1 := newItem(now.Add(-48*time.Hour), u1, m1.ID().Ref(),
+		text(fText, "Apple pie"),
+		item.NewField(fNum, value.TypeNumber.Value(10.0).AsMultiple(), nil),
+		item.NewField(fBool, value.TypeBool.Value(true).AsMultiple(), nil),
+		item.NewField(fDate, value.TypeDateTime.Value(time.Date(2024, 3, 1, 15, 0, 0, 0, time.UTC)).AsMultiple(), nil),
+		text(fTags, "red", "green"),
+	)
+	i2 := newItem(now.Add(-24*time.Hour), u2, nil,
+		text(fText, "banana"),
+		item.NewField(fNum, value.TypeInteger.Value(int64(20)).AsMultiple(), nil),
+		item.NewField(fBool, value.TypeBool.Value(false).AsMultiple(), nil),
+		item.NewField(fDate, value.TypeDateTime.Value(time.Date(2024, 3, 12, 0, 0, 0, 0, time.UTC)).AsMultiple(), nil),
+		text(fTags, "yellow", "green"),
+	)
+	i3 := newItem(now, u1, nil,
+		text(fText, "cherry pie"),
+		item.NewField(fNum, value.TypeNumber.Value(30.5).AsMultiple(), nil),
+	)
+	// other models and projects are excluded
+	i4 := item.New().NewID().Schema(sid).Model(id.NewModelID()).Project(pid).Thread(id.NewThreadID().Ref()).
+		Fields([]*item.Field{text(fText, "apple")}).MustBuild()
+
+	r := NewItem()
+	for _, it := range []*item.Item{m1, i1, i2, i3, i4} {
+		require.NoError(t, r.Save(ctx, it))
+	}
+
+	field := func(f id.FieldID) view.FieldSelector {
+		return view.FieldSelector{ID: f.Ref(), Type: view.FieldTypeField}
+	}
+	search := func(q *item.Query, p *usecasex.Pagination) (item.List, *usecasex.PageInfo) {
+		t.Helper()
+		res, pi, err := r.Search(ctx, *sp, q, p)
+		require.NoError(t, err)
+		return res.Unwrap(), pi
+	}
+	query := func() *item.Query {
+		return item.NewQuery(pid, mid, nil, "", nil)
+	}
+	filter := func(c view.Condition) item.List {
+		t.Helper()
+		res, _ := search(query().WithFilter(&c), nil)
+		return res
+	}
+
+	all, _ := search(query(), nil)
+	assert.ElementsMatch(t, item.List{i1, i2, i3}, all)
+
+	// keyword is case-insensitive and only the first value of non-multiple fields is searched
+	res, _ := search(item.NewQuery(pid, mid, nil, "PIE", nil), nil)
+	assert.ElementsMatch(t, item.List{i1, i3}, res)
+	res, _ = search(item.NewQuery(pid, mid, nil, "yellow", nil), nil)
+	assert.Equal(t, item.List{i2}, res)
+	res, _ = search(item.NewQuery(pid, mid, nil, u2.String(), nil), nil)
+	assert.Equal(t, item.List{i2}, res)
+
+	tests := []struct {
+		name string
+		cond view.Condition
+		want item.List
+	}{
+		{
+			name: "basic equals",
+			cond: view.Condition{ConditionType: view.ConditionTypeBasic, BasicCondition: &view.BasicCondition{Field: field(fText), Op: view.BasicOperatorEquals, Value: "banana"}},
+			want: item.List{i2},
+		},
+		{
+			name: "basic not equals number",
+			cond: view.Condition{ConditionType: view.ConditionTypeBasic, BasicCondition: &view.BasicCondition{Field: field(fNum), Op: view.BasicOperatorNotEquals, Value: 20}},
+			want: item.List{i1, i3},
+		},
+		{
+			name: "basic equals creation user",
+			cond: view.Condition{ConditionType: view.ConditionTypeBasic, BasicCondition: &view.BasicCondition{Field: view.FieldSelector{Type: view.FieldTypeCreationUser}, Op: view.BasicOperatorEquals, Value: u1.String()}},
+			want: item.List{i1, i3},
+		},
+		{
+			name: "nullable empty",
+			cond: view.Condition{ConditionType: view.ConditionTypeNullable, NullableCondition: &view.NullableCondition{Field: field(fBool), Op: view.NullableOperatorEmpty}},
+			want: item.List{i3},
+		},
+		{
+			name: "nullable not empty meta field",
+			cond: view.Condition{ConditionType: view.ConditionTypeNullable, NullableCondition: &view.NullableCondition{Field: view.FieldSelector{ID: fMeta.Ref(), Type: view.FieldTypeMetaField}, Op: view.NullableOperatorNotEmpty}},
+			want: item.List{i1},
+		},
+		{
+			name: "multiple includes any",
+			cond: view.Condition{ConditionType: view.ConditionTypeMultiple, MultipleCondition: &view.MultipleCondition{Field: field(fTags), Op: view.MultipleOperatorIncludesAny, Value: []any{"red", "yellow"}}},
+			want: item.List{i1, i2},
+		},
+		{
+			name: "multiple includes all",
+			cond: view.Condition{ConditionType: view.ConditionTypeMultiple, MultipleCondition: &view.MultipleCondition{Field: field(fTags), Op: view.MultipleOperatorIncludesAll, Value: []any{"yellow", "green"}}},
+			want: item.List{i2},
+		},
+		{
+			name: "multiple not includes any",
+			cond: view.Condition{ConditionType: view.ConditionTypeMultiple, MultipleCondition: &view.MultipleCondition{Field: field(fTags), Op: view.MultipleOperatorNotIncludesAny, Value: []any{"red"}}},
+			want: item.List{i2, i3},
+		},
+		{
+			name: "multiple not includes all",
+			cond: view.Condition{ConditionType: view.ConditionTypeMultiple, MultipleCondition: &view.MultipleCondition{Field: field(fTags), Op: view.MultipleOperatorNotIncludesAll, Value: []any{"red", "green"}}},
+			want: item.List{i2, i3},
+		},
+		{
+			name: "bool equals",
+			cond: view.Condition{ConditionType: view.ConditionTypeBool, BoolCondition: &view.BoolCondition{Field: field(fBool), Op: view.BoolOperatorEquals, Value: true}},
+			want: item.List{i1},
+		},
+		{
+			name: "bool not equals",
+			cond: view.Condition{ConditionType: view.ConditionTypeBool, BoolCondition: &view.BoolCondition{Field: field(fBool), Op: view.BoolOperatorNotEquals, Value: true}},
+			want: item.List{i2, i3},
+		},
+		{
+			name: "string contains",
+			cond: view.Condition{ConditionType: view.ConditionTypeString, StringCondition: &view.StringCondition{Field: field(fText), Op: view.StringOperatorContains, Value: "pie"}},
+			want: item.List{i1, i3},
+		},
+		{
+			name: "string not starts with",
+			cond: view.Condition{ConditionType: view.ConditionTypeString, StringCondition: &view.StringCondition{Field: field(fText), Op: view.StringOperatorNotStartsWith, Value: "Apple"}},
+			want: item.List{i2, i3},
+		},
+		{
+			name: "string ends with",
+			cond: view.Condition{ConditionType: view.ConditionTypeString, StringCondition: &view.StringCondition{Field: field(fText), Op: view.StringOperatorEndsWith, Value: "na"}},
+			want: item.List{i2},
+		},
+		{
+			name: "number greater than or equal to",
+			cond: view.Condition{ConditionType: view.ConditionTypeNumber, NumberCondition: &view.NumberCondition{Field: field(fNum), Op: view.NumberOperatorGreaterThanOrEqualTo, Value: 20}},
+			want: item.List{i2, i3},
+		},
+		{
+			name: "number less than",
+			cond: view.Condition{ConditionType: view.ConditionTypeNumber, NumberCondition: &view.NumberCondition{Field: field(fNum), Op: view.NumberOperatorLessThan, Value: 20}},
+			want: item.List{i1},
+		},
+		{
+			name: "time before or on",
+			cond: view.Condition{ConditionType: view.ConditionTypeTime, TimeCondition: &view.TimeCondition{Field: field(fDate), Op: view.TimeOperatorBeforeOrOn, Value: time.Date(2024, 3, 1, 23, 0, 0, 0, time.UTC)}},
+			want: item.List{i1},
+		},
+		{
+			name: "time after creation date",
+			cond: view.Condition{ConditionType: view.ConditionTypeTime, TimeCondition: &view.TimeCondition{Field: view.FieldSelector{Type: view.FieldTypeCreationDate}, Op: view.TimeOperatorAfter, Value: now.Add(-48 * time.Hour)}},
+			want: item.List{i2, i3},
+		},
+		{
+			name: "time of this week",
+			cond: view.Condition{ConditionType: view.ConditionTypeTime, TimeCondition: &view.TimeCondition{Field: field(fDate), Op: view.TimeOperatorOfThisWeek}},
+			want: item.List{i2},
+		},
+		{
+			name: "and",
+			cond: view.Condition{ConditionType: view.ConditionTypeAnd, AndCondition: &view.AndCondition{Conditions: []view.Condition{
+				{ConditionType: view.ConditionTypeString, StringCondition: &view.StringCondition{Field: field(fText), Op: view.StringOperatorContains, Value: "pie"}},
+				{ConditionType: view.ConditionTypeNumber, NumberCondition: &view.NumberCondition{Field: field(fNum), Op: view.NumberOperatorGreaterThan, Value: 15}},
+			}}},
+			want: item.List{i3},
+		},
+		{
+			name: "or",
+			cond: view.Condition{ConditionType: view.ConditionTypeOr, OrCondition: &view.OrCondition{Conditions: []view.Condition{
+				{ConditionType: view.ConditionTypeBool, BoolCondition: &view.BoolCondition{Field: field(fBool), Op: view.BoolOperatorEquals, Value: false}},
+				{ConditionType: view.ConditionTypeNullable, NullableCondition: &view.NullableCondition{Field: field(fDate), Op: view.NullableOperatorEmpty}},
+			}}},
+			want: item.List{i2, i3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ElementsMatch(t, tt.want, filter(tt.cond))
+		})
+	}
+
+	// sort
+	res, _ = search(query().WithSort(&view.Sort{Field: field(fNum), Direction: view.DirectionDesc}), nil)
+	assert.Equal(t, item.List{i3, i2, i1}, res, "integers and numbers are compared")
+	res, _ = search(query().WithSort(&view.Sort{Field: field(fText), Direction: view.DirectionAsc}), nil)
+	assert.Equal(t, item.List{i1, i2, i3}, res)
+	res, _ = search(query().WithSort(&view.Sort{Field: view.FieldSelector{Type: view.FieldTypeCreationDate}, Direction: view.DirectionDesc}), nil)
+	assert.Equal(t, item.List{i3, i2, i1}, res)
+	res, _ = search(query().WithSort(&view.Sort{Field: field(fBool), Direction: view.DirectionAsc}), nil)
+	assert.Equal(t, item.List{i3, i2, i1}, res, "empty values come first")
+
+	// pagination
+	q := query().WithSort(&view.Sort{Field: view.FieldSelector{Type: view.FieldTypeCreationDate}, Direction: view.DirectionAsc})
+	res, pi := search(q, usecasex.CursorPagination{First: lo.ToPtr(int64(2))}.Wrap())
+	assert.Equal(t, item.List{i1, i2}, res)
+	assert.Equal(t, usecasex.NewPageInfo(3, usecasex.Cursor(i1.ID().String()).Ref(), usecasex.Cursor(i2.ID().String()).Ref(), true, false), pi)
+	res, pi = search(q, usecasex.CursorPagination{First: lo.ToPtr(int64(2)), After: pi.EndCursor}.Wrap())
+	assert.Equal(t, item.List{i3}, res)
+	assert.False(t, pi.HasNextPage)
+	res, pi = search(q, usecasex.OffsetPagination{Offset: 1, Limit: 1}.Wrap())
+	assert.Equal(t, item.List{i2}, res)
+	assert.Equal(t, int64(3), pi.TotalCount)
+}
+
+func TestItem_SearchGeo(t *testing.T) {
+	ctx := context.Background()
+	pid := id.NewProjectID()
+	mid := id.NewModelID()
+	fGeo := id.NewFieldID()
+
+	s := schema.New().NewID().Workspace(accountdomain.NewWorkspaceID()).Project(pid).Fields(schema.FieldList{
+		schema.NewField(schema.NewGeometryObject(schema.GeometryObjectSupportedTypeList{
+			schema.GeometryObjectSupportedTypePoint,
+			schema.GeometryObjectSupportedTypeLineString,
+			schema.GeometryObjectSupportedTypePolygon,
+		}).TypeProperty()).ID(fGeo).Key(id.RandomKey()).MustBuild(),
+	}).MustBuild()
+	sp := schema.NewPackage(s, nil, nil, nil)
+
+	newItem := func(g string) *item.Item {
+		return item.New().NewID().Schema(s.ID()).Model(mid).Project(pid).Thread(id.NewThreadID().Ref()).
+			Fields([]*item.Field{item.NewField(fGeo, value.TypeGeometryObject.Value(g).AsMultiple(), nil)}).
+			MustBuild()
+	}
+	i1 := newItem(`{"type":"Point","coordinates":[139.76,35.68]}`)
+	i2 := newItem(`{"type":"Point","coordinates":[135.50,34.69]}`)
+	i3 := newItem(`{"type":"LineString","coordinates":[[139.0,35.0],[140.0,36.0]]}`)
+	i4 := newItem(`{"type":"Polygon","coordinates":[[[130,30],[131,30],[131,31],[130,31],[130,30]]]}`)
+
+	r := NewItem()
+	for _, it := range []*item.Item{i1, i2, i3, i4} {
+		require.NoError(t, r.Save(ctx, it))
+	}
+
+	field := view.FieldSelector{ID: fGeo.Ref(), Type: view.FieldTypeField}
+	tests := []struct {
+		name string
+		geo  view.GeoCondition
+		want item.List
+	}{
+		{
+			name: "within bbox",
+			geo:  view.GeoCondition{Field: field, Op: view.GeoOperatorWithinBBox, BBox: []float64{138.5, 34.5, 140.5, 36.5}},
+			want: item.List{i1, i3},
+		},
+		{
+			name: "within polygon",
+			geo: view.GeoCondition{Field: field, Op: view.GeoOperatorWithinPolygon, Polygon: [][][]float64{{
+				{135, 34}, {136, 34}, {136, 35}, {135, 35}, {135, 34},
+			}}},
+			want: item.List{i2},
+		},
+		{
+			name: "within concave polygon excludes crossing lines",
+			geo: view.GeoCondition{Field: field, Op: view.GeoOperatorWithinPolygon, Polygon: [][][]float64{{
+				{138.5, 34.5}, {140.5, 34.5}, {140.5, 36.5}, {139.6, 35.4}, {138.5, 36.5}, {138.5, 34.5},
+			}}},
+			want: item.List{},
+		},
+		{
+			name: "within polygon with hole",
+			geo: view.GeoCondition{Field: field, Op: view.GeoOperatorWithinPolygon, Polygon: [][][]float64{
+				{{138, 34}, {141, 34}, {141, 37}, {138, 37}, {138, 34}},
+				{{139.75, 35.67}, {139.77, 35.67}, {139.77, 35.69}, {139.75, 35.69}, {139.75, 35.67}},
+			}},
+			want: item.List{i3},
+		},
+		{
+			name: "intersects line",
+			geo: view.GeoCondition{Field: field, Op: view.GeoOperatorIntersects, Geometry: geojson.NewLineStringGeometry([][]float64{
+				{139.0, 36.0}, {140.0, 35.0},
+			})},
+			want: item.List{i3},
+		},
+		{
+			name: "intersects point in polygon",
+			geo:  view.GeoCondition{Field: field, Op: view.GeoOperatorIntersects, Geometry: geojson.NewPointGeometry([]float64{130.5, 30.5})},
+			want: item.List{i4},
+		},
+		{
+			name: "near",
+			geo:  view.GeoCondition{Field: field, Op: view.GeoOperatorNear, Point: []float64{139.70, 35.69}, Distance: 10000},
+			want: item.List{i1},
+		},
+		{
+			name: "incomplete condition",
+			geo:  view.GeoCondition{Field: field, Op: view.GeoOperatorWithinBBox},
+			want: item.List{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, _, err := r.Search(ctx, *sp, item.NewQuery(pid, mid, nil, "", nil).WithGeoFilter(tt.geo), nil)
+			require.NoError(t, err)
+			assert.ElementsMatch(t, tt.want, res.Unwrap())
+		})
+	}
+}
+
+func TestItem_Search_CopiedModel(t *testing.T) {
+	ctx := context.Background()
+	pid := id.NewProjectID()
+	mid := id.NewModelID()
+	fText := id.NewFieldID()
+
+	s := schema.New().NewID().Workspace(accountdomain.NewWorkspaceID()).Project(pid).Fields(schema.FieldList{
+		schema.NewField(schema.NewText(nil).TypeProperty()).ID(fText).Key(id.RandomKey()).MustBuild(),
+	}).MustBuild()
+	sp := schema.NewPackage(s, nil, nil, nil)
+
+	newItem := func(text string) *item.Item {
+		return item.New().NewID().Schema(s.ID()).Model(mid).Project(pid).Thread(id.NewThreadID().Ref()).
+			Fields([]*item.Field{item.NewField(fText, value.TypeText.Value(text).AsMultiple(), nil)}).
+			MustBuild()
+	}
+	i1 := newItem("apple")
+	i2 := newItem("banana")
+	i3 := newItem("apple pie")
+
+	index := &testItemIndex{items: map[id.ItemID]*item.Item{}}
+	r := NewItemWithSearchIndex(index)
+	require.NoError(t, r.Save(ctx, i1))
+	require.NoError(t, r.Save(ctx, i2))
+	// items written by the copy task do not go through the repository, so they are not indexed
+	r.(*Item).data.SaveOne(i3.ID(), i3, nil)
+
+	// the keyword is matched with field values while the index misses items of the model
+	res, _, err := r.Search(ctx, *sp, item.NewQuery(pid, mid, nil, "apple", nil), nil)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, item.List{i1, i3}, res.Unwrap())
+
+	// once the model is reindexed, the index is used again
+	require.NoError(t, r.Reindex(ctx, mid))
+	res, _, err = r.Search(ctx, *sp, item.NewQuery(pid, mid, nil, "apple", nil), nil)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, item.List{i1, i2, i3}, res.Unwrap())
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"slices"
 	"testing"
 	"time"
 
@@ -16,6 +17,7 @@ import (
 	"github.com/reearth/reearthx/asset/domain/version"
 	"github.com/reearth/reearthx/asset/usecase/repo"
 	"github.com/reearth/reearthx/rerror"
+	"github.com/reearth/reearthx/usecasex"
 	"github.com/reearth/reearthx/util"
 	"github.com/samber/lo"
 	"github.com/stretchr/testify/assert"
@@ -263,6 +265,47 @@ func TestItem_FindBySchema(t *testing.T) {
 	assert.Nil(t, got)
 }
 
+func TestItem_FindByModel_Sort(t *testing.T) {
+	ctx := context.Background()
+	mid := id.NewModelID()
+	pid := id.NewProjectID()
+	now := time.Now().Truncate(time.Millisecond)
+	newItem := func(ts time.Time) *item.Item {
+		return item.New().
+			NewID().
+			Schema(id.NewSchemaID()).
+			Project(pid).
+			Model(mid).
+			Thread(id.NewThreadID().Ref()).
+			Timestamp(ts).
+			MustBuild()
+	}
+	i1 := newItem(now.Add(2 * time.Second))
+	i2 := newItem(now)
+	i3 := newItem(now.Add(time.Second))
+
+	r := NewItem()
+	_ = r.Save(ctx, i1)
+	_ = r.Save(ctx, i2)
+	_ = r.Save(ctx, i3)
+
+	got, pi, err := r.FindByModel(ctx, mid, nil, &usecasex.Sort{Key: "timestamp"}, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, pi)
+	assert.Equal(t, item.List{i2, i3, i1}, got.Unwrap())
+
+	s := &usecasex.Sort{Key: "timestamp", Reverted: true}
+	got, pi, err = r.FindByModel(ctx, mid, nil, s, usecasex.CursorPagination{First: lo.ToPtr(int64(2))}.Wrap())
+	assert.NoError(t, err)
+	assert.Equal(t, item.List{i1, i3}, got.Unwrap())
+	assert.Equal(t, usecasex.NewPageInfo(3, usecasex.Cursor(i1.ID().String()).Ref(), usecasex.Cursor(i3.ID().String()).Ref(), true, false), pi)
+
+	got, pi, err = r.FindByModel(ctx, mid, nil, s, usecasex.CursorPagination{First: lo.ToPtr(int64(2)), After: pi.EndCursor}.Wrap())
+	assert.NoError(t, err)
+	assert.Equal(t, item.List{i2}, got.Unwrap())
+	assert.False(t, pi.HasNextPage)
+}
+
 func TestItem_FindByFieldValue(t *testing.T) {
 	ctx := context.Background()
 	mID := id.NewModelID()
@@ -473,3 +516,78 @@ func TestItem_Copy(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, changes, lo.ToPtr(string(wantChanges)))
 }
+
+type testItemIndex struct {
+	err   error
+	items map[id.ItemID]*item.Item
+}
+
+func (i *testItemIndex) Index(_ context.Context, items item.List) error {
+	if i.err != nil {
+		return i.err
+	}
+	for _, it := range items {
+		i.items[it.ID()] = it
+	}
+	return nil
+}
+
+func (i *testItemIndex) Delete(_ context.Context, ids id.ItemIDList) error {
+	if i.err != nil {
+		return i.err
+	}
+	for _, iid := range ids {
+		delete(i.items, iid)
+	}
+	return nil
+}
+
+// Search returns the indexed items of the model whatever the keyword is, so that tests can tell the index was used.
+func (i *testItemIndex) Search(_ context.Context, q *item.Query) (id.ItemIDList, error) {
+	if i.err != nil {
+		return nil, i.err
+	}
+	var res id.ItemIDList
+	for _, it := range i.items {
+		if it.Model() == q.Model() {
+			res = append(res, it.ID())
+		}
+	}
+	slices.SortFunc(res, func(a, b id.ItemID) int { return a.Compare(b) })
+	return res, nil
+}
+
+func (i *testItemIndex) Count(_ context.Context, m id.ModelID) (int64, error) {
+	if i.err != nil {
+		return 0, i.err
+	}
+	return int64(lo.CountBy(lo.Values(i.items), func(it *item.Item) bool { return it.Model() == m })), nil
+}
+
+func TestItem_Reindex(t *testing.T) {
+	ctx := context.Background()
+	m1, m2 := id.NewModelID(), id.NewModelID()
+	newItem := func(m id.ModelID) *item.Item {
+		return item.New().NewID().Schema(id.NewSchemaID()).Model(m).Project(id.NewProjectID()).
+			Thread(id.NewThreadID().Ref()).MustBuild()
+	}
+	i1, i2, i3 := newItem(m1), newItem(m1), newItem(m2)
+
+	index := &testItemIndex{items: map[id.ItemID]*item.Item{}}
+	r := NewItemWithSearchIndex(index)
+
+	// failures of the index do not fail saving items
+	index.err = errors.New("index error")
+	for _, i := range (item.List{i1, i2, i3}) {
+		assert.NoError(t, r.Save(ctx, i))
+	}
+	assert.NoError(t, r.Remove(ctx, i2.ID()))
+	assert.Empty(t, index.items)
+	assert.Equal(t, index.err, r.Reindex(ctx, m1))
+
+	index.err = nil
+	assert.NoError(t, r.Reindex(ctx, m1))
+	assert.Equal(t, map[id.ItemID]*item.Item{i1.ID(): i1}, index.items)
+
+	assert.NoError(t, NewItem().Reindex(ctx, m1))
+}
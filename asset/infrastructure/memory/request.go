@@ -10,7 +10,6 @@ import (
 	"github.com/reearth/reearthx/rerror"
 	"github.com/reearth/reearthx/usecasex"
 	"github.com/reearth/reearthx/util"
-	"github.com/samber/lo"
 	"golang.org/x/exp/slices"
 )
 
@@ -81,19 +80,19 @@ func (r *Request) FindByProject(
 		return res
 	})
 
-	var startCursor, endCursor *usecasex.Cursor
-	if len(result) > 0 {
-		startCursor = lo.ToPtr(usecasex.Cursor(result[0].ID().String()))
-		endCursor = lo.ToPtr(usecasex.Cursor(result[len(result)-1].ID().String()))
+	slices.SortFunc(result, usecasex.SortFunc(sort, requestSortValue, func(r *request.Request) string {
+		return r.ID().String()
+	}))
+	result, pageInfo, err := usecasex.PaginateSlice(result, page, func(r *request.Request) usecasex.Cursor {
+		return usecasex.Cursor(r.ID().String())
+	})
+	if err != nil {
+		return nil, nil, err
 	}
-
-	return result, usecasex.NewPageInfo(
-		int64(len(result)),
-		startCursor,
-		endCursor,
-		true,
-		true,
-	), nil
+	if pageInfo == nil {
+		pageInfo = usecasex.NewPageInfo(int64(len(result)), nil, nil, false, false)
+	}
+	return result, pageInfo, nil
 }
 
 func (r *Request) Save(ctx context.Context, a *request.Request) error {
@@ -138,3 +137,29 @@ func (r *Request) FindByItems(
 func SetRequestError(r repo.Request, err error) {
 	r.(*Request).err = err
 }
+
+func requestSortValue(r *request.Request, key string) any {
+	switch key {
+	case "id":
+		return r.ID().String()
+	case "title":
+		return r.Title()
+	case "state":
+		return r.State().String()
+	case "createdby":
+		return r.CreatedBy().String()
+	case "createdat":
+		return r.CreatedAt()
+	case "updatedat":
+		return r.UpdatedAt()
+	case "approvedat":
+		if t := r.ApprovedAt(); t != nil {
+			return *t
+		}
+	case "closedat":
+		if t := r.ClosedAt(); t != nil {
+			return *t
+		}
+	}
+	return nil
+}
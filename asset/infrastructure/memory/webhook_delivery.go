@@ -0,0 +1,93 @@
+package memory
+
+import (
+	"context"
+	"slices"
+
+	"github.com/reearth/reearthx/asset/domain/id"
+	"github.com/reearth/reearthx/asset/domain/integration"
+	"github.com/reearth/reearthx/asset/usecase/repo"
+	"github.com/reearth/reearthx/rerror"
+	"github.com/reearth/reearthx/usecasex"
+	"github.com/reearth/reearthx/util"
+)
+
+type WebhookDelivery struct {
+	data *util.SyncMap[id.WebhookDeliveryID, *integration.Delivery]
+	err  error
+}
+
+func NewWebhookDelivery() repo.WebhookDelivery {
+	return &WebhookDelivery{
+		data: &util.SyncMap[id.WebhookDeliveryID, *integration.Delivery]{},
+	}
+}
+
+func (r *WebhookDelivery) FindByID(
+	_ context.Context,
+	dId id.WebhookDeliveryID,
+) (*integration.Delivery, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	if d, ok := r.data.Load(dId); ok {
+		return d.Clone(), nil
+	}
+	return nil, rerror.ErrNotFound
+}
+
+func (r *WebhookDelivery) FindByWebhook(
+	_ context.Context,
+	wId id.WebhookID,
+	page *usecasex.Pagination,
+) (integration.DeliveryList, *usecasex.PageInfo, error) {
+	if r.err != nil {
+		return nil, nil, r.err
+	}
+
+	result := integration.DeliveryList(r.data.FindAll(func(_ id.WebhookDeliveryID, d *integration.Delivery) bool {
+		return d.Webhook() == wId
+	})).Clone()
+
+	slices.SortFunc(result, func(a, b *integration.Delivery) int {
+		return b.ID().Compare(a.ID())
+	})
+	result, pageInfo, err := usecasex.PaginateSlice(result, page, func(d *integration.Delivery) usecasex.Cursor {
+		return usecasex.Cursor(d.ID().String())
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if pageInfo == nil {
+		pageInfo = usecasex.NewPageInfo(int64(len(result)), nil, nil, false, false)
+	}
+	return result, pageInfo, nil
+}
+
+func (r *WebhookDelivery) FindInProgress(_ context.Context) (integration.DeliveryList, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	result := integration.DeliveryList(r.data.FindAll(func(_ id.WebhookDeliveryID, d *integration.Delivery) bool {
+		return d.InProgress()
+	})).Clone()
+	slices.SortFunc(result, func(a, b *integration.Delivery) int {
+		return a.ID().Compare(b.ID())
+	})
+	return result, nil
+}
+
+func (r *WebhookDelivery) Save(_ context.Context, d *integration.Delivery) error {
+	if r.err != nil {
+		return r.err
+	}
+
+	r.data.Store(d.ID(), d.Clone())
+	return nil
+}
+
+func SetWebhookDeliveryError(r repo.WebhookDelivery, err error) {
+	r.(*WebhookDelivery).err = err
+}
@@ -0,0 +1,88 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/reearth/reearthx/asset/domain/event"
+	"github.com/reearth/reearthx/asset/domain/id"
+	"github.com/reearth/reearthx/asset/domain/integration"
+	"github.com/reearth/reearthx/rerror"
+	"github.com/reearth/reearthx/usecasex"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookDelivery_FindByID(t *testing.T) {
+	ctx := context.Background()
+	d := integration.NewDeliveryBuilder().NewID().Integration(id.NewIntegrationID()).Webhook(id.NewWebhookID()).EventType(event.ItemCreate).MustBuild()
+	r := NewWebhookDelivery()
+	assert.NoError(t, r.Save(ctx, d))
+
+	got, err := r.FindByID(ctx, d.ID())
+	assert.NoError(t, err)
+	assert.Equal(t, d, got)
+
+	// saved deliveries are not affected by changes of the original
+	d.AddAttempt(integration.NewDeliveryAttempt(d.CreatedAt(), nil, 200, 0, nil))
+	got, err = r.FindByID(ctx, d.ID())
+	assert.NoError(t, err)
+	assert.Equal(t, integration.DeliveryStatusPending, got.Status())
+
+	got, err = r.FindByID(ctx, id.NewWebhookDeliveryID())
+	assert.Nil(t, got)
+	assert.Equal(t, rerror.ErrNotFound, err)
+
+	wantErr := errors.New("test")
+	SetWebhookDeliveryError(r, wantErr)
+	_, err = r.FindByID(ctx, d.ID())
+	assert.Same(t, wantErr, err)
+	assert.Same(t, wantErr, r.Save(ctx, d))
+}
+
+func TestWebhookDelivery_FindByWebhook(t *testing.T) {
+	ctx := context.Background()
+	wid := id.NewWebhookID()
+	d1 := integration.NewDeliveryBuilder().NewID().Integration(id.NewIntegrationID()).Webhook(wid).MustBuild()
+	d2 := integration.NewDeliveryBuilder().NewID().Integration(id.NewIntegrationID()).Webhook(wid).MustBuild()
+	d3 := integration.NewDeliveryBuilder().NewID().Integration(id.NewIntegrationID()).Webhook(wid).MustBuild()
+	d4 := integration.NewDeliveryBuilder().NewID().Integration(id.NewIntegrationID()).Webhook(id.NewWebhookID()).MustBuild()
+	r := NewWebhookDelivery()
+	for _, d := range []*integration.Delivery{d1, d2, d3, d4} {
+		assert.NoError(t, r.Save(ctx, d))
+	}
+
+	got, pi, err := r.FindByWebhook(ctx, wid, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, integration.DeliveryList{d3, d2, d1}, got)
+	assert.Equal(t, int64(3), pi.TotalCount)
+
+	got, pi, err = r.FindByWebhook(ctx, wid, usecasex.CursorPagination{First: lo.ToPtr(int64(2))}.Wrap())
+	assert.NoError(t, err)
+	assert.Equal(t, integration.DeliveryList{d3, d2}, got)
+	assert.True(t, pi.HasNextPage)
+
+	got, _, err = r.FindByWebhook(ctx, id.NewWebhookID(), nil)
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+func TestWebhookDelivery_FindInProgress(t *testing.T) {
+	ctx := context.Background()
+	newDelivery := func(status integration.DeliveryStatus) *integration.Delivery {
+		return integration.NewDeliveryBuilder().NewID().Integration(id.NewIntegrationID()).Webhook(id.NewWebhookID()).Status(status).MustBuild()
+	}
+	d1 := newDelivery(integration.DeliveryStatusPending)
+	d2 := newDelivery(integration.DeliveryStatusSucceeded)
+	d3 := newDelivery(integration.DeliveryStatusRetrying)
+	d4 := newDelivery(integration.DeliveryStatusFailed)
+	r := NewWebhookDelivery()
+	for _, d := range []*integration.Delivery{d1, d2, d3, d4} {
+		assert.NoError(t, r.Save(ctx, d))
+	}
+
+	got, err := r.FindInProgress(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, integration.DeliveryList{d1, d3}, got)
+}
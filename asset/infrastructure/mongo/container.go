@@ -54,6 +54,7 @@ func New(
 		Group:             NewGroup(client),
 		Event:             NewEvent(client),
 		WorkspaceSettings: NewWorkspaceSettings(client),
+		WebhookDelivery:   NewWebhookDelivery(client),
 	}
 
 	// init
@@ -92,6 +93,7 @@ func Init(r *repo.Container) error {
 		r.Integration.(*Integration).Init,
 		r.Event.(*Event).Init,
 		r.WorkspaceSettings.(*WorkspaceSettingsRepo).Init,
+		r.WebhookDelivery.(*WebhookDelivery).Init,
 	)
 }
 
@@ -12,6 +12,7 @@ import (
 	"github.com/reearth/reearthx/asset/infrastructure/mongo/mongodoc"
 	"github.com/reearth/reearthx/asset/infrastructure/mongo/mongogit"
 	"github.com/reearth/reearthx/asset/usecase/repo"
+	"github.com/reearth/reearthx/log"
 	"github.com/reearth/reearthx/mongox"
 	"github.com/reearth/reearthx/rerror"
 	"github.com/reearth/reearthx/usecasex"
@@ -37,8 +38,12 @@ var itemIndexes = []string{
 	"schema,id,__r,project",
 }
 
+// itemGeoIndex is a 2dsphere index for GeoJSON values of geometry fields
+const itemGeoIndex = "geo.g"
+
 type Item struct {
 	client *mongogit.Collection
+	search repo.ItemSearchIndex
 	f      repo.ProjectFilter
 }
 
@@ -46,20 +51,35 @@ func NewItem(client *mongox.Client) repo.Item {
 	return &Item{client: mongogit.NewCollection(client.WithCollection("item"))}
 }
 
+// NewItemWithSearchIndex returns an item repository that keeps the search index in sync on save
+// and looks up keywords in it.
+func NewItemWithSearchIndex(client *mongox.Client, search repo.ItemSearchIndex) repo.Item {
+	return &Item{
+		client: mongogit.NewCollection(client.WithCollection("item")),
+		search: search,
+	}
+}
+
 func (r *Item) Filtered(f repo.ProjectFilter) repo.Item {
 	return &Item{
 		client: r.client,
+		search: r.search,
 		f:      r.f.Merge(f),
 	}
 }
 
 func (r *Item) Init() error {
+	if i, ok := r.search.(interface{ Init() error }); ok {
+		if err := i.Init(); err != nil {
+			return err
+		}
+	}
 	return createIndexes2(
 		context.Background(),
 		r.client.Client(),
 		append(
-			r.client.Indexes(),
-			mongox.IndexFromKeys(itemIndexes, false)...,
+			append(r.client.Indexes(), mongox.IndexFromKeys(itemIndexes, false)...),
+			mongox.GeoIndexFromKey(itemGeoIndex),
 		)...,
 	)
 }
@@ -243,7 +263,14 @@ func (r *Item) Save(ctx context.Context, item *item.Item) error {
 		return repo.ErrOperationDenied
 	}
 	doc, id := mongodoc.NewItem(item)
-	return r.client.SaveOne(ctx, id, doc, nil)
+	if err := r.storableGeo(ctx, doc); err != nil {
+		return err
+	}
+	if err := r.client.SaveOne(ctx, id, doc, nil); err != nil {
+		return err
+	}
+	r.index(ctx, item)
+	return nil
 }
 
 func (r *Item) SaveAll(ctx context.Context, items item.List) error {
@@ -257,7 +284,14 @@ func (r *Item) SaveAll(ctx context.Context, items item.List) error {
 		}
 	}
 	docs, ids := mongodoc.NewItems(items)
-	return r.client.SaveMany(ctx, ids, lo.ToAnySlice(docs))
+	if err := r.storableGeo(ctx, docs...); err != nil {
+		return err
+	}
+	if err := r.client.SaveMany(ctx, ids, lo.ToAnySlice(docs)); err != nil {
+		return err
+	}
+	r.index(ctx, items...)
+	return nil
 }
 
 func (r *Item) UpdateRef(
@@ -269,8 +303,59 @@ func (r *Item) UpdateRef(
 	return r.client.UpdateRef(ctx, item.String(), ref, vr)
 }
 
-func (r *Item) Remove(ctx context.Context, id id.ItemID) error {
-	return r.client.RemoveOne(ctx, r.writeFilter(bson.M{"id": id.String()}))
+func (r *Item) Remove(ctx context.Context, iid id.ItemID) error {
+	if err := r.client.RemoveOne(ctx, r.writeFilter(bson.M{"id": iid.String()})); err != nil {
+		return err
+	}
+	if r.search != nil {
+		if err := r.search.Delete(ctx, id.ItemIDList{iid}); err != nil {
+			log.Errorfc(ctx, "mongo: item: failed to delete item from search index: %v", err)
+		}
+	}
+	return nil
+}
+
+// index updates the search index. Failures are only logged so that saving items does not fail because of the index.
+func (r *Item) index(ctx context.Context, items ...*item.Item) {
+	if r.search == nil {
+		return
+	}
+	if err := r.search.Index(ctx, items); err != nil {
+		log.Errorfc(ctx, "mongo: item: failed to index items: %v", err)
+	}
+}
+
+// reindexBatchSize is the number of items Reindex adds to the search index at once
+const reindexBatchSize = 100
+
+func (r *Item) Reindex(ctx context.Context, modelID id.ModelID) error {
+	if r.search == nil {
+		return nil
+	}
+	c := &mongox.BatchConsumer{
+		Size: reindexBatchSize,
+		Callback: func(rows []bson.Raw) error {
+			items := make(item.List, 0, len(rows))
+			for _, row := range rows {
+				var d mongodoc.ItemDocument
+				if err := bson.Unmarshal(row, &d); err != nil {
+					return err
+				}
+				i, err := d.Model()
+				if err != nil {
+					return err
+				}
+				items = append(items, i)
+			}
+			return r.search.Index(ctx, items)
+		},
+	}
+	return r.client.Find(
+		ctx,
+		r.readFilter(bson.M{"modelid": modelID.String()}),
+		version.Eq(version.Latest.OrVersion()),
+		c,
+	)
 }
 
 func (r *Item) Archive(ctx context.Context, id id.ItemID, pid id.ProjectID, b bool) error {
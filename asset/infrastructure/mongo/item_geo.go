@@ -0,0 +1,132 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/reearth/reearthx/asset/domain/value"
+	"github.com/reearth/reearthx/asset/infrastructure/mongo/mongodoc"
+	"github.com/reearth/reearthx/log"
+	"github.com/reearth/reearthx/mongox"
+	"github.com/reearth/reearthx/rerror"
+	"github.com/samber/lo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// fillItemGeoBatchSize is the number of items FillItemGeo updates at once
+const fillItemGeoBatchSize = 1000
+
+// FillItemGeo stores the GeoJSON values of geometry fields of items saved by older versions, which are not matched
+// by geo conditions until then. It updates all versions of the items and can be run again safely.
+// Applications should call it once from a DB migration after upgrading.
+func FillItemGeo(ctx context.Context, client *mongox.Client) error {
+	r := NewItem(client).(*Item)
+	filter := bson.M{
+		"geo":        bson.M{"$exists": false},
+		"fields.v.t": bson.M{"$in": []string{string(value.TypeGeometryObject), string(value.TypeGeometryEditor)}},
+	}
+	return r.client.Client().Find(ctx, filter, &mongox.BatchConsumer{
+		Size: fillItemGeoBatchSize,
+		Callback: func(rows []bson.Raw) error {
+			return r.fillGeo(ctx, rows)
+		},
+	})
+}
+
+func (r *Item) fillGeo(ctx context.Context, rows []bson.Raw) error {
+	ids := make([]primitive.ObjectID, 0, len(rows))
+	docs := make([]*mongodoc.ItemDocument, 0, len(rows))
+	for _, row := range rows {
+		var meta struct {
+			ObjectID primitive.ObjectID `bson:"_id"`
+		}
+		var d mongodoc.ItemDocument
+		if err := bson.Unmarshal(row, &meta); err != nil {
+			return err
+		}
+		if err := bson.Unmarshal(row, &d); err != nil {
+			return err
+		}
+		i, err := d.Model()
+		if err != nil {
+			return err
+		}
+		doc, _ := mongodoc.NewItem(i)
+		ids = append(ids, meta.ObjectID)
+		docs = append(docs, doc)
+	}
+	if err := r.storableGeo(ctx, docs...); err != nil {
+		return err
+	}
+
+	models := make([]mongo.WriteModel, 0, len(docs))
+	for i, d := range docs {
+		// items without valid values get an empty array so that they are not read again
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": ids[i]}).
+			SetUpdate(bson.M{"$set": bson.M{"geo": lo.Ternary(d.Geo == nil, []mongodoc.ItemGeoDocument{}, d.Geo)}}))
+	}
+	if len(models) == 0 {
+		return nil
+	}
+	if _, err := r.client.Client().Client().BulkWrite(ctx, models); err != nil {
+		return rerror.ErrInternalBy(err)
+	}
+	return nil
+}
+
+// storableGeo drops the GeoJSON values of the documents that MongoDB cannot store in the 2dsphere index,
+// such as self-intersecting polygons, so that saving items does not fail because of their geometries.
+// The items are still saved with the values in their fields, but they are not matched by geo conditions.
+func (r *Item) storableGeo(ctx context.Context, docs ...*mongodoc.ItemDocument) error {
+	geo := lo.FlatMap(docs, func(d *mongodoc.ItemDocument, _ int) []bson.M {
+		return lo.Map(d.Geo, func(g mongodoc.ItemGeoDocument, _ int) bson.M { return g.G })
+	})
+	if len(geo) == 0 {
+		return nil
+	}
+	// usually all values are valid, so they are checked at once first
+	if ok, err := r.validGeo(ctx, geo...); err != nil || ok {
+		return err
+	}
+
+	for _, d := range docs {
+		var valid []mongodoc.ItemGeoDocument
+		for _, g := range d.Geo {
+			ok, err := r.validGeo(ctx, g.G)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				log.Warnfc(ctx, "mongo: item: geometry of field %s of item %s cannot be indexed", g.F, d.ID)
+				continue
+			}
+			valid = append(valid, g)
+		}
+		d.Geo = valid
+	}
+	return nil
+}
+
+// validGeo reports whether MongoDB accepts the GeoJSON values. They are parsed by a query with the same rules
+// as the 2dsphere index applies to stored values. Retrying a failed write without the values is not possible,
+// since any error aborts the transaction the item is saved in, so the query runs outside of the transaction.
+func (r *Item) validGeo(ctx context.Context, geo ...bson.M) (bool, error) {
+	filter := bson.M{
+		"id": "",
+		"$or": lo.Map(geo, func(g bson.M, _ int) bson.M {
+			return bson.M{itemGeoIndex: bson.M{"$geoIntersects": bson.M{"$geometry": g}}}
+		}),
+	}
+	err := r.client.Client().Client().FindOne(mongo.NewSessionContext(ctx, nil), filter).Err()
+	if err == nil || errors.Is(err, mongo.ErrNoDocuments) {
+		return true, nil
+	}
+	var se mongo.ServerError
+	if errors.As(err, &se) {
+		return false, nil
+	}
+	return false, rerror.ErrInternalBy(err)
+}
@@ -2,17 +2,23 @@ package mongo
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"regexp"
 	"time"
 
+	geojson "github.com/paulmach/go.geojson"
 	"github.com/reearth/reearthx/asset/domain/id"
 	"github.com/reearth/reearthx/asset/domain/item"
 	"github.com/reearth/reearthx/asset/domain/item/view"
 	"github.com/reearth/reearthx/asset/domain/schema"
 	"github.com/reearth/reearthx/asset/domain/value"
+	"github.com/reearth/reearthx/asset/domain/version"
+	"github.com/reearth/reearthx/asset/usecase/repo"
 	"github.com/reearth/reearthx/idx"
 	"github.com/reearth/reearthx/log"
+	"github.com/reearth/reearthx/rerror"
 	"github.com/reearth/reearthx/usecasex"
 	"github.com/reearth/reearthx/util"
 	"github.com/samber/lo"
@@ -31,19 +37,67 @@ func (r *Item) Search(
 	t := time.Now()
 	defer func() { span.End(); log.Infof("trace: mongo/item/search %s", time.Since(t)) }()
 
+	ranked, err := r.searchIndex(ctx, query)
+	if err != nil {
+		return nil, nil, rerror.ErrInternalBy(err)
+	}
+
 	res, pi, err := r.paginateAggregation(
 		ctx,
-		buildPipeline(query, sp),
+		buildPipeline(query, sp, ranked),
 		query.Ref(),
-		sort(query),
+		sort(query, ranked != nil),
 		pagination,
 	)
 	return res, pi, err
 }
 
-func buildPipeline(query *item.Query, sp schema.Package) []any {
+// searchIndex looks up the keyword of the query in the search index. It returns nil when field values should be
+// scanned instead: when the repository has no index, when too many items match to filter them by IDs,
+// or when the index has fewer items of the model than the repository, e.g. until a copied model is reindexed.
+func (r *Item) searchIndex(ctx context.Context, query *item.Query) (id.ItemIDList, error) {
+	if r.search == nil || query.Keyword() == "" {
+		return nil, nil
+	}
+
+	indexed, err := r.search.Count(ctx, query.Model())
+	if err != nil {
+		return nil, err
+	}
+	saved, err := r.client.Count(
+		ctx,
+		r.readFilter(bson.M{"modelid": query.Model().String()}),
+		version.Eq(version.Latest.OrVersion()),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if indexed < saved {
+		return nil, nil
+	}
+
+	ids, err := r.search.Search(ctx, query)
+	if errors.Is(err, repo.ErrTooManySearchResults) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return lo.Ternary(ids == nil, id.ItemIDList{}, ids), nil
+}
+
+// buildPipeline builds the search pipeline. When ranked is not nil, the keyword has been already looked up
+// in the search index and items are filtered by the ranked IDs instead of scanning the field values.
+func buildPipeline(query *item.Query, sp schema.Package, ranked id.ItemIDList) []any {
+	// geo conditions on item fields are applied with the basic filter so that the 2dsphere index can be used
+	geo, rest := splitGeoCondition(query.Filter())
+
 	// apply basic filter like project, model, schema
-	pipeline := []any{basicFilterStage(query)}
+	pipeline := []any{basicFilterStage(query, geo...)}
+
+	if ranked != nil {
+		pipeline = append(pipeline, rankedFilterStages(ranked)...)
+	}
 
 	// if the query has any meta fields, lookup the meta item
 	if query.HasMetaFields() {
@@ -76,20 +130,23 @@ func buildPipeline(query *item.Query, sp schema.Package) []any {
 	pipeline = append(pipeline, basicFieldsAliasStages(query, sp)...)
 
 	// apply text filter
-	if query.Keyword() != "" {
+	if query.Keyword() != "" && ranked == nil {
 		pipeline = append(pipeline, textFilterStage(query.Keyword(), sp))
 	}
 
 	// apply filters and sort to pipeline
-	filterStage := filter(query.Filter(), sp)
+	filterStage := filter(rest, sp)
 	if filterStage != nil {
 		pipeline = append(pipeline, bson.M{"$match": filterStage})
 	}
 	return pipeline
 }
 
-func sort(query *item.Query) *usecasex.Sort {
+func sort(query *item.Query, byScore bool) *usecasex.Sort {
 	var s *usecasex.Sort
+	if query.Sort() == nil && byScore {
+		return &usecasex.Sort{Key: "__temp.score"}
+	}
 	if query.Sort() != nil {
 		reverted := query.Sort().Direction == view.DirectionDesc
 		s = &usecasex.Sort{
@@ -100,7 +157,7 @@ func sort(query *item.Query) *usecasex.Sort {
 	return s
 }
 
-func basicFilterStage(query *item.Query) any {
+func basicFilterStage(query *item.Query, geo ...view.Condition) any {
 	filter := bson.M{
 		"project": query.Project().String(),
 		"modelid": query.Model().String(),
@@ -108,9 +165,59 @@ func basicFilterStage(query *item.Query) any {
 	if query.Schema() != nil {
 		filter["schema"] = query.Schema().String()
 	}
+	if len(geo) > 0 {
+		filter["$and"] = lo.Map(geo, func(c view.Condition, _ int) any {
+			return filterGeo(&c, schema.Package{})
+		})
+	}
 	return bson.M{"$match": filter}
 }
 
+// splitGeoCondition takes the geo conditions on item fields out of the condition when they are
+// combined only by AND, and returns them and the rest of the condition, which is nil if nothing is left.
+// Conditions under OR and on meta fields are kept as they need the other stages of the pipeline.
+func splitGeoCondition(c *view.Condition) ([]view.Condition, *view.Condition) {
+	if c == nil {
+		return nil, nil
+	}
+	switch c.ConditionType {
+	case view.ConditionTypeGeo:
+		if c.GeoCondition.Field.Type == view.FieldTypeField {
+			return []view.Condition{*c}, nil
+		}
+	case view.ConditionTypeAnd:
+		var geo, rest []view.Condition
+		for _, cc := range c.AndCondition.Conditions {
+			g, r := splitGeoCondition(&cc)
+			geo = append(geo, g...)
+			if r != nil {
+				rest = append(rest, *r)
+			}
+		}
+		if len(geo) == 0 {
+			return nil, c
+		}
+		if len(rest) == 0 {
+			return geo, nil
+		}
+		return geo, &view.Condition{
+			ConditionType: view.ConditionTypeAnd,
+			AndCondition:  &view.AndCondition{Conditions: rest},
+		}
+	}
+	return nil, c
+}
+
+// rankedFilterStages filters items by the IDs returned by the search index and
+// sets their positions as scores so that items can be sorted by relevance.
+func rankedFilterStages(ranked id.ItemIDList) []any {
+	ids := ranked.Strings()
+	return []any{
+		bson.M{"$match": bson.M{"id": bson.M{"$in": ids}}},
+		bson.M{"$set": bson.M{"__temp.score": bson.M{"$indexOfArray": bson.A{ids, "$id"}}}},
+	}
+}
+
 func lookupMetaItem() []any {
 	return []any{
 		bson.M{
@@ -330,6 +437,8 @@ func filter(c *view.Condition, sp schema.Package) any {
 		ff = lo.Assign(ff, filterDate(c, sp))
 	case view.ConditionTypeMultiple:
 		ff = lo.Assign(ff, filterMultiple(c, sp))
+	case view.ConditionTypeGeo:
+		ff = lo.Assign(ff, filterGeo(c, sp))
 	case view.ConditionTypeAnd:
 		ff["$and"] = lo.Map(c.AndCondition.Conditions, func(c view.Condition, _ int) any {
 			return filter(&c, sp)
@@ -344,6 +453,66 @@ func filter(c *view.Condition, sp schema.Package) any {
 	return ff
 }
 
+// earthRadius is the equatorial radius in meters used by $centerSphere
+const earthRadius = 6378100.0
+
+func filterGeo(c *view.Condition, _ schema.Package) bson.M {
+	gc := c.GeoCondition
+	if gc.Field.ID == nil {
+		return bson.M{"$expr": false}
+	}
+
+	var g bson.M
+	switch gc.Op {
+	case view.GeoOperatorWithinBBox, view.GeoOperatorWithinPolygon:
+		if r := geoJSON(gc.Region()); r != nil {
+			g = bson.M{"$geoWithin": bson.M{"$geometry": r}}
+		}
+	case view.GeoOperatorIntersects:
+		if r := geoJSON(gc.Region()); r != nil {
+			g = bson.M{"$geoIntersects": bson.M{"$geometry": r}}
+		}
+	case view.GeoOperatorNear:
+		// $near and $nearSphere are not allowed in $match, so the distance is expressed as a spherical cap
+		if len(gc.Point) >= 2 {
+			g = bson.M{"$geoWithin": bson.M{"$centerSphere": bson.A{
+				bson.A{gc.Point[0], gc.Point[1]},
+				gc.Distance / earthRadius,
+			}}}
+		}
+	}
+	if g == nil {
+		return bson.M{"$expr": false}
+	}
+
+	return bson.M{geoKey(gc.Field): bson.M{
+		"$elemMatch": bson.M{"f": gc.Field.ID.String(), "g": g},
+	}}
+}
+
+// returns the key of GeoJSON values for the given field selector
+func geoKey(f view.FieldSelector) string {
+	if f.Type == view.FieldTypeMetaField {
+		return "__temp.meta.geo"
+	}
+	return "geo"
+}
+
+func geoJSON(g *geojson.Geometry) bson.M {
+	if g == nil {
+		return nil
+	}
+	b, err := g.MarshalJSON()
+	if err != nil {
+		return nil
+	}
+	res := bson.M{}
+	if err := json.Unmarshal(b, &res); err != nil {
+		return nil
+	}
+	return res
+}
+
 func filterMultiple(c *view.Condition, _ schema.Package) bson.M {
 	f := bson.M{}
 	switch c.MultipleCondition.Op {
@@ -0,0 +1,182 @@
+package mongo
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/reearth/reearthx/asset/domain/id"
+	"github.com/reearth/reearthx/asset/domain/item"
+	"github.com/reearth/reearthx/asset/usecase/repo"
+	"github.com/reearth/reearthx/mongox"
+	"github.com/samber/lo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const itemSearchLimit = 1000
+
+var itemSearchIndexes = []string{
+	"project,modelid,schema",
+	"tokens",
+}
+
+// languages supported by the text index of MongoDB. Other languages are indexed without stemming and stop words.
+var itemSearchLanguages = map[string]string{
+	"da": "danish",
+	"de": "german",
+	"en": "english",
+	"es": "spanish",
+	"fi": "finnish",
+	"fr": "french",
+	"hu": "hungarian",
+	"it": "italian",
+	"nb": "norwegian",
+	"nl": "dutch",
+	"pt": "portuguese",
+	"ro": "romanian",
+	"ru": "russian",
+	"sv": "swedish",
+	"tr": "turkish",
+}
+
+var _ repo.ItemSearchIndex = &ItemSearchIndex{}
+
+// ItemSearchIndex is a search index stored in a MongoDB collection with a text index.
+// Words are ranked by the text score, and the last word of keywords also matches as a prefix of words.
+type ItemSearchIndex struct {
+	client   *mongox.Collection
+	language string
+	limit    int64
+}
+
+type itemSearchDocument struct {
+	ID       string
+	Project  string
+	ModelID  string
+	Schema   string
+	Text     string
+	Tokens   []string
+	Language string
+}
+
+// NewItemSearchIndex returns a search index that analyzes texts in the given language such as "en".
+func NewItemSearchIndex(client *mongox.Client, language string) *ItemSearchIndex {
+	return &ItemSearchIndex{
+		client:   client.WithCollection("item_search"),
+		language: language,
+		limit:    itemSearchLimit,
+	}
+}
+
+func (r *ItemSearchIndex) Init() error {
+	return createIndexes2(
+		context.Background(),
+		r.client,
+		append(
+			mongox.IndexFromKeys(itemSearchIndexes, false),
+			mongox.IndexFromKey("id", true),
+			mongox.Index{Name: "text", Key: bson.D{{Key: "text", Value: "text"}}},
+		)...,
+	)
+}
+
+func (r *ItemSearchIndex) Index(ctx context.Context, items item.List) error {
+	items = lo.Filter(items, func(i *item.Item, _ int) bool { return i != nil })
+	if len(items) == 0 {
+		return nil
+	}
+
+	ids := lo.Map(items, func(i *item.Item, _ int) string { return i.ID().String() })
+	docs := lo.Map(items, func(i *item.Item, _ int) any {
+		text := strings.Join(i.TextValues(), "\n")
+		return &itemSearchDocument{
+			ID:       i.ID().String(),
+			Project:  i.Project().String(),
+			ModelID:  i.Model().String(),
+			Schema:   i.Schema().String(),
+			Text:     text,
+			Tokens:   lo.Uniq(searchTokens(text)),
+			Language: mongoLanguage(r.language),
+		}
+	})
+	return r.client.SaveAll(ctx, ids, docs)
+}
+
+func (r *ItemSearchIndex) Delete(ctx context.Context, ids id.ItemIDList) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.client.RemoveAll(ctx, bson.M{"id": bson.M{"$in": ids.Strings()}})
+}
+
+func (r *ItemSearchIndex) Search(ctx context.Context, q *item.Query) (id.ItemIDList, error) {
+	tokens := searchTokens(q.Keyword())
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+
+	filter := bson.M{
+		"project": q.Project().String(),
+		"modelid": q.Model().String(),
+	}
+	if q.Schema() != nil {
+		filter["schema"] = q.Schema().String()
+	}
+
+	lang := q.Language()
+	if lang == "" {
+		lang = r.language
+	}
+	matched, err := r.find(ctx, lo.Assign(filter, bson.M{
+		"$text": bson.M{"$search": q.Keyword(), "$language": mongoLanguage(lang)},
+	}), options.Find().
+		SetProjection(bson.M{"id": 1, "score": bson.M{"$meta": "textScore"}}).
+		SetSort(bson.D{{Key: "score", Value: bson.M{"$meta": "textScore"}}}))
+	if err != nil {
+		return nil, err
+	}
+
+	// items whose words start with the last word follow the ranked items
+	prefix := tokens[len(tokens)-1]
+	prefixed, err := r.find(ctx, lo.Assign(filter, bson.M{
+		"tokens": bson.M{"$regex": "^" + regexp.QuoteMeta(prefix)},
+	}), options.Find().SetProjection(bson.M{"id": 1}))
+	if err != nil {
+		return nil, err
+	}
+
+	res := lo.Uniq(append(matched, prefixed...))
+	if int64(len(res)) > r.limit {
+		return nil, repo.ErrTooManySearchResults
+	}
+	return id.ItemIDListFrom(res)
+}
+
+func (r *ItemSearchIndex) Count(ctx context.Context, m id.ModelID) (int64, error) {
+	return r.client.Count(ctx, bson.M{"modelid": m.String()})
+}
+
+// find returns the IDs of the documents up to one more than the limit so that the caller can tell that they exceed it.
+func (r *ItemSearchIndex) find(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]string, error) {
+	c := &mongox.SliceConsumer[itemSearchDocument]{}
+	if err := r.client.Find(ctx, filter, c, opts.SetLimit(r.limit+1)); err != nil {
+		return nil, err
+	}
+	return lo.Map(c.Result, func(d itemSearchDocument, _ int) string { return d.ID }), nil
+}
+
+func mongoLanguage(lang string) string {
+	if l, ok := itemSearchLanguages[strings.ToLower(lang)]; ok {
+		return l
+	}
+	return "none"
+}
+
+// searchTokens splits the text into lower-cased words.
+func searchTokens(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
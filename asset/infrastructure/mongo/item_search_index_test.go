@@ -0,0 +1,140 @@
+package mongo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/reearth/reearthx/account/accountdomain"
+	"github.com/reearth/reearthx/asset/domain/id"
+	"github.com/reearth/reearthx/asset/domain/item"
+	"github.com/reearth/reearthx/asset/domain/schema"
+	"github.com/reearth/reearthx/asset/domain/value"
+	"github.com/reearth/reearthx/asset/usecase/repo"
+	"github.com/reearth/reearthx/mongox"
+	"github.com/reearth/reearthx/mongox/mongotest"
+	"github.com/reearth/reearthx/usecasex"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestItemSearchIndex(t *testing.T) {
+	pID := id.NewProjectID()
+	mID := id.NewModelID()
+	sf := schema.NewField(schema.NewText(nil).TypeProperty()).NewID().RandomKey().MustBuild()
+	s := schema.New().
+		NewID().
+		Project(pID).
+		Workspace(accountdomain.NewWorkspaceID()).
+		Fields([]*schema.Field{sf}).
+		MustBuild()
+	sp := schema.NewPackage(s, nil, nil, nil)
+	newItem := func(text string) *item.Item {
+		return item.New().
+			NewID().
+			Schema(s.ID()).
+			Model(mID).
+			Fields([]*item.Field{item.NewField(sf.ID(), value.TypeText.Value(text).AsMultiple(), nil)}).
+			Project(pID).
+			Thread(id.NewThreadID().Ref()).
+			MustBuild()
+	}
+	i1 := newItem("apple banana")
+	i2 := newItem("apple apple apple")
+	i3 := newItem("pineapple")
+	i4 := newItem("applesauce")
+
+	init := mongotest.Connect(t)
+	client := mongox.NewClientWithDatabase(init(t))
+	ctx := context.Background()
+
+	index := NewItemSearchIndex(client, "en")
+	r := NewItemWithSearchIndex(client, index)
+	require.NoError(t, r.(*Item).Init())
+	for _, i := range (item.List{i1, i2, i3, i4}) {
+		require.NoError(t, r.Save(ctx, i))
+	}
+
+	ids, err := index.Search(ctx, item.NewQuery(pID, mID, nil, "apple", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, id.ItemIDList{i2.ID(), i1.ID(), i4.ID()}, ids)
+
+	ids, err = index.Search(ctx, item.NewQuery(id.NewProjectID(), mID, nil, "apple", nil))
+	assert.NoError(t, err)
+	assert.Empty(t, ids)
+
+	got, _, err := r.Search(
+		ctx,
+		*sp,
+		item.NewQuery(pID, mID, nil, "apple", nil),
+		usecasex.CursorPagination{First: lo.ToPtr(int64(10))}.Wrap(),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, id.ItemIDList{i2.ID(), i1.ID(), i4.ID()}, got.Unwrap().IDs())
+
+	// when the index has too many results, field values are scanned
+	index.limit = 2
+	_, err = index.Search(ctx, item.NewQuery(pID, mID, nil, "apple", nil))
+	assert.ErrorIs(t, err, repo.ErrTooManySearchResults)
+	got, _, err = r.Search(
+		ctx,
+		*sp,
+		item.NewQuery(pID, mID, nil, "apple", nil),
+		usecasex.CursorPagination{First: lo.ToPtr(int64(10))}.Wrap(),
+	)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, id.ItemIDList{i1.ID(), i2.ID(), i3.ID(), i4.ID()}, got.Unwrap().IDs())
+	index.limit = itemSearchLimit
+
+	require.NoError(t, r.Remove(ctx, i2.ID()))
+	ids, err = index.Search(ctx, item.NewQuery(pID, mID, nil, "apple", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, id.ItemIDList{i1.ID(), i4.ID()}, ids)
+
+	count, err := index.Count(ctx, mID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+
+	// items saved without the index, e.g. by the copy task, are found by scanning field values until Reindex
+	i5 := newItem("apple tart")
+	require.NoError(t, NewItem(client).Save(ctx, i5))
+	ids, err = index.Search(ctx, item.NewQuery(pID, mID, nil, "tart", nil))
+	assert.NoError(t, err)
+	assert.Empty(t, ids)
+	got, _, err = r.Search(
+		ctx,
+		*sp,
+		item.NewQuery(pID, mID, nil, "tart", nil),
+		usecasex.CursorPagination{First: lo.ToPtr(int64(10))}.Wrap(),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, id.ItemIDList{i5.ID()}, got.Unwrap().IDs())
+	require.NoError(t, r.Reindex(ctx, mID))
+	ids, err = index.Search(ctx, item.NewQuery(pID, mID, nil, "tart", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, id.ItemIDList{i5.ID()}, ids)
+}
+
+func TestSearchTokens(t *testing.T) {
+	assert.Equal(t, []string{"hello", "world", "2024", "東京タワー"}, searchTokens("Hello, World! 2024\n東京タワー"))
+	assert.Empty(t, searchTokens(" - "))
+}
+
+func TestMongoLanguage(t *testing.T) {
+	assert.Equal(t, "english", mongoLanguage("en"))
+	assert.Equal(t, "german", mongoLanguage("DE"))
+	assert.Equal(t, "none", mongoLanguage("ja"))
+	assert.Equal(t, "none", mongoLanguage(""))
+}
+
+func TestRankedFilterStages(t *testing.T) {
+	i1, i2 := id.NewItemID(), id.NewItemID()
+	sp := *schema.NewPackage(schema.New().NewID().Project(id.NewProjectID()).Workspace(accountdomain.NewWorkspaceID()).MustBuild(), nil, nil, nil)
+	q := item.NewQuery(id.NewProjectID(), id.NewModelID(), nil, "foo", nil)
+
+	pipeline := buildPipeline(q, sp, id.ItemIDList{i1, i2})
+	assert.Equal(t, rankedFilterStages(id.ItemIDList{i1, i2}), pipeline[1:3])
+	assert.NotContains(t, pipeline, textFilterStage("foo", sp))
+	assert.Contains(t, buildPipeline(q, sp, nil), textFilterStage("foo", sp))
+	assert.Equal(t, "__temp.score", sort(item.NewQuery(id.NewProjectID(), id.NewModelID(), nil, "foo", nil), true).Key)
+}
@@ -6,10 +6,12 @@ import (
 	"testing"
 	"time"
 
+	geojson "github.com/paulmach/go.geojson"
 	"github.com/reearth/reearthx/account/accountdomain"
 
 	"github.com/reearth/reearthx/asset/domain/id"
 	"github.com/reearth/reearthx/asset/domain/item"
+	"github.com/reearth/reearthx/asset/domain/item/view"
 	"github.com/reearth/reearthx/asset/domain/schema"
 	"github.com/reearth/reearthx/asset/domain/task"
 	"github.com/reearth/reearthx/asset/domain/value"
@@ -22,6 +24,7 @@ import (
 	"github.com/reearth/reearthx/util"
 	"github.com/samber/lo"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -575,6 +578,342 @@ func TestItem_Search(t *testing.T) {
 	}
 }
 
+func TestItem_SearchGeo(t *testing.T) {
+	pID := id.NewProjectID()
+	mID := id.NewModelID()
+	sf := schema.NewField(
+		schema.NewGeometryObject(schema.GeometryObjectSupportedTypeList{
+			schema.GeometryObjectSupportedTypePoint,
+			schema.GeometryObjectSupportedTypeLineString,
+		}).TypeProperty(),
+	).NewID().RandomKey().MustBuild()
+	s := schema.New().
+		NewID().
+		Project(pID).
+		Workspace(accountdomain.NewWorkspaceID()).
+		Fields([]*schema.Field{sf}).
+		MustBuild()
+	sp := schema.NewPackage(s, nil, nil, nil)
+	newItem := func(g string) *item.Item {
+		return item.New().
+			NewID().
+			Schema(s.ID()).
+			Model(mID).
+			Fields([]*item.Field{
+				item.NewField(sf.ID(), value.TypeGeometryObject.Value(g).AsMultiple(), nil),
+			}).
+			Project(pID).
+			Thread(id.NewThreadID().Ref()).
+			MustBuild()
+	}
+	i1 := newItem(`{"type":"Point","coordinates":[139.76,35.68]}`)
+	i2 := newItem(`{"type":"Point","coordinates":[135.50,34.69]}`)
+	i3 := newItem(`{"type":"LineString","coordinates":[[139.0,35.0],[140.0,36.0]]}`)
+	field := view.FieldSelector{Type: view.FieldTypeField, ID: sf.ID().Ref()}
+
+	tests := []struct {
+		Name     string
+		Geo      view.GeoCondition
+		Expected []id.ItemID
+	}{
+		{
+			Name:     "within bbox",
+			Geo:      view.GeoCondition{Field: field, Op: view.GeoOperatorWithinBBox, BBox: []float64{138.5, 34.5, 140.5, 36.5}},
+			Expected: []id.ItemID{i1.ID(), i3.ID()},
+		},
+		{
+			Name: "within polygon",
+			Geo: view.GeoCondition{Field: field, Op: view.GeoOperatorWithinPolygon, Polygon: [][][]float64{{
+				{135, 34}, {136, 34}, {136, 35}, {135, 35}, {135, 34},
+			}}},
+			Expected: []id.ItemID{i2.ID()},
+		},
+		{
+			Name: "intersects",
+			Geo: view.GeoCondition{Field: field, Op: view.GeoOperatorIntersects, Geometry: geojson.NewLineStringGeometry([][]float64{
+				{139.0, 36.0}, {140.0, 35.0},
+			})},
+			Expected: []id.ItemID{i3.ID()},
+		},
+		{
+			Name:     "near",
+			Geo:      view.GeoCondition{Field: field, Op: view.GeoOperatorNear, Point: []float64{139.70, 35.69}, Distance: 10000},
+			Expected: []id.ItemID{i1.ID()},
+		},
+	}
+
+	init := mongotest.Connect(t)
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			client := mongox.NewClientWithDatabase(init(t))
+
+			r := NewItem(client)
+			ctx := context.Background()
+			assert.NoError(t, r.(*Item).Init())
+			for _, i := range (item.List{i1, i2, i3}) {
+				assert.NoError(t, r.Save(ctx, i))
+			}
+
+			got, _, err := r.Search(
+				ctx,
+				*sp,
+				item.NewQuery(pID, mID, nil, "", nil).WithGeoFilter(tc.Geo),
+				usecasex.CursorPagination{First: lo.ToPtr(int64(10))}.Wrap(),
+			)
+			assert.NoError(t, err)
+			assert.ElementsMatch(t, tc.Expected, got.Unwrap().IDs())
+		})
+	}
+}
+
+func TestItem_Save_InvalidGeo(t *testing.T) {
+	pID := id.NewProjectID()
+	mID := id.NewModelID()
+	sf := schema.NewField(
+		schema.NewGeometryObject(schema.GeometryObjectSupportedTypeList{
+			schema.GeometryObjectSupportedTypePoint,
+			schema.GeometryObjectSupportedTypePolygon,
+		}).TypeProperty(),
+	).NewID().RandomKey().Multiple(true).MustBuild()
+	s := schema.New().
+		NewID().
+		Project(pID).
+		Workspace(accountdomain.NewWorkspaceID()).
+		Fields([]*schema.Field{sf}).
+		MustBuild()
+	sp := schema.NewPackage(s, nil, nil, nil)
+	newItem := func(g ...any) *item.Item {
+		return item.New().
+			NewID().
+			Schema(s.ID()).
+			Model(mID).
+			Fields([]*item.Field{
+				item.NewField(sf.ID(), value.NewMultiple(value.TypeGeometryObject, g), nil),
+			}).
+			Project(pID).
+			Thread(id.NewThreadID().Ref()).
+			MustBuild()
+	}
+	point := `{"type":"Point","coordinates":[139.76,35.68]}`
+	bowtie := `{"type":"Polygon","coordinates":[[[0,0],[4,4],[4,0],[0,4],[0,0]]]}`
+	i1 := newItem(point, bowtie)
+	i2 := newItem(bowtie)
+	i3 := newItem(point)
+
+	init := mongotest.Connect(t)
+	client := mongox.NewClientWithDatabase(init(t))
+	ctx := context.Background()
+	r := NewItem(client)
+	require.NoError(t, r.(*Item).Init())
+
+	// geometries MongoDB cannot index do not fail saving items
+	require.NoError(t, r.Save(ctx, i1))
+	require.NoError(t, r.SaveAll(ctx, item.List{i2, i3}))
+
+	got, err := r.FindByID(ctx, i1.ID(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, i1.Fields(), got.Value().Fields())
+
+	res, _, err := r.Search(
+		ctx,
+		*sp,
+		item.NewQuery(pID, mID, nil, "", nil).WithGeoFilter(view.GeoCondition{
+			Field: view.FieldSelector{Type: view.FieldTypeField, ID: sf.ID().Ref()},
+			Op:    view.GeoOperatorWithinBBox,
+			BBox:  []float64{138.5, 34.5, 140.5, 36.5},
+		}),
+		usecasex.CursorPagination{First: lo.ToPtr(int64(10))}.Wrap(),
+	)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, id.ItemIDList{i1.ID(), i3.ID()}, res.Unwrap().IDs())
+}
+
+func TestFillItemGeo(t *testing.T) {
+	pID := id.NewProjectID()
+	mID := id.NewModelID()
+	sf := schema.NewField(
+		schema.NewGeometryObject(schema.GeometryObjectSupportedTypeList{
+			schema.GeometryObjectSupportedTypePoint,
+			schema.GeometryObjectSupportedTypePolygon,
+		}).TypeProperty(),
+	).NewID().RandomKey().MustBuild()
+	s := schema.New().
+		NewID().
+		Project(pID).
+		Workspace(accountdomain.NewWorkspaceID()).
+		Fields([]*schema.Field{sf}).
+		MustBuild()
+	sp := schema.NewPackage(s, nil, nil, nil)
+	newItem := func(g string) *item.Item {
+		return item.New().
+			NewID().
+			Schema(s.ID()).
+			Model(mID).
+			Fields([]*item.Field{item.NewField(sf.ID(), value.TypeGeometryObject.Value(g).AsMultiple(), nil)}).
+			Project(pID).
+			Thread(id.NewThreadID().Ref()).
+			MustBuild()
+	}
+	i1 := newItem(`{"type":"Point","coordinates":[139.76,35.68]}`)
+	i2 := newItem(`{"type":"Polygon","coordinates":[[[0,0],[4,4],[4,0],[0,4],[0,0]]]}`)
+
+	init := mongotest.Connect(t)
+	client := mongox.NewClientWithDatabase(init(t))
+	ctx := context.Background()
+	r := NewItem(client)
+	require.NoError(t, r.(*Item).Init())
+	require.NoError(t, r.SaveAll(ctx, item.List{i1, i2}))
+
+	// items saved by older versions have no geo values
+	c := client.WithCollection("item").Client()
+	_, err := c.UpdateMany(ctx, bson.M{}, bson.M{"$unset": bson.M{"geo": ""}})
+	require.NoError(t, err)
+
+	search := func() id.ItemIDList {
+		res, _, err := r.Search(
+			ctx,
+			*sp,
+			item.NewQuery(pID, mID, nil, "", nil).WithGeoFilter(view.GeoCondition{
+				Field: view.FieldSelector{Type: view.FieldTypeField, ID: sf.ID().Ref()},
+				Op:    view.GeoOperatorWithinBBox,
+				BBox:  []float64{138.5, 34.5, 140.5, 36.5},
+			}),
+			usecasex.CursorPagination{First: lo.ToPtr(int64(10))}.Wrap(),
+		)
+		require.NoError(t, err)
+		return res.Unwrap().IDs()
+	}
+	assert.Empty(t, search())
+
+	require.NoError(t, FillItemGeo(ctx, client))
+	assert.Equal(t, id.ItemIDList{i1.ID()}, search())
+	n, err := c.CountDocuments(ctx, bson.M{"geo": bson.M{"$exists": false}, "id": bson.M{"$in": []string{i1.ID().String(), i2.ID().String()}}})
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	// it can be run again
+	require.NoError(t, FillItemGeo(ctx, client))
+	assert.Equal(t, id.ItemIDList{i1.ID()}, search())
+}
+
+func TestFilterGeo(t *testing.T) {
+	fid := id.NewFieldID()
+	field := view.FieldSelector{Type: view.FieldTypeField, ID: &fid}
+
+	assert.Equal(t, bson.M{"geo": bson.M{"$elemMatch": bson.M{
+		"f": fid.String(),
+		"g": bson.M{"$geoWithin": bson.M{"$geometry": bson.M{
+			"type": "Polygon",
+			"coordinates": []any{[]any{
+				[]any{1.0, 2.0}, []any{3.0, 2.0}, []any{3.0, 4.0}, []any{1.0, 4.0}, []any{1.0, 2.0},
+			}},
+		}}},
+	}}}, filterGeo(&view.Condition{
+		ConditionType: view.ConditionTypeGeo,
+		GeoCondition:  &view.GeoCondition{Field: field, Op: view.GeoOperatorWithinBBox, BBox: []float64{1, 2, 3, 4}},
+	}, schema.Package{}))
+
+	assert.Equal(t, bson.M{"__temp.meta.geo": bson.M{"$elemMatch": bson.M{
+		"f": fid.String(),
+		"g": bson.M{"$geoIntersects": bson.M{"$geometry": bson.M{
+			"type":        "Point",
+			"coordinates": []any{1.0, 2.0},
+		}}},
+	}}}, filterGeo(&view.Condition{
+		ConditionType: view.ConditionTypeGeo,
+		GeoCondition: &view.GeoCondition{
+			Field:    view.FieldSelector{Type: view.FieldTypeMetaField, ID: &fid},
+			Op:       view.GeoOperatorIntersects,
+			Geometry: geojson.NewPointGeometry([]float64{1, 2}),
+		},
+	}, schema.Package{}))
+
+	assert.Equal(t, bson.M{"geo": bson.M{"$elemMatch": bson.M{
+		"f": fid.String(),
+		"g": bson.M{"$geoWithin": bson.M{"$centerSphere": bson.A{
+			bson.A{1.0, 2.0},
+			1.0,
+		}}},
+	}}}, filterGeo(&view.Condition{
+		ConditionType: view.ConditionTypeGeo,
+		GeoCondition: &view.GeoCondition{
+			Field:    field,
+			Op:       view.GeoOperatorNear,
+			Point:    []float64{1, 2},
+			Distance: earthRadius,
+		},
+	}, schema.Package{}))
+
+	assert.Equal(t, bson.M{"$expr": false}, filterGeo(&view.Condition{
+		ConditionType: view.ConditionTypeGeo,
+		GeoCondition:  &view.GeoCondition{Field: field, Op: view.GeoOperatorWithinPolygon},
+	}, schema.Package{}))
+}
+
+func TestSplitGeoCondition(t *testing.T) {
+	fid := id.NewFieldID()
+	geo := view.Condition{
+		ConditionType: view.ConditionTypeGeo,
+		GeoCondition: &view.GeoCondition{
+			Field: view.FieldSelector{Type: view.FieldTypeField, ID: &fid},
+			Op:    view.GeoOperatorWithinBBox,
+			BBox:  []float64{1, 2, 3, 4},
+		},
+	}
+	metaGeo := view.Condition{
+		ConditionType: view.ConditionTypeGeo,
+		GeoCondition: &view.GeoCondition{
+			Field: view.FieldSelector{Type: view.FieldTypeMetaField, ID: &fid},
+			Op:    view.GeoOperatorWithinBBox,
+			BBox:  []float64{1, 2, 3, 4},
+		},
+	}
+	str := view.Condition{
+		ConditionType:   view.ConditionTypeString,
+		StringCondition: &view.StringCondition{Field: view.FieldSelector{Type: view.FieldTypeField, ID: &fid}, Op: view.StringOperatorContains, Value: "a"},
+	}
+	and := func(c ...view.Condition) *view.Condition {
+		return &view.Condition{ConditionType: view.ConditionTypeAnd, AndCondition: &view.AndCondition{Conditions: c}}
+	}
+	or := &view.Condition{ConditionType: view.ConditionTypeOr, OrCondition: &view.OrCondition{Conditions: []view.Condition{geo, str}}}
+
+	g, rest := splitGeoCondition(nil)
+	assert.Nil(t, g)
+	assert.Nil(t, rest)
+
+	g, rest = splitGeoCondition(&geo)
+	assert.Equal(t, []view.Condition{geo}, g)
+	assert.Nil(t, rest)
+
+	g, rest = splitGeoCondition(and(str, *and(geo, metaGeo)))
+	assert.Equal(t, []view.Condition{geo}, g)
+	assert.Equal(t, and(str, *and(metaGeo)), rest)
+
+	g, rest = splitGeoCondition(and(geo, geo))
+	assert.Equal(t, []view.Condition{geo, geo}, g)
+	assert.Nil(t, rest)
+
+	// conditions under OR and on meta fields are kept
+	g, rest = splitGeoCondition(or)
+	assert.Nil(t, g)
+	assert.Equal(t, or, rest)
+	g, rest = splitGeoCondition(&metaGeo)
+	assert.Nil(t, g)
+	assert.Equal(t, &metaGeo, rest)
+
+	// the geo condition is applied in the first stage
+	newSchema := func() *schema.Schema {
+		return schema.New().NewID().Project(id.NewProjectID()).Workspace(accountdomain.NewWorkspaceID()).MustBuild()
+	}
+	sp := *schema.NewPackage(newSchema(), newSchema(), nil, nil)
+	q := item.NewQuery(id.NewProjectID(), id.NewModelID(), nil, "", nil).WithFilter(and(str, geo))
+	pipeline := buildPipeline(q, sp, nil)
+	assert.Equal(t, bson.A{filterGeo(&geo, sp)}, bson.A(pipeline[0].(bson.M)["$match"].(bson.M)["$and"].([]any)))
+	assert.Equal(t, bson.M{"$match": filter(and(str), sp)}, pipeline[len(pipeline)-1])
+}
+
 func TestItem_FindByModelAndValue(t *testing.T) {
 	init := mongotest.Connect(t)
 	sid := id.NewSchemaID()
@@ -1,8 +1,10 @@
 package mongodoc
 
 import (
+	"encoding/json"
 	"time"
 
+	geojson "github.com/paulmach/go.geojson"
 	"github.com/reearth/reearthx/account/accountdomain"
 	"github.com/reearth/reearthx/asset/domain/id"
 	"github.com/reearth/reearthx/asset/domain/item"
@@ -11,6 +13,7 @@ import (
 	"github.com/reearth/reearthx/mongox"
 	"github.com/reearth/reearthx/util"
 	"github.com/samber/lo"
+	"go.mongodb.org/mongo-driver/bson"
 )
 
 type ItemDocument struct {
@@ -27,7 +30,8 @@ type ItemDocument struct {
 	Schema               string
 	ModelID              string
 	Fields               []ItemFieldDocument
-	Assets               []string `bson:"assets,omitempty"`
+	Assets               []string          `bson:"assets,omitempty"`
+	Geo                  []ItemGeoDocument `bson:"geo,omitempty"`
 	IsMetadata           bool
 }
 
@@ -37,6 +41,12 @@ type ItemFieldDocument struct {
 	V         ValueDocument `bson:"v,omitempty"`
 }
 
+// ItemGeoDocument holds a geometry field value as a GeoJSON object so that it can be indexed by 2dsphere.
+type ItemGeoDocument struct {
+	F string `bson:"f"`
+	G bson.M `bson:"g"`
+}
+
 type ItemConsumer = mongox.SliceFuncConsumer[*ItemDocument, *item.Item]
 
 func NewItemConsumer() *ItemConsumer {
@@ -86,6 +96,7 @@ func NewItem(i *item.Item) (*ItemDocument, string) {
 		UpdatedByIntegration: i.UpdatedByIntegration().StringRef(),
 		Integration:          i.Integration().StringRef(),
 		Assets:               i.AssetIDs().Strings(),
+		Geo:                  newItemGeo(i.Fields()),
 		IsMetadata:           i.IsMetadata(),
 		Thread:               i.Thread().StringRef(),
 	}, itmId
@@ -149,6 +160,29 @@ func (d *ItemDocument) Model() (*item.Item, error) {
 	return ib.Build()
 }
 
+// newItemGeo returns the values of geometry fields that are GeoJSON geometries. Whether MongoDB can store them
+// in the 2dsphere index is checked by the repository when the item is saved.
+func newItemGeo(fields item.Fields) []ItemGeoDocument {
+	var res []ItemGeoDocument
+	for _, f := range fields {
+		if f == nil || !f.IsGeometryField() {
+			continue
+		}
+		vv, _ := f.Value().ValuesString()
+		for _, v := range vv {
+			if _, err := geojson.UnmarshalGeometry([]byte(v)); err != nil {
+				continue
+			}
+			m := bson.M{}
+			if err := json.Unmarshal([]byte(v), &m); err != nil {
+				continue
+			}
+			res = append(res, ItemGeoDocument{F: f.FieldID().String(), G: m})
+		}
+	}
+	return res
+}
+
 func NewItems(items item.List) ([]*ItemDocument, []string) {
 	res := make([]*ItemDocument, 0, len(items))
 	ids := make([]string, 0, len(items))
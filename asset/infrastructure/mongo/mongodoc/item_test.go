@@ -11,7 +11,9 @@ import (
 	"github.com/reearth/reearthx/asset/domain/project"
 	"github.com/reearth/reearthx/asset/domain/schema"
 	"github.com/reearth/reearthx/asset/domain/thread"
+	"github.com/reearth/reearthx/asset/domain/value"
 	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
 )
 
 func TestItemDocument_Model(t *testing.T) {
@@ -204,6 +206,38 @@ func TestNewItem(t *testing.T) {
 	}
 }
 
+func TestNewItem_Geo(t *testing.T) {
+	f1, f2, f3 := schema.NewFieldID(), schema.NewFieldID(), schema.NewFieldID()
+	i := item.New().
+		NewID().
+		Project(project.NewID()).
+		Schema(schema.NewID()).
+		Model(model.NewID()).
+		Thread(thread.NewID().Ref()).
+		Fields([]*item.Field{
+			item.NewField(f1, value.NewMultiple(value.TypeGeometryObject, []any{
+				`{"type":"Point","coordinates":[139.7,35.6]}`,
+				`{"type":"Point","coordinates":[200,35.6]}`,
+				`invalid`,
+			}), nil),
+			item.NewField(f2, value.TypeGeometryEditor.Value(
+				`{"type":"LineString","coordinates":[[139.6,35.9],[139.7,36.3]]}`,
+			).AsMultiple(), nil),
+			item.NewField(f3, value.TypeText.Value(`{"type":"Point","coordinates":[1,2]}`).AsMultiple(), nil),
+		}).
+		MustBuild()
+
+	got, _ := NewItem(i)
+	assert.Equal(t, []ItemGeoDocument{
+		{F: f1.String(), G: bson.M{"type": "Point", "coordinates": []any{139.7, 35.6}}},
+		// values out of range are checked by MongoDB when the item is saved
+		{F: f1.String(), G: bson.M{"type": "Point", "coordinates": []any{200.0, 35.6}}},
+		{F: f2.String(), G: bson.M{"type": "LineString", "coordinates": []any{
+			[]any{139.6, 35.9}, []any{139.7, 36.3},
+		}}},
+	}, got.Geo)
+}
+
 func TestNewItemConsumer(t *testing.T) {
 	c := NewItemConsumer()
 	assert.NotNil(t, c)
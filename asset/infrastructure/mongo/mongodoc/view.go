@@ -3,6 +3,7 @@ package mongodoc
 import (
 	"time"
 
+	geojson "github.com/paulmach/go.geojson"
 	"github.com/reearth/reearthx/account/accountdomain"
 	"github.com/reearth/reearthx/asset/domain/id"
 	"github.com/reearth/reearthx/asset/domain/item/view"
@@ -113,6 +114,7 @@ type FilterDocument struct {
 	StringCondition   *StringConditionDocument
 	NumberCondition   *NumberConditionDocument
 	TimeCondition     *TimeConditionDocument
+	GeoCondition      *GeoConditionDocument
 	ConditionType     string
 }
 
@@ -204,6 +206,25 @@ func NewFilter(i *view.Condition) *FilterDocument {
 				Value: i.TimeCondition.Value,
 			},
 		}
+	case i.GeoCondition != nil:
+		var g *string
+		if i.GeoCondition.Geometry != nil {
+			if b, err := i.GeoCondition.Geometry.MarshalJSON(); err == nil {
+				g = lo.ToPtr(string(b))
+			}
+		}
+		return &FilterDocument{
+			ConditionType: "GEO",
+			GeoCondition: &GeoConditionDocument{
+				Field:    NewFieldSelector(i.GeoCondition.Field),
+				Op:       string(i.GeoCondition.Op),
+				BBox:     i.GeoCondition.BBox,
+				Polygon:  i.GeoCondition.Polygon,
+				Geometry: g,
+				Point:    i.GeoCondition.Point,
+				Distance: i.GeoCondition.Distance,
+			},
+		}
 	default:
 		return nil
 	}
@@ -288,6 +309,22 @@ func (d *FilterDocument) Model() *view.Condition {
 				Value: d.TimeCondition.Value,
 			},
 		}
+	case "GEO":
+		var g *geojson.Geometry
+		if d.GeoCondition.Geometry != nil {
+			g, _ = geojson.UnmarshalGeometry([]byte(*d.GeoCondition.Geometry))
+		}
+		return &view.Condition{
+			GeoCondition: &view.GeoCondition{
+				Field:    d.GeoCondition.Field.Model(),
+				Op:       view.GeoOperator(d.GeoCondition.Op),
+				BBox:     d.GeoCondition.BBox,
+				Polygon:  d.GeoCondition.Polygon,
+				Geometry: g,
+				Point:    d.GeoCondition.Point,
+				Distance: d.GeoCondition.Distance,
+			},
+		}
 	default:
 		return nil
 	}
@@ -342,6 +379,16 @@ type TimeConditionDocument struct {
 	Op    string
 }
 
+type GeoConditionDocument struct {
+	Geometry *string
+	Field    FieldSelectorDocument
+	Op       string
+	BBox     []float64
+	Polygon  [][][]float64
+	Point    []float64
+	Distance float64
+}
+
 func NewView(i *view.View) (*ViewDocument, string) {
 	if i == nil {
 		return nil, ""
@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	geojson "github.com/paulmach/go.geojson"
 	"github.com/reearth/reearthx/account/accountdomain/user"
 	"github.com/reearth/reearthx/asset/domain/item/view"
 	"github.com/reearth/reearthx/asset/domain/model"
@@ -77,3 +78,20 @@ func TestNewView(t *testing.T) {
 	assert.Equal(t, want, got)
 	assert.Equal(t, want.ID, gotId)
 }
+
+func TestFilterDocument_Geo(t *testing.T) {
+	fid := schema.NewFieldID()
+	c := &view.Condition{
+		ConditionType: view.ConditionTypeGeo,
+		GeoCondition: &view.GeoCondition{
+			Field:    view.FieldSelector{Type: view.FieldTypeField, ID: &fid},
+			Op:       view.GeoOperatorIntersects,
+			Geometry: geojson.NewPointGeometry([]float64{139.7, 35.6}),
+		},
+	}
+
+	d := NewFilter(c)
+	assert.Equal(t, "GEO", d.ConditionType)
+	assert.Equal(t, `{"type":"Point","coordinates":[139.7,35.6]}`, *d.GeoCondition.Geometry)
+	assert.Equal(t, &view.Condition{GeoCondition: c.GeoCondition}, d.Model())
+}
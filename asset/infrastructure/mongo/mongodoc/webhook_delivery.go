@@ -0,0 +1,131 @@
+package mongodoc
+
+import (
+	"errors"
+	"time"
+
+	"github.com/reearth/reearthx/asset/domain/event"
+	"github.com/reearth/reearthx/asset/domain/id"
+	"github.com/reearth/reearthx/asset/domain/integration"
+	"github.com/reearth/reearthx/mongox"
+	"github.com/reearth/reearthx/util"
+)
+
+type WebhookDeliveryDocument struct {
+	ID          string
+	Integration string
+	Webhook     string
+	Event       string
+	EventType   string
+	URL         string
+	Payload     []byte
+	Status      string
+	Attempts    int
+	StatusCode  int
+	Error       string
+	UpdatedAt   time.Time
+	History     []*WebhookDeliveryAttemptDocument
+	ReplayOf    *string
+}
+
+type WebhookDeliveryAttemptDocument struct {
+	AttemptedAt    time.Time
+	RequestSize    int
+	RequestHash    string
+	ResponseStatus int
+	// Latency is in milliseconds
+	Latency int64
+	Error   string
+}
+
+type WebhookDeliveryConsumer = mongox.SliceFuncConsumer[*WebhookDeliveryDocument, *integration.Delivery]
+
+func NewWebhookDeliveryConsumer() *WebhookDeliveryConsumer {
+	return NewConsumer[*WebhookDeliveryDocument, *integration.Delivery]()
+}
+
+func NewWebhookDelivery(d *integration.Delivery) (*WebhookDeliveryDocument, string) {
+	did := d.ID().String()
+	var eid string
+	if e := d.Event(); !e.IsNil() {
+		eid = e.String()
+	}
+	return &WebhookDeliveryDocument{
+		ID:          did,
+		Integration: d.Integration().String(),
+		Webhook:     d.Webhook().String(),
+		Event:       eid,
+		EventType:   string(d.EventType()),
+		URL:         d.URL(),
+		Payload:     d.Payload(),
+		Status:      string(d.Status()),
+		Attempts:    d.Attempts(),
+		StatusCode:  d.StatusCode(),
+		Error:       d.Error(),
+		UpdatedAt:   d.UpdatedAt(),
+		History:     util.Map(d.History(), newWebhookDeliveryAttempt),
+		ReplayOf:    d.ReplayOf().StringRef(),
+	}, did
+}
+
+func (d *WebhookDeliveryDocument) Model() (*integration.Delivery, error) {
+	did, err := id.WebhookDeliveryIDFrom(d.ID)
+	if err != nil {
+		return nil, err
+	}
+	iid, err := id.IntegrationIDFrom(d.Integration)
+	if err != nil {
+		return nil, err
+	}
+	wid, err := id.WebhookIDFrom(d.Webhook)
+	if err != nil {
+		return nil, err
+	}
+
+	b := integration.NewDeliveryBuilder().
+		ID(did).
+		Integration(iid).
+		Webhook(wid).
+		EventType(event.Type(d.EventType)).
+		URL(d.URL).
+		Payload(d.Payload).
+		Status(integration.DeliveryStatus(d.Status)).
+		Attempts(d.Attempts).
+		StatusCode(d.StatusCode).
+		Error(d.Error).
+		UpdatedAt(d.UpdatedAt).
+		History(util.Map(d.History, func(a *WebhookDeliveryAttemptDocument) *integration.DeliveryAttempt {
+			return a.Model()
+		})).
+		ReplayOf(id.WebhookDeliveryIDFromRef(d.ReplayOf))
+	if eid := id.EventIDFromRef(&d.Event); eid != nil {
+		b = b.Event(*eid)
+	}
+	return b.Build()
+}
+
+func newWebhookDeliveryAttempt(a *integration.DeliveryAttempt) *WebhookDeliveryAttemptDocument {
+	return &WebhookDeliveryAttemptDocument{
+		AttemptedAt:    a.AttemptedAt(),
+		RequestSize:    a.RequestSize(),
+		RequestHash:    a.RequestHash(),
+		ResponseStatus: a.ResponseStatus(),
+		Latency:        a.Latency().Milliseconds(),
+		Error:          a.Error(),
+	}
+}
+
+func (d *WebhookDeliveryAttemptDocument) Model() *integration.DeliveryAttempt {
+	var err error
+	if d.Error != "" {
+		err = errors.New(d.Error)
+	}
+	return integration.NewDeliveryAttemptWith(
+		d.AttemptedAt,
+		d.RequestSize,
+		d.RequestHash,
+		d.ResponseStatus,
+		time.Duration(d.Latency)*time.Millisecond,
+		err,
+	)
+}
@@ -0,0 +1,56 @@
+package mongodoc
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/reearth/reearthx/asset/domain/event"
+	"github.com/reearth/reearthx/asset/domain/id"
+	"github.com/reearth/reearthx/asset/domain/integration"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookDeliveryDocument_Model(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	d := integration.NewDeliveryBuilder().
+		NewID().
+		Integration(id.NewIntegrationID()).
+		Webhook(id.NewWebhookID()).
+		Event(id.NewEventID()).
+		EventType(event.AssetCreate).
+		URL("https://example.com/hook").
+		Payload([]byte(`{}`)).
+		Status(integration.DeliveryStatusFailed).
+		Attempts(2).
+		StatusCode(502).
+		Error("unexpected response status: 502").
+		UpdatedAt(now).
+		History([]*integration.DeliveryAttempt{
+			integration.NewDeliveryAttempt(now, []byte(`{}`), 0, 30*time.Second, errors.New("timeout")),
+			integration.NewDeliveryAttempt(now.Add(time.Minute), []byte(`{}`), 502, 250*time.Millisecond, nil),
+		}).
+		ReplayOf(id.NewWebhookDeliveryID().Ref()).
+		MustBuild()
+
+	doc, did := NewWebhookDelivery(d)
+	assert.Equal(t, d.ID().String(), did)
+	assert.Equal(t, d.Event().String(), doc.Event)
+	assert.Equal(t, int64(250), doc.History[1].Latency)
+	assert.Equal(t, 2, doc.History[1].RequestSize)
+	assert.Equal(t, d.History()[1].RequestHash(), doc.History[1].RequestHash)
+	got, err := doc.Model()
+	assert.NoError(t, err)
+	assert.Equal(t, d, got)
+
+	// event is optional
+	d = integration.NewDeliveryBuilder().NewID().Integration(id.NewIntegrationID()).Webhook(id.NewWebhookID()).UpdatedAt(now).MustBuild()
+	doc, _ = NewWebhookDelivery(d)
+	assert.Empty(t, doc.Event)
+	got, err = doc.Model()
+	assert.NoError(t, err)
+	assert.Equal(t, d, got)
+
+	_, err = (&WebhookDeliveryDocument{ID: "x"}).Model()
+	assert.Error(t, err)
+}
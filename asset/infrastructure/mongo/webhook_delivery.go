@@ -0,0 +1,89 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/reearth/reearthx/asset/domain/id"
+	"github.com/reearth/reearthx/asset/domain/integration"
+	"github.com/reearth/reearthx/asset/infrastructure/mongo/mongodoc"
+	"github.com/reearth/reearthx/asset/usecase/repo"
+	"github.com/reearth/reearthx/mongox"
+	"github.com/reearth/reearthx/rerror"
+	"github.com/reearth/reearthx/usecasex"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// webhookDeliveryRetention is how long deliveries are kept after they were last updated
+const webhookDeliveryRetention = 30 * 24 * time.Hour
+
+type WebhookDelivery struct {
+	client *mongox.Collection
+}
+
+func NewWebhookDelivery(client *mongox.Client) repo.WebhookDelivery {
+	return &WebhookDelivery{client: client.WithCollection("webhook_delivery")}
+}
+
+func (r *WebhookDelivery) Init() error {
+	return createIndexes2(context.Background(), r.client,
+		mongox.IndexFromKey("id", true),
+		// deliveries of a webhook are listed from the newest one
+		mongox.IndexFromKey("webhook,id", false),
+		mongox.IndexFromKey("status", false),
+		mongox.TTLIndexFromKey("updatedat", int32(webhookDeliveryRetention.Seconds())),
+	)
+}
+
+func (r *WebhookDelivery) FindByID(
+	ctx context.Context,
+	dId id.WebhookDeliveryID,
+) (*integration.Delivery, error) {
+	c := mongodoc.NewWebhookDeliveryConsumer()
+	if err := r.client.FindOne(ctx, bson.M{"id": dId.String()}, c); err != nil {
+		return nil, err
+	}
+	return c.Result[0], nil
+}
+
+func (r *WebhookDelivery) FindByWebhook(
+	ctx context.Context,
+	wId id.WebhookID,
+	pagination *usecasex.Pagination,
+) (integration.DeliveryList, *usecasex.PageInfo, error) {
+	filter := bson.M{"webhook": wId.String()}
+	// ids are ordered by time, so the newest delivery has the largest id
+	sort := &usecasex.Sort{Key: "id", Reverted: true}
+
+	c := mongodoc.NewWebhookDeliveryConsumer()
+	if pagination == nil {
+		if err := r.client.Find(ctx, filter, c, options.Find().SetSort(bson.D{{Key: "id", Value: -1}})); err != nil {
+			return nil, nil, rerror.ErrInternalBy(err)
+		}
+		return c.Result, usecasex.NewPageInfo(int64(len(c.Result)), nil, nil, false, false), nil
+	}
+
+	pageInfo, err := r.client.Paginate(ctx, filter, sort, pagination, c)
+	if err != nil {
+		return nil, nil, rerror.ErrInternalBy(err)
+	}
+	return c.Result, pageInfo, nil
+}
+
+func (r *WebhookDelivery) FindInProgress(ctx context.Context) (integration.DeliveryList, error) {
+	c := mongodoc.NewWebhookDeliveryConsumer()
+	filter := bson.M{"status": bson.M{"$in": []string{
+		string(integration.DeliveryStatusPending),
+		string(integration.DeliveryStatusRetrying),
+	}}}
+	if err := r.client.Find(ctx, filter, c, options.Find().SetSort(bson.D{{Key: "id", Value: 1}})); err != nil {
+		return nil, rerror.ErrInternalBy(err)
+	}
+	return c.Result, nil
+}
+
+func (r *WebhookDelivery) Save(ctx context.Context, d *integration.Delivery) error {
+	doc, did := mongodoc.NewWebhookDelivery(d)
+	return r.client.SaveOne(ctx, did, doc)
+}
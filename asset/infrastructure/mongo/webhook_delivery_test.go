@@ -0,0 +1,74 @@
+package mongo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/reearth/reearthx/asset/domain/event"
+	"github.com/reearth/reearthx/asset/domain/id"
+	"github.com/reearth/reearthx/asset/domain/integration"
+	"github.com/reearth/reearthx/mongox"
+	"github.com/reearth/reearthx/mongox/mongotest"
+	"github.com/reearth/reearthx/rerror"
+	"github.com/reearth/reearthx/usecasex"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookDelivery(t *testing.T) {
+	now := time.Now().Truncate(time.Millisecond).UTC()
+	wid := id.NewWebhookID()
+	newDelivery := func(wid id.WebhookID) *integration.Delivery {
+		return integration.NewDeliveryBuilder().
+			NewID().
+			Integration(id.NewIntegrationID()).
+			Webhook(wid).
+			Event(id.NewEventID()).
+			EventType(event.ItemCreate).
+			URL("https://example.com/hook").
+			Payload([]byte(`{"type":"item.create"}`)).
+			UpdatedAt(now).
+			MustBuild()
+	}
+	d1, d2, d3 := newDelivery(wid), newDelivery(wid), newDelivery(id.NewWebhookID())
+
+	initDB := mongotest.Connect(t)
+	client := mongox.NewClientWithDatabase(initDB(t))
+	r := NewWebhookDelivery(client)
+	require.NoError(t, r.(*WebhookDelivery).Init())
+	ctx := context.Background()
+
+	for _, d := range []*integration.Delivery{d1, d2, d3} {
+		require.NoError(t, r.Save(ctx, d))
+	}
+
+	d1.AddAttempt(integration.NewDeliveryAttempt(now.Add(time.Second), d1.Payload(), 500, 120*time.Millisecond, nil))
+	require.NoError(t, r.Save(ctx, d1))
+
+	got, err := r.FindByID(ctx, d1.ID())
+	assert.NoError(t, err)
+	assert.Equal(t, d1, got)
+
+	_, err = r.FindByID(ctx, id.NewWebhookDeliveryID())
+	assert.ErrorIs(t, err, rerror.ErrNotFound)
+
+	list, pi, err := r.FindByWebhook(ctx, wid, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, integration.DeliveryList{d2, d1}, list)
+	assert.Equal(t, int64(2), pi.TotalCount)
+
+	list, pi, err = r.FindByWebhook(ctx, wid, usecasex.CursorPagination{First: lo.ToPtr(int64(1))}.Wrap())
+	assert.NoError(t, err)
+	assert.Equal(t, integration.DeliveryList{d2}, list)
+	assert.True(t, pi.HasNextPage)
+
+	d2.AddAttempt(integration.NewDeliveryAttempt(now.Add(time.Second), d2.Payload(), 200, 0, nil))
+	require.NoError(t, r.Save(ctx, d2))
+	d1.SetRetrying()
+	require.NoError(t, r.Save(ctx, d1))
+	list, err = r.FindInProgress(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, integration.DeliveryList{d1, d3}, list)
+}
@@ -0,0 +1,468 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/reearth/reearthx/asset/domain/id"
+	"github.com/reearth/reearthx/asset/domain/integration"
+	"github.com/reearth/reearthx/asset/domain/integrationapi"
+	"github.com/reearth/reearthx/asset/domain/task"
+	"github.com/reearth/reearthx/asset/usecase/gateway"
+	"github.com/reearth/reearthx/asset/usecase/repo"
+	"github.com/reearth/reearthx/log"
+	"github.com/reearth/reearthx/rerror"
+	"github.com/reearth/reearthx/util"
+)
+
+const (
+	defaultMaxAttempts = 5
+	defaultBackoff     = 10 * time.Second
+	defaultMaxBackoff  = 10 * time.Minute
+	defaultTimeout     = 30 * time.Second
+	defaultWorkers     = 4
+	defaultQueueSize   = 1000
+	// responses are read up to this size so that connections can be reused
+	maxResponseSize = 64 * 1024
+)
+
+var (
+	ErrInvalidPayload     = errors.New("invalid webhook payload")
+	ErrClosed             = errors.New("webhook task runner is closed")
+	ErrDeliveryInProgress = errors.New("webhook delivery is in progress")
+	ErrInvalidURL         = errors.New("invalid webhook url")
+)
+
+type Config struct {
+	Client *http.Client
+	// MaxAttempts is the number of attempts of a delivery including the first one.
+	MaxAttempts int
+	// Backoff is the interval before the first retry, which is doubled on each retry up to MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// Workers is the number of deliveries sent at the same time.
+	Workers int
+	// QueueSize is the number of deliveries waiting for workers. Run blocks while the queue is full.
+	QueueSize int
+	// Fallback runs tasks other than webhooks. They are skipped when it is nil.
+	Fallback gateway.TaskRunner
+}
+
+var (
+	_ gateway.TaskRunner       = &TaskRunner{}
+	_ gateway.WebhookDeliverer = &TaskRunner{}
+)
+
+// TaskRunner delivers webhooks in the background of the process with a fixed number of workers.
+// Each delivery is saved to the repository after every attempt so that it can be inspected and replayed later,
+// and deliveries that were in progress when the process stopped are sent again by Resume.
+type TaskRunner struct {
+	integrations repo.Integration
+	deliveries   repo.WebhookDelivery
+	conf         Config
+	queue        chan *job
+	// ctx is canceled by Close to stop workers and retries,
+	// and sendCtx is canceled when Close gives up waiting for the requests in progress.
+	ctx     context.Context
+	cancel  context.CancelFunc
+	sendCtx context.Context
+	abort   context.CancelFunc
+	// closing is held by enqueue so that Close can wait until nothing is added to the queue.
+	closing sync.RWMutex
+	closed  bool
+	mu      sync.Mutex
+	timers  map[*time.Timer]*job
+	// active is the set of deliveries accepted and not finished, which cannot be retried at the same time.
+	active  map[id.WebhookDeliveryID]struct{}
+	workers sync.WaitGroup
+	// wg counts deliveries from when they are accepted until they finish or are left to Resume.
+	wg sync.WaitGroup
+}
+
+// job is a delivery in the runner. attempts is the number of attempts made by the runner.
+type job struct {
+	delivery *integration.Delivery
+	secret   string
+	attempts int
+}
+
+func NewTaskRunner(
+	integrations repo.Integration,
+	deliveries repo.WebhookDelivery,
+	conf Config,
+) *TaskRunner {
+	if conf.Client == nil {
+		conf.Client = &http.Client{Timeout: defaultTimeout}
+	}
+	if conf.MaxAttempts <= 0 {
+		conf.MaxAttempts = defaultMaxAttempts
+	}
+	if conf.Backoff <= 0 {
+		conf.Backoff = defaultBackoff
+	}
+	if conf.MaxBackoff < conf.Backoff {
+		conf.MaxBackoff = max(defaultMaxBackoff, conf.Backoff)
+	}
+	if conf.Workers <= 0 {
+		conf.Workers = defaultWorkers
+	}
+	if conf.QueueSize <= 0 {
+		conf.QueueSize = defaultQueueSize
+	}
+
+	// deliveries outlive the request that triggered them and must not join its transaction
+	ctx, cancel := context.WithCancel(context.Background())
+	sendCtx, abort := context.WithCancel(context.Background())
+	r := &TaskRunner{
+		integrations: integrations,
+		deliveries:   deliveries,
+		conf:         conf,
+		queue:        make(chan *job, conf.QueueSize),
+		ctx:          ctx,
+		cancel:       cancel,
+		sendCtx:      sendCtx,
+		abort:        abort,
+		timers:       map[*time.Timer]*job{},
+		active:       map[id.WebhookDeliveryID]struct{}{},
+	}
+	r.workers.Add(conf.Workers)
+	for range conf.Workers {
+		go r.work()
+	}
+	return r
+}
+
+func (r *TaskRunner) Run(ctx context.Context, p task.Payload) error {
+	if p.Webhook == nil {
+		if r.conf.Fallback != nil {
+			return r.conf.Fallback.Run(ctx, p)
+		}
+		log.Warnfc(ctx, "webhook: task was skipped because only webhooks are supported")
+		return nil
+	}
+
+	w, ev := p.Webhook.Webhook, p.Webhook.Event
+	if w == nil || w.URL() == nil || ev == nil {
+		return ErrInvalidPayload
+	}
+
+	e, err := integrationapi.NewEventWith(ev, p.Webhook.Override, "v1")
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	d, err := integration.NewDeliveryBuilder().
+		NewID().
+		Integration(p.Webhook.Integration).
+		Webhook(w.ID()).
+		Event(ev.ID()).
+		EventType(ev.Type()).
+		URL(w.URL().String()).
+		Payload(body).
+		Build()
+	if err != nil {
+		return err
+	}
+
+	return r.accept(ctx, &job{delivery: d, secret: w.Secret()})
+}
+
+// Retry sends the delivery of the ID again to the current URL of the webhook with a new signature.
+// It returns ErrDeliveryInProgress if the delivery is being sent or waiting for a retry.
+// IDs of other tasks are passed to the fallback runner.
+func (r *TaskRunner) Retry(ctx context.Context, taskID string) error {
+	d, err := r.findDelivery(ctx, taskID)
+	if err != nil {
+		if r.conf.Fallback != nil && errors.Is(err, rerror.ErrNotFound) {
+			return r.conf.Fallback.Retry(ctx, taskID)
+		}
+		return err
+	}
+	return r.redeliver(ctx, d)
+}
+
+// Redeliver sends the delivery again like Retry does. It does not pass unknown IDs to the fallback runner.
+func (r *TaskRunner) Redeliver(ctx context.Context, did id.WebhookDeliveryID) error {
+	d, err := r.deliveries.FindByID(ctx, did)
+	if err != nil {
+		return err
+	}
+	return r.redeliver(ctx, d)
+}
+
+func (r *TaskRunner) redeliver(ctx context.Context, d *integration.Delivery) error {
+	secret, err := r.prepare(ctx, d)
+	if err != nil {
+		return err
+	}
+	d.SetRetrying()
+	return r.accept(ctx, &job{delivery: d, secret: secret})
+}
+
+// Resume queues the deliveries that were pending or retrying when the process stopped.
+// It should be called once on startup, and the deliveries of a repository should be sent by only one process.
+func (r *TaskRunner) Resume(ctx context.Context) error {
+	deliveries, err := r.deliveries.FindInProgress(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, d := range deliveries {
+		secret, err := r.prepare(ctx, d)
+		if err != nil {
+			log.Warnfc(ctx, "webhook: delivery %s was not resumed: %v", d.ID(), err)
+			continue
+		}
+		// a retrying delivery has at least one attempt left
+		j := &job{delivery: d, secret: secret, attempts: min(d.Attempts(), r.conf.MaxAttempts-1)}
+		if err := r.accept(ctx, j); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Wait blocks until all deliveries in progress finish.
+func (r *TaskRunner) Wait() {
+	r.wg.Wait()
+}
+
+// Close stops workers and retries, and waits for the requests in progress until the context is done.
+// Deliveries that have not finished are left pending or retrying in the repository for Resume.
+func (r *TaskRunner) Close(ctx context.Context) error {
+	r.cancel()
+	r.closing.Lock()
+	r.closed = true
+	r.closing.Unlock()
+
+	r.mu.Lock()
+	var canceled []*job
+	for t, j := range r.timers {
+		if t.Stop() {
+			canceled = append(canceled, j)
+		}
+	}
+	clear(r.timers)
+	r.mu.Unlock()
+	for _, j := range canceled {
+		log.Infof("webhook: retry of delivery %s was canceled", j.delivery.ID())
+		r.done(j)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		r.workers.Wait()
+		for len(r.queue) > 0 {
+			r.done(<-r.queue)
+		}
+		r.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		r.abort()
+		return nil
+	case <-ctx.Done():
+		r.abort()
+		<-done
+		return ctx.Err()
+	}
+}
+
+func (r *TaskRunner) findDelivery(ctx context.Context, taskID string) (*integration.Delivery, error) {
+	did, err := id.WebhookDeliveryIDFrom(taskID)
+	if err != nil {
+		return nil, rerror.ErrNotFound
+	}
+	return r.deliveries.FindByID(ctx, did)
+}
+
+// prepare sets the current URL of the webhook to the delivery and returns the secret of the webhook.
+func (r *TaskRunner) prepare(ctx context.Context, d *integration.Delivery) (string, error) {
+	in, err := r.integrations.FindByID(ctx, d.Integration())
+	if err != nil {
+		return "", err
+	}
+	w, ok := in.Webhook(d.Webhook())
+	if !ok {
+		return "", rerror.ErrNotFound
+	}
+	if w.URL() != nil {
+		d.SetURL(w.URL().String())
+	}
+	return w.Secret(), nil
+}
+
+// accept saves the delivery and queues it unless the delivery is already in the runner.
+func (r *TaskRunner) accept(ctx context.Context, j *job) error {
+	if r.ctx.Err() != nil {
+		return ErrClosed
+	}
+
+	did := j.delivery.ID()
+	r.mu.Lock()
+	if _, ok := r.active[did]; ok {
+		r.mu.Unlock()
+		return ErrDeliveryInProgress
+	}
+	r.active[did] = struct{}{}
+	r.wg.Add(1)
+	r.mu.Unlock()
+
+	if err := r.deliveries.Save(r.sendCtx, j.delivery); err != nil {
+		r.done(j)
+		return err
+	}
+	if err := r.enqueue(ctx, j); err != nil {
+		r.done(j)
+		return err
+	}
+	return nil
+}
+
+// done removes the job from the runner when it finishes or is left to Resume.
+func (r *TaskRunner) done(j *job) {
+	r.mu.Lock()
+	delete(r.active, j.delivery.ID())
+	r.mu.Unlock()
+	r.wg.Done()
+}
+
+func (r *TaskRunner) enqueue(ctx context.Context, j *job) error {
+	r.closing.RLock()
+	defer r.closing.RUnlock()
+	if r.closed {
+		return ErrClosed
+	}
+
+	select {
+	case r.queue <- j:
+		return nil
+	case <-r.ctx.Done():
+		return ErrClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *TaskRunner) work() {
+	defer r.workers.Done()
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+		case j := <-r.queue:
+			if r.ctx.Err() != nil {
+				r.done(j)
+				return
+			}
+			r.deliver(j)
+		}
+	}
+}
+
+// deliver sends the delivery once and schedules a retry if it failed temporarily.
+func (r *TaskRunner) deliver(j *job) {
+	ctx := context.WithoutCancel(r.sendCtx)
+	d := j.delivery
+
+	a, sent := r.send(r.sendCtx, d, j.secret)
+	j.attempts++
+	d.AddAttempt(a)
+	// requests that could not be made fail in the same way every time
+	retry := !a.Succeeded() && sent && retryable(a.ResponseStatus()) && j.attempts < r.conf.MaxAttempts
+	if retry {
+		d.SetRetrying()
+	}
+	if err := r.deliveries.Save(ctx, d); err != nil {
+		log.Errorfc(ctx, "webhook: failed to save delivery %s: %v", d.ID(), err)
+	}
+
+	if retry {
+		r.retryLater(j, r.backoff(j.attempts))
+		return
+	}
+	if !a.Succeeded() {
+		log.Warnfc(ctx, "webhook: delivery %s to %s failed after %d attempts: %s", d.ID(), d.URL(), d.Attempts(), d.Error())
+	}
+	r.done(j)
+}
+
+func (r *TaskRunner) retryLater(j *job, after time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.ctx.Err() != nil {
+		delete(r.active, j.delivery.ID())
+		r.wg.Done()
+		return
+	}
+
+	var t *time.Timer
+	t = time.AfterFunc(after, func() {
+		r.mu.Lock()
+		delete(r.timers, t)
+		r.mu.Unlock()
+		if err := r.enqueue(r.ctx, j); err != nil {
+			r.done(j)
+		}
+	})
+	r.timers[t] = j
+}
+
+// send posts the payload of the delivery once. sent is false if the request could not be made.
+func (r *TaskRunner) send(ctx context.Context, d *integration.Delivery, secret string) (_ *integration.DeliveryAttempt, sent bool) {
+	body := d.Payload()
+	now := util.Now()
+	if u, err := url.Parse(d.URL()); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return integration.NewDeliveryAttempt(now, body, 0, 0, fmt.Errorf("%w: %s", ErrInvalidURL, d.URL())), false
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL(), bytes.NewReader(body))
+	if err != nil {
+		return integration.NewDeliveryAttempt(now, body, 0, 0, err), false
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, string(d.EventType()))
+	req.Header.Set(HeaderDelivery, d.ID().String())
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+	if secret != "" {
+		req.Header.Set(HeaderSignature, Sign(secret, now, body))
+	}
+
+	start := time.Now()
+	res, err := r.conf.Client.Do(req)
+	if err != nil {
+		return integration.NewDeliveryAttempt(now, body, 0, time.Since(start), err), true
+	}
+	defer func() { _ = res.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, maxResponseSize))
+	return integration.NewDeliveryAttempt(now, body, res.StatusCode, time.Since(start), nil), true
+}
+
+func (r *TaskRunner) backoff(attempt int) time.Duration {
+	b := r.conf.Backoff
+	for i := 1; i < attempt && b < r.conf.MaxBackoff; i++ {
+		b *= 2
+	}
+	return min(b, r.conf.MaxBackoff)
+}
+
+// retryable reports whether the failure of a sent request may be temporary: network errors, server errors and rate limiting.
+// Network errors have no response status.
+func retryable(status int) bool {
+	return status == 0 || status >= 500 || status == http.StatusTooManyRequests || status == http.StatusRequestTimeout
+}
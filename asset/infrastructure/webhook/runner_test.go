@@ -0,0 +1,455 @@
+package webhook
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/reearth/reearthx/account/accountdomain"
+	"github.com/reearth/reearthx/asset/domain/asset"
+	"github.com/reearth/reearthx/asset/domain/event"
+	"github.com/reearth/reearthx/asset/domain/id"
+	"github.com/reearth/reearthx/asset/domain/integration"
+	"github.com/reearth/reearthx/asset/domain/operator"
+	"github.com/reearth/reearthx/asset/domain/project"
+	"github.com/reearth/reearthx/asset/domain/task"
+	"github.com/reearth/reearthx/asset/infrastructure/memory"
+	"github.com/reearth/reearthx/asset/usecase/gateway/gatewaymock"
+	"github.com/reearth/reearthx/asset/usecase/repo"
+	"github.com/reearth/reearthx/rerror"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+// newTestServer returns a server that responds with the statuses in order and then with 200.
+func newTestServer(t *testing.T, statuses ...int) *testServer {
+	s := &testServer{statuses: statuses}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.requests = append(s.requests, r)
+		s.bodies = append(s.bodies, body)
+		status := http.StatusOK
+		if len(s.statuses) > 0 {
+			status, s.statuses = s.statuses[0], s.statuses[1:]
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *testServer) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.requests)
+}
+
+type testData struct {
+	integration *integration.Integration
+	webhook     *integration.Webhook
+	event       *event.Event[any]
+	repos       *repo.Container
+}
+
+func newTestData(t *testing.T, u string) testData {
+	w := integration.NewWebhookBuilder().
+		NewID().
+		Url(lo.Must(url.Parse(u))).
+		Secret("secret").
+		Active(true).
+		Trigger(integration.WebhookTrigger{event.AssetCreate: true}).
+		MustBuild()
+	in := integration.New().NewID().Developer(accountdomain.NewUserID()).Webhook([]*integration.Webhook{w}).MustBuild()
+	uid := accountdomain.NewUserID()
+	a := asset.New().NewID().Project(project.NewID()).Size(100).NewUUID().
+		CreatedByUser(uid).Thread(id.NewThreadID().Ref()).MustBuild()
+	ev := event.New[any]().NewID().Timestamp(time.Now()).Type(event.AssetCreate).
+		Operator(operator.OperatorFromUser(uid)).Object(a).MustBuild()
+
+	repos := memory.New()
+	require.NoError(t, repos.Integration.Save(context.Background(), in))
+	return testData{integration: in, webhook: w, event: ev, repos: repos}
+}
+
+func (d testData) payload() task.Payload {
+	return task.WebhookPayload{
+		Webhook:     d.webhook,
+		Event:       d.event,
+		Integration: d.integration.ID(),
+	}.Payload()
+}
+
+func newTestRunner(t *testing.T, d testData, fallback *gatewaymock.MockTaskRunner) *TaskRunner {
+	conf := Config{
+		MaxAttempts: 3,
+		Backoff:     time.Millisecond,
+	}
+	if fallback != nil {
+		conf.Fallback = fallback
+	}
+	r := NewTaskRunner(d.repos.Integration, d.repos.WebhookDelivery, conf)
+	t.Cleanup(func() { _ = r.Close(context.Background()) })
+	return r
+}
+
+func findDeliveries(t *testing.T, d testData) integration.DeliveryList {
+	res, _, err := d.repos.WebhookDelivery.FindByWebhook(context.Background(), d.webhook.ID(), nil)
+	require.NoError(t, err)
+	return res
+}
+
+func TestTaskRunner_Run(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServer(t)
+	d := newTestData(t, s.URL+"/hook")
+	r := newTestRunner(t, d, nil)
+
+	require.NoError(t, r.Run(ctx, d.payload()))
+	r.Wait()
+
+	require.Equal(t, 1, s.count())
+	req, body := s.requests[0], s.bodies[0]
+	assert.Equal(t, "/hook", req.URL.Path)
+	assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+	assert.Equal(t, string(event.AssetCreate), req.Header.Get(HeaderEvent))
+	assert.NoError(t, Verify("secret", req.Header.Get(HeaderSignature), req.Header.Get(HeaderTimestamp), body, DefaultTolerance))
+
+	var payload map[string]any
+	require.NoError(t, json.Unmarshal(body, &payload))
+	assert.Equal(t, d.event.ID().String(), payload["eventId"])
+	assert.Equal(t, string(event.AssetCreate), payload["type"])
+
+	deliveries := findDeliveries(t, d)
+	require.Len(t, deliveries, 1)
+	dl := deliveries[0]
+	assert.Equal(t, req.Header.Get(HeaderDelivery), dl.ID().String())
+	assert.Equal(t, d.integration.ID(), dl.Integration())
+	assert.Equal(t, d.event.ID(), dl.Event())
+	assert.Equal(t, integration.DeliveryStatusSucceeded, dl.Status())
+	assert.Equal(t, 1, dl.Attempts())
+	assert.Equal(t, http.StatusOK, dl.StatusCode())
+	assert.Equal(t, body, dl.Payload())
+	require.Len(t, dl.History(), 1)
+	assert.Equal(t, len(body), dl.History()[0].RequestSize())
+	hash := sha256.Sum256(body)
+	assert.Equal(t, hex.EncodeToString(hash[:]), dl.History()[0].RequestHash())
+	assert.Equal(t, http.StatusOK, dl.History()[0].ResponseStatus())
+	assert.Positive(t, dl.History()[0].Latency())
+}
+
+func TestTaskRunner_Run_Retry(t *testing.T) {
+	ctx := context.Background()
+
+	// server errors are retried
+	s := newTestServer(t, http.StatusInternalServerError, http.StatusBadGateway)
+	d := newTestData(t, s.URL)
+	r := newTestRunner(t, d, nil)
+	require.NoError(t, r.Run(ctx, d.payload()))
+	r.Wait()
+	assert.Equal(t, 3, s.count())
+	dl := findDeliveries(t, d)[0]
+	assert.Equal(t, integration.DeliveryStatusSucceeded, dl.Status())
+	assert.Equal(t, 3, dl.Attempts())
+
+	// attempts are limited
+	s = newTestServer(t, http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+	d = newTestData(t, s.URL)
+	r = newTestRunner(t, d, nil)
+	require.NoError(t, r.Run(ctx, d.payload()))
+	r.Wait()
+	assert.Equal(t, 3, s.count())
+	dl = findDeliveries(t, d)[0]
+	assert.Equal(t, integration.DeliveryStatusFailed, dl.Status())
+	assert.Equal(t, 3, dl.Attempts())
+	assert.Equal(t, http.StatusServiceUnavailable, dl.StatusCode())
+	assert.Equal(t, []int{503, 503, 503}, lo.Map(dl.History(), func(a *integration.DeliveryAttempt, _ int) int {
+		return a.ResponseStatus()
+	}))
+
+	// client errors are not retried
+	s = newTestServer(t, http.StatusBadRequest)
+	d = newTestData(t, s.URL)
+	r = newTestRunner(t, d, nil)
+	require.NoError(t, r.Run(ctx, d.payload()))
+	r.Wait()
+	assert.Equal(t, 1, s.count())
+	dl = findDeliveries(t, d)[0]
+	assert.Equal(t, integration.DeliveryStatusFailed, dl.Status())
+	assert.Equal(t, "unexpected response status: 400", dl.Error())
+
+	// network errors are retried
+	s = newTestServer(t)
+	s.Close()
+	d = newTestData(t, s.URL)
+	r = newTestRunner(t, d, nil)
+	require.NoError(t, r.Run(ctx, d.payload()))
+	r.Wait()
+	dl = findDeliveries(t, d)[0]
+	assert.Equal(t, integration.DeliveryStatusFailed, dl.Status())
+	assert.Equal(t, 3, dl.Attempts())
+	assert.Equal(t, 0, dl.StatusCode())
+	assert.NotEmpty(t, dl.Error())
+
+	// requests that cannot be made are not retried
+	for _, u := range []string{"ftp://localhost/hook", "http:///hook"} {
+		d = newTestData(t, u)
+		r = newTestRunner(t, d, nil)
+		require.NoError(t, r.Run(ctx, d.payload()))
+		r.Wait()
+		dl = findDeliveries(t, d)[0]
+		assert.Equal(t, integration.DeliveryStatusFailed, dl.Status(), u)
+		assert.Equal(t, 1, dl.Attempts(), u)
+		assert.Equal(t, "invalid webhook url: "+u, dl.Error())
+	}
+}
+
+func TestTaskRunner_Retry(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServer(t, http.StatusNotFound)
+	d := newTestData(t, "http://localhost:0")
+	r := newTestRunner(t, d, nil)
+
+	// the url of the webhook is fixed after the first delivery failed
+	d.webhook.SetURL(lo.Must(url.Parse("http://localhost:0/old")))
+	require.NoError(t, r.Run(ctx, d.payload()))
+	r.Wait()
+	dl := findDeliveries(t, d)[0]
+	assert.Equal(t, integration.DeliveryStatusFailed, dl.Status())
+
+	d.webhook.SetURL(lo.Must(url.Parse(s.URL + "/new")))
+	require.NoError(t, d.repos.Integration.Save(ctx, d.integration))
+
+	// 404 is not retried
+	require.NoError(t, r.Retry(ctx, dl.ID().String()))
+	r.Wait()
+	assert.Equal(t, 1, s.count())
+
+	require.NoError(t, r.Retry(ctx, dl.ID().String()))
+	r.Wait()
+	assert.Equal(t, 2, s.count())
+	assert.Equal(t, "/new", s.requests[1].URL.Path)
+	assert.Equal(t, dl.Payload(), s.bodies[1])
+	assert.Equal(t, dl.ID().String(), s.requests[1].Header.Get(HeaderDelivery))
+
+	got := findDeliveries(t, d)
+	require.Len(t, got, 1)
+	assert.Equal(t, integration.DeliveryStatusSucceeded, got[0].Status())
+	assert.Equal(t, 5, got[0].Attempts())
+	assert.Equal(t, s.URL+"/new", got[0].URL())
+
+	// the webhook was deleted
+	d.integration.DeleteWebhook(d.webhook.ID())
+	require.NoError(t, d.repos.Integration.Save(ctx, d.integration))
+	assert.ErrorIs(t, r.Retry(ctx, dl.ID().String()), rerror.ErrNotFound)
+
+	assert.ErrorIs(t, r.Retry(ctx, id.NewWebhookDeliveryID().String()), rerror.ErrNotFound)
+	assert.ErrorIs(t, r.Retry(ctx, "x"), rerror.ErrNotFound)
+}
+
+func TestTaskRunner_Redeliver(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServer(t, http.StatusOK)
+	d := newTestData(t, s.URL)
+	// the fallback runner expects no calls
+	r := newTestRunner(t, d, gatewaymock.NewMockTaskRunner(gomock.NewController(t)))
+
+	require.NoError(t, r.Run(ctx, d.payload()))
+	r.Wait()
+	dl := findDeliveries(t, d)[0]
+
+	require.NoError(t, r.Redeliver(ctx, dl.ID()))
+	r.Wait()
+	assert.Equal(t, 2, s.count())
+	assert.Equal(t, dl.ID().String(), s.requests[1].Header.Get(HeaderDelivery))
+
+	// unknown deliveries are not passed to the fallback runner
+	assert.ErrorIs(t, r.Redeliver(ctx, id.NewWebhookDeliveryID()), rerror.ErrNotFound)
+}
+
+func TestTaskRunner_Retry_InProgress(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServer(t, http.StatusServiceUnavailable)
+	d := newTestData(t, s.URL)
+	r := NewTaskRunner(d.repos.Integration, d.repos.WebhookDelivery, Config{Backoff: time.Hour})
+	t.Cleanup(func() { _ = r.Close(ctx) })
+
+	require.NoError(t, r.Run(ctx, d.payload()))
+	require.Eventually(t, func() bool {
+		dl := findDeliveries(t, d)
+		return len(dl) == 1 && dl[0].Status() == integration.DeliveryStatusRetrying
+	}, time.Second, time.Millisecond)
+
+	// the delivery waiting for a retry is not sent twice
+	dl := findDeliveries(t, d)[0]
+	assert.ErrorIs(t, r.Retry(ctx, dl.ID().String()), ErrDeliveryInProgress)
+	assert.Equal(t, 1, s.count())
+}
+
+func TestTaskRunner_Workers(t *testing.T) {
+	ctx := context.Background()
+	var mu sync.Mutex
+	running, peak := 0, 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		mu.Lock()
+		running++
+		peak = max(peak, running)
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+	}))
+	t.Cleanup(s.Close)
+	d := newTestData(t, s.URL)
+	r := NewTaskRunner(d.repos.Integration, d.repos.WebhookDelivery, Config{Workers: 2})
+	t.Cleanup(func() { _ = r.Close(ctx) })
+
+	for range 6 {
+		require.NoError(t, r.Run(ctx, d.payload()))
+	}
+	r.Wait()
+
+	assert.Len(t, findDeliveries(t, d), 6)
+	assert.Equal(t, 2, peak)
+}
+
+func TestTaskRunner_Close(t *testing.T) {
+	ctx := context.Background()
+
+	// retries waiting for the backoff are canceled and left for Resume
+	s := newTestServer(t, http.StatusServiceUnavailable)
+	d := newTestData(t, s.URL)
+	r := NewTaskRunner(d.repos.Integration, d.repos.WebhookDelivery, Config{Backoff: time.Hour})
+	require.NoError(t, r.Run(ctx, d.payload()))
+	require.Eventually(t, func() bool {
+		dl := findDeliveries(t, d)
+		return len(dl) == 1 && dl[0].Status() == integration.DeliveryStatusRetrying
+	}, time.Second, time.Millisecond)
+	require.NoError(t, r.Close(ctx))
+	assert.Equal(t, 1, s.count())
+	assert.ErrorIs(t, r.Run(ctx, d.payload()), ErrClosed)
+
+	// requests in progress are aborted when the context is done
+	started, release := make(chan struct{}), make(chan struct{})
+	blocking := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		close(started)
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(blocking.Close)
+	t.Cleanup(func() { close(release) })
+	d = newTestData(t, blocking.URL)
+	r = NewTaskRunner(d.repos.Integration, d.repos.WebhookDelivery, Config{})
+	require.NoError(t, r.Run(ctx, d.payload()))
+	<-started
+	cctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, r.Close(cctx), context.DeadlineExceeded)
+	dl := findDeliveries(t, d)[0]
+	assert.Equal(t, integration.DeliveryStatusRetrying, dl.Status())
+	assert.Equal(t, 1, dl.Attempts())
+}
+
+func TestTaskRunner_Resume(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServer(t)
+	d := newTestData(t, s.URL)
+	newDelivery := func(status integration.DeliveryStatus, attempts int) *integration.Delivery {
+		dl := integration.NewDeliveryBuilder().NewID().Integration(d.integration.ID()).Webhook(d.webhook.ID()).
+			Event(d.event.ID()).EventType(d.event.Type()).URL("http://localhost:0").Payload([]byte("{}")).
+			Status(status).Attempts(attempts).MustBuild()
+		require.NoError(t, d.repos.WebhookDelivery.Save(ctx, dl))
+		return dl
+	}
+	pending := newDelivery(integration.DeliveryStatusPending, 0)
+	retrying := newDelivery(integration.DeliveryStatusRetrying, 5)
+	failed := newDelivery(integration.DeliveryStatusFailed, 1)
+
+	r := newTestRunner(t, d, nil)
+	require.NoError(t, r.Resume(ctx))
+	r.Wait()
+
+	assert.Equal(t, 2, s.count())
+	for _, dl := range []*integration.Delivery{pending, retrying} {
+		got, err := d.repos.WebhookDelivery.FindByID(ctx, dl.ID())
+		require.NoError(t, err)
+		assert.Equal(t, integration.DeliveryStatusSucceeded, got.Status())
+		assert.Equal(t, s.URL, got.URL())
+	}
+	got, err := d.repos.WebhookDelivery.FindByID(ctx, failed.ID())
+	require.NoError(t, err)
+	assert.Equal(t, integration.DeliveryStatusFailed, got.Status())
+}
+
+func TestTaskRunner_Fallback(t *testing.T) {
+	ctx := context.Background()
+	d := newTestData(t, "http://localhost:0")
+	p := (&task.DecompressAssetPayload{AssetID: "a"}).Payload()
+
+	// tasks other than webhooks are skipped without a fallback
+	assert.NoError(t, newTestRunner(t, d, nil).Run(ctx, p))
+
+	ctrl := gomock.NewController(t)
+	fallback := gatewaymock.NewMockTaskRunner(ctrl)
+	r := newTestRunner(t, d, fallback)
+	fallback.EXPECT().Run(ctx, p).Return(nil).Times(1)
+	fallback.EXPECT().Retry(ctx, "a").Return(nil).Times(1)
+	assert.NoError(t, r.Run(ctx, p))
+	assert.NoError(t, r.Retry(ctx, "a"))
+}
+
+func TestTaskRunner_Run_InvalidPayload(t *testing.T) {
+	ctx := context.Background()
+	d := newTestData(t, "http://localhost:0")
+	r := newTestRunner(t, d, nil)
+
+	assert.ErrorIs(t, r.Run(ctx, task.WebhookPayload{Webhook: d.webhook}.Payload()), ErrInvalidPayload)
+	assert.ErrorIs(t, r.Run(ctx, task.WebhookPayload{Event: d.event}.Payload()), ErrInvalidPayload)
+	assert.ErrorIs(t, r.Run(ctx, task.WebhookPayload{Webhook: d.webhook, Event: d.event}.Payload()), integration.ErrInvalidID)
+	r.Wait()
+	assert.Empty(t, findDeliveries(t, d))
+}
+
+func TestTaskRunner_backoff(t *testing.T) {
+	r := NewTaskRunner(nil, nil, Config{Backoff: time.Second, MaxBackoff: 5 * time.Second})
+	assert.Equal(t, time.Second, r.backoff(1))
+	assert.Equal(t, 2*time.Second, r.backoff(2))
+	assert.Equal(t, 4*time.Second, r.backoff(3))
+	assert.Equal(t, 5*time.Second, r.backoff(4))
+	assert.Equal(t, 5*time.Second, r.backoff(10))
+
+	r = NewTaskRunner(nil, nil, Config{})
+	assert.Equal(t, defaultBackoff, r.backoff(1))
+	assert.Equal(t, defaultMaxBackoff, r.backoff(100))
+	assert.Equal(t, defaultMaxAttempts, r.conf.MaxAttempts)
+}
+
+func TestRetryable(t *testing.T) {
+	assert.True(t, retryable(0))
+	assert.True(t, retryable(http.StatusInternalServerError))
+	assert.True(t, retryable(http.StatusTooManyRequests))
+	assert.False(t, retryable(http.StatusBadRequest))
+	assert.False(t, retryable(http.StatusUnauthorized))
+}
@@ -0,0 +1,65 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/reearth/reearthx/util"
+)
+
+const (
+	HeaderSignature = "X-Reearth-Signature"
+	HeaderTimestamp = "X-Reearth-Timestamp"
+	HeaderEvent     = "X-Reearth-Event"
+	HeaderDelivery  = "X-Reearth-Delivery"
+
+	signatureVersion = "v1"
+	// DefaultTolerance is how old a request can be when the receiver verifies it.
+	DefaultTolerance = 5 * time.Minute
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid signature")
+	ErrInvalidTimestamp = errors.New("invalid timestamp")
+)
+
+// Sign returns the value of the signature header, which is "v1=" followed by the hex-encoded HMAC-SHA256 of
+// the unix timestamp and the body joined with ".". Signing the timestamp prevents a request from being replayed later.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	return signatureVersion + "=" + hex.EncodeToString(mac(secret, strconv.FormatInt(timestamp.Unix(), 10), body))
+}
+
+// Verify checks the signature and the timestamp headers of a request received by a webhook endpoint.
+// Requests whose timestamps differ from the current time more than the tolerance are rejected.
+func Verify(secret, signature, timestamp string, body []byte, tolerance time.Duration) error {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidTimestamp
+	}
+	if d := util.Now().Sub(time.Unix(ts, 0)); d > tolerance || d < -tolerance {
+		return ErrInvalidTimestamp
+	}
+
+	sig, ok := strings.CutPrefix(signature, signatureVersion+"=")
+	if !ok {
+		return ErrInvalidSignature
+	}
+	got, err := hex.DecodeString(sig)
+	if err != nil || !hmac.Equal(got, mac(secret, timestamp, body)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func mac(secret, timestamp string, body []byte) []byte {
+	h := hmac.New(sha256.New, []byte(secret))
+	_, _ = h.Write([]byte(timestamp))
+	_, _ = h.Write([]byte("."))
+	_, _ = h.Write(body)
+	return h.Sum(nil)
+}
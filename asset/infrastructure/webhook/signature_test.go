@@ -0,0 +1,44 @@
+package webhook
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/reearth/reearthx/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSign(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	assert.Equal(
+		t,
+		"v1=49f24e537407743fa4a0242bb63b94b9a47ee99cbbe071ccd8a22550ae411686",
+		Sign("secret", now, []byte(`{"a":1}`)),
+	)
+	assert.NotEqual(t, Sign("secret", now, []byte(`{"a":1}`)), Sign("secret2", now, []byte(`{"a":1}`)))
+	assert.NotEqual(t, Sign("secret", now, []byte(`{"a":1}`)), Sign("secret", now.Add(time.Second), []byte(`{"a":1}`)))
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	defer util.MockNow(now)()
+	body := []byte(`{"a":1}`)
+	ts := strconv.FormatInt(now.Unix(), 10)
+	sig := Sign("secret", now, body)
+
+	assert.NoError(t, Verify("secret", sig, ts, body, DefaultTolerance))
+	assert.ErrorIs(t, Verify("secret2", sig, ts, body, DefaultTolerance), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify("secret", sig, ts, []byte(`{"a":2}`), DefaultTolerance), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify("secret", sig[3:], ts, body, DefaultTolerance), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify("secret", "v1=xx", ts, body, DefaultTolerance), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify("secret", sig, "x", body, DefaultTolerance), ErrInvalidTimestamp)
+
+	// the signature cannot be reused with another timestamp
+	later := strconv.FormatInt(now.Unix()+1, 10)
+	assert.ErrorIs(t, Verify("secret", sig, later, body, DefaultTolerance), ErrInvalidSignature)
+
+	// old requests are rejected
+	defer util.MockNow(now.Add(DefaultTolerance + time.Second))()
+	assert.ErrorIs(t, Verify("secret", sig, ts, body, DefaultTolerance), ErrInvalidTimestamp)
+}
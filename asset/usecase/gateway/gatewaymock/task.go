@@ -9,6 +9,7 @@ import (
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	id "github.com/reearth/reearthx/asset/domain/id"
 	task "github.com/reearth/reearthx/asset/domain/task"
 )
 
@@ -62,3 +63,40 @@ func (mr *MockTaskRunnerMockRecorder) Run(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockTaskRunner)(nil).Run), arg0, arg1)
 }
+
+// MockWebhookDeliverer is a mock of WebhookDeliverer interface.
+type MockWebhookDeliverer struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookDelivererMockRecorder
+}
+
+// MockWebhookDelivererMockRecorder is the mock recorder for MockWebhookDeliverer.
+type MockWebhookDelivererMockRecorder struct {
+	mock *MockWebhookDeliverer
+}
+
+// NewMockWebhookDeliverer creates a new mock instance.
+func NewMockWebhookDeliverer(ctrl *gomock.Controller) *MockWebhookDeliverer {
+	mock := &MockWebhookDeliverer{ctrl: ctrl}
+	mock.recorder = &MockWebhookDelivererMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookDeliverer) EXPECT() *MockWebhookDelivererMockRecorder {
+	return m.recorder
+}
+
+// Redeliver mocks base method.
+func (m *MockWebhookDeliverer) Redeliver(arg0 context.Context, arg1 id.WebhookDeliveryID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Redeliver", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Redeliver indicates an expected call of Redeliver.
+func (mr *MockWebhookDelivererMockRecorder) Redeliver(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Redeliver", reflect.TypeOf((*MockWebhookDeliverer)(nil).Redeliver), arg0, arg1)
+}
@@ -3,6 +3,7 @@ package gateway
 import (
 	"context"
 
+	"github.com/reearth/reearthx/asset/domain/id"
 	"github.com/reearth/reearthx/asset/domain/task"
 )
 
@@ -10,3 +11,9 @@ type TaskRunner interface {
 	Run(context.Context, task.Payload) error
 	Retry(context.Context, string) error
 }
+
+// WebhookDeliverer is implemented by task runners which deliver webhooks themselves and save the deliveries.
+type WebhookDeliverer interface {
+	// Redeliver sends the saved delivery to the current URL of its webhook.
+	Redeliver(context.Context, id.WebhookDeliveryID) error
+}
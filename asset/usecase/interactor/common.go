@@ -163,13 +163,16 @@ func webhooks(
 
 	for i, ev := range evl {
 		e := el[i]
-		for _, w := range integrations.ActiveWebhooks(ev.Type()) {
-			if err := g.TaskRunner.Run(ctx, task.WebhookPayload{
-				Webhook:  w,
-				Event:    ev,
-				Override: e.WebhookObject,
-			}.Payload()); err != nil {
-				return err
+		for _, in := range integrations {
+			for _, w := range in.ActiveWebhooks(ev.Type()) {
+				if err := g.TaskRunner.Run(ctx, task.WebhookPayload{
+					Webhook:     w,
+					Event:       ev,
+					Override:    e.WebhookObject,
+					Integration: in.ID(),
+				}.Payload()); err != nil {
+					return err
+				}
 			}
 		}
 	}
@@ -129,8 +129,9 @@ func TestCommon_webhook(t *testing.T) {
 
 	lo.Must0(db.Integration.Save(ctx, integrationInstance))
 	mRunner.EXPECT().Run(ctx, task.WebhookPayload{
-		Webhook: wh,
-		Event:   ev,
+		Webhook:     wh,
+		Event:       ev,
+		Integration: integrationInstance.ID(),
 	}.Payload()).Times(1).Return(nil)
 	err = webhook(ctx, db, gw, Event{Workspace: ws.ID()}, ev)
 	assert.NoError(t, err)
@@ -14,6 +14,7 @@ import (
 
 	"github.com/reearth/reearthx/account/accountdomain"
 	"github.com/reearth/reearthx/rerror"
+	"github.com/reearth/reearthx/usecasex"
 	"github.com/reearth/reearthx/util"
 	"github.com/samber/lo"
 )
@@ -402,3 +403,106 @@ func (i Integration) DeleteWebhook(
 			return nil
 		})
 }
+
+// FindWebhookDeliveries returns deliveries of the webhook from the newest one.
+func (i Integration) FindWebhookDeliveries(
+	ctx context.Context,
+	iId id.IntegrationID,
+	wId id.WebhookID,
+	pagination *usecasex.Pagination,
+	operator *usecase.Operator,
+) (integration.DeliveryList, *usecasex.PageInfo, error) {
+	if operator.AcOperator.User == nil {
+		return nil, nil, interfaces.ErrInvalidOperator
+	}
+	if _, err := i.findWebhook(ctx, iId, wId, operator); err != nil {
+		return nil, nil, err
+	}
+	return i.repos.WebhookDelivery.FindByWebhook(ctx, wId, pagination)
+}
+
+// ReplayWebhookDelivery creates a new delivery with the same payload as the delivery and sends it
+// so that the attempts of the original delivery are kept as they are.
+func (i Integration) ReplayWebhookDelivery(
+	ctx context.Context,
+	iId id.IntegrationID,
+	wId id.WebhookID,
+	dId id.WebhookDeliveryID,
+	operator *usecase.Operator,
+) (*integration.Delivery, error) {
+	if operator.AcOperator.User == nil {
+		return nil, interfaces.ErrInvalidOperator
+	}
+	var deliverer gateway.WebhookDeliverer
+	if i.gateways != nil {
+		deliverer, _ = i.gateways.TaskRunner.(gateway.WebhookDeliverer)
+	}
+	if deliverer == nil {
+		return nil, interfaces.ErrTaskRunnerNotConfigured
+	}
+	replay, err := Run1(ctx, operator, i.repos, Usecase().Transaction(),
+		func(ctx context.Context) (*integration.Delivery, error) {
+			d, err := i.findDelivery(ctx, iId, wId, dId, operator)
+			if err != nil {
+				return nil, err
+			}
+
+			replay := d.Replay()
+			if err := i.repos.WebhookDelivery.Save(ctx, replay); err != nil {
+				return nil, err
+			}
+			return replay, nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	// the replay is sent after it is committed so that the task runner can find it
+	if err := deliverer.Redeliver(ctx, replay.ID()); err != nil {
+		return nil, err
+	}
+	return replay, nil
+}
+
+func (i Integration) findWebhook(
+	ctx context.Context,
+	iId id.IntegrationID,
+	wId id.WebhookID,
+	operator *usecase.Operator,
+) (*integration.Webhook, error) {
+	in, err := i.repos.Integration.FindByID(ctx, iId)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.Developer() != *operator.AcOperator.User {
+		return nil, interfaces.ErrOperationDenied
+	}
+
+	w, ok := in.Webhook(wId)
+	if !ok {
+		return nil, rerror.ErrNotFound
+	}
+	return w, nil
+}
+
+func (i Integration) findDelivery(
+	ctx context.Context,
+	iId id.IntegrationID,
+	wId id.WebhookID,
+	dId id.WebhookDeliveryID,
+	operator *usecase.Operator,
+) (*integration.Delivery, error) {
+	if _, err := i.findWebhook(ctx, iId, wId, operator); err != nil {
+		return nil, err
+	}
+
+	d, err := i.repos.WebhookDelivery.FindByID(ctx, dId)
+	if err != nil {
+		return nil, err
+	}
+	if d.Integration() != iId || d.Webhook() != wId {
+		return nil, rerror.ErrNotFound
+	}
+	return d, nil
+}
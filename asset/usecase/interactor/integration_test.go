@@ -12,12 +12,16 @@ import (
 	"github.com/reearth/reearthx/asset/domain/id"
 	"github.com/reearth/reearthx/asset/domain/integration"
 	"github.com/reearth/reearthx/asset/usecase"
+	"github.com/reearth/reearthx/asset/usecase/gateway"
+	"github.com/reearth/reearthx/asset/usecase/gateway/gatewaymock"
 	"github.com/reearth/reearthx/asset/usecase/interfaces"
 
+	"github.com/golang/mock/gomock"
 	"github.com/reearth/reearthx/account/accountdomain"
 	"github.com/reearth/reearthx/account/accountdomain/user"
 	"github.com/reearth/reearthx/account/accountusecase"
 	"github.com/reearth/reearthx/rerror"
+	"github.com/reearth/reearthx/usecasex"
 	"github.com/samber/lo"
 	"github.com/stretchr/testify/assert"
 )
@@ -1063,3 +1067,120 @@ func TestNewIntegration(t *testing.T) {
 	r := memory.New()
 	assert.Equal(t, &Integration{repos: r}, NewIntegration(r, nil))
 }
+
+func TestIntegration_FindWebhookDeliveries(t *testing.T) {
+	ts := testSuite()
+	ctx := context.Background()
+	wId := id.NewWebhookID()
+	ts.I2.SetWebhook([]*integration.Webhook{integration.NewWebhookBuilder().ID(wId).MustBuild()})
+	d1 := integration.NewDeliveryBuilder().NewID().Integration(ts.IId2).Webhook(wId).MustBuild()
+	d2 := integration.NewDeliveryBuilder().NewID().Integration(ts.IId2).Webhook(wId).MustBuild()
+	d3 := integration.NewDeliveryBuilder().NewID().Integration(ts.IId2).Webhook(id.NewWebhookID()).MustBuild()
+
+	db := memory.New()
+	assert.NoError(t, db.Integration.Save(ctx, ts.I2.Clone()))
+	for _, d := range []*integration.Delivery{d1, d2, d3} {
+		assert.NoError(t, db.WebhookDelivery.Save(ctx, d))
+	}
+	i := Integration{repos: db}
+
+	got, pi, err := i.FindWebhookDeliveries(ctx, ts.IId2, wId, nil, ts.Op)
+	assert.NoError(t, err)
+	assert.Equal(t, integration.DeliveryList{d2, d1}, got)
+	assert.Equal(t, int64(2), pi.TotalCount)
+
+	got, _, err = i.FindWebhookDeliveries(ctx, ts.IId2, wId, usecasex.CursorPagination{First: lo.ToPtr(int64(1))}.Wrap(), ts.Op)
+	assert.NoError(t, err)
+	assert.Equal(t, integration.DeliveryList{d2}, got)
+
+	_, _, err = i.FindWebhookDeliveries(ctx, ts.IId2, id.NewWebhookID(), nil, ts.Op)
+	assert.Equal(t, rerror.ErrNotFound, err)
+
+	_, _, err = i.FindWebhookDeliveries(ctx, ts.IId2, wId, nil, &usecase.Operator{
+		AcOperator: &accountusecase.Operator{User: lo.ToPtr(accountdomain.NewUserID())},
+	})
+	assert.Equal(t, interfaces.ErrOperationDenied, err)
+
+	_, _, err = i.FindWebhookDeliveries(ctx, ts.IId2, wId, nil, &usecase.Operator{AcOperator: &accountusecase.Operator{}})
+	assert.Equal(t, interfaces.ErrInvalidOperator, err)
+}
+
+func TestIntegration_ReplayWebhookDelivery(t *testing.T) {
+	ts := testSuite()
+	ctx := context.Background()
+	wId := id.NewWebhookID()
+	ts.I2.SetWebhook([]*integration.Webhook{integration.NewWebhookBuilder().ID(wId).MustBuild()})
+	d := integration.NewDeliveryBuilder().
+		NewID().
+		Integration(ts.IId2).
+		Webhook(wId).
+		Event(id.NewEventID()).
+		Payload([]byte(`{"type":"item.create"}`)).
+		MustBuild()
+	d.AddAttempt(integration.NewDeliveryAttempt(ts.Now, d.Payload(), 200, time.Second, nil))
+
+	db := memory.New()
+	assert.NoError(t, db.Integration.Save(ctx, ts.I2.Clone()))
+	assert.NoError(t, db.WebhookDelivery.Save(ctx, d))
+
+	mockCtrl := gomock.NewController(t)
+	deliverer := gatewaymock.NewMockWebhookDeliverer(mockCtrl)
+	runner := struct {
+		*gatewaymock.MockTaskRunner
+		*gatewaymock.MockWebhookDeliverer
+	}{gatewaymock.NewMockTaskRunner(mockCtrl), deliverer}
+	i := Integration{repos: db, gateways: &gateway.Container{TaskRunner: runner}}
+
+	var redelivered id.WebhookDeliveryID
+	deliverer.EXPECT().Redeliver(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, did id.WebhookDeliveryID) error {
+		redelivered = did
+		return nil
+	}).Times(1)
+
+	got, err := i.ReplayWebhookDelivery(ctx, ts.IId2, wId, d.ID(), ts.Op)
+	assert.NoError(t, err)
+	assert.NotEqual(t, d.ID(), got.ID())
+	assert.Equal(t, d.ID().Ref(), got.ReplayOf())
+	assert.Equal(t, d.Payload(), got.Payload())
+	assert.Equal(t, integration.DeliveryStatusPending, got.Status())
+	assert.Equal(t, got.ID(), redelivered)
+
+	saved, err := db.WebhookDelivery.FindByID(ctx, got.ID())
+	assert.NoError(t, err)
+	assert.Equal(t, got, saved)
+
+	// the original delivery is not changed
+	orig, err := db.WebhookDelivery.FindByID(ctx, d.ID())
+	assert.NoError(t, err)
+	assert.Equal(t, d, orig)
+
+	_, err = i.ReplayWebhookDelivery(ctx, ts.IId2, wId, id.NewWebhookDeliveryID(), ts.Op)
+	assert.Equal(t, rerror.ErrNotFound, err)
+
+	_, err = i.ReplayWebhookDelivery(ctx, ts.IId2, id.NewWebhookID(), d.ID(), ts.Op)
+	assert.Equal(t, rerror.ErrNotFound, err)
+
+	// delivery of another webhook
+	other := integration.NewDeliveryBuilder().NewID().Integration(ts.IId2).Webhook(id.NewWebhookID()).MustBuild()
+	assert.NoError(t, db.WebhookDelivery.Save(ctx, other))
+	_, err = i.ReplayWebhookDelivery(ctx, ts.IId2, wId, other.ID(), ts.Op)
+	assert.Equal(t, rerror.ErrNotFound, err)
+
+	_, err = i.ReplayWebhookDelivery(ctx, ts.IId2, wId, d.ID(), &usecase.Operator{
+		AcOperator: &accountusecase.Operator{User: lo.ToPtr(accountdomain.NewUserID())},
+	})
+	assert.Equal(t, interfaces.ErrOperationDenied, err)
+
+	_, err = i.ReplayWebhookDelivery(ctx, ts.IId2, wId, d.ID(), &usecase.Operator{AcOperator: &accountusecase.Operator{}})
+	assert.Equal(t, interfaces.ErrInvalidOperator, err)
+
+	_, err = Integration{repos: db, gateways: &gateway.Container{}}.ReplayWebhookDelivery(ctx, ts.IId2, wId, d.ID(), ts.Op)
+	assert.Equal(t, interfaces.ErrTaskRunnerNotConfigured, err)
+
+	// task runners which do not deliver webhooks cannot replay deliveries
+	_, err = Integration{
+		repos:    db,
+		gateways: &gateway.Container{TaskRunner: gatewaymock.NewMockTaskRunner(mockCtrl)},
+	}.ReplayWebhookDelivery(ctx, ts.IId2, wId, d.ID(), ts.Op)
+	assert.Equal(t, interfaces.ErrTaskRunnerNotConfigured, err)
+}
@@ -8,8 +8,13 @@ import (
 	"github.com/reearth/reearthx/asset/domain/id"
 	"github.com/reearth/reearthx/asset/domain/integration"
 	"github.com/reearth/reearthx/asset/usecase"
+	"github.com/reearth/reearthx/i18n"
+	"github.com/reearth/reearthx/rerror"
+	"github.com/reearth/reearthx/usecasex"
 )
 
+var ErrTaskRunnerNotConfigured error = rerror.NewE(i18n.T("task runner is not configured"))
+
 type CreateIntegrationParam struct {
 	Name        string
 	Description *string
@@ -77,4 +82,18 @@ type Integration interface {
 		*usecase.Operator,
 	) (*integration.Webhook, error)
 	DeleteWebhook(context.Context, id.IntegrationID, id.WebhookID, *usecase.Operator) error
+	FindWebhookDeliveries(
+		context.Context,
+		id.IntegrationID,
+		id.WebhookID,
+		*usecasex.Pagination,
+		*usecase.Operator,
+	) (integration.DeliveryList, *usecasex.PageInfo, error)
+	ReplayWebhookDelivery(
+		context.Context,
+		id.IntegrationID,
+		id.WebhookID,
+		id.WebhookDeliveryID,
+		*usecase.Operator,
+	) (*integration.Delivery, error)
 }
@@ -2,6 +2,7 @@ package repo
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/reearth/reearthx/asset/domain/id"
@@ -69,4 +70,27 @@ type Item interface {
 	Remove(context.Context, id.ItemID) error
 	Archive(context.Context, id.ItemID, id.ProjectID, bool) error
 	Copy(context.Context, CopyParams) (*string, *string, error)
+	// Reindex adds the latest items of the model to the search index if the repository has one.
+	// Search scans field values for models whose items are not all indexed, such as models written by
+	// the copy task of Copy, and Reindex lets them use the index again. It also repairs the index after
+	// failures, which saving items only logs.
+	Reindex(context.Context, id.ModelID) error
+}
+
+// ErrTooManySearchResults is returned by ItemSearchIndex.Search when more items match than the index returns at once.
+// Item repositories then search by scanning field values so that no items are dropped from the result.
+var ErrTooManySearchResults = errors.New("too many search results")
+
+// ItemSearchIndex is a full-text index of items. When it is given to an Item repository,
+// Search looks up keywords in the index instead of scanning field values.
+type ItemSearchIndex interface {
+	// Index adds the items to the index or replaces them.
+	Index(context.Context, item.List) error
+	// Delete removes the items from the index.
+	Delete(context.Context, id.ItemIDList) error
+	// Search returns the IDs of items that match the keyword of the query, ordered by relevance,
+	// or ErrTooManySearchResults when they exceed the limit of the index.
+	Search(context.Context, *item.Query) (id.ItemIDList, error)
+	// Count returns the number of items of the model in the index.
+	Count(context.Context, id.ModelID) (int64, error)
 }
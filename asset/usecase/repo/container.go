@@ -30,6 +30,7 @@ type Container struct {
 	Group             Group
 	Policy            Policy
 	WorkspaceSettings WorkspaceSettings
+	WebhookDelivery   WebhookDelivery
 	Transaction       usecasex.Transaction
 }
 
@@ -58,6 +59,7 @@ func (c *Container) Filtered(workspace WorkspaceFilter, project ProjectFilter) *
 		Integration:       c.Integration,
 		WorkspaceSettings: c.WorkspaceSettings,
 		Event:             c.Event,
+		WebhookDelivery:   c.WebhookDelivery,
 	}
 }
 
@@ -0,0 +1,24 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/reearth/reearthx/asset/domain/id"
+	"github.com/reearth/reearthx/asset/domain/integration"
+	"github.com/reearth/reearthx/usecasex"
+)
+
+// WebhookDelivery stores deliveries for a limited time. Repositories may drop deliveries that have not been
+// updated for a retention period, e.g. 30 days for the mongo repository.
+type WebhookDelivery interface {
+	FindByID(context.Context, id.WebhookDeliveryID) (*integration.Delivery, error)
+	// FindByWebhook returns deliveries of the webhook from the newest one.
+	FindByWebhook(
+		context.Context,
+		id.WebhookID,
+		*usecasex.Pagination,
+	) (integration.DeliveryList, *usecasex.PageInfo, error)
+	// FindInProgress returns deliveries that are pending or retrying.
+	FindInProgress(context.Context) (integration.DeliveryList, error)
+	Save(context.Context, *integration.Delivery) error
+}
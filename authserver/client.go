@@ -1,27 +1,34 @@
 package authserver
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/zitadel/oidc/pkg/oidc"
 	"github.com/zitadel/oidc/pkg/op"
+	"golang.org/x/crypto/bcrypt"
 )
 
+var ErrInvalidClientSecret = errors.New("invalid client secret")
+
 type Client struct {
-	id                 string
-	applicationType    op.ApplicationType
-	authMethod         oidc.AuthMethod
-	accessTokenType    op.AccessTokenType
-	responseTypes      []oidc.ResponseType
-	grantTypes         []oidc.GrantType
-	allowedScopes      []string
-	redirectURIs       []string
-	logoutRedirectURIs []string
-	loginURI           string
-	idTokenLifetime    time.Duration
-	clockSkew          time.Duration
-	devMode            bool
+	id                   string
+	applicationType      op.ApplicationType
+	authMethod           oidc.AuthMethod
+	accessTokenType      op.AccessTokenType
+	responseTypes        []oidc.ResponseType
+	grantTypes           []oidc.GrantType
+	allowedScopes        []string
+	redirectURIs         []string
+	logoutRedirectURIs   []string
+	loginURI             string
+	idTokenLifetime      time.Duration
+	accessTokenLifetime  time.Duration
+	refreshTokenLifetime time.Duration
+	clockSkew            time.Duration
+	devMode              bool
+	secretHash           []byte
 }
 
 func NewLocalClient(dev bool, id string, domain string) op.Client {
@@ -69,6 +76,42 @@ func (c *Client) IDTokenLifetime() time.Duration {
 	return c.idTokenLifetime
 }
 
+// AccessTokenLifetime returns the lifetime of access tokens issued to the client. Zero means the server default.
+func (c *Client) AccessTokenLifetime() time.Duration {
+	return c.accessTokenLifetime
+}
+
+// RefreshTokenLifetime returns the lifetime of refresh tokens issued to the client. Zero means the server default.
+func (c *Client) RefreshTokenLifetime() time.Duration {
+	return c.refreshTokenLifetime
+}
+
+func (c *Client) AllowedScopes() []string {
+	return c.allowedScopes
+}
+
+func (c *Client) LoginURI() string {
+	return c.loginURI
+}
+
+func (c *Client) SecretHash() []byte {
+	return c.secretHash
+}
+
+// VerifySecret checks the secret presented by the client. Public clients, which have no secret, always pass.
+func (c *Client) VerifySecret(secret string) error {
+	if c.authMethod == oidc.AuthMethodNone {
+		return nil
+	}
+	if len(c.secretHash) == 0 || secret == "" {
+		return ErrInvalidClientSecret
+	}
+	if err := bcrypt.CompareHashAndPassword(c.secretHash, []byte(secret)); err != nil {
+		return ErrInvalidClientSecret
+	}
+	return nil
+}
+
 func (c *Client) AccessTokenType() op.AccessTokenType {
 	return c.accessTokenType
 }
@@ -0,0 +1,146 @@
+package authserver
+
+import (
+	"errors"
+	"time"
+
+	"github.com/zitadel/oidc/pkg/oidc"
+	"github.com/zitadel/oidc/pkg/op"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrInvalidClientID   = errors.New("invalid client id")
+	ErrClientSecretEmpty = errors.New("client secret is required for confidential clients")
+)
+
+type ClientBuilder struct {
+	c      *Client
+	secret string
+}
+
+func NewClient() *ClientBuilder {
+	return &ClientBuilder{c: &Client{
+		applicationType: op.ApplicationTypeWeb,
+		authMethod:      oidc.AuthMethodNone,
+		accessTokenType: op.AccessTokenTypeJWT,
+		responseTypes:   []oidc.ResponseType{oidc.ResponseTypeCode},
+		grantTypes:      []oidc.GrantType{oidc.GrantTypeCode},
+		allowedScopes:   []string{"openid", "profile", "email"},
+		idTokenLifetime: 5 * time.Minute,
+	}}
+}
+
+func (b *ClientBuilder) Build() (*Client, error) {
+	if b.c.id == "" {
+		return nil, ErrInvalidClientID
+	}
+	if b.secret != "" {
+		h, err := bcrypt.GenerateFromPassword([]byte(b.secret), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, err
+		}
+		b.c.secretHash = h
+		b.secret = ""
+	}
+	if b.c.authMethod != oidc.AuthMethodNone && len(b.c.secretHash) == 0 {
+		return nil, ErrClientSecretEmpty
+	}
+	return b.c, nil
+}
+
+func (b *ClientBuilder) MustBuild() *Client {
+	c, err := b.Build()
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+func (b *ClientBuilder) ID(id string) *ClientBuilder {
+	b.c.id = id
+	return b
+}
+
+func (b *ClientBuilder) ApplicationType(t op.ApplicationType) *ClientBuilder {
+	b.c.applicationType = t
+	return b
+}
+
+func (b *ClientBuilder) AuthMethod(m oidc.AuthMethod) *ClientBuilder {
+	b.c.authMethod = m
+	return b
+}
+
+func (b *ClientBuilder) AccessTokenType(t op.AccessTokenType) *ClientBuilder {
+	b.c.accessTokenType = t
+	return b
+}
+
+func (b *ClientBuilder) ResponseTypes(types []oidc.ResponseType) *ClientBuilder {
+	b.c.responseTypes = types
+	return b
+}
+
+func (b *ClientBuilder) GrantTypes(types []oidc.GrantType) *ClientBuilder {
+	b.c.grantTypes = types
+	return b
+}
+
+func (b *ClientBuilder) AllowedScopes(scopes []string) *ClientBuilder {
+	b.c.allowedScopes = scopes
+	return b
+}
+
+func (b *ClientBuilder) RedirectURIs(uris []string) *ClientBuilder {
+	b.c.redirectURIs = uris
+	return b
+}
+
+func (b *ClientBuilder) PostLogoutRedirectURIs(uris []string) *ClientBuilder {
+	b.c.logoutRedirectURIs = uris
+	return b
+}
+
+// LoginURI sets the URL format of the login page. "%s" is replaced with the auth request ID.
+func (b *ClientBuilder) LoginURI(uri string) *ClientBuilder {
+	b.c.loginURI = uri
+	return b
+}
+
+func (b *ClientBuilder) IDTokenLifetime(d time.Duration) *ClientBuilder {
+	b.c.idTokenLifetime = d
+	return b
+}
+
+func (b *ClientBuilder) AccessTokenLifetime(d time.Duration) *ClientBuilder {
+	b.c.accessTokenLifetime = d
+	return b
+}
+
+func (b *ClientBuilder) RefreshTokenLifetime(d time.Duration) *ClientBuilder {
+	b.c.refreshTokenLifetime = d
+	return b
+}
+
+func (b *ClientBuilder) ClockSkew(d time.Duration) *ClientBuilder {
+	b.c.clockSkew = d
+	return b
+}
+
+func (b *ClientBuilder) DevMode(dev bool) *ClientBuilder {
+	b.c.devMode = dev
+	return b
+}
+
+// Secret sets the plain client secret, which is hashed on Build.
+func (b *ClientBuilder) Secret(secret string) *ClientBuilder {
+	b.secret = secret
+	return b
+}
+
+// SecretHash sets an already hashed client secret, e.g. when loading a client from a database.
+func (b *ClientBuilder) SecretHash(hash []byte) *ClientBuilder {
+	b.c.secretHash = hash
+	return b
+}
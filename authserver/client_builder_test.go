@@ -0,0 +1,39 @@
+package authserver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zitadel/oidc/pkg/oidc"
+	"github.com/zitadel/oidc/pkg/op"
+)
+
+func TestClientBuilder_Build(t *testing.T) {
+	c, err := NewClient().
+		ID("cli").
+		ApplicationType(op.ApplicationTypeNative).
+		AuthMethod(oidc.AuthMethodPost).
+		GrantTypes([]oidc.GrantType{oidc.GrantTypeCode, oidc.GrantTypeRefreshToken}).
+		RedirectURIs([]string{"http://localhost:8080"}).
+		AccessTokenLifetime(time.Minute).
+		RefreshTokenLifetime(time.Hour).
+		Secret("secret").
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "cli", c.GetID())
+	assert.Equal(t, op.ApplicationTypeNative, c.ApplicationType())
+	assert.Equal(t, oidc.AuthMethodPost, c.AuthMethod())
+	assert.Equal(t, []oidc.GrantType{oidc.GrantTypeCode, oidc.GrantTypeRefreshToken}, c.GrantTypes())
+	assert.Equal(t, []string{"http://localhost:8080"}, c.RedirectURIs())
+	assert.Equal(t, time.Minute, c.AccessTokenLifetime())
+	assert.Equal(t, time.Hour, c.RefreshTokenLifetime())
+	assert.NotEmpty(t, c.SecretHash())
+	assert.NotEqual(t, []byte("secret"), c.SecretHash())
+
+	_, err = NewClient().Build()
+	assert.Same(t, ErrInvalidClientID, err)
+
+	_, err = NewClient().ID("a").AuthMethod(oidc.AuthMethodBasic).Build()
+	assert.Same(t, ErrClientSecretEmpty, err)
+}
@@ -0,0 +1,24 @@
+package authserver
+
+// clientCredentialsRequest is the token request of the client_credentials grant. The client is the subject of the token.
+type clientCredentialsRequest struct {
+	clientID  string
+	scopes    []string
+	audiences []string
+}
+
+func (r *clientCredentialsRequest) GetClientID() string {
+	return r.clientID
+}
+
+func (r *clientCredentialsRequest) GetSubject() string {
+	return r.clientID
+}
+
+func (r *clientCredentialsRequest) GetAudience() []string {
+	return r.audiences
+}
+
+func (r *clientCredentialsRequest) GetScopes() []string {
+	return r.scopes
+}
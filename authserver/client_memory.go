@@ -0,0 +1,42 @@
+package authserver
+
+import (
+	"context"
+
+	"github.com/reearth/reearthx/rerror"
+	"github.com/reearth/reearthx/util"
+)
+
+type ClientMemory struct {
+	data *util.SyncMap[string, *Client]
+}
+
+var _ ClientRepo = (*ClientMemory)(nil)
+
+func NewClientMemory() *ClientMemory {
+	return &ClientMemory{
+		data: &util.SyncMap[string, *Client]{},
+	}
+}
+
+func (r *ClientMemory) FindByID(_ context.Context, id string) (*Client, error) {
+	d, ok := r.data.Load(id)
+	if ok {
+		return d, nil
+	}
+	return nil, rerror.ErrNotFound
+}
+
+func (r *ClientMemory) FindAll(_ context.Context) ([]*Client, error) {
+	return r.data.Values(), nil
+}
+
+func (r *ClientMemory) Save(_ context.Context, client *Client) error {
+	r.data.Store(client.GetID(), client)
+	return nil
+}
+
+func (r *ClientMemory) Remove(_ context.Context, id string) error {
+	r.data.Delete(id)
+	return nil
+}
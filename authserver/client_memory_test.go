@@ -0,0 +1,33 @@
+package authserver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/reearth/reearthx/rerror"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientMemory(t *testing.T) {
+	ctx := context.Background()
+	c := NewClient().ID("a").MustBuild()
+	m := NewClientMemory()
+
+	got, err := m.FindByID(ctx, "a")
+	assert.Nil(t, got)
+	assert.Same(t, rerror.ErrNotFound, err)
+
+	assert.NoError(t, m.Save(ctx, c))
+	got, err = m.FindByID(ctx, "a")
+	assert.Same(t, c, got)
+	assert.NoError(t, err)
+
+	all, err := m.FindAll(ctx)
+	assert.Equal(t, []*Client{c}, all)
+	assert.NoError(t, err)
+
+	assert.NoError(t, m.Remove(ctx, "a"))
+	got, err = m.FindByID(ctx, "a")
+	assert.Nil(t, got)
+	assert.Same(t, rerror.ErrNotFound, err)
+}
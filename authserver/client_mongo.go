@@ -0,0 +1,138 @@
+package authserver
+
+import (
+	"context"
+	"time"
+
+	"github.com/reearth/reearthx/log"
+	"github.com/reearth/reearthx/mongox"
+	"github.com/samber/lo"
+	"github.com/zitadel/oidc/pkg/oidc"
+	"github.com/zitadel/oidc/pkg/op"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type ClientMongo struct {
+	client *mongox.Collection
+}
+
+var _ ClientRepo = (*ClientMongo)(nil)
+
+func NewClientMongo(client *mongox.Collection) *ClientMongo {
+	return &ClientMongo{client: client}
+}
+
+func (r *ClientMongo) Init(ctx context.Context) error {
+	added, deleted, err := r.client.Indexes(ctx, nil, []string{"id"})
+	if err != nil {
+		return err
+	}
+	if len(added) > 0 || len(deleted) > 0 {
+		log.Infofc(ctx, "mongo: authClient: index: deleted: %v, created: %v", deleted, added)
+	}
+	return nil
+}
+
+func (r *ClientMongo) FindByID(ctx context.Context, id string) (*Client, error) {
+	c := newClientMongoConsumer()
+	if err := r.client.FindOne(ctx, bson.M{"id": id}, c); err != nil {
+		return nil, err
+	}
+	return c.Result[0], nil
+}
+
+func (r *ClientMongo) FindAll(ctx context.Context) ([]*Client, error) {
+	c := newClientMongoConsumer()
+	if err := r.client.Find(ctx, bson.M{}, c); err != nil {
+		return nil, err
+	}
+	return c.Result, nil
+}
+
+func (r *ClientMongo) Save(ctx context.Context, client *Client) error {
+	doc, id := newClientMongoDocument(client)
+	return r.client.SaveOne(ctx, id, doc)
+}
+
+func (r *ClientMongo) Remove(ctx context.Context, id string) error {
+	return r.client.RemoveOne(ctx, bson.M{"id": id})
+}
+
+func newClientMongoConsumer() *mongox.SliceFuncConsumer[*clientMongoDocument, *Client] {
+	return mongox.NewSliceFuncConsumer(func(d *clientMongoDocument) (*Client, error) {
+		return d.Model()
+	})
+}
+
+type clientMongoDocument struct {
+	ID                     string        `bson:"id"`
+	ApplicationType        int           `bson:"applicationtype"`
+	AuthMethod             string        `bson:"authmethod"`
+	AccessTokenType        int           `bson:"accesstokentype"`
+	ResponseTypes          []string      `bson:"responsetypes"`
+	GrantTypes             []string      `bson:"granttypes"`
+	AllowedScopes          []string      `bson:"allowedscopes"`
+	RedirectURIs           []string      `bson:"redirecturis"`
+	PostLogoutRedirectURIs []string      `bson:"postlogoutredirecturis"`
+	LoginURI               string        `bson:"loginuri"`
+	IDTokenLifetime        time.Duration `bson:"idtokenlifetime"`
+	AccessTokenLifetime    time.Duration `bson:"accesstokenlifetime"`
+	RefreshTokenLifetime   time.Duration `bson:"refreshtokenlifetime"`
+	ClockSkew              time.Duration `bson:"clockskew"`
+	DevMode                bool          `bson:"devmode"`
+	SecretHash             string        `bson:"secrethash,omitempty"`
+}
+
+func newClientMongoDocument(c *Client) (*clientMongoDocument, string) {
+	if c == nil {
+		return nil, ""
+	}
+	return &clientMongoDocument{
+		ID:                     c.GetID(),
+		ApplicationType:        int(c.ApplicationType()),
+		AuthMethod:             string(c.AuthMethod()),
+		AccessTokenType:        int(c.AccessTokenType()),
+		ResponseTypes:          lo.Map(c.ResponseTypes(), func(t oidc.ResponseType, _ int) string { return string(t) }),
+		GrantTypes:             lo.Map(c.GrantTypes(), func(t oidc.GrantType, _ int) string { return string(t) }),
+		AllowedScopes:          c.AllowedScopes(),
+		RedirectURIs:           c.RedirectURIs(),
+		PostLogoutRedirectURIs: c.PostLogoutRedirectURIs(),
+		LoginURI:               c.LoginURI(),
+		IDTokenLifetime:        c.IDTokenLifetime(),
+		AccessTokenLifetime:    c.AccessTokenLifetime(),
+		RefreshTokenLifetime:   c.RefreshTokenLifetime(),
+		ClockSkew:              c.ClockSkew(),
+		DevMode:                c.DevMode(),
+		SecretHash:             string(c.SecretHash()),
+	}, c.GetID()
+}
+
+func (d *clientMongoDocument) Model() (*Client, error) {
+	if d == nil {
+		return nil, nil
+	}
+
+	var secretHash []byte
+	if d.SecretHash != "" {
+		secretHash = []byte(d.SecretHash)
+	}
+
+	return NewClient().
+		ID(d.ID).
+		ApplicationType(op.ApplicationType(d.ApplicationType)).
+		AuthMethod(oidc.AuthMethod(d.AuthMethod)).
+		AccessTokenType(op.AccessTokenType(d.AccessTokenType)).
+		ResponseTypes(lo.Map(d.ResponseTypes, func(t string, _ int) oidc.ResponseType { return oidc.ResponseType(t) })).
+		GrantTypes(lo.Map(d.GrantTypes, func(t string, _ int) oidc.GrantType { return oidc.GrantType(t) })).
+		AllowedScopes(d.AllowedScopes).
+		RedirectURIs(d.RedirectURIs).
+		PostLogoutRedirectURIs(d.PostLogoutRedirectURIs).
+		LoginURI(d.LoginURI).
+		IDTokenLifetime(d.IDTokenLifetime).
+		AccessTokenLifetime(d.AccessTokenLifetime).
+		RefreshTokenLifetime(d.RefreshTokenLifetime).
+		ClockSkew(d.ClockSkew).
+		DevMode(d.DevMode).
+		SecretHash(secretHash).
+		Build()
+}
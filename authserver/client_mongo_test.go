@@ -0,0 +1,49 @@
+package authserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/reearth/reearthx/mongox"
+	"github.com/reearth/reearthx/mongox/mongotest"
+	"github.com/reearth/reearthx/rerror"
+	"github.com/stretchr/testify/assert"
+	"github.com/zitadel/oidc/pkg/oidc"
+	"github.com/zitadel/oidc/pkg/op"
+)
+
+func TestClientMongo(t *testing.T) {
+	c := mongotest.Connect(t)(t)
+	m := NewClientMongo(mongox.NewCollection(c.Collection("auth_client")))
+	ctx := context.Background()
+
+	cl := NewClient().
+		ID("service").
+		ApplicationType(op.ApplicationTypeUserAgent).
+		AuthMethod(oidc.AuthMethodBasic).
+		GrantTypes([]oidc.GrantType{oidc.GrantTypeCode}).
+		RedirectURIs([]string{"https://example.com"}).
+		PostLogoutRedirectURIs([]string{"https://example.com/logout"}).
+		LoginURI("https://example.com/login?id=%s").
+		AccessTokenLifetime(time.Minute).
+		Secret("secret").
+		MustBuild()
+
+	got, err := m.FindByID(ctx, "service")
+	assert.Nil(t, got)
+	assert.Same(t, rerror.ErrNotFound, err)
+
+	assert.NoError(t, m.Save(ctx, cl))
+	got, err = m.FindByID(ctx, "service")
+	assert.NoError(t, err)
+	assert.Equal(t, cl, got)
+	assert.NoError(t, got.VerifySecret("secret"))
+
+	all, err := m.FindAll(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []*Client{cl}, all)
+
+	assert.NoError(t, m.Remove(ctx, "service"))
+	assert.Same(t, rerror.ErrNotFound, m.Remove(ctx, "service"))
+}
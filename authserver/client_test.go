@@ -0,0 +1,18 @@
+package authserver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zitadel/oidc/pkg/oidc"
+)
+
+func TestClient_VerifySecret(t *testing.T) {
+	c := NewClient().ID("a").AuthMethod(oidc.AuthMethodBasic).Secret("secret").MustBuild()
+	assert.NoError(t, c.VerifySecret("secret"))
+	assert.Same(t, ErrInvalidClientSecret, c.VerifySecret("secret2"))
+	assert.Same(t, ErrInvalidClientSecret, c.VerifySecret(""))
+
+	public := NewClient().ID("b").MustBuild()
+	assert.NoError(t, public.VerifySecret(""))
+}
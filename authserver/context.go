@@ -0,0 +1,24 @@
+package authserver
+
+import "context"
+
+type clientIPKey struct{}
+
+// GetClientIPFromContext returns the IP address of the client that sent the login request.
+// It is available in the context passed to SubLoader, e.g. to limit failed login attempts per IP.
+func GetClientIPFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if ip, ok := ctx.Value(clientIPKey{}).(string); ok {
+		return ip
+	}
+	return ""
+}
+
+func ContextWithClientIP(ctx context.Context, ip string) context.Context {
+	if ctx == nil {
+		return nil
+	}
+	return context.WithValue(ctx, clientIPKey{}, ip)
+}
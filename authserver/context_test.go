@@ -0,0 +1,14 @@
+package authserver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextWithClientIP(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, "", GetClientIPFromContext(ctx))
+	assert.Equal(t, "127.0.0.1", GetClientIPFromContext(ContextWithClientIP(ctx, "127.0.0.1")))
+}
@@ -0,0 +1,242 @@
+package authserver
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"math/big"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/reearth/reearthx/rerror"
+	"github.com/samber/lo"
+	"github.com/zitadel/oidc/pkg/oidc"
+	"github.com/zitadel/oidc/pkg/op"
+)
+
+// GrantTypeDeviceCode is the grant type of the device authorization grant (RFC 8628).
+const GrantTypeDeviceCode oidc.GrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+const (
+	defaultDeviceCodeLifetime = 10 * time.Minute
+	defaultDevicePollInterval = 5 * time.Second
+	userCodeChars             = "BCDFGHJKLMNPQRSTVWXZ"
+	userCodeLength            = 8
+)
+
+var (
+	ErrDeviceAuthorizationExpired = errors.New("device authorization expired")
+	ErrDeviceAuthorizationUsed    = errors.New("device authorization already used")
+)
+
+type DeviceFlowConfig struct {
+	// VerificationURL is the page where users enter the user code. Defaults to "/device" of the web URL.
+	// The page should send the code to the verification endpoint as the "user_code" query parameter.
+	VerificationURL *url.URL
+	// CodeLifetime is how long device and user codes are valid. Defaults to 10 minutes.
+	CodeLifetime time.Duration
+	// PollInterval is the minimum interval between token requests of a device. Defaults to 5 seconds.
+	PollInterval time.Duration
+}
+
+func (c DeviceFlowConfig) codeLifetime() time.Duration {
+	if c.CodeLifetime <= 0 {
+		return defaultDeviceCodeLifetime
+	}
+	return c.CodeLifetime
+}
+
+func (c DeviceFlowConfig) pollInterval() time.Duration {
+	if c.PollInterval <= 0 {
+		return defaultDevicePollInterval
+	}
+	return c.PollInterval
+}
+
+// DeviceAuthorization is a pending authorization of the device authorization grant.
+// Once the user starts the verification, the login is driven by an ordinary auth request.
+type DeviceAuthorization struct {
+	DeviceCode   string
+	UserCode     string
+	ClientID     string
+	Scopes       []string
+	RequestID    string
+	Subject      string
+	ExpiresAt    time.Time
+	LastPolledAt *time.Time
+}
+
+func (d *DeviceAuthorization) Approved() bool {
+	return d.Subject != ""
+}
+
+func (d *DeviceAuthorization) Expired(now time.Time) bool {
+	return !now.Before(d.ExpiresAt)
+}
+
+// NormalizeUserCode removes separators and lowercase letters that users may type.
+func NormalizeUserCode(code string) string {
+	code = strings.ToUpper(code)
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, code)
+}
+
+// FormatUserCode formats a user code as XXXX-XXXX for display.
+func FormatUserCode(code string) string {
+	if len(code) != userCodeLength {
+		return code
+	}
+	return code[:userCodeLength/2] + "-" + code[userCodeLength/2:]
+}
+
+func generateDeviceCode() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func generateUserCode() (string, error) {
+	var sb strings.Builder
+	max := big.NewInt(int64(len(userCodeChars)))
+	for i := 0; i < userCodeLength; i++ {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteByte(userCodeChars[n.Int64()])
+	}
+	return sb.String(), nil
+}
+
+// CreateDeviceAuthorization issues a device code and a user code to the client.
+func (s *Storage) CreateDeviceAuthorization(ctx context.Context, clientID string, scopes []string) (*DeviceAuthorization, error) {
+	client, err := s.GetClientByClientID(ctx, clientID)
+	if err != nil {
+		return nil, oidc.ErrInvalidClient().WithParent(err)
+	}
+	if !op.ValidateGrantType(client, GrantTypeDeviceCode) {
+		return nil, oidc.ErrUnauthorizedClient().WithDescription("device_code grant is not allowed for the client")
+	}
+
+	deviceCode, err := generateDeviceCode()
+	if err != nil {
+		return nil, err
+	}
+	userCode, err := generateUserCode()
+	if err != nil {
+		return nil, err
+	}
+
+	d := &DeviceAuthorization{
+		DeviceCode: deviceCode,
+		UserCode:   userCode,
+		ClientID:   clientID,
+		Scopes:     lo.Filter(scopes, func(s string, _ int) bool { return client.IsScopeAllowed(s) }),
+		ExpiresAt:  time.Now().UTC().Add(s.config.Device.codeLifetime()),
+	}
+	if err := s.deviceRepo.Save(ctx, d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// StartDeviceAuthorization creates an auth request for the user code so that the user can log in with the ordinary login page.
+// It returns the URL of the login page.
+func (s *Storage) StartDeviceAuthorization(ctx context.Context, userCode string) (string, error) {
+	d, err := s.deviceRepo.FindByUserCode(ctx, NormalizeUserCode(userCode))
+	if err != nil {
+		return "", err
+	}
+	if d.Expired(time.Now()) {
+		return "", ErrDeviceAuthorizationExpired
+	}
+	if d.Approved() {
+		return "", ErrDeviceAuthorizationUsed
+	}
+
+	client, err := s.GetClientByClientID(ctx, d.ClientID)
+	if err != nil {
+		return "", err
+	}
+
+	request := NewRequest().
+		NewID().
+		ClientID(d.ClientID).
+		ResponseType(oidc.ResponseTypeCode).
+		Scopes(d.Scopes).
+		Audiences(s.audiences()).
+		MustBuild()
+	if err := s.requestRepo.Save(ctx, request); err != nil {
+		return "", err
+	}
+
+	d.RequestID = request.GetID()
+	if err := s.deviceRepo.Save(ctx, d); err != nil {
+		return "", err
+	}
+	return client.LoginURL(request.GetID()), nil
+}
+
+// CompleteDeviceAuthorization approves the device authorization that the auth request was created for.
+// It reports false if the auth request does not belong to the device authorization grant.
+func (s *Storage) CompleteDeviceAuthorization(ctx context.Context, requestID, sub string) (bool, error) {
+	d, err := s.deviceRepo.FindByRequestID(ctx, requestID)
+	if errors.Is(err, rerror.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if d.Expired(time.Now()) {
+		return true, ErrDeviceAuthorizationExpired
+	}
+
+	d.Subject = sub
+	return true, s.deviceRepo.Save(ctx, d)
+}
+
+// DeviceTokenRequest returns the completed auth request of the device code, or an RFC 8628 error while it is not approved yet.
+func (s *Storage) DeviceTokenRequest(ctx context.Context, deviceCode, clientID string) (*Request, error) {
+	d, err := s.deviceRepo.FindByDeviceCode(ctx, deviceCode)
+	if errors.Is(err, rerror.ErrNotFound) || (err == nil && d.ClientID != clientID) {
+		return nil, oidc.ErrInvalidGrant().WithDescription("invalid device code")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	if d.Expired(now) {
+		_ = s.deviceRepo.Remove(ctx, deviceCode)
+		return nil, &oidc.Error{ErrorType: "expired_token"}
+	}
+
+	if !d.Approved() {
+		slowDown := d.LastPolledAt != nil && now.Before(d.LastPolledAt.Add(s.config.Device.pollInterval()))
+		d.LastPolledAt = &now
+		if err := s.deviceRepo.Save(ctx, d); err != nil {
+			return nil, err
+		}
+		if slowDown {
+			return nil, &oidc.Error{ErrorType: "slow_down"}
+		}
+		return nil, &oidc.Error{ErrorType: "authorization_pending"}
+	}
+
+	request, err := s.AuthRequestByID(ctx, d.RequestID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.deviceRepo.Remove(ctx, deviceCode); err != nil {
+		return nil, err
+	}
+	return request.(*Request), nil
+}
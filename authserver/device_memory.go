@@ -0,0 +1,60 @@
+package authserver
+
+import (
+	"context"
+
+	"github.com/reearth/reearthx/rerror"
+	"github.com/reearth/reearthx/util"
+)
+
+type DeviceAuthorizationMemory struct {
+	data *util.SyncMap[string, *DeviceAuthorization]
+}
+
+var _ DeviceAuthorizationRepo = (*DeviceAuthorizationMemory)(nil)
+
+func NewDeviceAuthorizationMemory() *DeviceAuthorizationMemory {
+	return &DeviceAuthorizationMemory{
+		data: &util.SyncMap[string, *DeviceAuthorization]{},
+	}
+}
+
+func (r *DeviceAuthorizationMemory) FindByDeviceCode(_ context.Context, code string) (*DeviceAuthorization, error) {
+	d, ok := r.data.Load(code)
+	if ok {
+		return d, nil
+	}
+	return nil, rerror.ErrNotFound
+}
+
+func (r *DeviceAuthorizationMemory) FindByUserCode(_ context.Context, code string) (*DeviceAuthorization, error) {
+	return r.find(func(d *DeviceAuthorization) bool {
+		return d.UserCode == code
+	})
+}
+
+func (r *DeviceAuthorizationMemory) FindByRequestID(_ context.Context, id string) (*DeviceAuthorization, error) {
+	return r.find(func(d *DeviceAuthorization) bool {
+		return d.RequestID == id
+	})
+}
+
+func (r *DeviceAuthorizationMemory) Save(_ context.Context, d *DeviceAuthorization) error {
+	r.data.Store(d.DeviceCode, d)
+	return nil
+}
+
+func (r *DeviceAuthorizationMemory) Remove(_ context.Context, code string) error {
+	r.data.Delete(code)
+	return nil
+}
+
+func (r *DeviceAuthorizationMemory) find(f func(*DeviceAuthorization) bool) (*DeviceAuthorization, error) {
+	d := r.data.Find(func(_ string, d *DeviceAuthorization) bool {
+		return f(d)
+	})
+	if d == nil {
+		return nil, rerror.ErrNotFound
+	}
+	return d, nil
+}
@@ -0,0 +1,43 @@
+package authserver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/reearth/reearthx/rerror"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeviceAuthorizationMemory(t *testing.T) {
+	ctx := context.Background()
+	d := &DeviceAuthorization{DeviceCode: "dc", UserCode: "UC", RequestID: "r"}
+	m := NewDeviceAuthorizationMemory()
+
+	got, err := m.FindByDeviceCode(ctx, "dc")
+	assert.Nil(t, got)
+	assert.Same(t, rerror.ErrNotFound, err)
+
+	assert.NoError(t, m.Save(ctx, d))
+	assert.Equal(t, 1, m.data.Len())
+
+	got, err = m.FindByDeviceCode(ctx, "dc")
+	assert.Same(t, d, got)
+	assert.NoError(t, err)
+
+	got, err = m.FindByUserCode(ctx, "UC")
+	assert.Same(t, d, got)
+	assert.NoError(t, err)
+
+	got, err = m.FindByRequestID(ctx, "r")
+	assert.Same(t, d, got)
+	assert.NoError(t, err)
+
+	got, err = m.FindByUserCode(ctx, "XX")
+	assert.Nil(t, got)
+	assert.Same(t, rerror.ErrNotFound, err)
+
+	assert.NoError(t, m.Remove(ctx, "dc"))
+	got, err = m.FindByDeviceCode(ctx, "dc")
+	assert.Nil(t, got)
+	assert.Same(t, rerror.ErrNotFound, err)
+}
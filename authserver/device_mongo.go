@@ -0,0 +1,112 @@
+package authserver
+
+import (
+	"context"
+	"time"
+
+	"github.com/reearth/reearthx/log"
+	"github.com/reearth/reearthx/mongox"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type DeviceAuthorizationMongo struct {
+	client *mongox.Collection
+}
+
+var _ DeviceAuthorizationRepo = (*DeviceAuthorizationMongo)(nil)
+
+func NewDeviceAuthorizationMongo(client *mongox.Collection) *DeviceAuthorizationMongo {
+	return &DeviceAuthorizationMongo{client: client}
+}
+
+func (r *DeviceAuthorizationMongo) Init(ctx context.Context) error {
+	res, err := r.client.Indexes2(
+		ctx,
+		mongox.IndexFromKey("id", true),
+		mongox.IndexFromKey("usercode", true),
+		mongox.IndexFromKey("requestid", false),
+		mongox.TTLIndexFromKey("expiresat", 0),
+	)
+	if err != nil {
+		return err
+	}
+	added, updated, deleted := res.AddedNames(), res.UpdatedNames(), res.DeletedNames()
+	if len(added) > 0 || len(updated) > 0 || len(deleted) > 0 {
+		log.Infofc(ctx, "mongo: authDevice: index: deleted: %v, updated: %v, created: %v", deleted, updated, added)
+	}
+	return nil
+}
+
+func (r *DeviceAuthorizationMongo) FindByDeviceCode(ctx context.Context, code string) (*DeviceAuthorization, error) {
+	return r.findOne(ctx, bson.M{"id": code})
+}
+
+func (r *DeviceAuthorizationMongo) FindByUserCode(ctx context.Context, code string) (*DeviceAuthorization, error) {
+	return r.findOne(ctx, bson.M{"usercode": code})
+}
+
+func (r *DeviceAuthorizationMongo) FindByRequestID(ctx context.Context, id string) (*DeviceAuthorization, error) {
+	return r.findOne(ctx, bson.M{"requestid": id})
+}
+
+func (r *DeviceAuthorizationMongo) Save(ctx context.Context, d *DeviceAuthorization) error {
+	doc, id := newDeviceAuthorizationMongoDocument(d)
+	return r.client.SaveOne(ctx, id, doc)
+}
+
+func (r *DeviceAuthorizationMongo) Remove(ctx context.Context, code string) error {
+	return r.client.RemoveOne(ctx, bson.M{"id": code})
+}
+
+func (r *DeviceAuthorizationMongo) findOne(ctx context.Context, filter any) (*DeviceAuthorization, error) {
+	c := mongox.NewSliceFuncConsumer(func(d *deviceAuthorizationMongoDocument) (*DeviceAuthorization, error) {
+		return d.Model(), nil
+	})
+	if err := r.client.FindOne(ctx, filter, c); err != nil {
+		return nil, err
+	}
+	return c.Result[0], nil
+}
+
+type deviceAuthorizationMongoDocument struct {
+	ID           string     `bson:"id"`
+	UserCode     string     `bson:"usercode"`
+	ClientID     string     `bson:"clientid"`
+	Scopes       []string   `bson:"scopes"`
+	RequestID    string     `bson:"requestid,omitempty"`
+	Subject      string     `bson:"subject,omitempty"`
+	ExpiresAt    time.Time  `bson:"expiresat"`
+	LastPolledAt *time.Time `bson:"lastpolledat"`
+}
+
+func newDeviceAuthorizationMongoDocument(d *DeviceAuthorization) (*deviceAuthorizationMongoDocument, string) {
+	if d == nil {
+		return nil, ""
+	}
+	return &deviceAuthorizationMongoDocument{
+		ID:           d.DeviceCode,
+		UserCode:     d.UserCode,
+		ClientID:     d.ClientID,
+		Scopes:       d.Scopes,
+		RequestID:    d.RequestID,
+		Subject:      d.Subject,
+		ExpiresAt:    d.ExpiresAt,
+		LastPolledAt: d.LastPolledAt,
+	}, d.DeviceCode
+}
+
+func (d *deviceAuthorizationMongoDocument) Model() *DeviceAuthorization {
+	if d == nil {
+		return nil
+	}
+	return &DeviceAuthorization{
+		DeviceCode:   d.ID,
+		UserCode:     d.UserCode,
+		ClientID:     d.ClientID,
+		Scopes:       d.Scopes,
+		RequestID:    d.RequestID,
+		Subject:      d.Subject,
+		ExpiresAt:    d.ExpiresAt,
+		LastPolledAt: d.LastPolledAt,
+	}
+}
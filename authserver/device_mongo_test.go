@@ -0,0 +1,53 @@
+package authserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/reearth/reearthx/mongox"
+	"github.com/reearth/reearthx/mongox/mongotest"
+	"github.com/reearth/reearthx/rerror"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeviceAuthorizationMongo(t *testing.T) {
+	c := mongotest.Connect(t)(t)
+	m := NewDeviceAuthorizationMongo(mongox.NewCollection(c.Collection("auth_device")))
+	ctx := context.Background()
+	assert.NoError(t, m.Init(ctx))
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	d := &DeviceAuthorization{
+		DeviceCode:   "dc",
+		UserCode:     "UC",
+		ClientID:     "c",
+		Scopes:       []string{"openid"},
+		RequestID:    "r",
+		ExpiresAt:    now.Add(time.Minute),
+		LastPolledAt: &now,
+	}
+
+	got, err := m.FindByDeviceCode(ctx, "dc")
+	assert.Nil(t, got)
+	assert.Same(t, rerror.ErrNotFound, err)
+
+	assert.NoError(t, m.Save(ctx, d))
+
+	got, err = m.FindByDeviceCode(ctx, "dc")
+	assert.Equal(t, d, got)
+	assert.NoError(t, err)
+
+	got, err = m.FindByUserCode(ctx, "UC")
+	assert.Equal(t, d, got)
+	assert.NoError(t, err)
+
+	got, err = m.FindByRequestID(ctx, "r")
+	assert.Equal(t, d, got)
+	assert.NoError(t, err)
+
+	assert.NoError(t, m.Remove(ctx, "dc"))
+	got, err = m.FindByDeviceCode(ctx, "dc")
+	assert.Nil(t, got)
+	assert.Same(t, rerror.ErrNotFound, err)
+}
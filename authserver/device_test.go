@@ -0,0 +1,26 @@
+package authserver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeUserCode(t *testing.T) {
+	assert.Equal(t, "BCDFGHJK", NormalizeUserCode("bcdf-ghjk"))
+	assert.Equal(t, "BCDFGHJK", NormalizeUserCode("BCDF GHJK"))
+}
+
+func TestFormatUserCode(t *testing.T) {
+	assert.Equal(t, "BCDF-GHJK", FormatUserCode("BCDFGHJK"))
+	assert.Equal(t, "BCD", FormatUserCode("BCD"))
+}
+
+func TestGenerateUserCode(t *testing.T) {
+	code, err := generateUserCode()
+	assert.NoError(t, err)
+	assert.Len(t, code, userCodeLength)
+	for _, c := range code {
+		assert.Contains(t, userCodeChars, string(c))
+	}
+}
@@ -9,12 +9,16 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/labstack/echo/v4"
 	"github.com/reearth/reearthx/log"
+	"github.com/reearth/reearthx/util"
 	"github.com/zitadel/oidc/pkg/op"
 )
 
 const (
-	loginEndpoint  = "api/login"
-	logoutEndpoint = "api/logout"
+	loginEndpoint               = "api/login"
+	mfaLoginEndpoint            = "api/login/mfa"
+	logoutEndpoint              = "api/logout"
+	deviceAuthorizationEndpoint = "oauth/device/code"
+	deviceVerificationEndpoint  = "device"
 )
 
 type EndpointConfig struct {
@@ -28,6 +32,11 @@ type EndpointConfig struct {
 	UserRepo        UserRepo
 	ConfigRepo      ConfigRepo
 	RequestRepo     RequestRepo
+	ClientRepo      ClientRepo
+	SessionRepo     SessionRepo
+	KeyRotation     *KeyRotationConfig
+	DeviceRepo      DeviceAuthorizationRepo
+	Device          DeviceFlowConfig
 	DenyHTTP        bool
 }
 
@@ -44,6 +53,12 @@ func (c *EndpointConfig) normalize() {
 		c.Issuer = c.Issuer + "/"
 	}
 
+	if c.Device.VerificationURL == nil {
+		u := util.CopyURL(c.WebURL)
+		u.Path = "/device"
+		c.Device.VerificationURL = u
+	}
+
 	if c.Dev {
 		_ = os.Setenv(op.OidcDevMode, "true")
 	} else {
@@ -61,6 +76,11 @@ func (c EndpointConfig) storageConfig() StorageConfig {
 		DN:             c.DN,
 		ConfigRepo:     c.ConfigRepo,
 		RequestRepo:    c.RequestRepo,
+		ClientRepo:     c.ClientRepo,
+		SessionRepo:    c.SessionRepo,
+		KeyRotation:    c.KeyRotation,
+		DeviceRepo:     c.DeviceRepo,
+		Device:         c.Device,
 		Issuer:         c.Issuer,
 	}
 }
@@ -86,18 +106,38 @@ func Endpoint(ctx context.Context, cfg EndpointConfig, g *echo.Group) {
 		log.Fatalfc(ctx, "auth: walk failed: %s\n", err)
 	}
 
-	g.POST(loginEndpoint, LoginHandler(ctx, LoginHandlerConfig{
-		SubLoader: cfg.UserRepo.Sub,
-		URL:       cfg.URL,
-		WebURL:    cfg.WebURL,
-		Storage:   storage,
-	}))
+	loginHandlerConfig := LoginHandlerConfig{
+		SubLoader:             cfg.UserRepo.Sub,
+		URL:                   cfg.URL,
+		WebURL:                cfg.WebURL,
+		DeviceVerificationURL: cfg.Device.VerificationURL,
+		Storage:               storage,
+	}
+	if mfa, ok := cfg.UserRepo.(MFAUserRepo); ok {
+		loginHandlerConfig.MFA = mfa
+	}
+	g.POST(loginEndpoint, LoginHandler(ctx, loginHandlerConfig))
+	g.POST(mfaLoginEndpoint, MFALoginHandler(ctx, loginHandlerConfig))
+
+	deviceHandlerConfig := DeviceHandlerConfig{
+		URL:             cfg.URL,
+		VerificationURL: cfg.Device.VerificationURL,
+		Storage:         storage,
+	}
+	g.POST(deviceAuthorizationEndpoint, DeviceAuthorizationHandler(ctx, deviceHandlerConfig))
+	g.GET(deviceVerificationEndpoint, DeviceVerificationHandler(ctx, deviceHandlerConfig))
+
+	logoutHandlerConfig := SessionLogoutHandlerConfig{}
+	if sessions, ok := storage.(SessionTerminator); ok {
+		logoutHandlerConfig.Sessions = sessions
+	}
+	logoutHandler := SessionLogoutHandler(ctx, logoutHandlerConfig)
 
-	g.GET(logoutEndpoint, LogoutHandler())
+	g.GET(logoutEndpoint, logoutHandler)
 
 	// compatibility with auth0/auth0-spa-js; the logout endpoint URL is hard-coded
 	// https://github.com/auth0/auth0-spa-js/issues/845
-	g.GET("v2/logout", LogoutHandler())
+	g.GET("v2/logout", logoutHandler)
 
 	debugMsg := ""
 	if cfg.Dev {
@@ -15,12 +15,14 @@ import (
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/reearth/reearthx/util"
 	"github.com/samber/lo"
 	"github.com/stretchr/testify/assert"
 	"github.com/zitadel/oidc/pkg/oidc"
+	"github.com/zitadel/oidc/pkg/op"
 	"gopkg.in/square/go-jose.v2"
 	"gopkg.in/square/go-jose.v2/jwt"
 )
@@ -262,6 +264,482 @@ func TestEndpoint(t *testing.T) {
 	assert.Equal(t, claims3["auth_time"], claims5["auth_time"])
 }
 
+func TestEndpoint_Revocation(t *testing.T) {
+	e := echo.New()
+	Endpoint(context.Background(), EndpointConfig{
+		Issuer:          "https://example.com/",
+		URL:             lo.Must(url.Parse("https://example.com")),
+		WebURL:          lo.Must(url.Parse("https://web.example.com")),
+		DefaultClientID: "default-client",
+		UserRepo:        &userRepo{},
+		ConfigRepo:      &configRepo{},
+		RequestRepo:     &requestRepo{},
+	}, e.Group(""))
+
+	ts := httptest.NewServer(e)
+	defer ts.Close()
+
+	refresh := func(token string) *http.Response {
+		return send(http.MethodPost, ts.URL+"/oauth/token", true, map[string]string{
+			"grant_type":    "refresh_token",
+			"client_id":     "default-client",
+			"refresh_token": token,
+		}, nil)
+	}
+
+	// rotated refresh tokens can no longer be used
+	r := login(t, ts.URL, "default-client", "")
+	refreshToken := r["refresh_token"].(string)
+	res := refresh(refreshToken)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	var r2 map[string]any
+	lo.Must0(json.Unmarshal(lo.Must(io.ReadAll(res.Body)), &r2))
+	refreshToken2 := r2["refresh_token"].(string)
+	assert.Equal(t, http.StatusBadRequest, refresh(refreshToken).StatusCode)
+
+	// reusing a rotated refresh token revokes the whole session
+	assert.Equal(t, http.StatusBadRequest, refresh(refreshToken2).StatusCode)
+
+	// concurrent refreshes with the same token succeed only once
+	r = login(t, ts.URL, "default-client", "")
+	refreshToken = r["refresh_token"].(string)
+	statuses := make(chan int, 5)
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			statuses <- refresh(refreshToken).StatusCode
+		}()
+	}
+	wg.Wait()
+	close(statuses)
+	succeeded := 0
+	for s := range statuses {
+		if s == http.StatusOK {
+			succeeded++
+		}
+	}
+	assert.Equal(t, 1, succeeded)
+
+	// revocation
+	r = login(t, ts.URL, "default-client", "")
+	res = refresh(r["refresh_token"].(string))
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	lo.Must0(json.Unmarshal(lo.Must(io.ReadAll(res.Body)), &r2))
+	refreshToken2 = r2["refresh_token"].(string)
+	res = send(http.MethodPost, ts.URL+"/revoke", true, map[string]string{
+		"client_id":       "default-client",
+		"token":           refreshToken2,
+		"token_type_hint": "refresh_token",
+	}, nil)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, http.StatusBadRequest, refresh(refreshToken2).StatusCode)
+
+	// access tokens of a revoked session are rejected by userinfo
+	res = send(http.MethodGet, ts.URL+"/userinfo", false, nil, map[string]string{
+		"Authorization": "Bearer " + r2["access_token"].(string),
+	})
+	assert.Equal(t, http.StatusForbidden, res.StatusCode)
+
+	// logout
+	r = login(t, ts.URL, "default-client", "")
+	res = send(http.MethodGet, ts.URL+"/api/logout", false, map[string]string{
+		"id_token_hint": r["id_token"].(string),
+		"returnTo":      "https://web.example.com",
+	}, nil)
+	assert.Equal(t, http.StatusTemporaryRedirect, res.StatusCode)
+	assert.Equal(t, "https://web.example.com", res.Header.Get("Location"))
+	assert.Equal(t, http.StatusBadRequest, refresh(r["refresh_token"].(string)).StatusCode)
+}
+
+func TestEndpoint_KeyRotation(t *testing.T) {
+	e := echo.New()
+	cr := &configRepo{}
+	Endpoint(context.Background(), EndpointConfig{
+		Issuer:          "https://example.com/",
+		URL:             lo.Must(url.Parse("https://example.com")),
+		WebURL:          lo.Must(url.Parse("https://web.example.com")),
+		DefaultClientID: "default-client",
+		UserRepo:        &userRepo{},
+		ConfigRepo:      cr,
+		RequestRepo:     &requestRepo{},
+		KeyRotation: &KeyRotationConfig{
+			Interval:        time.Hour,
+			ActivationDelay: time.Nanosecond,
+		},
+	}, e.Group(""))
+
+	ts := httptest.NewServer(e)
+	defer ts.Close()
+
+	// the initial key is rotated at startup and both keys are published
+	assert.Len(t, cr.c.Keys, 2)
+	assert.Equal(t, legacyKeyID, cr.c.Keys[0].ID)
+	assert.NotNil(t, cr.c.Keys[0].RetiredAt)
+	newKeyID := cr.c.Keys[1].ID
+
+	res := send(http.MethodGet, ts.URL+"/jwks.json", false, nil, nil)
+	var jwks jose.JSONWebKeySet
+	lo.Must0(json.Unmarshal(lo.Must(io.ReadAll(res.Body)), &jwks))
+	assert.Len(t, jwks.Keys, 2)
+
+	r := login(t, ts.URL, "default-client", "")
+	for _, k := range []string{"access_token", "id_token", "refresh_token"} {
+		token := lo.Must(jwt.ParseSigned(r[k].(string)))
+		assert.Equal(t, newKeyID, token.Headers[0].KeyID, k)
+	}
+}
+
+func TestEndpoint_ConfidentialClient(t *testing.T) {
+	ctx := context.Background()
+	clients := NewClientMemory()
+	lo.Must0(clients.Save(ctx, NewClient().
+		ID("service").
+		AuthMethod(oidc.AuthMethodBasic).
+		GrantTypes([]oidc.GrantType{oidc.GrantTypeCode, oidc.GrantTypeRefreshToken}).
+		AllowedScopes([]string{"openid", "profile", "email", "offline_access"}).
+		RedirectURIs([]string{"https://web.example.com"}).
+		AccessTokenLifetime(time.Minute).
+		Secret("secret").
+		MustBuild()))
+
+	e := echo.New()
+	Endpoint(ctx, EndpointConfig{
+		Issuer:          "https://example.com/",
+		URL:             lo.Must(url.Parse("https://example.com")),
+		WebURL:          lo.Must(url.Parse("https://web.example.com")),
+		DefaultClientID: "default-client",
+		UserRepo:        &userRepo{},
+		ConfigRepo:      &configRepo{},
+		RequestRepo:     &requestRepo{},
+		ClientRepo:      clients,
+	}, e.Group(""))
+
+	ts := httptest.NewServer(e)
+	defer ts.Close()
+
+	assert.Nil(t, login(t, ts.URL, "service", "wrong"))
+
+	r := login(t, ts.URL, "service", "secret")
+	assert.NotNil(t, r)
+	assert.LessOrEqual(t, r["expires_in"], float64(60))
+	assert.NotEmpty(t, r["refresh_token"])
+
+	// the default client is still available
+	assert.NotNil(t, login(t, ts.URL, "default-client", ""))
+}
+
+func TestEndpoint_ClientCredentials(t *testing.T) {
+	ctx := context.Background()
+	clients := NewClientMemory()
+	lo.Must0(clients.Save(ctx, NewClient().
+		ID("service").
+		AuthMethod(oidc.AuthMethodBasic).
+		GrantTypes([]oidc.GrantType{oidc.GrantTypeClientCredentials}).
+		AllowedScopes([]string{"read"}).
+		Secret("secret").
+		MustBuild()))
+	lo.Must0(clients.Save(ctx, NewClient().
+		ID("public").
+		GrantTypes([]oidc.GrantType{oidc.GrantTypeClientCredentials}).
+		MustBuild()))
+
+	e := echo.New()
+	Endpoint(ctx, EndpointConfig{
+		Issuer:          "https://example.com/",
+		URL:             lo.Must(url.Parse("https://example.com")),
+		WebURL:          lo.Must(url.Parse("https://web.example.com")),
+		DefaultClientID: "default-client",
+		UserRepo:        &userRepo{},
+		ConfigRepo:      &configRepo{},
+		RequestRepo:     &requestRepo{},
+		ClientRepo:      clients,
+	}, e.Group(""))
+
+	ts := httptest.NewServer(e)
+	defer ts.Close()
+
+	token := func(clientID, secret string) *http.Response {
+		return send(http.MethodPost, ts.URL+"/oauth/token", true, map[string]string{
+			"grant_type": "client_credentials",
+			"scope":      "read write",
+		}, map[string]string{
+			"Authorization": "Basic " + base64.StdEncoding.EncodeToString([]byte(clientID+":"+secret)),
+		})
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, token("service", "wrong").StatusCode)
+	assert.Equal(t, http.StatusBadRequest, token("public", "").StatusCode)
+	assert.Equal(t, http.StatusBadRequest, token("default-client", "").StatusCode)
+
+	res := token("service", "secret")
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	var r map[string]any
+	lo.Must0(json.Unmarshal(lo.Must(io.ReadAll(res.Body)), &r))
+	assert.Equal(t, "Bearer", r["token_type"])
+	assert.Nil(t, r["refresh_token"])
+
+	claims := map[string]any{}
+	lo.Must0(lo.Must(jwt.ParseSigned(r["access_token"].(string))).UnsafeClaimsWithoutVerification(&claims))
+	assert.Equal(t, "service", claims["sub"])
+	assert.Equal(t, []any{"https://example.com"}, claims["aud"])
+}
+
+func TestEndpoint_DeviceAuthorization(t *testing.T) {
+	ctx := context.Background()
+	clients := NewClientMemory()
+	lo.Must0(clients.Save(ctx, NewClient().
+		ID("cli").
+		ApplicationType(op.ApplicationTypeNative).
+		GrantTypes([]oidc.GrantType{GrantTypeDeviceCode, oidc.GrantTypeRefreshToken}).
+		AllowedScopes([]string{"openid", "profile", "email", "offline_access"}).
+		MustBuild()))
+
+	e := echo.New()
+	Endpoint(ctx, EndpointConfig{
+		Issuer:          "https://example.com/",
+		URL:             lo.Must(url.Parse("https://example.com")),
+		WebURL:          lo.Must(url.Parse("https://web.example.com")),
+		DefaultClientID: "default-client",
+		UserRepo:        &userRepo{},
+		ConfigRepo:      &configRepo{},
+		RequestRepo:     &requestRepo{},
+		ClientRepo:      clients,
+		Device: DeviceFlowConfig{
+			PollInterval: time.Millisecond,
+		},
+	}, e.Group(""))
+
+	ts := httptest.NewServer(e)
+	defer ts.Close()
+
+	// clients without the grant type are rejected
+	res := send(http.MethodPost, ts.URL+"/oauth/device/code", true, map[string]string{
+		"client_id": "default-client",
+	}, nil)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	res = send(http.MethodPost, ts.URL+"/oauth/device/code", true, map[string]string{
+		"client_id": "cli",
+		"scope":     "openid offline_access",
+	}, nil)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	var d map[string]any
+	lo.Must0(json.Unmarshal(lo.Must(io.ReadAll(res.Body)), &d))
+	deviceCode := d["device_code"].(string)
+	userCode := d["user_code"].(string)
+	assert.Equal(t, "https://web.example.com/device", d["verification_uri"])
+	assert.Equal(t, "https://example.com/device?user_code="+userCode, d["verification_uri_complete"])
+	assert.Equal(t, float64(600), d["expires_in"])
+
+	poll := func() (int, map[string]any) {
+		res := send(http.MethodPost, ts.URL+"/oauth/token", true, map[string]string{
+			"grant_type":  string(GrantTypeDeviceCode),
+			"client_id":   "cli",
+			"device_code": deviceCode,
+		}, nil)
+		var r map[string]any
+		lo.Must0(json.Unmarshal(lo.Must(io.ReadAll(res.Body)), &r))
+		return res.StatusCode, r
+	}
+
+	status, r := poll()
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "authorization_pending", r["error"])
+
+	// invalid user code
+	res = send(http.MethodGet, ts.URL+"/device?user_code=AAAA-AAAA", false, nil, nil)
+	assert.Equal(t, http.StatusFound, res.StatusCode)
+	assert.Equal(t, "https://web.example.com/device?error=Invalid+or+expired+code.&user_code=AAAA-AAAA", res.Header.Get("Location"))
+
+	// verification
+	res = send(http.MethodGet, ts.URL+"/device?user_code="+strings.ToLower(userCode), false, nil, nil)
+	assert.Equal(t, http.StatusFound, res.StatusCode)
+	loc := res.Header.Get("Location")
+	assert.Contains(t, loc, "https://web.example.com/login?id=")
+	reqID := lo.Must(url.Parse(loc)).Query().Get("id")
+
+	res = send(http.MethodPost, ts.URL+"/api/login", true, map[string]string{
+		"username": "aaa@example.com",
+		"password": "aaa",
+		"id":       reqID,
+	}, nil)
+	assert.Equal(t, http.StatusFound, res.StatusCode)
+	assert.Equal(t, "https://web.example.com/device?success=true", res.Header.Get("Location"))
+
+	time.Sleep(time.Millisecond)
+	status, r = poll()
+	assert.Equal(t, http.StatusOK, status)
+	assert.NotEmpty(t, r["access_token"])
+	assert.NotEmpty(t, r["id_token"])
+	assert.NotEmpty(t, r["refresh_token"])
+
+	claims := map[string]any{}
+	lo.Must0(lo.Must(jwt.ParseSigned(r["id_token"].(string))).UnsafeClaimsWithoutVerification(&claims))
+	assert.Equal(t, "subsub", claims["sub"])
+	assert.Equal(t, "cli", claims["azp"])
+
+	// device codes can be used only once
+	status, r = poll()
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "invalid_grant", r["error"])
+}
+
+func TestEndpoint_MFA(t *testing.T) {
+	ctx := context.Background()
+	e := echo.New()
+	Endpoint(ctx, EndpointConfig{
+		Issuer:          "https://example.com/",
+		URL:             lo.Must(url.Parse("https://example.com")),
+		WebURL:          lo.Must(url.Parse("https://web.example.com")),
+		DefaultClientID: "default-client",
+		UserRepo:        &mfaUserRepo{},
+		ConfigRepo:      &configRepo{},
+		RequestRepo:     &requestRepo{},
+	}, e.Group(""))
+
+	ts := httptest.NewServer(e)
+	defer ts.Close()
+
+	authorize := func() (string, string) {
+		verifier, challenge := randomCodeChallenge()
+		res := send(http.MethodGet, ts.URL+"/authorize", false, map[string]string{
+			"response_type":         "code",
+			"client_id":             "default-client",
+			"redirect_uri":          "https://web.example.com",
+			"scope":                 "openid email profile",
+			"code_challenge":        challenge,
+			"code_challenge_method": "S256",
+		}, nil)
+		reqID := lo.Must(url.Parse(res.Header.Get("Location"))).Query().Get("id")
+
+		res = send(http.MethodPost, ts.URL+"/api/login", true, map[string]string{
+			"username": "aaa@example.com",
+			"password": "aaa",
+			"id":       reqID,
+		}, nil)
+		assert.Equal(t, http.StatusFound, res.StatusCode)
+		assert.Equal(t, "https://web.example.com/login/mfa?id="+reqID, res.Header.Get("Location"))
+		return reqID, verifier
+	}
+
+	mfa := func(reqID, code string) string {
+		res := send(http.MethodPost, ts.URL+"/api/login/mfa", true, map[string]string{
+			"code": code,
+			"id":   reqID,
+		}, nil)
+		assert.Equal(t, http.StatusFound, res.StatusCode)
+		return res.Header.Get("Location")
+	}
+
+	amr := func(reqID, verifier string) any {
+		res := send(http.MethodGet, ts.URL+"/authorize/callback?id="+reqID, false, nil, nil)
+		code := lo.Must(url.Parse(res.Header.Get("Location"))).Query().Get("code")
+		res = send(http.MethodPost, ts.URL+"/oauth/token", true, map[string]string{
+			"grant_type":    "authorization_code",
+			"redirect_uri":  "https://web.example.com",
+			"client_id":     "default-client",
+			"code":          code,
+			"code_verifier": verifier,
+		}, nil)
+		var r map[string]any
+		lo.Must0(json.Unmarshal(lo.Must(io.ReadAll(res.Body)), &r))
+		claims := map[string]any{}
+		lo.Must0(lo.Must(jwt.ParseSigned(r["id_token"].(string))).UnsafeClaimsWithoutVerification(&claims))
+		return claims["amr"]
+	}
+
+	// the callback is not available until the second step is completed
+	reqID, verifier := authorize()
+	res := send(http.MethodGet, ts.URL+"/authorize/callback?id="+reqID, false, nil, nil)
+	assert.NotContains(t, res.Header.Get("Location"), "code=")
+
+	assert.Equal(t, "https://web.example.com/login/mfa?error=Invalid+code.&id="+reqID, mfa(reqID, "000000"))
+	assert.Equal(t, "https://example.com/authorize/callback?id="+reqID, mfa(reqID, "123456"))
+	assert.Equal(t, []any{"password", "otp", "mfa"}, amr(reqID, verifier))
+
+	// recovery code
+	reqID, verifier = authorize()
+	assert.Equal(t, "https://example.com/authorize/callback?id="+reqID, mfa(reqID, "recovery"))
+	assert.Equal(t, []any{"password", "mfa"}, amr(reqID, verifier))
+
+	// too many failures
+	reqID, _ = authorize()
+	for i := 0; i < maxMFAAttempts-1; i++ {
+		assert.Equal(t, "https://web.example.com/login/mfa?error=Invalid+code.&id="+reqID, mfa(reqID, "000000"))
+	}
+	assert.Equal(t, "https://web.example.com/login?error=Too+many+failed+attempts.+Please+log+in+again.&id="+reqID, mfa(reqID, "000000"))
+	assert.Equal(t, "https://web.example.com/login?error=Bad+request%21&id="+reqID, mfa(reqID, "123456"))
+
+	// posting the password again does not reset the attempts
+	res = send(http.MethodPost, ts.URL+"/api/login", true, map[string]string{
+		"username": "aaa@example.com",
+		"password": "aaa",
+		"id":       reqID,
+	}, nil)
+	assert.Equal(t, "https://web.example.com/login?error=Too+many+failed+attempts.+Please+log+in+again.&id="+reqID, res.Header.Get("Location"))
+	assert.Equal(t, "https://web.example.com/login?error=Bad+request%21&id="+reqID, mfa(reqID, "123456"))
+
+	reqID, _ = authorize()
+	assert.Equal(t, "https://web.example.com/login/mfa?error=Invalid+code.&id="+reqID, mfa(reqID, "000000"))
+	res = send(http.MethodPost, ts.URL+"/api/login", true, map[string]string{
+		"username": "aaa@example.com",
+		"password": "aaa",
+		"id":       reqID,
+	}, nil)
+	assert.Equal(t, "https://web.example.com/login/mfa?id="+reqID, res.Header.Get("Location"))
+	for i := 1; i < maxMFAAttempts-1; i++ {
+		assert.Equal(t, "https://web.example.com/login/mfa?error=Invalid+code.&id="+reqID, mfa(reqID, "000000"))
+	}
+	assert.Equal(t, "https://web.example.com/login?error=Too+many+failed+attempts.+Please+log+in+again.&id="+reqID, mfa(reqID, "000000"))
+}
+
+func login(t *testing.T, u, clientID, secret string) map[string]any {
+	t.Helper()
+
+	verifier, challenge := randomCodeChallenge()
+	res := send(http.MethodGet, u+"/authorize", false, map[string]string{
+		"response_type":         "code",
+		"client_id":             clientID,
+		"redirect_uri":          "https://web.example.com",
+		"scope":                 "openid email profile offline_access",
+		"code_challenge":        challenge,
+		"code_challenge_method": "S256",
+	}, nil)
+	reqID := lo.Must(url.Parse(res.Header.Get("Location"))).Query().Get("id")
+
+	_ = send(http.MethodPost, u+"/api/login", true, map[string]string{
+		"username": "aaa@example.com",
+		"password": "aaa",
+		"id":       reqID,
+	}, nil)
+
+	res = send(http.MethodGet, u+"/authorize/callback?id="+reqID, false, nil, nil)
+	code := lo.Must(url.Parse(res.Header.Get("Location"))).Query().Get("code")
+
+	var headers map[string]string
+	if secret != "" {
+		headers = map[string]string{
+			"Authorization": "Basic " + base64.StdEncoding.EncodeToString([]byte(clientID+":"+secret)),
+		}
+	}
+	res = send(http.MethodPost, u+"/oauth/token", true, map[string]string{
+		"grant_type":    "authorization_code",
+		"redirect_uri":  "https://web.example.com",
+		"client_id":     clientID,
+		"code":          code,
+		"code_verifier": verifier,
+	}, headers)
+	if res.StatusCode != http.StatusOK {
+		return nil
+	}
+
+	var r map[string]any
+	lo.Must0(json.Unmarshal(lo.Must(io.ReadAll(res.Body)), &r))
+	return r
+}
+
 var httpClient = &http.Client{
 	CheckRedirect: func(req *http.Request, via []*http.Request) error {
 		return http.ErrUseLastResponse
@@ -328,6 +806,9 @@ func TestEndpointHTTPDomain(t *testing.T) {
 type userRepo struct{}
 
 func (*userRepo) Sub(ctx context.Context, email, password, _requestID string) (string, error) {
+	if GetClientIPFromContext(ctx) == "" {
+		return "", errors.New("no client ip")
+	}
 	if email == "aaa@example.com" && password == "aaa" {
 		return "subsub", nil
 	}
@@ -343,6 +824,24 @@ func (*userRepo) Info(ctx context.Context, sub string, _ []string, ui oidc.UserI
 	return errors.New("not found")
 }
 
+type mfaUserRepo struct {
+	userRepo
+}
+
+func (*mfaUserRepo) MFAEnabled(_ context.Context, sub string) (bool, error) {
+	return sub == "subsub", nil
+}
+
+func (*mfaUserRepo) VerifyMFA(_ context.Context, sub, code string) (bool, error) {
+	if sub == "subsub" && code == "123456" {
+		return false, nil
+	}
+	if sub == "subsub" && code == "recovery" {
+		return true, nil
+	}
+	return false, errors.New("invalid code")
+}
+
 type configRepo struct {
 	m sync.Mutex
 	c Config
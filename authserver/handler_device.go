@@ -0,0 +1,163 @@
+package authserver
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/reearth/reearthx/log"
+	"github.com/reearth/reearthx/util"
+	httphelper "github.com/zitadel/oidc/pkg/http"
+	"github.com/zitadel/oidc/pkg/oidc"
+	"github.com/zitadel/oidc/pkg/op"
+)
+
+type DeviceHandlerConfig struct {
+	URL             *url.URL
+	VerificationURL *url.URL
+	Storage         op.Storage
+}
+
+type deviceAuthorizationForm struct {
+	ClientID     string `form:"client_id"`
+	ClientSecret string `form:"client_secret"`
+	Scope        string `form:"scope"`
+}
+
+type deviceAuthorizationResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+// DeviceAuthorizationHandler is the device authorization endpoint of RFC 8628.
+func DeviceAuthorizationHandler(ctx context.Context, cfg DeviceHandlerConfig) echo.HandlerFunc {
+	return func(ec echo.Context) error {
+		s := cfg.Storage.(*Storage)
+		w, r := ec.Response(), ec.Request()
+
+		form := new(deviceAuthorizationForm)
+		if err := ec.Bind(form); err != nil {
+			op.RequestError(w, r, oidc.ErrInvalidRequest().WithDescription("error parsing form").WithParent(err))
+			return nil
+		}
+
+		clientID, err := authorizeDeviceClient(r, s, form.ClientID, form.ClientSecret)
+		if err != nil {
+			op.RequestError(w, r, err)
+			return nil
+		}
+
+		d, err := s.CreateDeviceAuthorization(r.Context(), clientID, strings.Fields(form.Scope))
+		if err != nil {
+			log.Errorfc(ctx, "auth: failed to create a device authorization: %s\n", err)
+			op.RequestError(w, r, err)
+			return nil
+		}
+
+		userCode := FormatUserCode(d.UserCode)
+		return ec.JSON(http.StatusOK, deviceAuthorizationResponse{
+			DeviceCode:              d.DeviceCode,
+			UserCode:                userCode,
+			VerificationURI:         cfg.VerificationURL.String(),
+			VerificationURIComplete: withQuery(cfg.URL, deviceVerificationEndpoint, url.Values{"user_code": {userCode}}),
+			ExpiresIn:               int(s.config.Device.codeLifetime().Seconds()),
+			Interval:                int(s.config.Device.pollInterval().Seconds()),
+		})
+	}
+}
+
+// DeviceVerificationHandler receives the user code entered on the verification page and sends the user to the login page.
+func DeviceVerificationHandler(ctx context.Context, cfg DeviceHandlerConfig) echo.HandlerFunc {
+	return func(ec echo.Context) error {
+		userCode := ec.QueryParam("user_code")
+		if userCode == "" {
+			return ec.Redirect(http.StatusFound, cfg.VerificationURL.String())
+		}
+
+		loginURL, err := cfg.Storage.(*Storage).StartDeviceAuthorization(ec.Request().Context(), userCode)
+		if err != nil {
+			log.Errorfc(ctx, "auth: failed to start the device authorization: %s\n", err)
+			return ec.Redirect(http.StatusFound, withQuery(cfg.VerificationURL, "", url.Values{
+				"user_code": {userCode},
+				"error":     {"Invalid or expired code."},
+			}))
+		}
+
+		return ec.Redirect(http.StatusFound, loginURL)
+	}
+}
+
+// deviceTokenHandler handles token requests of the device_code grant, which the OpenID provider does not support.
+func deviceTokenHandler(storage op.Storage, creator func() op.TokenCreator) func(handler http.Handler) http.Handler {
+	return func(handler http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/oauth/token" || r.FormValue("grant_type") != string(GrantTypeDeviceCode) {
+				handler.ServeHTTP(w, r)
+				return
+			}
+
+			s := storage.(*Storage)
+			ctx := r.Context()
+
+			clientID, err := authorizeDeviceClient(r, s, r.FormValue("client_id"), r.FormValue("client_secret"))
+			if err != nil {
+				op.RequestError(w, r, err)
+				return
+			}
+
+			request, err := s.DeviceTokenRequest(ctx, r.FormValue("device_code"), clientID)
+			if err != nil {
+				op.RequestError(w, r, err)
+				return
+			}
+
+			client, err := s.GetClientByClientID(ctx, clientID)
+			if err != nil {
+				op.RequestError(w, r, oidc.ErrInvalidClient().WithParent(err))
+				return
+			}
+
+			res, err := op.CreateTokenResponse(ctx, request, client, creator(), true, "", "")
+			if err != nil {
+				op.RequestError(w, r, err)
+				return
+			}
+			httphelper.MarshalJSON(w, res)
+		})
+	}
+}
+
+// authorizeDeviceClient authenticates the client with HTTP basic auth or the client secret of the form.
+// Public clients only need to present their ID.
+func authorizeDeviceClient(r *http.Request, s *Storage, clientID, clientSecret string) (string, error) {
+	if id, secret, ok := r.BasicAuth(); ok {
+		clientID, clientSecret = id, secret
+	}
+	if clientID == "" {
+		return "", oidc.ErrInvalidClient().WithDescription("client_id missing")
+	}
+
+	if err := s.AuthorizeClientIDSecret(r.Context(), clientID, clientSecret); err != nil {
+		return "", oidc.ErrInvalidClient().WithDescription("invalid client_id / client_secret").WithParent(err)
+	}
+	return clientID, nil
+}
+
+func withQuery(u *url.URL, p string, query url.Values) string {
+	v := util.CopyURL(u)
+	if p != "" {
+		v.Path = strings.TrimSuffix(v.Path, "/") + "/" + p
+	}
+	q := v.Query()
+	for k, vs := range query {
+		q[k] = vs
+	}
+	v.RawQuery = q.Encode()
+	return v.String()
+}
@@ -15,10 +15,13 @@ import (
 type SubLoader func(ctx context.Context, email, password, authRequestID string) (string, error)
 
 type LoginHandlerConfig struct {
-	SubLoader SubLoader
-	URL       *url.URL
-	WebURL    *url.URL
-	Storage   op.Storage
+	SubLoader             SubLoader
+	URL                   *url.URL
+	WebURL                *url.URL
+	DeviceVerificationURL *url.URL
+	Storage               op.Storage
+	// MFA enables the second login step. It is optional.
+	MFA MFAUserRepo
 }
 
 type loginForm struct {
@@ -27,6 +30,11 @@ type loginForm struct {
 	AuthRequestID string `json:"id" form:"id"`
 }
 
+type mfaLoginForm struct {
+	Code          string `json:"code" form:"code"`
+	AuthRequestID string `json:"id" form:"id"`
+}
+
 func LoginHandler(ctx context.Context, cfg LoginHandlerConfig) func(ctx echo.Context) error {
 	return func(ec echo.Context) error {
 		request := new(loginForm)
@@ -55,7 +63,7 @@ func LoginHandler(ctx context.Context, cfg LoginHandlerConfig) func(ctx echo.Con
 		}
 
 		// check user credentials from db
-		sub, err := cfg.SubLoader(ctx, request.Email, request.Password, request.AuthRequestID)
+		sub, err := cfg.SubLoader(ContextWithClientIP(ctx, ec.RealIP()), request.Email, request.Password, request.AuthRequestID)
 		if err != nil || sub == "" {
 			if err == nil && sub == "" {
 				err = errors.New("empty sub")
@@ -67,20 +75,131 @@ func LoginHandler(ctx context.Context, cfg LoginHandlerConfig) func(ctx echo.Con
 			)
 		}
 
-		// Complete the auth request && set the subject
-		if err := cfg.Storage.(*Storage).CompleteAuthRequest(ctx, request.AuthRequestID, sub); err != nil {
-			log.Errorfc(ctx, "auth: failed to complete the auth request: %s\n", err)
+		if cfg.MFA != nil {
+			enabled, err := cfg.MFA.MFAEnabled(ctx, sub)
+			if err != nil {
+				log.Errorfc(ctx, "auth: failed to check mfa: %s\n", err)
+				return ec.Redirect(
+					http.StatusFound,
+					redirectURL(cfg.WebURL, "/login", request.AuthRequestID, "Bad request!"),
+				)
+			}
+			if enabled {
+				if err := cfg.Storage.(*Storage).StartMFA(ctx, request.AuthRequestID, sub); err != nil {
+					log.Errorfc(ctx, "auth: failed to start mfa: %s\n", err)
+					if errors.Is(err, ErrTooManyMFAAttempts) {
+						return ec.Redirect(
+							http.StatusFound,
+							redirectURL(cfg.WebURL, "/login", request.AuthRequestID, "Too many failed attempts. Please log in again."),
+						)
+					}
+					return ec.Redirect(
+						http.StatusFound,
+						redirectURL(cfg.WebURL, "/login", request.AuthRequestID, "Bad request!"),
+					)
+				}
+				return ec.Redirect(
+					http.StatusFound,
+					redirectURL(cfg.WebURL, "/login/mfa", request.AuthRequestID, ""),
+				)
+			}
+		}
+
+		return completeLogin(ctx, ec, cfg, request.AuthRequestID, sub, nil)
+	}
+}
+
+// MFALoginHandler handles the second login step that verifies a TOTP code or a recovery code.
+func MFALoginHandler(ctx context.Context, cfg LoginHandlerConfig) func(ctx echo.Context) error {
+	return func(ec echo.Context) error {
+		request := new(mfaLoginForm)
+		if err := ec.Bind(request); err != nil {
+			log.Errorc(ctx, "auth: filed to parse mfa login request")
+			return ec.Redirect(
+				http.StatusFound,
+				redirectURL(cfg.WebURL, "/login", "", "Bad request!"),
+			)
+		}
+
+		authRequest, err := cfg.Storage.AuthRequestByID(ctx, request.AuthRequestID)
+		if err != nil || cfg.MFA == nil || authRequest.(*Request).MFASubject() == "" {
+			log.Errorfc(ctx, "auth: invalid mfa login request: %v\n", err)
 			return ec.Redirect(
 				http.StatusFound,
 				redirectURL(cfg.WebURL, "/login", request.AuthRequestID, "Bad request!"),
 			)
 		}
+		sub := authRequest.(*Request).MFASubject()
 
+		recovery, err := cfg.MFA.VerifyMFA(ctx, sub, request.Code)
+		if err != nil {
+			log.Errorfc(ctx, "auth: wrong mfa code: %s\n", err)
+			restart, err := cfg.Storage.(*Storage).FailMFA(ctx, request.AuthRequestID)
+			if err != nil {
+				log.Errorfc(ctx, "auth: failed to update the auth request: %s\n", err)
+			}
+			if restart {
+				return ec.Redirect(
+					http.StatusFound,
+					redirectURL(cfg.WebURL, "/login", request.AuthRequestID, "Too many failed attempts. Please log in again."),
+				)
+			}
+			return ec.Redirect(
+				http.StatusFound,
+				redirectURL(cfg.WebURL, "/login/mfa", request.AuthRequestID, "Invalid code."),
+			)
+		}
+
+		amr := []string{AMRPassword, AMROTP, AMRMFA}
+		if recovery {
+			amr = []string{AMRPassword, AMRMFA}
+		}
+		return completeLogin(ctx, ec, cfg, request.AuthRequestID, sub, amr)
+	}
+}
+
+func completeLogin(ctx context.Context, ec echo.Context, cfg LoginHandlerConfig, requestID, sub string, amr []string) error {
+	// Complete the auth request && set the subject
+	if err := cfg.Storage.(*Storage).CompleteAuthRequestWithAMR(ctx, requestID, sub, amr); err != nil {
+		log.Errorfc(ctx, "auth: failed to complete the auth request: %s\n", err)
+		return ec.Redirect(
+			http.StatusFound,
+			redirectURL(cfg.WebURL, "/login", requestID, "Bad request!"),
+		)
+	}
+
+	// logins for the device authorization grant end on the verification page instead of returning to the client
+	device, err := cfg.Storage.(*Storage).CompleteDeviceAuthorization(ctx, requestID, sub)
+	if err != nil {
+		log.Errorfc(ctx, "auth: failed to complete the device authorization: %s\n", err)
+	}
+	if device {
+		q := url.Values{"success": {"true"}}
+		if err != nil {
+			q = url.Values{"error": {"Invalid or expired code."}}
+		}
+		return ec.Redirect(http.StatusFound, withQuery(deviceVerificationURL(cfg), "", q))
+	}
+	if err != nil {
 		return ec.Redirect(
 			http.StatusFound,
-			redirectURL(cfg.URL, "/authorize/callback", request.AuthRequestID, ""),
+			redirectURL(cfg.WebURL, "/login", requestID, "Bad request!"),
 		)
 	}
+
+	return ec.Redirect(
+		http.StatusFound,
+		redirectURL(cfg.URL, "/authorize/callback", requestID, ""),
+	)
+}
+
+func deviceVerificationURL(cfg LoginHandlerConfig) *url.URL {
+	if cfg.DeviceVerificationURL != nil {
+		return cfg.DeviceVerificationURL
+	}
+	u := util.CopyURL(cfg.WebURL)
+	u.Path = "/device"
+	return u
 }
 
 func redirectURL(u *url.URL, p string, requestID, err string) string {
@@ -1,14 +1,39 @@
 package authserver
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/labstack/echo/v4"
+	"github.com/reearth/reearthx/log"
 )
 
+// SessionTerminator terminates the sessions an ID token was issued for. It is implemented by Storage.
+type SessionTerminator interface {
+	TerminateSessionByIDToken(ctx context.Context, idToken string) error
+}
+
+type SessionLogoutHandlerConfig struct {
+	Sessions SessionTerminator
+}
+
 func LogoutHandler() echo.HandlerFunc {
 	return func(ec echo.Context) error {
 		u := ec.QueryParam("returnTo")
 		return ec.Redirect(http.StatusTemporaryRedirect, u)
 	}
 }
+
+// SessionLogoutHandler works like LogoutHandler, and also terminates the sessions of the ID token given as id_token_hint.
+func SessionLogoutHandler(ctx context.Context, cfg SessionLogoutHandlerConfig) echo.HandlerFunc {
+	logout := LogoutHandler()
+	return func(ec echo.Context) error {
+		if hint := ec.QueryParam("id_token_hint"); hint != "" && cfg.Sessions != nil {
+			// logging out should always succeed from the user's point of view
+			if err := cfg.Sessions.TerminateSessionByIDToken(ec.Request().Context(), hint); err != nil {
+				log.Errorfc(ctx, "auth: failed to terminate the session: %s\n", err)
+			}
+		}
+		return logout(ec)
+	}
+}
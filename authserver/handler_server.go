@@ -20,16 +20,19 @@ type ServerConfig struct {
 }
 
 func Server(ctx context.Context, cfg ServerConfig) (*mux.Router, error) {
+	var handler op.OpenIDProvider
 	handler, err := op.NewOpenIDProvider(
 		ctx,
 		&op.Config{
 			Issuer:                cfg.Issuer,
 			CryptoKey:             sha256.Sum256([]byte(cfg.Key)),
 			GrantTypeRefreshToken: true,
+			AuthMethodPost:        true,
 		},
 		cfg.Storage,
 		op.WithHttpInterceptors(jsonToFormHandler()),
 		op.WithHttpInterceptors(setURLVarsHandler()),
+		op.WithHttpInterceptors(deviceTokenHandler(cfg.Storage, func() op.TokenCreator { return handler })),
 		op.WithCustomEndSessionEndpoint(op.NewEndpoint(logoutEndpoint)),
 		op.WithCustomKeysEndpoint(op.NewEndpoint(jwksEndpoint)),
 	)
@@ -13,14 +13,46 @@ import (
 	"gopkg.in/square/go-jose.v2"
 )
 
-func initKeys(keyBytes, certBytes []byte) (*rsa.PrivateKey, *jose.SigningKey, *jose.JSONWebKeySet, error) {
+// legacyKeyID is the key ID of the key stored in Config.Key and Config.Cert.
+const legacyKeyID = "RE01"
+
+// Key is a signing key. It is published in the JWKS from its creation until it is retired,
+// and is used for signing from ActivatedAt if it is the newest active key.
+type Key struct {
+	ID          string
+	PrivateKey  *rsa.PrivateKey
+	Certificate *x509.Certificate
+	ActivatedAt time.Time
+	RetiredAt   *time.Time
+}
+
+func (k *Key) Active(now time.Time) bool {
+	return !k.ActivatedAt.After(now) && !k.Retired(now)
+}
+
+func (k *Key) Retired(now time.Time) bool {
+	return k.RetiredAt != nil && !k.RetiredAt.After(now)
+}
+
+func (k *Key) SigningKey() jose.SigningKey {
+	return jose.SigningKey{
+		Algorithm: jose.RS256,
+		Key:       jose.JSONWebKey{Key: k.PrivateKey, Use: "sig", Algorithm: string(jose.RS256), KeyID: k.ID, Certificates: []*x509.Certificate{k.Certificate}},
+	}
+}
+
+func (k *Key) PublicKey() jose.JSONWebKey {
+	return jose.JSONWebKey{Key: k.PrivateKey.Public(), Use: "sig", Algorithm: string(jose.RS256), KeyID: k.ID, Certificates: []*x509.Certificate{k.Certificate}}
+}
+
+func parseKey(id string, keyBytes, certBytes []byte) (*Key, error) {
 	keyBlock, _ := pem.Decode(keyBytes)
 	if keyBlock == nil {
-		return nil, nil, nil, fmt.Errorf("failed to decode the key bytes")
+		return nil, fmt.Errorf("failed to decode the key bytes")
 	}
 	key, err := x509.ParsePKCS1PrivateKey(keyBlock.Bytes)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("failed to parse the private key bytes: %w", err)
+		return nil, fmt.Errorf("failed to parse the private key bytes: %w", err)
 	}
 
 	var certActualBytes []byte
@@ -34,22 +66,28 @@ func initKeys(keyBytes, certBytes []byte) (*rsa.PrivateKey, *jose.SigningKey, *j
 	var cert *x509.Certificate
 	cert, err = x509.ParseCertificate(certActualBytes)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("failed to parse the cert bytes: %w", err)
+		return nil, fmt.Errorf("failed to parse the cert bytes: %w", err)
 	}
 
-	keyID := "RE01"
-	sk := jose.SigningKey{
-		Algorithm: jose.RS256,
-		Key:       jose.JSONWebKey{Key: key, Use: "sig", Algorithm: string(jose.RS256), KeyID: keyID, Certificates: []*x509.Certificate{cert}},
-	}
-
-	return key, &sk, &jose.JSONWebKeySet{
-		Keys: []jose.JSONWebKey{
-			{Key: key.Public(), Use: "sig", Algorithm: string(jose.RS256), KeyID: keyID, Certificates: []*x509.Certificate{cert}},
-		},
+	return &Key{
+		ID:          id,
+		PrivateKey:  key,
+		Certificate: cert,
 	}, nil
 }
 
+func encodeKey(k *Key) (keyPem, certPem []byte) {
+	keyPem = pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(k.PrivateKey),
+	})
+	certPem = pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: k.Certificate.Raw,
+	})
+	return
+}
+
 func generateCert(name pkix.Name) (keyPem, certPem []byte, err error) {
 	key, err := rsa.GenerateKey(rand.Reader, 2048)
 	if err != nil {
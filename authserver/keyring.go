@@ -0,0 +1,196 @@
+package authserver
+
+import (
+	"crypto/x509/pkix"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gopkg.in/square/go-jose.v2"
+)
+
+const (
+	defaultKeyRotationCheckInterval = 10 * time.Minute
+	defaultKeyRetentionPeriod       = 7 * 24 * time.Hour
+)
+
+type KeyRotationConfig struct {
+	// Interval is how often a new signing key is generated. Zero disables rotation.
+	Interval time.Duration
+	// ActivationDelay is how long a new key is published in the JWKS before it is used for signing,
+	// so that every server instance and relying party knows the key before tokens signed with it appear.
+	// Defaults to twice the CheckInterval.
+	ActivationDelay time.Duration
+	// RetentionPeriod is how long a replaced key stays in the JWKS. It should exceed the longest token lifetime.
+	RetentionPeriod time.Duration
+	// CheckInterval is how often keys are reloaded from the ConfigRepo and checked for rotation.
+	CheckInterval time.Duration
+}
+
+func (c KeyRotationConfig) normalize() KeyRotationConfig {
+	if c.CheckInterval <= 0 {
+		c.CheckInterval = defaultKeyRotationCheckInterval
+	}
+	if c.ActivationDelay <= 0 {
+		c.ActivationDelay = 2 * c.CheckInterval
+	}
+	if c.RetentionPeriod <= 0 {
+		c.RetentionPeriod = defaultKeyRetentionPeriod
+	}
+	return c
+}
+
+// KeyRing holds the signing keys of the server. It is safe for concurrent use.
+type KeyRing struct {
+	lock    sync.RWMutex
+	keys    []*Key
+	changed chan struct{}
+}
+
+func NewKeyRing(keys ...*Key) *KeyRing {
+	return &KeyRing{
+		keys:    sortKeys(keys),
+		changed: make(chan struct{}),
+	}
+}
+
+func (r *KeyRing) Keys() []*Key {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return append([]*Key{}, r.keys...)
+}
+
+// Set replaces all keys and notifies the listeners of Changed.
+func (r *KeyRing) Set(keys []*Key) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.keys = sortKeys(keys)
+	close(r.changed)
+	r.changed = make(chan struct{})
+}
+
+// Changed returns a channel that is closed when the keys are replaced next time.
+func (r *KeyRing) Changed() <-chan struct{} {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.changed
+}
+
+// SigningKey returns the newest active key.
+func (r *KeyRing) SigningKey(now time.Time) *Key {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	for i := len(r.keys) - 1; i >= 0; i-- {
+		if r.keys[i].Active(now) {
+			return r.keys[i]
+		}
+	}
+	return nil
+}
+
+// KeySet returns the public keys of all keys that are not retired yet, including the ones not activated yet.
+func (r *KeyRing) KeySet(now time.Time) *jose.JSONWebKeySet {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	ks := &jose.JSONWebKeySet{}
+	for i := len(r.keys) - 1; i >= 0; i-- {
+		if !r.keys[i].Retired(now) {
+			ks.Keys = append(ks.Keys, r.keys[i].PublicKey())
+		}
+	}
+	return ks
+}
+
+// Rotate drops retired keys and, if the newest key is older than the rotation interval,
+// generates a new key and schedules the retirement of the others. It reports whether the keys changed.
+func (r *KeyRing) Rotate(now time.Time, cfg KeyRotationConfig, name pkix.Name) (bool, error) {
+	if cfg.Interval <= 0 {
+		return false, nil
+	}
+	cfg = cfg.normalize()
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	keys := make([]*Key, 0, len(r.keys)+1)
+	for _, k := range r.keys {
+		if !k.Retired(now) {
+			keys = append(keys, k)
+		}
+	}
+	changed := len(keys) != len(r.keys)
+
+	if len(keys) == 0 || !now.Before(keys[len(keys)-1].ActivatedAt.Add(cfg.Interval)) {
+		keyPem, certPem, err := generateCert(name)
+		if err != nil {
+			return false, fmt.Errorf("could not generate raw cert: %w", err)
+		}
+		k, err := parseKey(uuid.NewString(), keyPem, certPem)
+		if err != nil {
+			return false, err
+		}
+		k.ActivatedAt = now.Add(cfg.ActivationDelay)
+
+		retiredAt := k.ActivatedAt.Add(cfg.RetentionPeriod)
+		for _, o := range keys {
+			if o.RetiredAt == nil {
+				o.RetiredAt = &retiredAt
+			}
+		}
+		keys = append(keys, k)
+		changed = true
+	}
+
+	if changed {
+		r.keys = keys
+		close(r.changed)
+		r.changed = make(chan struct{})
+	}
+	return changed, nil
+}
+
+func sortKeys(keys []*Key) []*Key {
+	keys = append([]*Key{}, keys...)
+	sort.SliceStable(keys, func(i, j int) bool {
+		return keys[i].ActivatedAt.Before(keys[j].ActivatedAt)
+	})
+	return keys
+}
+
+func keysFromConfig(c *Config) ([]*Key, error) {
+	if len(c.Keys) == 0 {
+		k, err := parseKey(legacyKeyID, []byte(c.Key), []byte(c.Cert))
+		if err != nil {
+			return nil, err
+		}
+		return []*Key{k}, nil
+	}
+
+	keys := make([]*Key, 0, len(c.Keys))
+	for _, ck := range c.Keys {
+		k, err := parseKey(ck.ID, []byte(ck.Key), []byte(ck.Cert))
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", ck.ID, err)
+		}
+		k.ActivatedAt = ck.ActivatedAt
+		k.RetiredAt = ck.RetiredAt
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+func (c *Config) setKeys(keys []*Key) {
+	c.Keys = make([]ConfigKey, 0, len(keys))
+	for _, k := range keys {
+		keyPem, certPem := encodeKey(k)
+		c.Keys = append(c.Keys, ConfigKey{
+			ID:          k.ID,
+			Key:         string(keyPem),
+			Cert:        string(certPem),
+			ActivatedAt: k.ActivatedAt,
+			RetiredAt:   k.RetiredAt,
+		})
+	}
+}
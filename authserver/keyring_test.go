@@ -0,0 +1,102 @@
+package authserver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/square/go-jose.v2"
+)
+
+func TestKeyRing(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	k1 := testKey(t, "k1", time.Time{}, nil)
+	k2 := testKey(t, "k2", now.Add(time.Hour), nil)
+	k3 := testKey(t, "k3", time.Time{}, lo.ToPtr(now))
+
+	r := NewKeyRing(k2, k1, k3)
+	assert.Same(t, k1, r.SigningKey(now))
+	assert.Same(t, k2, r.SigningKey(now.Add(time.Hour)))
+	assert.Equal(t, []string{"k2", "k1"}, lo.Map(r.KeySet(now).Keys, func(k jose.JSONWebKey, _ int) string {
+		return k.KeyID
+	}))
+}
+
+func TestKeyRing_Rotate(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cfg := KeyRotationConfig{
+		Interval:        24 * time.Hour,
+		ActivationDelay: time.Hour,
+		RetentionPeriod: 48 * time.Hour,
+	}
+	k1 := testKey(t, "k1", time.Time{}, nil)
+	r := NewKeyRing(k1)
+	changed := r.Changed()
+
+	// disabled
+	ok, err := r.Rotate(now, KeyRotationConfig{}, dummyName)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = r.Rotate(now, cfg, dummyName)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Len(t, r.Keys(), 2)
+	assert.Equal(t, lo.ToPtr(now.Add(49*time.Hour)), k1.RetiredAt)
+	k2 := r.Keys()[1]
+	assert.Equal(t, now.Add(time.Hour), k2.ActivatedAt)
+	select {
+	case <-changed:
+	default:
+		assert.Fail(t, "not notified")
+	}
+
+	// the new key is published but not used yet
+	assert.Same(t, k1, r.SigningKey(now))
+	assert.Len(t, r.KeySet(now).Keys, 2)
+	assert.Same(t, k2, r.SigningKey(now.Add(time.Hour)))
+
+	// the newest key is not old enough
+	ok, err = r.Rotate(now.Add(2*time.Hour), cfg, dummyName)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// retired keys are dropped
+	ok, err = r.Rotate(now.Add(50*time.Hour), cfg, dummyName)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []string{k2.ID, r.Keys()[1].ID}, lo.Map(r.Keys(), func(k *Key, _ int) string { return k.ID }))
+}
+
+func TestKeysFromConfig(t *testing.T) {
+	keyPem, certPem := lo.Must2(generateCert(dummyName))
+	c := &Config{Key: string(keyPem), Cert: string(certPem)}
+
+	keys, err := keysFromConfig(c)
+	assert.NoError(t, err)
+	assert.Len(t, keys, 1)
+	assert.Equal(t, legacyKeyID, keys[0].ID)
+
+	retiredAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	keys[0].RetiredAt = &retiredAt
+	c.setKeys(append(keys, testKey(t, "k2", retiredAt, nil)))
+	assert.Equal(t, string(keyPem), c.Key)
+	assert.Len(t, c.Keys, 2)
+
+	keys2, err := keysFromConfig(c)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{legacyKeyID, "k2"}, lo.Map(keys2, func(k *Key, _ int) string { return k.ID }))
+	assert.Equal(t, keys[0].PrivateKey, keys2[0].PrivateKey)
+	assert.Equal(t, &retiredAt, keys2[0].RetiredAt)
+	assert.Equal(t, retiredAt, keys2[1].ActivatedAt)
+}
+
+func testKey(t *testing.T, id string, activatedAt time.Time, retiredAt *time.Time) *Key {
+	t.Helper()
+	keyPem, certPem := lo.Must2(generateCert(dummyName))
+	k := lo.Must(parseKey(id, keyPem, certPem))
+	k.ActivatedAt = activatedAt
+	k.RetiredAt = retiredAt
+	return k
+}
@@ -80,6 +80,9 @@ type mongoDocument struct {
 	Nonce         string                      `bson:"nonce"`
 	CodeChallenge *mongoCodeChallengeDocument `bson:"codechallenge"`
 	AuthorizedAt  *time.Time                  `bson:"authorizedat"`
+	AMR           []string                    `bson:"amr,omitempty"`
+	MFASubject    string                      `bson:"mfasubject,omitempty"`
+	MFAAttempts   int                         `bson:"mfaattempts,omitempty"`
 }
 
 type mongoCodeChallengeDocument struct {
@@ -112,6 +115,9 @@ func newMongoDocument(req *Request) (*mongoDocument, string) {
 		Nonce:         req.GetNonce(),
 		CodeChallenge: cc,
 		AuthorizedAt:  req.AuthorizedAt(),
+		AMR:           req.amr,
+		MFASubject:    req.MFASubject(),
+		MFAAttempts:   req.MFAAttempts(),
 	}, reqID
 }
 
@@ -146,6 +152,8 @@ func (d *mongoDocument) Model() (*Request, error) {
 		Nonce(d.Nonce).
 		CodeChallenge(cc).
 		AuthorizedAt(d.AuthorizedAt).
+		AMR(d.AMR).
+		MFA(d.MFASubject, d.MFAAttempts).
 		MustBuild()
 	return req, nil
 }
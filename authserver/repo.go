@@ -2,6 +2,7 @@ package authserver
 
 import (
 	"context"
+	"time"
 
 	"github.com/zitadel/oidc/pkg/oidc"
 )
@@ -11,6 +12,13 @@ type UserRepo interface {
 	Info(context.Context, string, []string, oidc.UserInfoSetter) error
 }
 
+// MFAUserRepo can be implemented by a UserRepo to require a second login step with a TOTP code for users who enabled it.
+type MFAUserRepo interface {
+	MFAEnabled(ctx context.Context, sub string) (bool, error)
+	// VerifyMFA checks a TOTP code or a recovery code and reports whether a recovery code was used.
+	VerifyMFA(ctx context.Context, sub, code string) (bool, error)
+}
+
 type RequestRepo interface {
 	FindByID(context.Context, RequestID) (*Request, error)
 	FindByCode(context.Context, string) (*Request, error)
@@ -22,6 +30,17 @@ type RequestRepo interface {
 type Config struct {
 	Cert string
 	Key  string
+	// Keys holds the signing keys once keys have been rotated. Cert and Key are kept as they are
+	// for backward compatibility and are ignored while Keys is not empty.
+	Keys []ConfigKey
+}
+
+type ConfigKey struct {
+	ID          string
+	Cert        string
+	Key         string
+	ActivatedAt time.Time
+	RetiredAt   *time.Time
 }
 
 type ConfigRepo interface {
@@ -29,3 +48,28 @@ type ConfigRepo interface {
 	Save(context.Context, *Config) error
 	Unlock(context.Context) error
 }
+
+type SessionRepo interface {
+	FindByID(context.Context, string) (*Session, error)
+	FindByTokenID(context.Context, string) (*Session, error)
+	FindBySubject(context.Context, string) ([]*Session, error)
+	Save(context.Context, *Session) error
+	// Rotate saves the session only when its stored refresh token ID is still the given one.
+	// It returns ErrRefreshTokenRotated when the token has already been rotated or the session was terminated.
+	Rotate(context.Context, *Session, string) error
+}
+
+type ClientRepo interface {
+	FindByID(context.Context, string) (*Client, error)
+	FindAll(context.Context) ([]*Client, error)
+	Save(context.Context, *Client) error
+	Remove(context.Context, string) error
+}
+
+type DeviceAuthorizationRepo interface {
+	FindByDeviceCode(context.Context, string) (*DeviceAuthorization, error)
+	FindByUserCode(context.Context, string) (*DeviceAuthorization, error)
+	FindByRequestID(context.Context, string) (*DeviceAuthorization, error)
+	Save(context.Context, *DeviceAuthorization) error
+	Remove(context.Context, string) error
+}
@@ -1,6 +1,7 @@
 package authserver
 
 import (
+	"errors"
 	"time"
 
 	"github.com/reearth/reearthx/idx"
@@ -9,6 +10,17 @@ import (
 
 var essentialScopes = []string{"openid", "profile", "email"}
 
+// authentication method references (RFC 8176) set on the amr claim
+const (
+	AMRPassword = "password"
+	AMROTP      = "otp"
+	AMRMFA      = "mfa"
+)
+
+const maxMFAAttempts = 5
+
+var ErrTooManyMFAAttempts = errors.New("too many mfa attempts")
+
 type RequestIDType struct{}
 
 func (a RequestIDType) Type() string {
@@ -38,6 +50,9 @@ type Request struct {
 	nonce         string
 	codeChallenge *oidc.CodeChallenge
 	authorizedAt  *time.Time
+	amr           []string
+	mfaSubject    string
+	mfaAttempts   int
 }
 
 func (a *Request) ID() RequestID {
@@ -53,8 +68,11 @@ func (a *Request) GetACR() string {
 }
 
 func (a *Request) GetAMR() []string {
+	if len(a.amr) > 0 {
+		return a.amr
+	}
 	return []string{
-		"password",
+		AMRPassword,
 	}
 }
 
@@ -137,6 +155,41 @@ func (a *Request) Complete(sub string) {
 	a.authorizedAt = &now
 }
 
+// CompleteWithAMR completes the request recording how the user was authenticated.
+func (a *Request) CompleteWithAMR(sub string, amr []string) {
+	a.Complete(sub)
+	a.amr = amr
+	a.mfaSubject = ""
+	a.mfaAttempts = 0
+}
+
+// MFASubject returns the subject who passed the first login step and is waiting for the second factor.
+func (a *Request) MFASubject() string {
+	return a.mfaSubject
+}
+
+func (a *Request) MFAAttempts() int {
+	return a.mfaAttempts
+}
+
+// MFAExhausted reports whether the request has used up its attempts of the second factor.
+func (a *Request) MFAExhausted() bool {
+	return a.mfaAttempts >= maxMFAAttempts
+}
+
+// StartMFA keeps the failed attempts of the request so that posting the password again does not grant more guesses.
+func (a *Request) StartMFA(sub string) {
+	a.mfaSubject = sub
+}
+
+// FailMFA counts a failed second factor. After too many failures the request can no longer be completed.
+func (a *Request) FailMFA() {
+	a.mfaAttempts++
+	if a.MFAExhausted() {
+		a.mfaSubject = ""
+	}
+}
+
 func (a *Request) SetCode(code string) {
 	a.code = code
 }
@@ -94,3 +94,14 @@ func (b *RequestBuilder) AuthorizedAt(authorizedAt *time.Time) *RequestBuilder {
 	b.r.authorizedAt = authorizedAt
 	return b
 }
+
+func (b *RequestBuilder) AMR(amr []string) *RequestBuilder {
+	b.r.amr = amr
+	return b
+}
+
+func (b *RequestBuilder) MFA(subject string, attempts int) *RequestBuilder {
+	b.r.mfaSubject = subject
+	b.r.mfaAttempts = attempts
+	return b
+}
@@ -0,0 +1,50 @@
+package authserver
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrSessionTerminated   = errors.New("session terminated")
+	ErrRefreshTokenRotated = errors.New("refresh token already rotated")
+)
+
+// Session tracks the tokens issued for a single login so that they can be revoked together.
+// Its ID is the auth_id carried by refresh tokens across rotations.
+type Session struct {
+	ID             string
+	Subject        string
+	ClientID       string
+	RefreshTokenID string
+	AccessTokenID  string
+	ExpiresAt      time.Time
+	TerminatedAt   *time.Time
+}
+
+func (s *Session) Terminated() bool {
+	return s != nil && s.TerminatedAt != nil
+}
+
+func (s *Session) Terminate(now time.Time) {
+	if s == nil || s.TerminatedAt != nil {
+		return
+	}
+	s.TerminatedAt = &now
+}
+
+func (s *Session) clone() *Session {
+	if s == nil {
+		return nil
+	}
+	c := *s
+	if s.TerminatedAt != nil {
+		t := *s.TerminatedAt
+		c.TerminatedAt = &t
+	}
+	return &c
+}
+
+func (s *Session) HasToken(tokenID string) bool {
+	return s != nil && tokenID != "" && (s.RefreshTokenID == tokenID || s.AccessTokenID == tokenID)
+}
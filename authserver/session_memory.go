@@ -0,0 +1,99 @@
+package authserver
+
+import (
+	"context"
+	"sync"
+
+	"github.com/reearth/reearthx/rerror"
+	"github.com/reearth/reearthx/util"
+)
+
+// SessionMemory keeps sessions until they expire like the TTL index of SessionMongo does.
+// Sessions are stored and returned as copies, so callers have to save the sessions they modify.
+type SessionMemory struct {
+	lock     sync.Mutex
+	sessions util.ExpiringMap[string, Session]
+	// tokens maps the token IDs of the sessions to their session IDs
+	tokens util.ExpiringMap[string, string]
+}
+
+var _ SessionRepo = (*SessionMemory)(nil)
+
+func NewSessionMemory() *SessionMemory {
+	return &SessionMemory{}
+}
+
+func (r *SessionMemory) FindByID(_ context.Context, id string) (*Session, error) {
+	s, ok := r.sessions.Load(id)
+	if !ok {
+		return nil, rerror.ErrNotFound
+	}
+	return s.clone(), nil
+}
+
+func (r *SessionMemory) FindByTokenID(_ context.Context, tokenID string) (*Session, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	id, ok := r.tokens.Load(tokenID)
+	if !ok {
+		return nil, rerror.ErrNotFound
+	}
+	s, ok := r.sessions.Load(id)
+	if !ok || !s.HasToken(tokenID) {
+		return nil, rerror.ErrNotFound
+	}
+	return s.clone(), nil
+}
+
+func (r *SessionMemory) FindBySubject(_ context.Context, subject string) ([]*Session, error) {
+	var res []*Session
+	r.sessions.Range(func(_ string, s Session) bool {
+		if s.Subject == subject {
+			res = append(res, s.clone())
+		}
+		return true
+	})
+	return res, nil
+}
+
+func (r *SessionMemory) Save(_ context.Context, session *Session) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.save(*session.clone())
+	return nil
+}
+
+func (r *SessionMemory) Rotate(_ context.Context, session *Session, refreshTokenID string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	cur, ok := r.sessions.Load(session.ID)
+	if !ok {
+		return rerror.ErrNotFound
+	}
+	if cur.Terminated() || cur.RefreshTokenID != refreshTokenID {
+		return ErrRefreshTokenRotated
+	}
+	r.save(*session.clone())
+	return nil
+}
+
+// save stores the session and replaces its token IDs in the index. The lock must be held.
+func (r *SessionMemory) save(s Session) {
+	if prev, ok := r.sessions.Load(s.ID); ok {
+		for _, t := range []string{prev.RefreshTokenID, prev.AccessTokenID} {
+			if t != "" && !s.HasToken(t) {
+				r.tokens.Delete(t)
+			}
+		}
+	}
+
+	r.sessions.Store(s.ID, s, s.ExpiresAt)
+	for _, t := range []string{s.RefreshTokenID, s.AccessTokenID} {
+		if t != "" {
+			r.tokens.Store(t, s.ID, s.ExpiresAt)
+		}
+	}
+}
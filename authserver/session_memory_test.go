@@ -0,0 +1,121 @@
+package authserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/reearth/reearthx/rerror"
+	"github.com/reearth/reearthx/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSessionMemory(t *testing.T) {
+	assert.Equal(t, &SessionMemory{}, NewSessionMemory())
+}
+
+func TestSessionMemory_FindByID(t *testing.T) {
+	ctx := context.Background()
+	s := &Session{ID: "a"}
+	m := NewSessionMemory()
+
+	got, err := m.FindByID(ctx, "a")
+	assert.Nil(t, got)
+	assert.Same(t, rerror.ErrNotFound, err)
+
+	assert.NoError(t, m.Save(ctx, s))
+	got, err = m.FindByID(ctx, "a")
+	assert.Equal(t, s, got)
+	assert.NotSame(t, s, got)
+	assert.NoError(t, err)
+}
+
+func TestSessionMemory_FindByTokenID(t *testing.T) {
+	ctx := context.Background()
+	s := &Session{ID: "a", RefreshTokenID: "r", AccessTokenID: "t"}
+	m := NewSessionMemory()
+	assert.NoError(t, m.Save(ctx, s))
+
+	got, err := m.FindByTokenID(ctx, "r")
+	assert.Equal(t, s, got)
+	assert.NoError(t, err)
+
+	got, err = m.FindByTokenID(ctx, "t")
+	assert.Equal(t, s, got)
+	assert.NoError(t, err)
+
+	got, err = m.FindByTokenID(ctx, "x")
+	assert.Nil(t, got)
+	assert.Same(t, rerror.ErrNotFound, err)
+
+	// replaced tokens are removed from the index
+	assert.NoError(t, m.Save(ctx, &Session{ID: "a", RefreshTokenID: "r2", AccessTokenID: "t"}))
+	got, err = m.FindByTokenID(ctx, "r")
+	assert.Nil(t, got)
+	assert.Same(t, rerror.ErrNotFound, err)
+	got, err = m.FindByTokenID(ctx, "r2")
+	assert.Equal(t, "a", got.ID)
+	assert.NoError(t, err)
+}
+
+func TestSessionMemory_FindBySubject(t *testing.T) {
+	ctx := context.Background()
+	s1 := &Session{ID: "a", Subject: "sub"}
+	s2 := &Session{ID: "b", Subject: "sub2"}
+	m := NewSessionMemory()
+	assert.NoError(t, m.Save(ctx, s1))
+	assert.NoError(t, m.Save(ctx, s2))
+
+	got, err := m.FindBySubject(ctx, "sub")
+	assert.Equal(t, []*Session{s1}, got)
+	assert.NoError(t, err)
+}
+
+func TestSessionMemory_Save(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	defer util.MockNow(now)()
+
+	s := &Session{ID: "a", AccessTokenID: "t", ExpiresAt: now.Add(time.Hour)}
+	m := NewSessionMemory()
+	assert.NoError(t, m.Save(ctx, s))
+
+	// changes are not visible until the session is saved
+	s.Terminate(now)
+	got, _ := m.FindByID(ctx, "a")
+	assert.False(t, got.Terminated())
+	got.Terminate(now)
+	got, _ = m.FindByID(ctx, "a")
+	assert.False(t, got.Terminated())
+
+	// sessions are dropped once they expire
+	defer util.MockNow(now.Add(time.Hour))()
+	got, err := m.FindByID(ctx, "a")
+	assert.Nil(t, got)
+	assert.Same(t, rerror.ErrNotFound, err)
+	got, err = m.FindByTokenID(ctx, "t")
+	assert.Nil(t, got)
+	assert.Same(t, rerror.ErrNotFound, err)
+}
+
+func TestSessionMemory_Rotate(t *testing.T) {
+	ctx := context.Background()
+	m := NewSessionMemory()
+	assert.NoError(t, m.Save(ctx, &Session{ID: "a", RefreshTokenID: "r"}))
+
+	assert.Same(t, rerror.ErrNotFound, m.Rotate(ctx, &Session{ID: "b"}, "r"))
+
+	s := &Session{ID: "a", RefreshTokenID: "r2"}
+	assert.NoError(t, m.Rotate(ctx, s, "r"))
+	got, _ := m.FindByID(ctx, "a")
+	assert.Equal(t, s, got)
+
+	// the same token cannot be rotated twice
+	assert.Same(t, ErrRefreshTokenRotated, m.Rotate(ctx, &Session{ID: "a", RefreshTokenID: "r3"}, "r"))
+	got, _ = m.FindByID(ctx, "a")
+	assert.Equal(t, s, got)
+
+	s.Terminate(time.Now())
+	assert.NoError(t, m.Save(ctx, s))
+	assert.Same(t, ErrRefreshTokenRotated, m.Rotate(ctx, &Session{ID: "a", RefreshTokenID: "r3"}, "r2"))
+}
@@ -0,0 +1,135 @@
+package authserver
+
+import (
+	"context"
+	"time"
+
+	"github.com/reearth/reearthx/log"
+	"github.com/reearth/reearthx/mongox"
+	"github.com/reearth/reearthx/rerror"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type SessionMongo struct {
+	client *mongox.Collection
+}
+
+var _ SessionRepo = (*SessionMongo)(nil)
+
+func NewSessionMongo(client *mongox.Collection) *SessionMongo {
+	return &SessionMongo{client: client}
+}
+
+func (r *SessionMongo) Init(ctx context.Context) error {
+	res, err := r.client.Indexes2(
+		ctx,
+		append(
+			mongox.IndexFromKeys([]string{"subject", "refreshtokenid", "accesstokenid"}, false),
+			mongox.IndexFromKey("id", true),
+			// sessions are dropped once all of their tokens have expired
+			mongox.TTLIndexFromKey("expiresat", 0),
+		)...,
+	)
+	if err != nil {
+		return err
+	}
+	added, updated, deleted := res.AddedNames(), res.UpdatedNames(), res.DeletedNames()
+	if len(added) > 0 || len(updated) > 0 || len(deleted) > 0 {
+		log.Infofc(ctx, "mongo: authSession: index: deleted: %v, updated: %v, created: %v", deleted, updated, added)
+	}
+	return nil
+}
+
+func (r *SessionMongo) FindByID(ctx context.Context, id string) (*Session, error) {
+	return r.findOne(ctx, bson.M{"id": id})
+}
+
+func (r *SessionMongo) FindByTokenID(ctx context.Context, tokenID string) (*Session, error) {
+	return r.findOne(ctx, bson.M{"$or": []bson.M{
+		{"refreshtokenid": tokenID},
+		{"accesstokenid": tokenID},
+	}})
+}
+
+func (r *SessionMongo) FindBySubject(ctx context.Context, subject string) ([]*Session, error) {
+	c := newSessionMongoConsumer()
+	if err := r.client.Find(ctx, bson.M{"subject": subject}, c); err != nil {
+		return nil, err
+	}
+	return c.Result, nil
+}
+
+func (r *SessionMongo) Save(ctx context.Context, session *Session) error {
+	doc, id := newSessionMongoDocument(session)
+	return r.client.SaveOne(ctx, id, doc)
+}
+
+func (r *SessionMongo) Rotate(ctx context.Context, session *Session, refreshTokenID string) error {
+	doc, id := newSessionMongoDocument(session)
+	res, err := r.client.Client().ReplaceOne(ctx, bson.M{
+		"id":             id,
+		"refreshtokenid": refreshTokenID,
+		"terminatedat":   nil,
+	}, doc)
+	if err != nil {
+		return rerror.ErrInternalBy(err)
+	}
+	if res.MatchedCount == 0 {
+		return ErrRefreshTokenRotated
+	}
+	return nil
+}
+
+func (r *SessionMongo) findOne(ctx context.Context, filter any) (*Session, error) {
+	c := newSessionMongoConsumer()
+	if err := r.client.FindOne(ctx, filter, c); err != nil {
+		return nil, err
+	}
+	return c.Result[0], nil
+}
+
+func newSessionMongoConsumer() *mongox.SliceFuncConsumer[*sessionMongoDocument, *Session] {
+	return mongox.NewSliceFuncConsumer(func(d *sessionMongoDocument) (*Session, error) {
+		return d.Model(), nil
+	})
+}
+
+type sessionMongoDocument struct {
+	ID             string     `bson:"id"`
+	Subject        string     `bson:"subject"`
+	ClientID       string     `bson:"clientid"`
+	RefreshTokenID string     `bson:"refreshtokenid,omitempty"`
+	AccessTokenID  string     `bson:"accesstokenid,omitempty"`
+	ExpiresAt      time.Time  `bson:"expiresat"`
+	TerminatedAt   *time.Time `bson:"terminatedat"`
+}
+
+func newSessionMongoDocument(s *Session) (*sessionMongoDocument, string) {
+	if s == nil {
+		return nil, ""
+	}
+	return &sessionMongoDocument{
+		ID:             s.ID,
+		Subject:        s.Subject,
+		ClientID:       s.ClientID,
+		RefreshTokenID: s.RefreshTokenID,
+		AccessTokenID:  s.AccessTokenID,
+		ExpiresAt:      s.ExpiresAt,
+		TerminatedAt:   s.TerminatedAt,
+	}, s.ID
+}
+
+func (d *sessionMongoDocument) Model() *Session {
+	if d == nil {
+		return nil
+	}
+	return &Session{
+		ID:             d.ID,
+		Subject:        d.Subject,
+		ClientID:       d.ClientID,
+		RefreshTokenID: d.RefreshTokenID,
+		AccessTokenID:  d.AccessTokenID,
+		ExpiresAt:      d.ExpiresAt,
+		TerminatedAt:   d.TerminatedAt,
+	}
+}
@@ -0,0 +1,133 @@
+package authserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/reearth/reearthx/mongox"
+	"github.com/reearth/reearthx/mongox/mongotest"
+	"github.com/reearth/reearthx/rerror"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestSessionMongo_FindByID(t *testing.T) {
+	c := mongotest.Connect(t)(t)
+	col := c.Collection("auth_session")
+	m := NewSessionMongo(mongox.NewCollection(col))
+	ctx := context.Background()
+
+	got, err := m.FindByID(ctx, "a")
+	assert.Nil(t, got)
+	assert.Same(t, rerror.ErrNotFound, err)
+
+	_, _ = col.InsertOne(ctx, bson.M{
+		"id":      "a",
+		"subject": "sub",
+	})
+
+	got, err = m.FindByID(ctx, "a")
+	assert.Equal(t, &Session{ID: "a", Subject: "sub"}, got)
+	assert.NoError(t, err)
+}
+
+func TestSessionMongo_FindByTokenID(t *testing.T) {
+	c := mongotest.Connect(t)(t)
+	col := c.Collection("auth_session")
+	m := NewSessionMongo(mongox.NewCollection(col))
+	ctx := context.Background()
+
+	_, _ = col.InsertOne(ctx, bson.M{
+		"id":             "a",
+		"refreshtokenid": "r",
+		"accesstokenid":  "t",
+	})
+
+	got, err := m.FindByTokenID(ctx, "r")
+	assert.Equal(t, "a", got.ID)
+	assert.NoError(t, err)
+
+	got, err = m.FindByTokenID(ctx, "t")
+	assert.Equal(t, "a", got.ID)
+	assert.NoError(t, err)
+
+	got, err = m.FindByTokenID(ctx, "x")
+	assert.Nil(t, got)
+	assert.Same(t, rerror.ErrNotFound, err)
+}
+
+func TestSessionMongo_FindBySubject(t *testing.T) {
+	c := mongotest.Connect(t)(t)
+	col := c.Collection("auth_session")
+	m := NewSessionMongo(mongox.NewCollection(col))
+	ctx := context.Background()
+
+	_, _ = col.InsertMany(ctx, []any{
+		bson.M{"id": "a", "subject": "sub"},
+		bson.M{"id": "b", "subject": "sub2"},
+	})
+
+	got, err := m.FindBySubject(ctx, "sub")
+	assert.Equal(t, []*Session{{ID: "a", Subject: "sub"}}, got)
+	assert.NoError(t, err)
+}
+
+func TestSessionMongo_Save(t *testing.T) {
+	c := mongotest.Connect(t)(t)
+	col := c.Collection("auth_session")
+	m := NewSessionMongo(mongox.NewCollection(col))
+	ctx := context.Background()
+
+	exp := time.Now().Truncate(time.Millisecond).UTC()
+	s := &Session{
+		ID:             "a",
+		Subject:        "sub",
+		ClientID:       "client",
+		RefreshTokenID: "r",
+		AccessTokenID:  "t",
+		ExpiresAt:      exp,
+	}
+	s.Terminate(exp)
+
+	assert.NoError(t, m.Save(ctx, s))
+	var data bson.M
+	assert.NoError(t, col.FindOne(ctx, bson.M{"id": "a"}).Decode(&data))
+	assert.Equal(t, bson.M{
+		"_id":            data["_id"],
+		"id":             "a",
+		"subject":        "sub",
+		"clientid":       "client",
+		"refreshtokenid": "r",
+		"accesstokenid":  "t",
+		"expiresat":      primitive.NewDateTimeFromTime(exp),
+		"terminatedat":   primitive.NewDateTimeFromTime(exp),
+	}, data)
+}
+
+func TestSessionMongo_Rotate(t *testing.T) {
+	c := mongotest.Connect(t)(t)
+	col := c.Collection("auth_session")
+	m := NewSessionMongo(mongox.NewCollection(col))
+	ctx := context.Background()
+
+	_, _ = col.InsertOne(ctx, bson.M{
+		"id":             "a",
+		"refreshtokenid": "r",
+	})
+
+	assert.NoError(t, m.Rotate(ctx, &Session{ID: "a", RefreshTokenID: "r2"}, "r"))
+	got, err := m.FindByID(ctx, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, "r2", got.RefreshTokenID)
+
+	// the same token cannot be rotated twice
+	assert.Same(t, ErrRefreshTokenRotated, m.Rotate(ctx, &Session{ID: "a", RefreshTokenID: "r3"}, "r"))
+	got, _ = m.FindByID(ctx, "a")
+	assert.Equal(t, "r2", got.RefreshTokenID)
+
+	got.Terminate(time.Now())
+	assert.NoError(t, m.Save(ctx, got))
+	assert.Same(t, ErrRefreshTokenRotated, m.Rotate(ctx, &Session{ID: "a", RefreshTokenID: "r3"}, "r2"))
+}
@@ -2,7 +2,6 @@ package authserver
 
 import (
 	"context"
-	"crypto/rsa"
 	"crypto/x509/pkix"
 	"errors"
 	"fmt"
@@ -10,6 +9,8 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/reearth/reearthx/log"
+	"github.com/reearth/reearthx/rerror"
+	"github.com/samber/lo"
 	"github.com/zitadel/oidc/pkg/crypto"
 	"github.com/zitadel/oidc/pkg/oidc"
 	"github.com/zitadel/oidc/pkg/op"
@@ -20,10 +21,12 @@ type Storage struct {
 	config         StorageConfig
 	userInfoSetter UserInfoProvider
 	clients        map[string]op.Client
+	clientRepo     ClientRepo
+	deviceRepo     DeviceAuthorizationRepo
 	requestRepo    RequestRepo
-	keySet         jose.JSONWebKeySet
-	key            *rsa.PrivateKey
-	sigKey         jose.SigningKey
+	sessionRepo    SessionRepo
+	keys           *KeyRing
+	name           pkix.Name
 	issuer         string
 }
 
@@ -39,9 +42,14 @@ type StorageConfig struct {
 	DN              *DNConfig
 	ConfigRepo      ConfigRepo
 	RequestRepo     RequestRepo
+	ClientRepo      ClientRepo
+	SessionRepo     SessionRepo
+	DeviceRepo      DeviceAuthorizationRepo
+	Device          DeviceFlowConfig
 	UserInfoSetter  UserInfoProvider
 	AudienceForTest string
 	Issuer          string
+	KeyRotation     *KeyRotationConfig
 }
 
 type DNConfig struct {
@@ -112,36 +120,117 @@ func NewStorage(ctx context.Context, cfg StorageConfig) (op.Storage, error) {
 		log.Infoc(ctx, "auth: init a new private key and certificate")
 	}
 
-	key, sigKey, keySet, err := initKeys(keyBytes, certBytes)
+	keys, err := keysFromConfig(c)
 	if err != nil {
 		return nil, fmt.Errorf("could not init keys: %w", err)
 	}
+	keyRing := NewKeyRing(keys...)
 
-	return &Storage{
+	if cfg.KeyRotation != nil {
+		if err := rotateKeys(ctx, keyRing, c, *cfg.KeyRotation, name, cfg.ConfigRepo); err != nil {
+			return nil, err
+		}
+	}
+
+	sessionRepo := cfg.SessionRepo
+	if sessionRepo == nil {
+		sessionRepo = NewSessionMemory()
+	}
+
+	deviceRepo := cfg.DeviceRepo
+	if deviceRepo == nil {
+		deviceRepo = NewDeviceAuthorizationMemory()
+	}
+
+	s := &Storage{
 		config:         cfg,
 		userInfoSetter: cfg.UserInfoSetter,
 		requestRepo:    cfg.RequestRepo,
-		key:            key,
-		sigKey:         *sigKey,
-		keySet:         *keySet,
+		sessionRepo:    sessionRepo,
+		clientRepo:     cfg.ClientRepo,
+		deviceRepo:     deviceRepo,
+		keys:           keyRing,
+		name:           name,
 		clients: map[string]op.Client{
 			client.GetID(): client,
 		},
 		issuer: cfg.Issuer,
-	}, nil
+	}
+
+	if cfg.KeyRotation != nil && cfg.KeyRotation.Interval > 0 {
+		go s.runKeyRotation(ctx, cfg.KeyRotation.normalize())
+	}
+
+	return s, nil
 }
 
-func (s *Storage) Health(_ context.Context) error {
+func (s *Storage) runKeyRotation(ctx context.Context, cfg KeyRotationConfig) {
+	ticker := time.NewTicker(cfg.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.reloadKeys(ctx, cfg); err != nil {
+				log.Errorfc(ctx, "auth: failed to rotate keys: %s\n", err)
+			}
+		}
+	}
+}
+
+// reloadKeys loads the keys saved by any server instance, rotates them if needed and applies them.
+func (s *Storage) reloadKeys(ctx context.Context, cfg KeyRotationConfig) error {
+	c, err := s.config.ConfigRepo.Load(ctx)
+	if err != nil {
+		return fmt.Errorf("could not load auth config: %w", err)
+	}
+	defer func() {
+		if err := s.config.ConfigRepo.Unlock(ctx); err != nil {
+			log.Errorfc(ctx, "auth: could not release config lock: %s\n", err)
+		}
+	}()
+	if c == nil {
+		return errors.New("auth config not found")
+	}
+
+	keys, err := keysFromConfig(c)
+	if err != nil {
+		return err
+	}
+	keyRing := NewKeyRing(keys...)
+	if err := rotateKeys(ctx, keyRing, c, cfg, s.name, s.config.ConfigRepo); err != nil {
+		return err
+	}
+
+	s.keys.Set(keyRing.Keys())
 	return nil
 }
 
-func (s *Storage) CreateAuthRequest(ctx context.Context, authReq *oidc.AuthRequest, _ string) (op.AuthRequest, error) {
-	audiences := []string{
-		s.config.Domain,
+func rotateKeys(ctx context.Context, keyRing *KeyRing, c *Config, cfg KeyRotationConfig, name pkix.Name, repo ConfigRepo) error {
+	changed, err := keyRing.Rotate(time.Now().UTC(), cfg, name)
+	if err != nil {
+		return fmt.Errorf("could not rotate keys: %w", err)
 	}
-	if s.config.Dev && s.config.AudienceForTest != "" {
-		audiences = append(audiences, s.config.AudienceForTest)
+	if !changed {
+		return nil
+	}
+
+	c.setKeys(keyRing.Keys())
+	if err := repo.Save(ctx, c); err != nil {
+		return fmt.Errorf("could not save keys: %w", err)
 	}
+	log.Infoc(ctx, "auth: signing keys rotated")
+	return nil
+}
+
+func (s *Storage) Health(_ context.Context) error {
+	return nil
+}
+
+func (s *Storage) CreateAuthRequest(ctx context.Context, authReq *oidc.AuthRequest, _ string) (op.AuthRequest, error) {
+	audiences := s.audiences()
 
 	var cc *oidc.CodeChallenge
 	if authReq.CodeChallenge != "" {
@@ -169,6 +258,16 @@ func (s *Storage) CreateAuthRequest(ctx context.Context, authReq *oidc.AuthReque
 	return request, nil
 }
 
+func (s *Storage) audiences() []string {
+	audiences := []string{
+		s.config.Domain,
+	}
+	if s.config.Dev && s.config.AudienceForTest != "" {
+		audiences = append(audiences, s.config.AudienceForTest)
+	}
+	return audiences
+}
+
 func (s *Storage) AuthRequestByID(ctx context.Context, requestID string) (op.AuthRequest, error) {
 	if requestID == "" {
 		return nil, errors.New("invalid id")
@@ -217,22 +316,65 @@ func (s *Storage) DeleteAuthRequest(ctx context.Context, requestID string) error
 	return s.requestRepo.Remove(ctx, reqId)
 }
 
-func (s *Storage) CreateAccessToken(_ context.Context, _ op.TokenRequest) (string, time.Time, error) {
-	return uuid.NewString(), time.Now().UTC().Add(5 * time.Hour), nil
+func (s *Storage) CreateAccessToken(ctx context.Context, request op.TokenRequest) (string, time.Time, error) {
+	var clientID string
+	var client op.Client
+	if r, ok := request.(interface{ GetClientID() string }); ok {
+		clientID = r.GetClientID()
+		client, _ = s.GetClientByClientID(ctx, clientID)
+	}
+
+	accessTokenID := uuid.NewString()
+	expiration := time.Now().UTC().Add(accessTokenLifetime(client, defaultAccessTokenLifetime))
+
+	if err := s.sessionRepo.Save(ctx, &Session{
+		ID:            uuid.NewString(),
+		Subject:       request.GetSubject(),
+		ClientID:      clientID,
+		AccessTokenID: accessTokenID,
+		ExpiresAt:     expiration,
+	}); err != nil {
+		return "", time.Time{}, err
+	}
+
+	return accessTokenID, expiration, nil
 }
 
-func (s *Storage) CreateAccessAndRefreshTokens(ctx context.Context, request op.TokenRequest, refreshToken string) (accessTokenID string, newRefreshToken string, expiration time.Time, err error) {
-	keyCh := make(chan jose.SigningKey, 1)
-	s.GetSigningKey(ctx, keyCh)
-	signer := op.NewSigner(ctx, s, keyCh)
+// ClientCredentialsTokenRequest implements op.ClientCredentialsStorage, which enables the client_credentials grant.
+func (s *Storage) ClientCredentialsTokenRequest(ctx context.Context, clientID string, scopes []string) (op.TokenRequest, error) {
+	client, err := s.GetClientByClientID(ctx, clientID)
+	if err != nil {
+		return nil, oidc.ErrInvalidClient().WithParent(err)
+	}
+	// public clients can not authenticate themselves
+	if client.AuthMethod() == oidc.AuthMethodNone {
+		return nil, oidc.ErrUnauthorizedClient().WithDescription("client_credentials grant requires a confidential client")
+	}
 
+	return &clientCredentialsRequest{
+		clientID:  clientID,
+		scopes:    lo.Filter(scopes, func(s string, _ int) bool { return client.IsScopeAllowed(s) }),
+		audiences: s.audiences(),
+	}, nil
+}
+
+func (s *Storage) CreateAccessAndRefreshTokens(ctx context.Context, request op.TokenRequest, refreshToken string) (accessTokenID string, newRefreshToken string, expiration time.Time, err error) {
 	now := time.Now().UTC()
-	refreshTokenExpiresAt := now.Add(24 * time.Hour)
+	key := s.keys.SigningKey(now)
+	if key == nil {
+		err = errors.New("no signing key")
+		return
+	}
+	signer, err := jose.NewSigner(key.SigningKey(), &jose.SignerOptions{})
+	if err != nil {
+		return
+	}
 
 	var client op.Client
 	var authTime time.Time
 	var authID string
 	var amr []string
+	var prevRefreshTokenID string
 	switch authReq := request.(type) {
 	case *Request:
 		client, _ = s.GetClientByClientID(ctx, authReq.GetClientID())
@@ -244,8 +386,10 @@ func (s *Storage) CreateAccessAndRefreshTokens(ctx context.Context, request op.T
 		authID = authReq.AuthID
 		authTime = authReq.GetAuthTime()
 		amr = authReq.GetAMR()
+		prevRefreshTokenID = authReq.JWTID
 	}
 
+	refreshTokenExpiresAt := now.Add(refreshTokenLifetime(client))
 	audience := request.GetAudience()
 	skew := client.ClockSkew()
 	if len(audience) == 0 {
@@ -265,56 +409,221 @@ func (s *Storage) CreateAccessAndRefreshTokens(ctx context.Context, request op.T
 		AuthTime:  oidc.Time(authTime),
 	}
 
-	newRefreshToken, err = crypto.Sign(claims, signer.Signer())
+	newRefreshToken, err = crypto.Sign(claims, signer)
+	if err != nil {
+		return
+	}
+
+	found, err := s.sessionRepo.FindByID(ctx, authID)
+	if errors.Is(err, rerror.ErrNotFound) {
+		found, err = nil, nil
+	}
 	if err != nil {
 		return
 	}
-	return uuid.NewString(), newRefreshToken, now.Add(5 * time.Minute), nil
+	if found.Terminated() {
+		err = ErrSessionTerminated
+		return
+	}
+
+	accessTokenID = uuid.NewString()
+	session := &Session{ID: authID}
+	if found != nil {
+		// the found session is not modified so that the rotation below can be compared with the stored one
+		*session = *found
+	}
+	session.Subject = claims.Subject
+	session.ClientID = claims.ClientID
+	session.RefreshTokenID = claims.JWTID
+	session.AccessTokenID = accessTokenID
+	session.ExpiresAt = refreshTokenExpiresAt
+
+	// refresh tokens issued before sessions were tracked have no session to rotate
+	if found == nil || prevRefreshTokenID == "" {
+		err = s.sessionRepo.Save(ctx, session)
+	} else {
+		err = s.sessionRepo.Rotate(ctx, session, prevRefreshTokenID)
+	}
+	if errors.Is(err, ErrRefreshTokenRotated) {
+		s.revokeReusedSession(ctx, authID)
+	}
+	if err != nil {
+		return
+	}
+
+	return accessTokenID, newRefreshToken, now.Add(accessTokenLifetime(client, defaultRefreshedAccessTokenLifetime)), nil
 }
 
 func (s *Storage) TokenRequestByRefreshToken(ctx context.Context, refreshToken string) (op.RefreshTokenRequest, error) {
-	v := op.NewAccessTokenVerifier(s.issuer, keySet{&s.keySet})
+	v := op.NewAccessTokenVerifier(s.issuer, keySet{s.keys})
 	claims, err := s.verifyRefreshToken(ctx, refreshToken, v)
 	if err != nil {
 		return nil, fmt.Errorf("invalid refresh token: %w", err)
 	}
+
+	session, err := s.sessionRepo.FindByID(ctx, claims.AuthID)
+	if err != nil && !errors.Is(err, rerror.ErrNotFound) {
+		return nil, err
+	}
+	// refresh tokens issued before sessions were tracked have no session
+	if session != nil {
+		if session.Terminated() {
+			return nil, fmt.Errorf("invalid refresh token: %w", ErrSessionTerminated)
+		}
+		if session.RefreshTokenID != claims.JWTID {
+			s.revokeReusedSession(ctx, session.ID)
+			return nil, fmt.Errorf("invalid refresh token: %w", ErrRefreshTokenRotated)
+		}
+	}
 	return claims, nil
 }
 
-func (s *Storage) TerminateSession(_ context.Context, _, _ string) error {
+// revokeReusedSession terminates the session whose rotated refresh token was used again,
+// since either the legitimate client or an attacker holds a leaked token.
+func (s *Storage) revokeReusedSession(ctx context.Context, authID string) {
+	session, err := s.sessionRepo.FindByID(ctx, authID)
+	if err != nil || session.Terminated() {
+		return
+	}
+	session.Terminate(time.Now().UTC())
+	if err := s.sessionRepo.Save(ctx, session); err != nil {
+		log.Errorfc(ctx, "auth: failed to revoke session %s after refresh token reuse: %v", authID, err)
+	}
+}
+
+// GetRefreshTokenInfo implements op.CanRefreshTokenInfo so that refresh tokens can be revoked by their ID.
+func (s *Storage) GetRefreshTokenInfo(ctx context.Context, clientID string, token string) (string, string, error) {
+	v := op.NewAccessTokenVerifier(s.issuer, keySet{s.keys})
+	claims, err := s.verifyRefreshToken(ctx, token, v)
+	if err != nil || claims.AuthID == "" || claims.ClientID != clientID {
+		return "", "", op.ErrInvalidRefreshToken
+	}
+	return claims.Subject, claims.JWTID, nil
+}
+
+func (s *Storage) TerminateSession(ctx context.Context, userID, clientID string) error {
+	if userID == "" {
+		return nil
+	}
+
+	sessions, err := s.sessionRepo.FindBySubject(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	for _, session := range sessions {
+		if session.Terminated() || (clientID != "" && session.ClientID != clientID) {
+			continue
+		}
+		session.Terminate(now)
+		if err := s.sessionRepo.Save(ctx, session); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-func (s *Storage) GetSigningKey(_ context.Context, keyCh chan<- jose.SigningKey) {
-	keyCh <- s.sigKey
+// TerminateSessionByIDToken terminates the sessions of the subject and client the ID token was issued for.
+// Expired ID tokens are accepted, as clients commonly hold one that has already expired when logging out.
+func (s *Storage) TerminateSessionByIDToken(ctx context.Context, idToken string) error {
+	claims := oidc.EmptyIDTokenClaims()
+	decrypted, err := oidc.DecryptToken(idToken)
+	if err != nil {
+		return fmt.Errorf("invalid id token: %w", err)
+	}
+	payload, err := oidc.ParseToken(decrypted, claims)
+	if err != nil {
+		return fmt.Errorf("invalid id token: %w", err)
+	}
+	if err := oidc.CheckIssuer(claims, s.issuer); err != nil {
+		return fmt.Errorf("invalid id token: %w", err)
+	}
+	if err := oidc.CheckSignature(ctx, decrypted, payload, claims, nil, keySet{s.keys}); err != nil {
+		return fmt.Errorf("invalid id token: %w", err)
+	}
+	return s.TerminateSession(ctx, claims.GetSubject(), claims.GetAuthorizedParty())
+}
+
+// GetSigningKey sends the current signing key and keeps sending a new one each time the signing key changes until ctx is done.
+func (s *Storage) GetSigningKey(ctx context.Context, keyCh chan<- jose.SigningKey) {
+	var current string
+	for {
+		changed := s.keys.Changed()
+		if k := s.keys.SigningKey(time.Now().UTC()); k != nil && k.ID != current {
+			current = k.ID
+			select {
+			case <-ctx.Done():
+				return
+			case keyCh <- k.SigningKey():
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-changed:
+		case <-time.After(signingKeyCheckInterval): // pending keys are activated by time
+		}
+	}
 }
 
 func (s *Storage) GetKeySet(_ context.Context) (*jose.JSONWebKeySet, error) {
-	return &s.keySet, nil
+	return s.keys.KeySet(time.Now().UTC()), nil
 }
 
 func (s *Storage) GetKeyByIDAndUserID(_ context.Context, kid, _ string) (*jose.JSONWebKey, error) {
-	return &s.keySet.Key(kid)[0], nil
+	keys := s.keys.KeySet(time.Now().UTC()).Key(kid)
+	if len(keys) == 0 {
+		return nil, errors.New("key not found")
+	}
+	return &keys[0], nil
 }
 
-func (s *Storage) GetClientByClientID(_ context.Context, clientID string) (op.Client, error) {
+func (s *Storage) GetClientByClientID(ctx context.Context, clientID string) (op.Client, error) {
 	if clientID == "" {
 		return nil, errors.New("invalid client id")
 	}
 
-	client, exists := s.clients[clientID]
-	if !exists {
+	if client, exists := s.clients[clientID]; exists {
+		return client, nil
+	}
+
+	if s.clientRepo == nil {
 		return nil, errors.New("not found")
 	}
 
+	client, err := s.clientRepo.FindByID(ctx, clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	if client.LoginURI() == "" {
+		// registered clients share the login page of the default client unless they have their own
+		c := *client
+		c.loginURI = s.config.ClientDomain + "/login?id=%s"
+		client = &c
+	}
 	return client, nil
 }
 
-func (s *Storage) AuthorizeClientIDSecret(_ context.Context, _ string, _ string) error {
-	return nil
+func (s *Storage) AuthorizeClientIDSecret(ctx context.Context, clientID string, clientSecret string) error {
+	client, err := s.GetClientByClientID(ctx, clientID)
+	if err != nil {
+		return err
+	}
+
+	c, ok := client.(*Client)
+	if !ok {
+		return ErrInvalidClientSecret
+	}
+	return c.VerifySecret(clientSecret)
 }
 
-func (s *Storage) SetUserinfoFromToken(ctx context.Context, userinfo oidc.UserInfoSetter, _tokenID, subject, _origin string) error {
+func (s *Storage) SetUserinfoFromToken(ctx context.Context, userinfo oidc.UserInfoSetter, tokenID, subject, _origin string) error {
+	if err := s.checkToken(ctx, tokenID); err != nil {
+		return err
+	}
 	userinfo.SetSubject(subject)
 	return s.userInfoSetter(ctx, subject, nil, userinfo)
 }
@@ -331,7 +640,10 @@ func (s *Storage) GetPrivateClaimsFromScopes(_ context.Context, _, _ string, _ [
 	return nil, nil
 }
 
-func (s *Storage) SetIntrospectionFromToken(ctx context.Context, introspect oidc.IntrospectionResponse, _, subject, clientID string) error {
+func (s *Storage) SetIntrospectionFromToken(ctx context.Context, introspect oidc.IntrospectionResponse, tokenID, subject, clientID string) error {
+	if err := s.checkToken(ctx, tokenID); err != nil {
+		return err
+	}
 	if err := s.SetUserinfoFromScopes(ctx, introspect, subject, clientID, []string{}); err != nil {
 		return err
 	}
@@ -347,21 +659,69 @@ func (s *Storage) ValidateJWTProfileScopes(_ context.Context, _ string, scope []
 	return scope, nil
 }
 
-func (s *Storage) RevokeToken(_ context.Context, _ string, _ string, _ string) *oidc.Error {
+func (s *Storage) RevokeToken(ctx context.Context, tokenID string, _ string, clientID string) *oidc.Error {
+	session, err := s.sessionRepo.FindByTokenID(ctx, tokenID)
+	if errors.Is(err, rerror.ErrNotFound) {
+		// RFC 7009: invalid or unknown tokens do not cause an error
+		return nil
+	}
+	if err != nil {
+		return oidc.ErrServerError().WithParent(err)
+	}
+	if session.ClientID != "" && session.ClientID != clientID {
+		return oidc.ErrInvalidClient().WithDescription("token was not issued to the client")
+	}
+	if session.Terminated() {
+		return nil
+	}
+
+	session.Terminate(time.Now().UTC())
+	if err := s.sessionRepo.Save(ctx, session); err != nil {
+		return oidc.ErrServerError().WithParent(err)
+	}
 	return nil
 }
 
 func (s *Storage) CompleteAuthRequest(ctx context.Context, requestId, sub string) error {
+	return s.CompleteAuthRequestWithAMR(ctx, requestId, sub, nil)
+}
+
+func (s *Storage) CompleteAuthRequestWithAMR(ctx context.Context, requestId, sub string, amr []string) error {
 	request, err := s.AuthRequestByID(ctx, requestId)
 	if err != nil {
 		return err
 	}
 	req := request.(*Request)
-	req.Complete(sub)
+	req.CompleteWithAMR(sub, amr)
 	err = s.updateRequest(ctx, requestId, *req)
 	return err
 }
 
+// StartMFA records that the subject passed the password step of the request and has to enter the second factor.
+func (s *Storage) StartMFA(ctx context.Context, requestId, sub string) error {
+	request, err := s.AuthRequestByID(ctx, requestId)
+	if err != nil {
+		return err
+	}
+	req := request.(*Request)
+	if req.MFAExhausted() {
+		return ErrTooManyMFAAttempts
+	}
+	req.StartMFA(sub)
+	return s.updateRequest(ctx, requestId, *req)
+}
+
+// FailMFA counts a failed second factor of the request. It reports true if the request can no longer be completed.
+func (s *Storage) FailMFA(ctx context.Context, requestId string) (bool, error) {
+	request, err := s.AuthRequestByID(ctx, requestId)
+	if err != nil {
+		return false, err
+	}
+	req := request.(*Request)
+	req.FailMFA()
+	return req.MFASubject() == "", s.updateRequest(ctx, requestId, *req)
+}
+
 func (s *Storage) updateRequest(ctx context.Context, requestID string, req Request) error {
 	if requestID == "" {
 		return errors.New("invalid id")
@@ -382,6 +742,20 @@ func (s *Storage) updateRequest(ctx context.Context, requestID string, req Reque
 	return nil
 }
 
+func (s *Storage) checkToken(ctx context.Context, tokenID string) error {
+	session, err := s.sessionRepo.FindByTokenID(ctx, tokenID)
+	if errors.Is(err, rerror.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if session.Terminated() {
+		return ErrSessionTerminated
+	}
+	return nil
+}
+
 func (s *Storage) verifyRefreshToken(ctx context.Context, token string, v op.AccessTokenVerifier) (*refreshTokenClaims, error) {
 	claims := new(refreshTokenClaims)
 	decrypted, err := oidc.DecryptToken(token)
@@ -404,13 +778,34 @@ func (s *Storage) verifyRefreshToken(ctx context.Context, token string, v op.Acc
 	return claims, nil
 }
 
+const (
+	signingKeyCheckInterval             = time.Minute
+	defaultAccessTokenLifetime          = 5 * time.Hour
+	defaultRefreshedAccessTokenLifetime = 5 * time.Minute
+	defaultRefreshTokenLifetime         = 24 * time.Hour
+)
+
+func accessTokenLifetime(client op.Client, def time.Duration) time.Duration {
+	if c, ok := client.(*Client); ok && c.AccessTokenLifetime() > 0 {
+		return c.AccessTokenLifetime()
+	}
+	return def
+}
+
+func refreshTokenLifetime(client op.Client) time.Duration {
+	if c, ok := client.(*Client); ok && c.RefreshTokenLifetime() > 0 {
+		return c.RefreshTokenLifetime()
+	}
+	return defaultRefreshTokenLifetime
+}
+
 type keySet struct {
-	keySet *jose.JSONWebKeySet
+	keys *KeyRing
 }
 
 func (k keySet) VerifySignature(ctx context.Context, jws *jose.JSONWebSignature) (payload []byte, err error) {
 	keyID, alg := oidc.GetKeyIDAndAlg(jws)
-	key, err := oidc.FindMatchingKey(keyID, oidc.KeyUseSignature, alg, k.keySet.Keys...)
+	key, err := oidc.FindMatchingKey(keyID, oidc.KeyUseSignature, alg, k.keys.KeySet(time.Now().UTC()).Keys...)
 	if err != nil {
 		return nil, fmt.Errorf("invalid signature: %w", err)
 	}
@@ -0,0 +1,55 @@
+package client
+
+import (
+	"time"
+
+	"github.com/reearth/reearthx/util"
+)
+
+// decisionCache caches decisions per user. A nil cache caches nothing.
+type decisionCache struct {
+	ttl       time.Duration
+	decisions util.ExpiringMap[decisionKey, bool]
+}
+
+type decisionKey struct {
+	user       string
+	service    string
+	permission Permission
+}
+
+func newDecisionCache(ttl time.Duration) *decisionCache {
+	return &decisionCache{ttl: ttl}
+}
+
+func (c *decisionCache) get(userId, service string, perm Permission) (bool, bool) {
+	// decisions of unknown users are not cached since the user is identified by the token
+	if c == nil || userId == "" {
+		return false, false
+	}
+	return c.decisions.Load(decisionKey{user: userId, service: service, permission: perm})
+}
+
+func (c *decisionCache) set(userId, service string, perm Permission, allowed bool) {
+	if c == nil || userId == "" || c.ttl <= 0 {
+		return
+	}
+	// users who are not seen again are dropped by the map once their decisions expire
+	c.decisions.Store(decisionKey{user: userId, service: service, permission: perm}, allowed, util.Now().Add(c.ttl))
+}
+
+func (c *decisionCache) invalidateUser(userId string) {
+	if c == nil {
+		return
+	}
+	c.decisions.DeleteFunc(func(k decisionKey, _ bool) bool {
+		return k.user == userId
+	})
+}
+
+func (c *decisionCache) invalidateAll() {
+	if c == nil {
+		return
+	}
+	c.decisions.Clear()
+}
@@ -0,0 +1,39 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/reearth/reearthx/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecisionCache(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	defer util.MockNow(now)()
+
+	c := newDecisionCache(time.Minute)
+	perm := Permission{Resource: "project", Action: "read"}
+	c.set("a", "flow", perm, true)
+	c.set("b", "flow", perm, false)
+
+	allowed, ok := c.get("a", "flow", perm)
+	assert.True(t, ok)
+	assert.True(t, allowed)
+	allowed, ok = c.get("b", "flow", perm)
+	assert.True(t, ok)
+	assert.False(t, allowed)
+	_, ok = c.get("a", "cms", perm)
+	assert.False(t, ok)
+
+	c.invalidateUser("a")
+	_, ok = c.get("a", "flow", perm)
+	assert.False(t, ok)
+	_, ok = c.get("b", "flow", perm)
+	assert.True(t, ok)
+
+	// decisions expire after the TTL
+	defer util.MockNow(now.Add(time.Minute))()
+	_, ok = c.get("b", "flow", perm)
+	assert.False(t, ok)
+}
@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/reearth/reearthx/appx"
@@ -13,6 +15,19 @@ import (
 
 const defaultRequestTimeout = 30 * time.Second
 
+// ErrUnavailable is wrapped by errors that are returned when the dashboard cannot be reached or fails.
+var ErrUnavailable = errors.New("dashboard is unavailable")
+
+type unavailableError struct{ err error }
+
+func (e unavailableError) Error() string {
+	return e.err.Error()
+}
+
+func (e unavailableError) Unwrap() []error {
+	return []error{ErrUnavailable, e.err}
+}
+
 const (
 	checkPermissionQuery = `
         query CheckPermission($input: CheckPermissionInput!) {
@@ -22,6 +37,8 @@ const (
         }
     `
 	graphqlPath = "/api/graphql"
+	// batchAliasPrefix prefixes aliases of checkPermission fields in a batch query
+	batchAliasPrefix = "c"
 )
 
 type Client struct {
@@ -54,6 +71,15 @@ type CheckPermissionResponse struct {
 	} `json:"errors"`
 }
 
+type CheckPermissionsResponse struct {
+	Data map[string]struct {
+		Allowed bool `json:"allowed"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
 type GraphQLQuery struct {
 	Query     string      `json:"query"`
 	Variables interface{} `json:"variables"`
@@ -72,6 +98,23 @@ func (c *Client) CheckPermission(ctx context.Context, authInfo *appx.AuthInfo, i
 	return c.executeRequest(req)
 }
 
+// CheckPermissions checks all inputs in one request. The results are in the same order as inputs.
+func (c *Client) CheckPermissions(ctx context.Context, authInfo *appx.AuthInfo, inputs []CheckPermissionInput) ([]bool, error) {
+	if err := c.validateInput(authInfo); err != nil {
+		return nil, err
+	}
+	if len(inputs) == 0 {
+		return nil, nil
+	}
+
+	req, err := c.createBatchRequest(ctx, authInfo, inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.executeBatchRequest(req, len(inputs))
+}
+
 func (c *Client) validateInput(authInfo *appx.AuthInfo) error {
 	if authInfo == nil {
 		return fmt.Errorf("auth info is required")
@@ -101,6 +144,47 @@ func (c *Client) createRequest(ctx context.Context, authInfo *appx.AuthInfo, inp
 	return req, nil
 }
 
+// createBatchRequest builds a query that has an aliased checkPermission field for each input.
+func (c *Client) createBatchRequest(ctx context.Context, authInfo *appx.AuthInfo, inputs []CheckPermissionInput) (*http.Request, error) {
+	gqlRequest := GraphQLQuery{
+		Query:     checkPermissionsQuery(len(inputs)),
+		Variables: batchVariables(inputs),
+	}
+
+	requestBody, err := json.Marshal(gqlRequest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.dashboardURL+graphqlPath, bytes.NewBuffer(requestBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	c.setHeaders(req, authInfo)
+	return req, nil
+}
+
+func checkPermissionsQuery(n int) string {
+	var params, fields strings.Builder
+	for i := range n {
+		if i > 0 {
+			params.WriteString(", ")
+		}
+		fmt.Fprintf(&params, "$input%d: CheckPermissionInput!", i)
+		fmt.Fprintf(&fields, "\n            %s%d: checkPermission(input: $input%d) { allowed }", batchAliasPrefix, i, i)
+	}
+	return fmt.Sprintf("\n        query CheckPermissions(%s) {%s\n        }\n    ", params.String(), fields.String())
+}
+
+func batchVariables(inputs []CheckPermissionInput) map[string]interface{} {
+	res := make(map[string]interface{}, len(inputs))
+	for i, input := range inputs {
+		res[fmt.Sprintf("input%d", i)] = input
+	}
+	return res
+}
+
 func (c *Client) setHeaders(req *http.Request, authInfo *appx.AuthInfo) {
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", authInfo.Token))
 	req.Header.Set("Content-Type", "application/json")
@@ -132,3 +216,37 @@ func (c *Client) executeRequest(req *http.Request) (bool, error) {
 
 	return false, nil
 }
+
+func (c *Client) executeBatchRequest(req *http.Request, n int) ([]bool, error) {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, unavailableError{fmt.Errorf("failed to send request: %w", err)}
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return nil, unavailableError{fmt.Errorf("server returned non-OK status: %d", resp.StatusCode)}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("server returned non-OK status: %d", resp.StatusCode)
+	}
+
+	var response CheckPermissionsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if len(response.Errors) > 0 {
+		return nil, fmt.Errorf("GraphQL error: %s", response.Errors[0].Message)
+	}
+
+	res := make([]bool, n)
+	for i := range res {
+		r, ok := response.Data[fmt.Sprintf("%s%d", batchAliasPrefix, i)]
+		if !ok {
+			return nil, fmt.Errorf("missing result of check %d", i)
+		}
+		res[i] = r.Allowed
+	}
+	return res, nil
+}
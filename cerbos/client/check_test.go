@@ -148,3 +148,51 @@ func TestClient_CheckPermission(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_CheckPermissions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var gqlRequest struct {
+			Query     string                          `json:"query"`
+			Variables map[string]CheckPermissionInput `json:"variables"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&gqlRequest))
+		assert.Contains(t, gqlRequest.Query, "query CheckPermissions($input0: CheckPermissionInput!, $input1: CheckPermissionInput!)")
+		assert.Contains(t, gqlRequest.Query, "c0: checkPermission(input: $input0)")
+		assert.Contains(t, gqlRequest.Query, "c1: checkPermission(input: $input1)")
+		assert.Equal(t, "read", gqlRequest.Variables["input0"].Action)
+		assert.Equal(t, "write", gqlRequest.Variables["input1"].Action)
+
+		_, _ = w.Write([]byte(`{"data":{"c0":{"allowed":true},"c1":{"allowed":false}}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	authInfo := &appx.AuthInfo{Token: "test-token"}
+
+	got, err := client.CheckPermissions(context.Background(), authInfo, []CheckPermissionInput{
+		{Service: "flow", Resource: "project", Action: "read"},
+		{Service: "flow", Resource: "project", Action: "write"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []bool{true, false}, got)
+
+	got, err = client.CheckPermissions(context.Background(), authInfo, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, got)
+
+	_, err = client.CheckPermissions(context.Background(), nil, nil)
+	assert.EqualError(t, err, "auth info is required")
+}
+
+func TestClient_CheckPermissions_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data":{"c0":{"allowed":true}}}`))
+	}))
+	defer server.Close()
+
+	_, err := NewClient(server.URL).CheckPermissions(context.Background(), &appx.AuthInfo{}, []CheckPermissionInput{
+		{Resource: "project", Action: "read"},
+		{Resource: "project", Action: "write"},
+	})
+	assert.EqualError(t, err, "missing result of check 1")
+}
@@ -2,7 +2,9 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/reearth/reearthx/appx"
 )
@@ -10,6 +12,17 @@ import (
 type PermissionChecker struct {
 	Service      string
 	DashboardURL string
+	cache        *decisionCache
+	fallback     Fallback
+}
+
+// Fallback answers checks when the dashboard is unavailable, e.g. with an in-process generator.Evaluator.
+type Fallback func(ctx context.Context, userId string, perms []Permission) (map[Permission]bool, error)
+
+// Permission is a pair of a resource and an action to check.
+type Permission struct {
+	Resource string
+	Action   string
 }
 
 func NewPermissionChecker(service string, dashboardURL string) *PermissionChecker {
@@ -19,18 +32,93 @@ func NewPermissionChecker(service string, dashboardURL string) *PermissionChecke
 	}
 }
 
+// WithCache makes the checker cache decisions per user for ttl.
+// Decisions changed on the dashboard are not reflected until they expire or are invalidated.
+func (p *PermissionChecker) WithCache(ttl time.Duration) *PermissionChecker {
+	p.cache = newDecisionCache(ttl)
+	return p
+}
+
+// WithFallback makes the checker answer with f when the dashboard is unavailable. Fallback decisions are not cached.
+func (p *PermissionChecker) WithFallback(f Fallback) *PermissionChecker {
+	p.fallback = f
+	return p
+}
+
 func (p *PermissionChecker) CheckPermission(ctx context.Context, authInfo *appx.AuthInfo, userId string, resource string, action string) (bool, error) {
+	perm := Permission{Resource: resource, Action: action}
+	res, err := p.CheckPermissions(ctx, authInfo, userId, []Permission{perm})
+	if err != nil {
+		return false, err
+	}
+	return res[perm], nil
+}
+
+// CheckPermissions checks the permissions of the user. Permissions not cached are checked in one request.
+func (p *PermissionChecker) CheckPermissions(ctx context.Context, authInfo *appx.AuthInfo, userId string, perms []Permission) (map[Permission]bool, error) {
 	if p == nil {
-		return false, fmt.Errorf("permission checker not found")
+		return nil, fmt.Errorf("permission checker not found")
 	}
 
-	input := CheckPermissionInput{
-		UserId:   userId,
-		Service:  p.Service,
-		Resource: resource,
-		Action:   action,
+	res := make(map[Permission]bool, len(perms))
+	var inputs []CheckPermissionInput
+	var missing []Permission
+	for _, perm := range perms {
+		if _, ok := res[perm]; ok {
+			continue
+		}
+		if allowed, ok := p.cache.get(userId, p.Service, perm); ok {
+			res[perm] = allowed
+			continue
+		}
+		// reserve the key so that duplicated permissions are checked only once
+		res[perm] = false
+		missing = append(missing, perm)
+		inputs = append(inputs, CheckPermissionInput{
+			UserId:   userId,
+			Service:  p.Service,
+			Resource: perm.Resource,
+			Action:   perm.Action,
+		})
+	}
+	if len(inputs) == 0 {
+		return res, nil
 	}
 
 	client := NewClient(p.DashboardURL)
-	return client.CheckPermission(ctx, authInfo, input)
+	allowed, err := client.CheckPermissions(ctx, authInfo, inputs)
+	if errors.Is(err, ErrUnavailable) && p.fallback != nil {
+		fallback, ferr := p.fallback(ctx, userId, missing)
+		if ferr != nil {
+			return nil, errors.Join(err, ferr)
+		}
+		for _, perm := range missing {
+			res[perm] = fallback[perm]
+		}
+		return res, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i, perm := range missing {
+		res[perm] = allowed[i]
+		p.cache.set(userId, p.Service, perm, allowed[i])
+	}
+	return res, nil
+}
+
+// InvalidateUser drops cached decisions of the user, e.g. after their roles are changed.
+func (p *PermissionChecker) InvalidateUser(userId string) {
+	if p == nil {
+		return
+	}
+	p.cache.invalidateUser(userId)
+}
+
+// InvalidateAll drops all cached decisions, e.g. after policies are changed.
+func (p *PermissionChecker) InvalidateAll() {
+	if p == nil {
+		return
+	}
+	p.cache.invalidateAll()
 }
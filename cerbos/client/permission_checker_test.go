@@ -0,0 +1,161 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/reearth/reearthx/appx"
+	"github.com/reearth/reearthx/util"
+	"github.com/stretchr/testify/assert"
+)
+
+// newPermissionServer allows read actions only and counts checks.
+func newPermissionServer(t *testing.T, requests, checks *int32) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var gqlRequest struct {
+			Variables map[string]CheckPermissionInput `json:"variables"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&gqlRequest))
+		atomic.AddInt32(requests, 1)
+		atomic.AddInt32(checks, int32(len(gqlRequest.Variables)))
+
+		data := map[string]any{}
+		for k, v := range gqlRequest.Variables {
+			assert.Equal(t, "flow", v.Service)
+			data[fmt.Sprintf("c%s", k[len("input"):])] = map[string]bool{"allowed": v.Action == "read"}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": data})
+	}))
+}
+
+func TestPermissionChecker_CheckPermissions(t *testing.T) {
+	var requests, checks int32
+	server := newPermissionServer(t, &requests, &checks)
+	defer server.Close()
+
+	ctx := context.Background()
+	authInfo := &appx.AuthInfo{Token: "token"}
+	p := NewPermissionChecker("flow", server.URL)
+
+	got, err := p.CheckPermissions(ctx, authInfo, "user", []Permission{
+		{Resource: "project", Action: "read"},
+		{Resource: "project", Action: "write"},
+		{Resource: "project", Action: "read"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[Permission]bool{
+		{Resource: "project", Action: "read"}:  true,
+		{Resource: "project", Action: "write"}: false,
+	}, got)
+	assert.Equal(t, int32(1), requests)
+	assert.Equal(t, int32(2), checks)
+
+	allowed, err := p.CheckPermission(ctx, authInfo, "user", "project", "read")
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+	// no cache
+	assert.Equal(t, int32(2), requests)
+
+	_, err = (*PermissionChecker)(nil).CheckPermission(ctx, authInfo, "user", "project", "read")
+	assert.EqualError(t, err, "permission checker not found")
+}
+
+func TestPermissionChecker_WithCache(t *testing.T) {
+	var requests, checks int32
+	server := newPermissionServer(t, &requests, &checks)
+	defer server.Close()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	defer util.MockNow(now)()
+
+	ctx := context.Background()
+	authInfo := &appx.AuthInfo{Token: "token"}
+	p := NewPermissionChecker("flow", server.URL).WithCache(time.Minute)
+	read := Permission{Resource: "project", Action: "read"}
+	write := Permission{Resource: "project", Action: "write"}
+
+	_, err := p.CheckPermissions(ctx, authInfo, "user", []Permission{read})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), checks)
+
+	// only write is checked
+	got, err := p.CheckPermissions(ctx, authInfo, "user", []Permission{read, write})
+	assert.NoError(t, err)
+	assert.Equal(t, map[Permission]bool{read: true, write: false}, got)
+	assert.Equal(t, int32(2), checks)
+
+	// decisions are cached per user
+	_, err = p.CheckPermissions(ctx, authInfo, "user2", []Permission{read})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), checks)
+
+	p.InvalidateUser("user")
+	_, err = p.CheckPermissions(ctx, authInfo, "user", []Permission{read, write})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(5), checks)
+	_, err = p.CheckPermissions(ctx, authInfo, "user2", []Permission{read})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(5), checks)
+
+	p.InvalidateAll()
+	_, err = p.CheckPermissions(ctx, authInfo, "user2", []Permission{read})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(6), checks)
+
+	// expired
+	defer util.MockNow(now.Add(time.Minute))()
+	allowed, err := p.CheckPermission(ctx, authInfo, "user", "project", "read")
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+	assert.Equal(t, int32(7), checks)
+
+	// unknown users are not cached
+	_, err = p.CheckPermissions(ctx, authInfo, "", []Permission{read})
+	assert.NoError(t, err)
+	_, err = p.CheckPermissions(ctx, authInfo, "", []Permission{read})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(9), checks)
+	assert.Equal(t, int32(8), requests)
+}
+
+func TestPermissionChecker_WithFallback(t *testing.T) {
+	var status int32 = http.StatusServiceUnavailable
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(int(atomic.LoadInt32(&status)))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	authInfo := &appx.AuthInfo{Token: "token"}
+	read := Permission{Resource: "project", Action: "read"}
+	write := Permission{Resource: "project", Action: "write"}
+
+	_, err := NewPermissionChecker("flow", server.URL).CheckPermission(ctx, authInfo, "user", "project", "read")
+	assert.ErrorIs(t, err, ErrUnavailable)
+
+	p := NewPermissionChecker("flow", server.URL).WithCache(time.Minute).WithFallback(func(_ context.Context, userId string, perms []Permission) (map[Permission]bool, error) {
+		assert.Equal(t, "user", userId)
+		assert.Equal(t, []Permission{read, write}, perms)
+		return map[Permission]bool{read: true}, nil
+	})
+	got, err := p.CheckPermissions(ctx, authInfo, "user", []Permission{read, write})
+	assert.NoError(t, err)
+	assert.Equal(t, map[Permission]bool{read: true, write: false}, got)
+
+	// fallback decisions are not cached
+	_, err = p.CheckPermissions(ctx, authInfo, "user", []Permission{read, write})
+	assert.NoError(t, err)
+
+	// the fallback is not used for client errors
+	atomic.StoreInt32(&status, http.StatusBadRequest)
+	_, err = p.CheckPermissions(ctx, authInfo, "user", []Permission{read, write})
+	assert.EqualError(t, err, "server returned non-OK status: 400")
+	assert.NotErrorIs(t, err, ErrUnavailable)
+}
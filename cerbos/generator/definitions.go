@@ -7,6 +7,8 @@ import (
 type ResourceRule struct {
 	Resource string
 	Actions  map[string]ActionRule
+	// Tests are written as a Cerbos test suite next to the policy and are run when the policy is generated.
+	Tests []PolicyTest
 }
 
 type ActionRule struct {
@@ -0,0 +1,363 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	EffectAllow = "EFFECT_ALLOW"
+	EffectDeny  = "EFFECT_DENY"
+)
+
+// Principal is the user whose permission is checked.
+type Principal struct {
+	ID    string
+	Roles []string
+	Attr  map[string]any
+}
+
+// Resource is the resource to be accessed. Kind is the resource name of the policy, e.g. "flow:project".
+type Resource struct {
+	Kind string
+	ID   string
+	Attr map[string]any
+}
+
+// Evaluator evaluates policies in process like Cerbos does.
+//
+// A request is allowed if a rule for the action and a role of the principal allows it and no such rule denies it.
+// Conditions are CEL expressions that can refer to the principal as P or request.principal and to the resource
+// as R or request.resource. See expr.go for the functions available. Conditions using anything else are
+// never satisfied.
+// A condition that fails to evaluate, e.g. because of a missing attribute, is not satisfied as a whole,
+// so the rule is skipped even if the failing expression is negated by none.
+type Evaluator struct {
+	policies map[string][]evaluatorRule
+}
+
+type evaluatorRule struct {
+	actions   []string
+	roles     []string
+	effect    string
+	condition matcher
+}
+
+// NewEvaluator compiles the policies. Policies for the same resource are merged.
+func NewEvaluator(policies ...CerbosPolicy) (*Evaluator, error) {
+	e := &Evaluator{policies: map[string][]evaluatorRule{}}
+	for _, p := range policies {
+		resource := p.ResourcePolicy.Resource
+		if resource == "" {
+			return nil, fmt.Errorf("invalid resource name")
+		}
+		for i, r := range p.ResourcePolicy.Rules {
+			rule, err := compileRule(r)
+			if err != nil {
+				return nil, fmt.Errorf("policy %s rule %d: %w", resource, i, err)
+			}
+			e.policies[resource] = append(e.policies[resource], rule)
+		}
+	}
+	return e, nil
+}
+
+// LoadEvaluator loads YAML policies in the root of fsys, e.g. os.DirFS of the output directory of GeneratePolicies.
+func LoadEvaluator(fsys fs.FS) (*Evaluator, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policies: %w", err)
+	}
+
+	var policies []CerbosPolicy
+	for _, entry := range entries {
+		if entry.IsDir() || (path.Ext(entry.Name()) != ".yaml" && path.Ext(entry.Name()) != ".yml") {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read policy %s: %w", entry.Name(), err)
+		}
+		var policy CerbosPolicy
+		if err := yaml.Unmarshal(data, &policy); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal policy %s: %w", entry.Name(), err)
+		}
+		// other kinds of policies such as test suites
+		if policy.ResourcePolicy.Resource == "" {
+			continue
+		}
+		policies = append(policies, policy)
+	}
+	return NewEvaluator(policies...)
+}
+
+// Resources returns the sorted names of the resources that have policies.
+func (e *Evaluator) Resources() []string {
+	res := make([]string, 0, len(e.policies))
+	for r := range e.policies {
+		res = append(res, r)
+	}
+	slices.Sort(res)
+	return res
+}
+
+// Check returns true if the principal is allowed to do the action on the resource.
+// Requests for resources without policies are denied.
+func (e *Evaluator) Check(principal Principal, resource Resource, action string) bool {
+	rules := e.policies[resource.Kind]
+	if len(rules) == 0 {
+		return false
+	}
+
+	env := newEvaluatorEnv(principal, resource)
+	allowed := false
+	for _, r := range rules {
+		if !r.matchAction(action) || !r.matchRoles(principal.Roles) {
+			continue
+		}
+		if r.condition != nil {
+			if ok, err := r.condition.match(env); err != nil || !ok {
+				continue
+			}
+		}
+		if r.effect == EffectDeny {
+			return false
+		}
+		allowed = true
+	}
+	return allowed
+}
+
+// checkSupported returns the error of the first condition of the resources the evaluator cannot evaluate.
+func (e *Evaluator) checkSupported(resources ...string) error {
+	for _, resource := range e.Resources() {
+		if !slices.Contains(resources, resource) {
+			continue
+		}
+		for i, r := range e.policies[resource] {
+			if err := unsupported(r.condition); err != nil {
+				return fmt.Errorf("policy %s rule %d: %w", resource, i, err)
+			}
+		}
+	}
+	return nil
+}
+
+// CheckActions checks multiple actions at once.
+func (e *Evaluator) CheckActions(principal Principal, resource Resource, actions []string) map[string]bool {
+	res := make(map[string]bool, len(actions))
+	for _, a := range actions {
+		res[a] = e.Check(principal, resource, a)
+	}
+	return res
+}
+
+func compileRule(r Rule) (evaluatorRule, error) {
+	if r.Effect != EffectAllow && r.Effect != EffectDeny {
+		return evaluatorRule{}, fmt.Errorf("invalid effect %q", r.Effect)
+	}
+	res := evaluatorRule{
+		actions: r.Actions,
+		roles:   r.Roles,
+		effect:  r.Effect,
+	}
+	if r.Condition != nil {
+		m, err := compileMatch(r.Condition.Match)
+		if err != nil {
+			return evaluatorRule{}, err
+		}
+		res.condition = m
+	}
+	return res, nil
+}
+
+func (r evaluatorRule) matchAction(action string) bool {
+	return slices.ContainsFunc(r.actions, func(a string) bool {
+		return matchWildcard(a, action)
+	})
+}
+
+func (r evaluatorRule) matchRoles(roles []string) bool {
+	return slices.Contains(r.roles, "*") || slices.ContainsFunc(roles, func(role string) bool {
+		return slices.Contains(r.roles, role)
+	})
+}
+
+// matchWildcard matches actions with patterns such as "*" and "view:*".
+func matchWildcard(pattern, action string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(action, prefix)
+	}
+	return pattern == action
+}
+
+func newEvaluatorEnv(principal Principal, resource Resource) map[string]any {
+	p := map[string]any{
+		"id":    principal.ID,
+		"roles": normalizeValue(principal.Roles),
+		"attr":  attrValue(principal.Attr),
+	}
+	r := map[string]any{
+		"kind": resource.Kind,
+		"id":   resource.ID,
+		"attr": attrValue(resource.Attr),
+	}
+	// policies define no variables, globals or derived roles
+	empty := map[string]any{}
+	return map[string]any{
+		"P": p,
+		"R": r,
+		"request": map[string]any{
+			"principal": p,
+			"resource":  r,
+		},
+		"V":         empty,
+		"variables": empty,
+		"G":         empty,
+		"globals":   empty,
+		"runtime":   map[string]any{"effectiveDerivedRoles": []any{}},
+	}
+}
+
+func attrValue(attr map[string]any) any {
+	if attr == nil {
+		return map[string]any{}
+	}
+	return normalizeValue(attr)
+}
+
+// matcher is a compiled Match. Errors are propagated like CEL does for && and ||:
+// they are hidden only by a branch that decides the result on its own.
+type matcher interface {
+	match(vars map[string]any) (bool, error)
+}
+
+type exprMatcher struct{ expr *expr }
+
+func (m exprMatcher) match(vars map[string]any) (bool, error) {
+	return evalBool(m.expr, vars)
+}
+
+// unsupportedMatcher is a condition the evaluator cannot evaluate. It is never satisfied.
+type unsupportedMatcher struct{ err error }
+
+func (m unsupportedMatcher) match(map[string]any) (bool, error) {
+	return false, m.err
+}
+
+type allMatcher []matcher
+
+func (m allMatcher) match(vars map[string]any) (bool, error) {
+	var firstErr error
+	for _, c := range m {
+		ok, err := c.match(vars)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	return firstErr == nil, firstErr
+}
+
+type anyMatcher []matcher
+
+func (m anyMatcher) match(vars map[string]any) (bool, error) {
+	var firstErr error
+	for _, c := range m {
+		ok, err := c.match(vars)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return false, firstErr
+}
+
+type noneMatcher []matcher
+
+func (m noneMatcher) match(vars map[string]any) (bool, error) {
+	ok, err := anyMatcher(m).match(vars)
+	if err != nil {
+		return false, err
+	}
+	return !ok, nil
+}
+
+// unsupported returns the error of the first unsupported expression in the matcher.
+func unsupported(m matcher) error {
+	var ms []matcher
+	switch m := m.(type) {
+	case unsupportedMatcher:
+		return m.err
+	case allMatcher:
+		ms = m
+	case anyMatcher:
+		ms = m
+	case noneMatcher:
+		ms = m
+	}
+	for _, c := range ms {
+		if err := unsupported(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func compileMatch(m Match) (matcher, error) {
+	n := 0
+	var res matcher
+	if m.Expr != nil {
+		n++
+		e, err := parseExpr(*m.Expr)
+		if errors.Is(err, ErrUnsupportedExpression) {
+			res = unsupportedMatcher{err: err}
+		} else if err != nil {
+			return nil, fmt.Errorf("invalid expression %q: %w", *m.Expr, err)
+		} else {
+			res = exprMatcher{expr: e}
+		}
+	}
+	for _, c := range []struct {
+		exprs *MatchExpressions
+		build func([]matcher) matcher
+	}{
+		{m.All, func(ms []matcher) matcher { return allMatcher(ms) }},
+		{m.Any, func(ms []matcher) matcher { return anyMatcher(ms) }},
+		{m.None, func(ms []matcher) matcher { return noneMatcher(ms) }},
+	} {
+		if c.exprs == nil {
+			continue
+		}
+		n++
+		ms := make([]matcher, 0, len(c.exprs.Of))
+		for _, o := range c.exprs.Of {
+			cm, err := compileMatch(o)
+			if err != nil {
+				return nil, err
+			}
+			ms = append(ms, cm)
+		}
+		res = c.build(ms)
+	}
+	if n != 1 {
+		return nil, errors.New("match must have exactly one of expr, all, any and none")
+	}
+	return res, nil
+}
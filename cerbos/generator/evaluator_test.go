@@ -0,0 +1,168 @@
+package generator
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluator_Check(t *testing.T) {
+	policies, err := BuildPolicies("flow", []ResourceRule{
+		{
+			Resource: "project",
+			Actions: map[string]ActionRule{
+				"read":  {Roles: []string{"owner", "reader"}},
+				"write": {Roles: []string{"owner"}},
+				"delete": {
+					Roles:     []string{"owner", "admin"},
+					Condition: AnyOf(`P.attr.role == "admin"`, `R.attr.owner == P.id`),
+				},
+				"view": {
+					Roles:     []string{"reader"},
+					Condition: NoneOf(`R.attr.archived == true`, `R.attr.deleted == true`),
+				},
+				"approve": {
+					Roles:     []string{"owner"},
+					Condition: AllOf(`request.resource.attr.status == "PENDING_APPROVAL"`, `"GB" in R.attr.geographies`),
+				},
+			},
+		},
+	})
+	assert.NoError(t, err)
+	// a deny rule overrides allow rules
+	policies[0].ResourcePolicy.Rules = append(policies[0].ResourcePolicy.Rules, Rule{
+		Actions:   []string{"*"},
+		Effect:    EffectDeny,
+		Roles:     []string{"*"},
+		Condition: SimpleExpr(`R.attr.locked == true`),
+	})
+
+	e, err := NewEvaluator(policies...)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"flow:project"}, e.Resources())
+
+	owner := Principal{ID: "u1", Roles: []string{"owner"}}
+	reader := Principal{ID: "u2", Roles: []string{"reader"}}
+	admin := Principal{ID: "u3", Roles: []string{"admin"}, Attr: map[string]any{"role": "admin"}}
+	project := Resource{Kind: "flow:project", ID: "p1", Attr: map[string]any{"owner": "u1"}}
+
+	assert.True(t, e.Check(owner, project, "read"))
+	assert.True(t, e.Check(reader, project, "read"))
+	assert.False(t, e.Check(reader, project, "write"))
+	assert.False(t, e.Check(owner, project, "unknown"))
+	assert.False(t, e.Check(owner, Resource{Kind: "flow:unknown"}, "read"))
+
+	assert.True(t, e.Check(owner, project, "delete"))
+	assert.True(t, e.Check(admin, project, "delete"))
+	assert.False(t, e.Check(Principal{ID: "u4", Roles: []string{"owner"}}, project, "delete"))
+
+	// conditions with missing attributes are not satisfied even if they are negated by none
+	assert.False(t, e.Check(reader, project, "view"))
+	assert.False(t, e.Check(reader, Resource{Kind: "flow:project", Attr: map[string]any{"archived": false}}, "view"))
+	assert.False(t, e.Check(reader, Resource{Kind: "flow:project", Attr: map[string]any{"archived": true}}, "view"))
+	assert.True(t, e.Check(reader, Resource{Kind: "flow:project", Attr: map[string]any{"archived": false, "deleted": false}}, "view"))
+
+	assert.False(t, e.Check(owner, project, "approve"))
+	assert.True(t, e.Check(owner, Resource{Kind: "flow:project", Attr: map[string]any{
+		"status":      "PENDING_APPROVAL",
+		"geographies": []string{"GB"},
+	}}, "approve"))
+
+	locked := Resource{Kind: "flow:project", Attr: map[string]any{"owner": "u1", "locked": true}}
+	assert.Equal(t, map[string]bool{"read": false, "write": false}, e.CheckActions(owner, locked, []string{"read", "write"}))
+	assert.Equal(t, map[string]bool{"read": true, "write": true}, e.CheckActions(owner, project, []string{"read", "write"}))
+}
+
+func TestEvaluator_Check_MissingAttribute(t *testing.T) {
+	policies, err := BuildPolicies("flow", []ResourceRule{
+		{
+			Resource: "project",
+			Actions: map[string]ActionRule{
+				"edit": {Roles: []string{"owner"}, Condition: NoneOf(`R.attr.locked == true`)},
+			},
+		},
+	})
+	assert.NoError(t, err)
+	e, err := NewEvaluator(policies...)
+	assert.NoError(t, err)
+
+	owner := Principal{ID: "u1", Roles: []string{"owner"}}
+	assert.False(t, e.Check(owner, Resource{Kind: "flow:project"}, "edit"))
+	assert.False(t, e.Check(owner, Resource{Kind: "flow:project", Attr: map[string]any{"locked": true}}, "edit"))
+	assert.True(t, e.Check(owner, Resource{Kind: "flow:project", Attr: map[string]any{"locked": false}}, "edit"))
+}
+
+func TestEvaluator_Check_Unsupported(t *testing.T) {
+	policies, err := BuildPolicies("flow", []ResourceRule{
+		{
+			Resource: "project",
+			Actions: map[string]ActionRule{
+				"read": {Roles: []string{"owner"}, Condition: SimpleExpr(`hierarchy("a.b").size() == 2`)},
+				"edit": {Roles: []string{"owner"}, Condition: NoneOf(`unknown(R.attr.locked)`)},
+			},
+		},
+	})
+	assert.NoError(t, err)
+	e, err := NewEvaluator(policies...)
+	assert.NoError(t, err)
+
+	owner := Principal{ID: "u1", Roles: []string{"owner"}}
+	project := Resource{Kind: "flow:project", Attr: map[string]any{"locked": false}}
+	assert.False(t, e.Check(owner, project, "read"))
+	assert.False(t, e.Check(owner, project, "edit"))
+}
+
+func TestNewEvaluator_Error(t *testing.T) {
+	_, err := NewEvaluator(CerbosPolicy{})
+	assert.EqualError(t, err, "invalid resource name")
+
+	_, err = NewEvaluator(CerbosPolicy{ResourcePolicy: ResourcePolicy{
+		Resource: "flow:project",
+		Rules:    []Rule{{Actions: []string{"read"}, Effect: "EFFECT_UNKNOWN"}},
+	}})
+	assert.EqualError(t, err, `policy flow:project rule 0: invalid effect "EFFECT_UNKNOWN"`)
+
+	_, err = NewEvaluator(CerbosPolicy{ResourcePolicy: ResourcePolicy{
+		Resource: "flow:project",
+		Rules:    []Rule{{Actions: []string{"read"}, Effect: EffectAllow, Condition: SimpleExpr("R.attr ==")}},
+	}})
+	assert.ErrorContains(t, err, `policy flow:project rule 0: invalid expression "R.attr =="`)
+
+	_, err = NewEvaluator(CerbosPolicy{ResourcePolicy: ResourcePolicy{
+		Resource: "flow:project",
+		Rules:    []Rule{{Actions: []string{"read"}, Effect: EffectAllow, Condition: &Condition{}}},
+	}})
+	assert.ErrorContains(t, err, "match must have exactly one of expr, all, any and none")
+}
+
+func TestLoadEvaluator(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, GeneratePolicies("flow", []ResourceRule{
+		{
+			Resource: "project",
+			Actions: map[string]ActionRule{
+				"read": {Roles: []string{"reader"}, Condition: SimpleExpr(`R.attr.public == true`)},
+			},
+		},
+		{
+			Resource: "workflow",
+			Actions: map[string]ActionRule{
+				"read": {Roles: []string{"reader"}},
+			},
+		},
+	}, dir))
+	assert.NoError(t, os.WriteFile(dir+"/README.md", []byte("not a policy"), 0644))
+
+	e, err := LoadEvaluator(os.DirFS(dir))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"flow:project", "flow:workflow"}, e.Resources())
+
+	reader := Principal{ID: "u1", Roles: []string{"reader"}}
+	assert.True(t, e.Check(reader, Resource{Kind: "flow:project", Attr: map[string]any{"public": true}}, "read"))
+	assert.False(t, e.Check(reader, Resource{Kind: "flow:project"}, "read"))
+	assert.True(t, e.Check(reader, Resource{Kind: "flow:workflow"}, "read"))
+
+	_, err = LoadEvaluator(os.DirFS(dir + "/missing"))
+	assert.Error(t, err)
+}
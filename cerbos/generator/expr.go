@@ -0,0 +1,319 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+	"github.com/google/cel-go/ext"
+	"github.com/reearth/reearthx/util"
+)
+
+// Conditions are compiled with cel-go in an environment similar to the one of Cerbos
+// (https://docs.cerbos.dev/cerbos/latest/policies/conditions):
+//
+//   - the standard CEL library including timestamp() and duration(), and the string, list, set, math and
+//     encoder extensions, e.g. lowerAscii() and split()
+//   - request, P, R, V (variables), G (globals) and runtime.effectiveDerivedRoles
+//   - now(), timeSince(), hasIntersection(), intersect(), except(), inIPAddrRange()
+//   - hierarchy() with ancestorOf(), descendentOf(), immediateChildOf(), immediateParentOf(), siblingOf()
+//     and overlaps()
+//
+// Expressions that are valid CEL but use anything else compile to an unsupported condition,
+// which is never satisfied so that the evaluator denies instead of guessing.
+
+// ErrUnsupportedExpression is returned by conditions that refer to functions or variables the evaluator does not provide.
+var ErrUnsupportedExpression = errors.New("unsupported expression")
+
+var celEnv = sync.OnceValues(func() (*cel.Env, error) {
+	return cel.NewEnv(
+		cel.Variable("request", cel.DynType),
+		cel.Variable("P", cel.DynType),
+		cel.Variable("R", cel.DynType),
+		cel.Variable("V", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("variables", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("G", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("globals", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("runtime", cel.MapType(cel.StringType, cel.DynType)),
+		cel.CrossTypeNumericComparisons(true),
+		cel.OptionalTypes(),
+		ext.Strings(),
+		ext.Lists(),
+		ext.Sets(),
+		ext.Math(),
+		ext.Encoders(),
+		cerbosFunctions(),
+		hierarchyFunctions(),
+	)
+})
+
+// expr is a compiled CEL expression.
+type expr struct {
+	program cel.Program
+}
+
+// parseExpr compiles a CEL expression. Syntax errors are returned as errors,
+// while references the environment does not provide result in ErrUnsupportedExpression.
+func parseExpr(src string) (*expr, error) {
+	env, err := celEnv()
+	if err != nil {
+		return nil, err
+	}
+	parsed, iss := env.Parse(src)
+	if iss.Err() != nil {
+		return nil, iss.Err()
+	}
+	checked, iss := env.Check(parsed)
+	if iss.Err() != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnsupportedExpression, iss.Err())
+	}
+	// constant regular expressions are compiled here instead of on every evaluation
+	prg, err := env.Program(checked, cel.EvalOptions(cel.OptOptimize))
+	if err != nil {
+		return nil, err
+	}
+	return &expr{program: prg}, nil
+}
+
+func (e *expr) eval(vars map[string]any) (any, error) {
+	v, _, err := e.program.Eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	return v.Value(), nil
+}
+
+func evalBool(e *expr, vars map[string]any) (bool, error) {
+	v, err := e.eval(vars)
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("condition is not a bool: %T", v)
+	}
+	return b, nil
+}
+
+// normalizeValue converts Go values of attributes into the values expressions handle:
+// int64, float64, string, bool, nil, timestamps, durations, []any and map[string]any.
+func normalizeValue(v any) any {
+	switch v.(type) {
+	case nil:
+		return nil
+	case time.Time, time.Duration:
+		return v
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Bool:
+		return rv.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		return rv.Float()
+	case reflect.String:
+		return rv.String()
+	case reflect.Slice, reflect.Array:
+		res := make([]any, rv.Len())
+		for i := range res {
+			res[i] = normalizeValue(rv.Index(i).Interface())
+		}
+		return res
+	case reflect.Map:
+		res := make(map[string]any, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			res[fmt.Sprint(iter.Key().Interface())] = normalizeValue(iter.Value().Interface())
+		}
+		return res
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return nil
+		}
+		return normalizeValue(rv.Elem().Interface())
+	}
+	return v
+}
+
+// cerbosFunctions declares the functions Cerbos adds to CEL.
+func cerbosFunctions() cel.EnvOption {
+	listType := cel.ListType(cel.DynType)
+	return cel.Lib(&celLib{options: []cel.EnvOption{
+		cel.Function("now",
+			cel.Overload("now", nil, cel.TimestampType,
+				cel.FunctionBinding(func(...ref.Val) ref.Val {
+					return types.Timestamp{Time: util.Now()}
+				}),
+			),
+		),
+		cel.Function("timeSince",
+			cel.MemberOverload("timestamp_timeSince", []*cel.Type{cel.TimestampType}, cel.DurationType,
+				cel.UnaryBinding(func(v ref.Val) ref.Val {
+					return types.Duration{Duration: util.Now().Sub(v.(types.Timestamp).Time)}
+				}),
+			),
+		),
+		cel.Function("hasIntersection",
+			cel.Overload("hasIntersection_list_list", []*cel.Type{listType, listType}, cel.BoolType,
+				cel.BinaryBinding(func(a, b ref.Val) ref.Val {
+					return types.Bool(len(filterList(a, b, true)) > 0)
+				}),
+			),
+		),
+		cel.Function("intersect",
+			cel.Overload("intersect_list_list", []*cel.Type{listType, listType}, listType,
+				cel.BinaryBinding(func(a, b ref.Val) ref.Val {
+					return types.DefaultTypeAdapter.NativeToValue(filterList(a, b, true))
+				}),
+			),
+		),
+		cel.Function("except",
+			cel.MemberOverload("list_except_list", []*cel.Type{listType, listType}, listType,
+				cel.BinaryBinding(func(a, b ref.Val) ref.Val {
+					return types.DefaultTypeAdapter.NativeToValue(filterList(a, b, false))
+				}),
+			),
+		),
+		cel.Function("inIPAddrRange",
+			cel.MemberOverload("string_inIPAddrRange_string", []*cel.Type{cel.StringType, cel.StringType}, cel.BoolType,
+				cel.BinaryBinding(func(ip, cidr ref.Val) ref.Val {
+					addr, err := netip.ParseAddr(string(ip.(types.String)))
+					if err != nil {
+						return types.NewErr("invalid ip address: %s", ip)
+					}
+					prefix, err := netip.ParsePrefix(string(cidr.(types.String)))
+					if err != nil {
+						return types.NewErr("invalid ip address range: %s", cidr)
+					}
+					return types.Bool(prefix.Contains(addr))
+				}),
+			),
+		),
+	}})
+}
+
+// filterList returns the items of a that are contained in b if contained is true, or that are not otherwise.
+func filterList(a, b ref.Val, contained bool) []ref.Val {
+	other := b.(traits.Lister)
+	var res []ref.Val
+	for it := a.(traits.Lister).Iterator(); it.HasNext() == types.True; {
+		if v := it.Next(); (other.Contains(v) == types.True) == contained {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+var hierarchyType = cel.OpaqueType("hierarchy")
+
+// hierarchyValue is a dot-separated hierarchy such as "a.b.c".
+type hierarchyValue []string
+
+func (h hierarchyValue) ConvertToNative(typeDesc reflect.Type) (any, error) {
+	if typeDesc == reflect.TypeOf([]string(nil)) {
+		return []string(h), nil
+	}
+	return nil, fmt.Errorf("unsupported conversion from hierarchy to %v", typeDesc)
+}
+
+func (h hierarchyValue) ConvertToType(t ref.Type) ref.Val {
+	switch t {
+	case types.TypeType:
+		return hierarchyType
+	case types.StringType:
+		return types.String(strings.Join(h, "."))
+	}
+	return types.NewErr("type conversion error from hierarchy to %v", t)
+}
+
+func (h hierarchyValue) Equal(other ref.Val) ref.Val {
+	o, ok := other.(hierarchyValue)
+	return types.Bool(ok && slices.Equal(h, o))
+}
+
+func (h hierarchyValue) Type() ref.Type {
+	return hierarchyType
+}
+
+func (h hierarchyValue) Value() any {
+	return []string(h)
+}
+
+func (h hierarchyValue) ancestorOf(o hierarchyValue) bool {
+	return len(h) < len(o) && slices.Equal(h, o[:len(h)])
+}
+
+func (h hierarchyValue) immediateParentOf(o hierarchyValue) bool {
+	return len(h)+1 == len(o) && h.ancestorOf(o)
+}
+
+// hierarchyFunctions declares the hierarchy functions of Cerbos.
+func hierarchyFunctions() cel.EnvOption {
+	relation := func(name string, f func(a, b hierarchyValue) bool) cel.EnvOption {
+		return cel.Function(name,
+			cel.MemberOverload("hierarchy_"+name+"_hierarchy", []*cel.Type{hierarchyType, hierarchyType}, cel.BoolType,
+				cel.BinaryBinding(func(a, b ref.Val) ref.Val {
+					return types.Bool(f(a.(hierarchyValue), b.(hierarchyValue)))
+				}),
+			),
+		)
+	}
+
+	return cel.Lib(&celLib{options: []cel.EnvOption{
+		cel.Function("hierarchy",
+			cel.Overload("hierarchy_string", []*cel.Type{cel.StringType}, hierarchyType,
+				cel.UnaryBinding(func(v ref.Val) ref.Val {
+					return hierarchyValue(strings.Split(string(v.(types.String)), "."))
+				}),
+			),
+			cel.Overload("hierarchy_string_string", []*cel.Type{cel.StringType, cel.StringType}, hierarchyType,
+				cel.BinaryBinding(func(v, sep ref.Val) ref.Val {
+					return hierarchyValue(strings.Split(string(v.(types.String)), string(sep.(types.String))))
+				}),
+			),
+			cel.Overload("hierarchy_list", []*cel.Type{cel.ListType(cel.StringType)}, hierarchyType,
+				cel.UnaryBinding(func(v ref.Val) ref.Val {
+					l, err := v.ConvertToNative(reflect.TypeOf([]string(nil)))
+					if err != nil {
+						return types.NewErrFromString(err.Error())
+					}
+					return hierarchyValue(l.([]string))
+				}),
+			),
+		),
+		relation("ancestorOf", hierarchyValue.ancestorOf),
+		relation("descendentOf", func(a, b hierarchyValue) bool { return b.ancestorOf(a) }),
+		relation("immediateParentOf", hierarchyValue.immediateParentOf),
+		relation("immediateChildOf", func(a, b hierarchyValue) bool { return b.immediateParentOf(a) }),
+		relation("siblingOf", func(a, b hierarchyValue) bool {
+			return len(a) == len(b) && len(a) > 0 && !slices.Equal(a, b) && slices.Equal(a[:len(a)-1], b[:len(b)-1])
+		}),
+		relation("overlaps", func(a, b hierarchyValue) bool {
+			return slices.Equal(a, b) || a.ancestorOf(b) || b.ancestorOf(a)
+		}),
+	}})
+}
+
+type celLib struct {
+	options []cel.EnvOption
+}
+
+func (l *celLib) CompileOptions() []cel.EnvOption {
+	return l.options
+}
+
+func (l *celLib) ProgramOptions() []cel.ProgramOption {
+	return nil
+}
@@ -0,0 +1,142 @@
+package generator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/reearth/reearthx/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseExpr(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	defer util.MockNow(now)()
+
+	vars := newEvaluatorEnv(
+		Principal{ID: "u1", Roles: []string{"user"}, Attr: map[string]any{"ip": "10.0.1.2", "dept": "a.b"}},
+		Resource{Kind: "flow:project", ID: "r1", Attr: map[string]any{
+			"status":      "PENDING_APPROVAL",
+			"geographies": []string{"GB", "JP"},
+			"owner":       "u1",
+			"size":        3,
+			"score":       1.5,
+			"archived":    false,
+			"tags":        map[string]string{"env": "prod"},
+			"big":         int64(1<<53 + 1),
+			"expiresAt":   now.Add(time.Hour),
+			"path":        "a.b.c",
+		}},
+	)
+
+	tests := []struct {
+		expr string
+		want any
+	}{
+		{`R.attr.status == "PENDING_APPROVAL"`, true},
+		{`R.attr.status != 'PENDING_APPROVAL'`, false},
+		{`"GB" in R.attr.geographies`, true},
+		{`"US" in R.attr.geographies`, false},
+		{`"env" in R.attr.tags`, true},
+		{`R.attr.owner == P.id && !R.attr.archived`, true},
+		{`R.attr.owner == P.id || R.attr.missing`, true},
+		{`R.attr.missing || R.attr.owner == P.id`, true},
+		{`R.attr.size > 2 && R.attr.size <= 3`, true},
+		{`R.attr.score >= 1.5 && R.attr.score < 2`, true},
+		{`R.attr.size * 2 + 1`, int64(7)},
+		{`-R.attr.size % 2`, int64(-1)},
+		{`double(R.attr.size) / 2.0`, 1.5},
+		{`(1 + 2) * 3`, int64(9)},
+		{`R.attr.tags["env"]`, "prod"},
+		{`R.attr.geographies[1]`, "JP"},
+		{`size(R.attr.geographies) == 2`, true},
+		{`R.attr.status.size()`, int64(16)},
+		{`R.attr.status.startsWith("PENDING") && R.attr.status.endsWith("APPROVAL")`, true},
+		{`R.attr.status.contains("_")`, true},
+		{`R.attr.status.matches("^[A-Z_]+$")`, true},
+		{`has(R.attr.owner) && !has(R.attr.missing)`, true},
+		{`R.attr.geographies.exists(g, g == "JP")`, true},
+		{`R.attr.geographies.all(g, g.size() == 2)`, true},
+		{`R.attr.geographies.exists_one(g, g.startsWith("G"))`, true},
+		{`R.attr.geographies.filter(g, g != "GB") == ["JP"]`, true},
+		{`R.attr.archived ? "a" : "b"`, "b"},
+		{`[1, 2] + [3] == [1, 2, 3]`, true},
+		{`{"a": 1}.a == 1`, true},
+		{`int("10") + int(1.9)`, int64(11)},
+		{`string(1) + "a"`, "1a"},
+		{`double(1) == 1.0`, true},
+		{`"a\"b" == 'a"b'`, true},
+		{`request.resource.id == "r1" && request.principal.id == "u1"`, true},
+		// integers are not compared as floats
+		{`R.attr.big == 9007199254740992`, false},
+		{`R.attr.big == 9007199254740993`, true},
+		// standard library and extensions
+		{`R.attr.expiresAt > now()`, true},
+		{`timestamp("2024-01-01T00:00:00Z") == now()`, true},
+		{`R.attr.expiresAt - now() == duration("1h")`, true},
+		{`timestamp("2023-12-31T00:00:00Z").timeSince() == duration("24h")`, true},
+		{`R.attr.status.lowerAscii() == "pending_approval"`, true},
+		{`R.attr.status.split("_") == ["PENDING", "APPROVAL"]`, true},
+		{`sets.contains(R.attr.geographies, ["JP"])`, true},
+		// functions of Cerbos
+		{`hasIntersection(R.attr.geographies, ["JP", "US"])`, true},
+		{`intersect(R.attr.geographies, ["JP", "US"]) == ["JP"]`, true},
+		{`R.attr.geographies.except(["JP"]) == ["GB"]`, true},
+		{`P.attr.ip.inIPAddrRange("10.0.0.0/16")`, true},
+		{`P.attr.ip.inIPAddrRange("192.168.0.0/16")`, false},
+		{`hierarchy(P.attr.dept).ancestorOf(hierarchy(R.attr.path))`, true},
+		{`hierarchy(P.attr.dept).immediateParentOf(hierarchy(R.attr.path))`, true},
+		{`hierarchy(R.attr.path).descendentOf(hierarchy("a"))`, true},
+		{`hierarchy(R.attr.path).immediateChildOf(hierarchy("a"))`, false},
+		{`hierarchy(R.attr.path).siblingOf(hierarchy("a.b.d"))`, true},
+		{`hierarchy(R.attr.path).overlaps(hierarchy(P.attr.dept))`, true},
+		{`hierarchy("a/b", "/") == hierarchy(["a", "b"])`, true},
+		{`size(V) == 0 && size(runtime.effectiveDerivedRoles) == 0`, true},
+		// errors
+		{`R.attr.missing == null`, nil},
+		{`V.missing`, nil},
+		{`R.attr.size / 2.0`, nil},
+		{`P.attr.dept.inIPAddrRange("10.0.0.0/16")`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			e, err := parseExpr(tt.expr)
+			assert.NoError(t, err)
+			got, err := e.eval(vars)
+			if tt.want == nil {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseExpr_Error(t *testing.T) {
+	for _, src := range []string{
+		``,
+		`R.attr.status ==`,
+		`(1 + 2`,
+		`"unterminated`,
+		`R.attr.status # 1`,
+		`has(R)`,
+		`R.attr.list.exists(1, true)`,
+		`1 2`,
+	} {
+		_, err := parseExpr(src)
+		assert.Error(t, err, src)
+		assert.NotErrorIs(t, err, ErrUnsupportedExpression, src)
+	}
+
+	for _, src := range []string{
+		`unknown(1)`,
+		`R.attr.status.unknown()`,
+		`X.attr == 1`,
+		`hierarchy("a.b").commonAncestors(hierarchy("a.c"))`,
+		`hierarchy("a.b").size()`,
+	} {
+		_, err := parseExpr(src)
+		assert.ErrorIs(t, err, ErrUnsupportedExpression, src)
+	}
+}
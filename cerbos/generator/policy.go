@@ -44,17 +44,80 @@ type MatchExpressions struct {
 
 type DefineResourcesFunc func(builder *ResourceBuilder) []ResourceDefinition
 
+// GeneratePolicies writes a policy for each resource and a test suite for each resource that has tests.
+// Nothing is written if the policies fail validation. See ValidatePolicies.
 func GeneratePolicies(serviceName string, resourceRules []ResourceRule, outputDir string) error {
+	if err := ValidatePolicies(serviceName, resourceRules); err != nil {
+		return err
+	}
+
+	policies, err := BuildPolicies(serviceName, resourceRules)
+	if err != nil {
+		return err
+	}
+	for _, policy := range policies {
+		if err := writeYAML(outputDir, policyFilename(policy.ResourcePolicy.Resource), policy); err != nil {
+			return err
+		}
+	}
+
+	for _, r := range resourceRules {
+		if len(r.Tests) == 0 {
+			continue
+		}
+		kind := serviceName + ":" + r.Resource
+		suite, err := buildTestSuite(kind, r)
+		if err != nil {
+			return err
+		}
+		if err := writeYAML(outputDir, testSuiteFilename(kind), suite); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func policyFilename(resource string) string {
+	return fmt.Sprintf("%s.yaml", strings.ReplaceAll(resource, ":", "_"))
+}
+
+// testSuiteFilename returns the name Cerbos recognizes as a test suite.
+func testSuiteFilename(resource string) string {
+	return fmt.Sprintf("%s_test.yaml", strings.ReplaceAll(resource, ":", "_"))
+}
+
+func writeYAML(outputDir, filename string, v any) error {
+	outputPath := filepath.Join(outputDir, filename)
+
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal policy: %w", err)
+	}
+
+	if err := os.WriteFile(outputPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
+
+// BuildPolicies builds the policies GeneratePolicies writes.
+func BuildPolicies(serviceName string, resourceRules []ResourceRule) ([]CerbosPolicy, error) {
 	if resourceRules == nil {
-		return fmt.Errorf("resource rules is required")
+		return nil, fmt.Errorf("resource rules is required")
 	}
 
 	builder := NewResourceBuilder(serviceName)
 	resources := DefineResources(builder, resourceRules)
 
+	policies := make([]CerbosPolicy, 0, len(resources))
 	for _, resource := range resources {
 		if resource.Resource == "" {
-			return fmt.Errorf("invalid resource name")
+			return nil, fmt.Errorf("invalid resource name")
 		}
 
 		policy := CerbosPolicy{
@@ -76,22 +139,8 @@ func GeneratePolicies(serviceName string, resourceRules []ResourceRule, outputDi
 			policy.ResourcePolicy.Rules = append(policy.ResourcePolicy.Rules, rule)
 		}
 
-		filename := strings.ReplaceAll(resource.Resource, ":", "_")
-		outputPath := filepath.Join(outputDir, fmt.Sprintf("%s.yaml", filename))
-
-		if err := os.MkdirAll(outputDir, 0755); err != nil {
-			return fmt.Errorf("failed to create directory: %w", err)
-		}
-
-		data, err := yaml.Marshal(policy)
-		if err != nil {
-			return fmt.Errorf("failed to marshal policy: %w", err)
-		}
-
-		if err := os.WriteFile(outputPath, data, 0644); err != nil {
-			return fmt.Errorf("failed to write file: %w", err)
-		}
+		policies = append(policies, policy)
 	}
 
-	return nil
+	return policies, nil
 }
@@ -0,0 +1,259 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// PolicyTest is an expected decision of the policy of a resource.
+type PolicyTest struct {
+	Name      string
+	Principal Principal
+	// ResourceID defaults to the resource name.
+	ResourceID   string
+	ResourceAttr map[string]any
+	// Allow and Deny are the actions the principal is expected to be allowed and denied.
+	Allow []string
+	Deny  []string
+}
+
+// TestSuite is a Cerbos test suite. See https://docs.cerbos.dev/cerbos/latest/policies/compile#testing
+type TestSuite struct {
+	Name        string                   `yaml:"name"`
+	Description string                   `yaml:"description,omitempty"`
+	Principals  map[string]TestPrincipal `yaml:"principals"`
+	Resources   map[string]TestResource  `yaml:"resources"`
+	Tests       []TestTable              `yaml:"tests"`
+}
+
+type TestPrincipal struct {
+	ID    string         `yaml:"id"`
+	Roles []string       `yaml:"roles"`
+	Attr  map[string]any `yaml:"attr,omitempty"`
+}
+
+type TestResource struct {
+	Kind string         `yaml:"kind"`
+	ID   string         `yaml:"id"`
+	Attr map[string]any `yaml:"attr,omitempty"`
+}
+
+type TestTable struct {
+	Name     string            `yaml:"name"`
+	Input    TestInput         `yaml:"input"`
+	Expected []TestExpectation `yaml:"expected"`
+}
+
+type TestInput struct {
+	Principals []string `yaml:"principals"`
+	Resources  []string `yaml:"resources"`
+	Actions    []string `yaml:"actions"`
+}
+
+type TestExpectation struct {
+	Principal string            `yaml:"principal"`
+	Resource  string            `yaml:"resource"`
+	Actions   map[string]string `yaml:"actions"`
+}
+
+// BuildTestSuites builds a test suite for each resource that has tests. Suites are sorted by resource names.
+func BuildTestSuites(serviceName string, resourceRules []ResourceRule) ([]TestSuite, error) {
+	rules := slices.Clone(resourceRules)
+	slices.SortFunc(rules, func(a, b ResourceRule) int {
+		return strings.Compare(a.Resource, b.Resource)
+	})
+
+	var suites []TestSuite
+	for _, r := range rules {
+		if len(r.Tests) == 0 {
+			continue
+		}
+		suite, err := buildTestSuite(serviceName+":"+r.Resource, r)
+		if err != nil {
+			return nil, err
+		}
+		suites = append(suites, suite)
+	}
+	return suites, nil
+}
+
+func buildTestSuite(kind string, r ResourceRule) (TestSuite, error) {
+	suite := TestSuite{
+		Name:        testSuiteName(kind),
+		Description: fmt.Sprintf("Tests for %s", kind),
+		Principals:  map[string]TestPrincipal{},
+		Resources:   map[string]TestResource{},
+		Tests:       make([]TestTable, 0, len(r.Tests)),
+	}
+
+	for _, t := range r.Tests {
+		if err := validatePolicyTest(kind, r, t); err != nil {
+			return TestSuite{}, err
+		}
+
+		principal := TestPrincipal{ID: t.Principal.ID, Roles: t.Principal.Roles, Attr: t.Principal.Attr}
+		if p, ok := suite.Principals[principal.ID]; ok && !reflect.DeepEqual(p, principal) {
+			return TestSuite{}, fmt.Errorf("%s test %q: principal %s is declared differently in another test", kind, t.Name, principal.ID)
+		}
+		suite.Principals[principal.ID] = principal
+
+		resource := TestResource{Kind: kind, ID: t.ResourceID, Attr: t.ResourceAttr}
+		if resource.ID == "" {
+			resource.ID = r.Resource
+		}
+		if res, ok := suite.Resources[resource.ID]; ok && !reflect.DeepEqual(res, resource) {
+			return TestSuite{}, fmt.Errorf("%s test %q: resource %s is declared differently in another test", kind, t.Name, resource.ID)
+		}
+		suite.Resources[resource.ID] = resource
+
+		expected := make(map[string]string, len(t.Allow)+len(t.Deny))
+		for _, a := range t.Allow {
+			expected[a] = EffectAllow
+		}
+		for _, a := range t.Deny {
+			expected[a] = EffectDeny
+		}
+		suite.Tests = append(suite.Tests, TestTable{
+			Name: t.Name,
+			Input: TestInput{
+				Principals: []string{principal.ID},
+				Resources:  []string{resource.ID},
+				Actions:    append(slices.Clone(t.Allow), t.Deny...),
+			},
+			Expected: []TestExpectation{{
+				Principal: principal.ID,
+				Resource:  resource.ID,
+				Actions:   expected,
+			}},
+		})
+	}
+	return suite, nil
+}
+
+// validatePolicyTest checks that the actions and roles the test refers to are defined for the resource.
+func validatePolicyTest(kind string, r ResourceRule, t PolicyTest) error {
+	if t.Name == "" {
+		return fmt.Errorf("%s: test name is required", kind)
+	}
+	if t.Principal.ID == "" {
+		return fmt.Errorf("%s test %q: principal id is required", kind, t.Name)
+	}
+	if len(t.Allow)+len(t.Deny) == 0 {
+		return fmt.Errorf("%s test %q: no actions to test", kind, t.Name)
+	}
+
+	roles := map[string]struct{}{}
+	for _, a := range r.Actions {
+		for _, role := range a.Roles {
+			roles[role] = struct{}{}
+		}
+	}
+	_, anyRole := roles["*"]
+	for _, role := range t.Principal.Roles {
+		if _, ok := roles[role]; !ok && !anyRole {
+			return fmt.Errorf("%s test %q: role %s is not defined", kind, t.Name, role)
+		}
+	}
+
+	seen := map[string]struct{}{}
+	for _, a := range append(slices.Clone(t.Allow), t.Deny...) {
+		if _, ok := r.Actions[a]; !ok {
+			return fmt.Errorf("%s test %q: action %s is not defined", kind, t.Name, a)
+		}
+		if _, ok := seen[a]; ok {
+			return fmt.Errorf("%s test %q: action %s is expected more than once", kind, t.Name, a)
+		}
+		seen[a] = struct{}{}
+	}
+	return nil
+}
+
+// ValidatePolicies builds the policies of all resources with Evaluator so that invalid conditions are not generated,
+// and runs the tests of the resources that have them so that policies that do not grant what is intended are not generated.
+// Conditions of resources without tests may use CEL the evaluator does not support, which is left to Cerbos.
+func ValidatePolicies(serviceName string, resourceRules []ResourceRule) error {
+	policies, err := BuildPolicies(serviceName, resourceRules)
+	if err != nil {
+		return err
+	}
+	e, err := NewEvaluator(policies...)
+	if err != nil {
+		return err
+	}
+
+	suites, err := BuildTestSuites(serviceName, resourceRules)
+	if err != nil {
+		return err
+	}
+	tested := make([]string, 0, len(suites))
+	for _, r := range resourceRules {
+		if len(r.Tests) > 0 {
+			tested = append(tested, serviceName+":"+r.Resource)
+		}
+	}
+	if err := e.checkSupported(tested...); err != nil {
+		return fmt.Errorf("%w: the policy cannot be tested", err)
+	}
+
+	var errs []error
+	for _, s := range suites {
+		errs = append(errs, e.RunTestSuite(s)...)
+	}
+	return errors.Join(errs...)
+}
+
+// RunTestSuite runs the tests of the suite and returns an error for each unexpected decision.
+func (e *Evaluator) RunTestSuite(s TestSuite) []error {
+	var errs []error
+	for _, t := range s.Tests {
+		for _, exp := range t.Expected {
+			p, ok := s.Principals[exp.Principal]
+			if !ok {
+				errs = append(errs, fmt.Errorf("%s test %q: principal %s is not defined", s.Name, t.Name, exp.Principal))
+				continue
+			}
+			r, ok := s.Resources[exp.Resource]
+			if !ok {
+				errs = append(errs, fmt.Errorf("%s test %q: resource %s is not defined", s.Name, t.Name, exp.Resource))
+				continue
+			}
+
+			principal := Principal{ID: p.ID, Roles: p.Roles, Attr: p.Attr}
+			resource := Resource{Kind: r.Kind, ID: r.ID, Attr: r.Attr}
+			for _, action := range sortedKeys(exp.Actions) {
+				effect := EffectDeny
+				if e.Check(principal, resource, action) {
+					effect = EffectAllow
+				}
+				if want := exp.Actions[action]; effect != want {
+					errs = append(errs, fmt.Errorf("%s test %q: %s on %s by %s: expected %s but got %s", s.Name, t.Name, action, r.ID, p.ID, want, effect))
+				}
+			}
+		}
+	}
+	return errs
+}
+
+// testSuiteName returns a name such as "FlowProjectTestSuite".
+func testSuiteName(kind string) string {
+	var b strings.Builder
+	for _, w := range strings.FieldsFunc(kind, func(r rune) bool {
+		return r == ':' || r == '_' || r == '-' || r == '.'
+	}) {
+		b.WriteString(strings.ToUpper(w[:1]) + w[1:])
+	}
+	b.WriteString("TestSuite")
+	return b.String()
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
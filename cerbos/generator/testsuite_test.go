@@ -0,0 +1,257 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testResourceRules = []ResourceRule{
+	{
+		Resource: "project",
+		Actions: map[string]ActionRule{
+			"read":  {Roles: []string{"owner", "reader"}},
+			"write": {Roles: []string{"owner"}},
+			"delete": {
+				Roles:     []string{"owner"},
+				Condition: SimpleExpr(`R.attr.owner == P.id`),
+			},
+		},
+		Tests: []PolicyTest{
+			{
+				Name:      "reader can only read",
+				Principal: Principal{ID: "bob", Roles: []string{"reader"}},
+				Allow:     []string{"read"},
+				Deny:      []string{"write", "delete"},
+			},
+			{
+				Name:         "owner can delete own project",
+				Principal:    Principal{ID: "alice", Roles: []string{"owner"}},
+				ResourceID:   "own",
+				ResourceAttr: map[string]any{"owner": "alice"},
+				Allow:        []string{"read", "write", "delete"},
+			},
+		},
+	},
+	{
+		Resource: "workflow",
+		Actions: map[string]ActionRule{
+			"read": {Roles: []string{"reader"}},
+		},
+	},
+}
+
+func TestGeneratePolicies_TestSuite(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, GeneratePolicies("flow", testResourceRules, dir))
+
+	files, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"flow_project.yaml", "flow_project_test.yaml", "flow_workflow.yaml"}, func() (res []string) {
+		for _, f := range files {
+			res = append(res, f.Name())
+		}
+		return
+	}())
+
+	content, err := os.ReadFile(filepath.Join(dir, "flow_project_test.yaml"))
+	assert.NoError(t, err)
+	assert.Equal(t, `name: FlowProjectTestSuite
+description: Tests for flow:project
+principals:
+  alice:
+    id: alice
+    roles:
+    - owner
+  bob:
+    id: bob
+    roles:
+    - reader
+resources:
+  own:
+    kind: flow:project
+    id: own
+    attr:
+      owner: alice
+  project:
+    kind: flow:project
+    id: project
+tests:
+- name: reader can only read
+  input:
+    principals:
+    - bob
+    resources:
+    - project
+    actions:
+    - read
+    - write
+    - delete
+  expected:
+  - principal: bob
+    resource: project
+    actions:
+      delete: EFFECT_DENY
+      read: EFFECT_ALLOW
+      write: EFFECT_DENY
+- name: owner can delete own project
+  input:
+    principals:
+    - alice
+    resources:
+    - own
+    actions:
+    - read
+    - write
+    - delete
+  expected:
+  - principal: alice
+    resource: own
+    actions:
+      delete: EFFECT_ALLOW
+      read: EFFECT_ALLOW
+      write: EFFECT_ALLOW
+`, string(content))
+
+	// the evaluator ignores test suites
+	e, err := LoadEvaluator(os.DirFS(dir))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"flow:project", "flow:workflow"}, e.Resources())
+}
+
+func TestValidatePolicies(t *testing.T) {
+	assert.NoError(t, ValidatePolicies("flow", testResourceRules))
+
+	rules := func(tests ...PolicyTest) []ResourceRule {
+		return []ResourceRule{{
+			Resource: "project",
+			Actions: map[string]ActionRule{
+				"read":   {Roles: []string{"reader"}},
+				"delete": {Roles: []string{"owner"}, Condition: SimpleExpr(`R.attr.owner == P.id`)},
+			},
+			Tests: tests,
+		}}
+	}
+	reader := Principal{ID: "bob", Roles: []string{"reader"}}
+
+	tests := []struct {
+		name    string
+		rules   []ResourceRule
+		wantErr string
+	}{
+		{
+			name:    "no name",
+			rules:   rules(PolicyTest{Principal: reader, Allow: []string{"read"}}),
+			wantErr: "flow:project: test name is required",
+		},
+		{
+			name:    "no principal",
+			rules:   rules(PolicyTest{Name: "t", Allow: []string{"read"}}),
+			wantErr: `flow:project test "t": principal id is required`,
+		},
+		{
+			name:    "no actions",
+			rules:   rules(PolicyTest{Name: "t", Principal: reader}),
+			wantErr: `flow:project test "t": no actions to test`,
+		},
+		{
+			name:    "unknown action",
+			rules:   rules(PolicyTest{Name: "t", Principal: reader, Allow: []string{"write"}}),
+			wantErr: `flow:project test "t": action write is not defined`,
+		},
+		{
+			name:    "duplicated action",
+			rules:   rules(PolicyTest{Name: "t", Principal: reader, Allow: []string{"read"}, Deny: []string{"read"}}),
+			wantErr: `flow:project test "t": action read is expected more than once`,
+		},
+		{
+			name:    "unknown role",
+			rules:   rules(PolicyTest{Name: "t", Principal: Principal{ID: "bob", Roles: []string{"admin"}}, Allow: []string{"read"}}),
+			wantErr: `flow:project test "t": role admin is not defined`,
+		},
+		{
+			name: "principal declared differently",
+			rules: rules(
+				PolicyTest{Name: "t1", Principal: reader, Allow: []string{"read"}},
+				PolicyTest{Name: "t2", Principal: Principal{ID: "bob", Roles: []string{"owner"}}, Deny: []string{"read"}},
+			),
+			wantErr: `flow:project test "t2": principal bob is declared differently in another test`,
+		},
+		{
+			name:    "unexpected decision",
+			rules:   rules(PolicyTest{Name: "t", Principal: reader, Allow: []string{"read", "delete"}}),
+			wantErr: `FlowProjectTestSuite test "t": delete on project by bob: expected EFFECT_ALLOW but got EFFECT_DENY`,
+		},
+		{
+			name: "invalid condition",
+			rules: []ResourceRule{{
+				Resource: "project",
+				Actions: map[string]ActionRule{
+					"read": {Roles: []string{"reader"}, Condition: SimpleExpr(`R.attr.owner ==`)},
+				},
+				Tests: []PolicyTest{{Name: "t", Principal: reader, Deny: []string{"read"}}},
+			}},
+			wantErr: `policy flow:project rule 0: invalid expression "R.attr.owner =="`,
+		},
+		{
+			name: "unsupported condition",
+			rules: []ResourceRule{{
+				Resource: "project",
+				Actions: map[string]ActionRule{
+					"read": {Roles: []string{"reader"}, Condition: SimpleExpr(`hierarchy(R.attr.path).size() > 1`)},
+				},
+				Tests: []PolicyTest{{Name: "t", Principal: reader, Deny: []string{"read"}}},
+			}},
+			wantErr: `policy flow:project rule 0: unsupported expression`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePolicies("flow", tt.rules)
+			assert.ErrorContains(t, err, tt.wantErr)
+
+			// nothing is generated
+			dir := t.TempDir()
+			assert.Error(t, GeneratePolicies("flow", tt.rules, dir))
+			files, _ := os.ReadDir(dir)
+			assert.Empty(t, files)
+		})
+	}
+}
+
+func TestValidatePolicies_Untested(t *testing.T) {
+	// unsupported conditions of resources without tests are left to Cerbos
+	rules := []ResourceRule{
+		{
+			Resource: "project",
+			Actions: map[string]ActionRule{
+				"read":  {Roles: []string{"reader"}, Condition: SimpleExpr(`R.attr.expiresAt > now()`)},
+				"write": {Roles: []string{"owner"}, Condition: SimpleExpr(`hierarchy(R.attr.path).size() > 1`)},
+			},
+		},
+		{
+			Resource: "workspace",
+			Actions: map[string]ActionRule{
+				"read": {Roles: []string{"reader"}},
+			},
+			Tests: []PolicyTest{{Name: "t", Principal: Principal{ID: "bob", Roles: []string{"reader"}}, Allow: []string{"read"}}},
+		},
+	}
+	assert.NoError(t, ValidatePolicies("flow", rules))
+
+	dir := t.TempDir()
+	assert.NoError(t, GeneratePolicies("flow", rules, dir))
+	files, _ := os.ReadDir(dir)
+	assert.Len(t, files, 3)
+
+	// but invalid conditions are not generated even without tests
+	rules[0].Actions["edit"] = ActionRule{Roles: []string{"owner"}, Condition: SimpleExpr(`R.attr.owner ==`)}
+	assert.ErrorContains(t, ValidatePolicies("flow", rules), `invalid expression "R.attr.owner =="`)
+	dir = t.TempDir()
+	assert.Error(t, GeneratePolicies("flow", rules, dir))
+	files, _ = os.ReadDir(dir)
+	assert.Empty(t, files)
+}
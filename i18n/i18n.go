@@ -18,6 +18,8 @@ type Localizer = i18n.Localizer
 
 type LocalizeConfig = i18n.LocalizeConfig
 
+type MessageNotFoundErr = i18n.MessageNotFoundErr
+
 func T(id string) *Message {
 	return &Message{
 		ID: id,
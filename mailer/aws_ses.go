@@ -38,40 +38,73 @@ func NewSES(ctx context.Context, senderName, senderEmail string) Mailer {
 }
 
 func (m *awsMailer) SendMail(ctx context.Context, tos []Contact, subject, plainContent, htmlContent string) error {
-	mail := &ses.SendEmailInput{
+	return m.Send(ctx, newSimpleMessage(tos, subject, plainContent, htmlContent))
+}
+
+func (m *awsMailer) Send(ctx context.Context, msg *Message) error {
+	if err := msg.Validate(); err != nil {
+		return err
+	}
+
+	var err error
+	// SendEmail supports neither attachments nor custom headers
+	if len(msg.Attachments) > 0 || len(msg.Headers) > 0 {
+		err = m.sendRaw(ctx, msg)
+	} else {
+		_, err = m.client.SendEmail(ctx, m.sendEmailInput(msg))
+	}
+	if err != nil {
+		return err
+	}
+
+	logMail(ctx, msg.To, msg.Subject)
+	return nil
+}
+
+func (m *awsMailer) sendEmailInput(msg *Message) *ses.SendEmailInput {
+	return &ses.SendEmailInput{
 		Destination: &types.Destination{
-			CcAddresses: []string{},
-			ToAddresses: lo.Map(tos, func(t Contact, _ int) string {
-				return formatContact(t)
-			}),
+			ToAddresses:  lo.Map(msg.To, formatContactFunc),
+			CcAddresses:  lo.Map(msg.CC, formatContactFunc),
+			BccAddresses: lo.Map(msg.BCC, formatContactFunc),
 		},
+		ReplyToAddresses: lo.Map(msg.ReplyTo, formatContactFunc),
 		Message: &types.Message{
 			Body: &types.Body{
 				Html: &types.Content{
 					Charset: aws.String(charSet),
-					Data:    aws.String(htmlContent),
+					Data:    aws.String(msg.HTMLContent),
 				},
 				Text: &types.Content{
 					Charset: aws.String(charSet),
-					Data:    aws.String(plainContent),
+					Data:    aws.String(msg.PlainContent),
 				},
 			},
 			Subject: &types.Content{
 				Charset: aws.String(charSet),
-				Data:    aws.String(subject),
+				Data:    aws.String(msg.Subject),
 			},
 		},
 		Source: aws.String(formatContact(m.sender)),
 	}
+}
 
-	_, err := m.client.SendEmail(ctx, mail)
-
+func (m *awsMailer) sendRaw(ctx context.Context, msg *Message) error {
+	data, err := newMessage(formatContact(m.sender), msg).encodeMessage()
 	if err != nil {
 		return err
 	}
 
-	logMail(ctx, tos, subject)
-	return nil
+	_, err = m.client.SendRawEmail(ctx, &ses.SendRawEmailInput{
+		Destinations: lo.Map(msg.Recipients(), func(c Contact, _ int) string { return c.Email }),
+		RawMessage:   &types.RawMessage{Data: data},
+		Source:       aws.String(formatContact(m.sender)),
+	})
+	return err
+}
+
+func formatContactFunc(c Contact, _ int) string {
+	return formatContact(c)
 }
 
 func formatContact(contact Contact) string {
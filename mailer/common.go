@@ -3,18 +3,26 @@ package mailer
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"fmt"
-	"io"
+	"maps"
+	"mime"
 	"mime/multipart"
+	"mime/quotedprintable"
 	"net/mail"
 	"net/textproto"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/reearth/reearthx/log"
+	"github.com/reearth/reearthx/util"
 )
 
 type Mailer interface {
+	// SendMail sends a mail with plain and HTML contents. It is a shorthand of Send.
 	SendMail(ctx context.Context, toContacts []Contact, subject, plainContent, htmlContent string) error
+	Send(ctx context.Context, msg *Message) error
 }
 
 type Contact struct {
@@ -80,53 +88,171 @@ func verifyEmails(contacts []Contact) ([]string, error) {
 
 type message struct {
 	to           []string
+	cc           []string
+	replyTo      []string
 	from         string
 	subject      string
 	plainContent string
 	htmlContent  string
+	headers      map[string]string
+	attachments  []Attachment
 }
 
-func (m *message) encodeContent() (string, error) {
-	buf := bytes.NewBuffer(nil)
-	writer := multipart.NewWriter(buf)
-	boundary := writer.Boundary()
+func newMessage(from string, m *Message) *message {
+	return &message{
+		to:           formatAddresses(m.To),
+		cc:           formatAddresses(m.CC),
+		replyTo:      formatAddresses(m.ReplyTo),
+		from:         from,
+		subject:      m.Subject,
+		plainContent: m.PlainContent,
+		htmlContent:  m.HTMLContent,
+		headers:      m.Headers,
+		attachments:  m.Attachments,
+	}
+}
 
-	altBuffer, err := writer.CreatePart(textproto.MIMEHeader{"Content-Type": {"multipart/alternative; boundary=" + boundary}})
+type mimePart struct {
+	header textproto.MIMEHeader
+	body   []byte
+}
+
+func (m *message) encodeContent() (string, error) {
+	p, err := m.content()
 	if err != nil {
 		return "", err
 	}
-	altWriter := multipart.NewWriter(altBuffer)
-	err = altWriter.SetBoundary(boundary)
-	if err != nil {
-		return "", err
+
+	buf := bytes.NewBuffer(nil)
+	for _, k := range slices.Sorted(maps.Keys(p.header)) {
+		for _, v := range p.header[k] {
+			fmt.Fprintf(buf, "%s: %s\r\n", k, v)
+		}
 	}
-	var content io.Writer
-	content, err = altWriter.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/plain"}})
-	if err != nil {
-		return "", err
+	buf.WriteString("\r\n")
+	buf.Write(p.body)
+	return buf.String(), nil
+}
+
+// content builds the body as multipart/mixed (attachments) > multipart/related (inline images) > multipart/alternative (plain and HTML).
+func (m *message) content() (p mimePart, err error) {
+	var text []mimePart
+	if m.plainContent != "" || m.htmlContent == "" {
+		text = append(text, textPart("text/plain", m.plainContent+"\r\n\r\n"))
+	}
+	if m.htmlContent != "" {
+		text = append(text, textPart("text/html", m.htmlContent+"\r\n"))
+	}
+	if len(text) == 1 {
+		p = text[0]
+	} else if p, err = multipartPart("alternative", text); err != nil {
+		return
 	}
 
-	_, err = content.Write([]byte(m.plainContent + "\r\n\r\n"))
-	if err != nil {
-		return "", err
+	var inline, attached []mimePart
+	for _, a := range m.attachments {
+		if a.Inline() {
+			inline = append(inline, attachmentPart(a))
+		} else {
+			attached = append(attached, attachmentPart(a))
+		}
 	}
-	content, err = altWriter.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/html"}})
-	if err != nil {
-		return "", err
+	if len(inline) > 0 {
+		if p, err = multipartPart("related", append([]mimePart{p}, inline...)); err != nil {
+			return
+		}
 	}
-	_, err = content.Write([]byte(m.htmlContent + "\r\n"))
-	if err != nil {
-		return "", err
+	if len(attached) > 0 {
+		if p, err = multipartPart("mixed", append([]mimePart{p}, attached...)); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func textPart(contentType, content string) mimePart {
+	buf := bytes.NewBuffer(nil)
+	w := quotedprintable.NewWriter(buf)
+	_, _ = w.Write([]byte(content))
+	_ = w.Close()
+	return mimePart{
+		header: textproto.MIMEHeader{
+			"Content-Type":              {contentType + "; charset=UTF-8"},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		},
+		body: buf.Bytes(),
 	}
-	_ = altWriter.Close()
-	return buf.String(), nil
+}
+
+func attachmentPart(a Attachment) mimePart {
+	h := textproto.MIMEHeader{
+		"Content-Transfer-Encoding": {"base64"},
+	}
+	ct := a.contentType()
+	if mt, params, err := mime.ParseMediaType(ct); err == nil && a.Filename != "" {
+		params["name"] = a.Filename
+		ct = mime.FormatMediaType(mt, params)
+	}
+	h.Set("Content-Type", ct)
+	disposition := "attachment"
+	if a.Inline() {
+		disposition = "inline"
+		h.Set("Content-ID", "<"+a.ContentID+">")
+	}
+	if a.Filename != "" {
+		h.Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": a.Filename}))
+	} else {
+		h.Set("Content-Disposition", disposition)
+	}
+
+	// base64 lines must not be longer than 76 characters
+	enc := base64.StdEncoding.EncodeToString(a.Data)
+	buf := bytes.NewBuffer(make([]byte, 0, len(enc)+len(enc)/76*2+2))
+	for len(enc) > 76 {
+		buf.WriteString(enc[:76] + "\r\n")
+		enc = enc[76:]
+	}
+	buf.WriteString(enc + "\r\n")
+	return mimePart{header: h, body: buf.Bytes()}
+}
+
+func multipartPart(subtype string, parts []mimePart) (mimePart, error) {
+	buf := bytes.NewBuffer(nil)
+	w := multipart.NewWriter(buf)
+	for _, p := range parts {
+		pw, err := w.CreatePart(p.header)
+		if err != nil {
+			return mimePart{}, err
+		}
+		if _, err := pw.Write(p.body); err != nil {
+			return mimePart{}, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return mimePart{}, err
+	}
+	return mimePart{
+		header: textproto.MIMEHeader{"Content-Type": {"multipart/" + subtype + "; boundary=" + w.Boundary()}},
+		body:   buf.Bytes(),
+	}, nil
 }
 
 func (m *message) encodeMessage() ([]byte, error) {
 	buf := bytes.NewBuffer(nil)
-	fmt.Fprintf(buf, "Subject: %s\n", m.subject)
-	fmt.Fprintf(buf, "From: %s\n", m.from)
-	fmt.Fprintf(buf, "To: %s\n", strings.Join(m.to, ","))
+	fmt.Fprintf(buf, "Subject: %s\r\n", mime.QEncoding.Encode("UTF-8", m.subject))
+	fmt.Fprintf(buf, "From: %s\r\n", m.from)
+	fmt.Fprintf(buf, "To: %s\r\n", strings.Join(m.to, ", "))
+	if len(m.cc) > 0 {
+		fmt.Fprintf(buf, "Cc: %s\r\n", strings.Join(m.cc, ", "))
+	}
+	if len(m.replyTo) > 0 {
+		fmt.Fprintf(buf, "Reply-To: %s\r\n", strings.Join(m.replyTo, ", "))
+	}
+	fmt.Fprintf(buf, "Date: %s\r\n", util.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	for _, k := range slices.Sorted(maps.Keys(m.headers)) {
+		fmt.Fprintf(buf, "%s: %s\r\n", textproto.CanonicalMIMEHeaderKey(k), m.headers[k])
+	}
 	content, err := m.encodeContent()
 	if err != nil {
 		return nil, err
@@ -136,6 +262,17 @@ func (m *message) encodeMessage() ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+func formatAddresses(contacts []Contact) []string {
+	if len(contacts) == 0 {
+		return nil
+	}
+	res := make([]string, 0, len(contacts))
+	for _, c := range contacts {
+		res = append(res, (&mail.Address{Name: c.Name, Address: c.Email}).String())
+	}
+	return res
+}
+
 type ToList []Contact
 
 func (l ToList) String() string {
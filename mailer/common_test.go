@@ -1,6 +1,12 @@
 package mailer
 
 import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/textproto"
 	"strings"
 	"testing"
 
@@ -99,3 +105,76 @@ func Test_message_encodeMessage(t *testing.T) {
 		})
 	}
 }
+
+func Test_message_encodeMessage_rich(t *testing.T) {
+	m := newMessage("from@example.com", &Message{
+		To:           []Contact{{Email: "a@example.com", Name: "A"}},
+		CC:           []Contact{{Email: "b@example.com"}},
+		BCC:          []Contact{{Email: "c@example.com"}},
+		ReplyTo:      []Contact{{Email: "d@example.com"}},
+		Subject:      "こんにちは",
+		PlainContent: "plain",
+		HTMLContent:  `<img src="cid:logo">`,
+		Headers:      map[string]string{"list-unsubscribe": "<https://example.com/u>"},
+		Attachments: []Attachment{
+			{ContentID: "logo", ContentType: "image/png", Data: []byte("png")},
+			{Filename: "a.txt", Data: []byte("text")},
+		},
+	})
+	got, err := m.encodeMessage()
+	assert.NoError(t, err)
+
+	msg, err := mail.ReadMessage(bytes.NewReader(got))
+	assert.NoError(t, err)
+	assert.Equal(t, `"A" <a@example.com>`, msg.Header.Get("To"))
+	assert.Equal(t, "<b@example.com>", msg.Header.Get("Cc"))
+	assert.Equal(t, "", msg.Header.Get("Bcc"))
+	assert.Equal(t, "<d@example.com>", msg.Header.Get("Reply-To"))
+	assert.Equal(t, "<https://example.com/u>", msg.Header.Get("List-Unsubscribe"))
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	assert.NoError(t, err)
+	assert.Equal(t, "こんにちは", subject)
+
+	// mixed > related > alternative
+	mixed := readParts(t, msg.Header.Get("Content-Type"), msg.Body)
+	assert.Len(t, mixed, 2)
+	assert.Equal(t, `attachment; filename=a.txt`, mixed[1].header.Get("Content-Disposition"))
+	assert.Equal(t, "text/plain; charset=utf-8; name=a.txt", mixed[1].header.Get("Content-Type"))
+	assert.Equal(t, "dGV4dA==\r\n", string(mixed[1].body))
+
+	related := readParts(t, mixed[0].header.Get("Content-Type"), bytes.NewReader(mixed[0].body))
+	assert.Len(t, related, 2)
+	assert.Equal(t, "<logo>", related[1].header.Get("Content-Id"))
+	assert.Equal(t, "inline", related[1].header.Get("Content-Disposition"))
+
+	alternative := readParts(t, related[0].header.Get("Content-Type"), bytes.NewReader(related[0].body))
+	assert.Len(t, alternative, 2)
+	assert.Equal(t, "text/plain; charset=UTF-8", alternative[0].header.Get("Content-Type"))
+	assert.Equal(t, "text/html; charset=UTF-8", alternative[1].header.Get("Content-Type"))
+}
+
+type testPart struct {
+	header textproto.MIMEHeader
+	body   []byte
+}
+
+func readParts(t *testing.T, contentType string, body io.Reader) []testPart {
+	t.Helper()
+	mt, params, err := mime.ParseMediaType(contentType)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(mt, "multipart/"))
+
+	var res []testPart
+	r := multipart.NewReader(body, params["boundary"])
+	for {
+		p, err := r.NextRawPart()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		b, err := io.ReadAll(p)
+		assert.NoError(t, err)
+		res = append(res, testPart{header: p.Header, body: b})
+	}
+	return res
+}
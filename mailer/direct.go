@@ -24,7 +24,15 @@ func NewDirect(from string) Mailer {
 }
 
 func (m *direct) SendMail(ctx context.Context, to []Contact, subject, plainContent, htmlContent string) error {
-	emails, err := verifyEmails(to)
+	return m.Send(ctx, newSimpleMessage(to, subject, plainContent, htmlContent))
+}
+
+func (m *direct) Send(ctx context.Context, msg *Message) error {
+	if err := msg.Validate(); err != nil {
+		return err
+	}
+
+	emails, err := verifyEmails(msg.Recipients())
 	if err != nil {
 		return err
 	}
@@ -39,31 +47,24 @@ func (m *direct) SendMail(ctx context.Context, to []Contact, subject, plainConte
 		return err
 	}
 
-	msg, err := m.message(ctx, emails, subject, plainContent, htmlContent)
+	encodedMsg, err := m.message(ctx, msg)
 	if err != nil {
 		return err
 	}
 
 	for i, to := range emails {
 		host := mxHosts[i]
-		if err := m.send(ctx, to, host, msg); err != nil {
+		if err := m.send(ctx, to, host, encodedMsg); err != nil {
 			return err
 		}
 	}
 
-	logMail(ctx, to, subject)
+	logMail(ctx, msg.To, msg.Subject)
 	return nil
 }
 
-func (m *direct) message(ctx context.Context, emails []string, subject, plainContent, htmlContent string) ([]byte, error) {
-	msg := &message{
-		to:           emails,
-		from:         m.from,
-		subject:      subject,
-		plainContent: plainContent,
-		htmlContent:  htmlContent,
-	}
-	encodedMsg, err := msg.encodeMessage()
+func (m *direct) message(ctx context.Context, msg *Message) ([]byte, error) {
+	encodedMsg, err := newMessage(m.from, msg).encodeMessage()
 	if err != nil {
 		return nil, rerror.ErrInternalByWithContext(ctx, err)
 	}
@@ -13,8 +13,16 @@ func NewLogger() Mailer {
 	return &logger{}
 }
 
-func (m *logger) SendMail(ctx context.Context, to []Contact, subject, plainContent, _ string) error {
-	logMail(ctx, to, subject)
-	fmt.Printf("%s\n%s\n%s\n", loggerSep, plainContent, loggerSep)
+func (m *logger) SendMail(ctx context.Context, to []Contact, subject, plainContent, htmlContent string) error {
+	return m.Send(ctx, newSimpleMessage(to, subject, plainContent, htmlContent))
+}
+
+func (m *logger) Send(ctx context.Context, msg *Message) error {
+	logMail(ctx, msg.To, msg.Subject)
+	fmt.Printf("%s\n%s\n", loggerSep, msg.PlainContent)
+	for _, a := range msg.Attachments {
+		fmt.Printf("[attachment: %s (%s, %d bytes)]\n", a.Filename, a.contentType(), len(a.Data))
+	}
+	fmt.Printf("%s\n", loggerSep)
 	return nil
 }
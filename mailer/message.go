@@ -0,0 +1,131 @@
+package mailer
+
+import (
+	"fmt"
+	"maps"
+	"mime"
+	"net/textproto"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Message is a mail with everything the backends can send.
+type Message struct {
+	To      []Contact
+	CC      []Contact
+	BCC     []Contact
+	ReplyTo []Contact
+	Subject string
+	// PlainContent and HTMLContent are sent as alternatives of each other.
+	PlainContent string
+	HTMLContent  string
+	Attachments  []Attachment
+	// Headers are custom headers such as List-Unsubscribe.
+	// Headers managed by the mailer (From, To, Subject, Content-Type, etc.) cannot be overridden.
+	Headers map[string]string
+}
+
+// Attachment is a file attached to the message.
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+	// ContentID makes the attachment an inline image that can be referenced from HTMLContent as "cid:<ContentID>".
+	ContentID string
+}
+
+func (a Attachment) Inline() bool {
+	return a.ContentID != ""
+}
+
+var reservedHeaders = map[string]struct{}{
+	"Bcc":                       {},
+	"Cc":                        {},
+	"Content-Transfer-Encoding": {},
+	"Content-Type":              {},
+	"Date":                      {},
+	"From":                      {},
+	"Mime-Version":              {},
+	"Reply-To":                  {},
+	"Subject":                   {},
+	"To":                        {},
+}
+
+// Recipients returns all recipients including CC and BCC.
+func (m *Message) Recipients() []Contact {
+	res := make([]Contact, 0, len(m.To)+len(m.CC)+len(m.BCC))
+	res = append(res, m.To...)
+	res = append(res, m.CC...)
+	return append(res, m.BCC...)
+}
+
+func (m *Message) Validate() error {
+	if m == nil {
+		return fmt.Errorf("message is nil")
+	}
+	if len(m.To)+len(m.CC)+len(m.BCC) == 0 {
+		return fmt.Errorf("message has no recipients")
+	}
+	for _, l := range [][]Contact{m.To, m.CC, m.BCC, m.ReplyTo} {
+		if _, err := verifyEmails(l); err != nil {
+			return err
+		}
+	}
+	for k, v := range m.Headers {
+		if k == "" || strings.ContainsAny(k, ": \r\n") || strings.ContainsAny(v, "\r\n") {
+			return fmt.Errorf("invalid header %q", k)
+		}
+		if _, ok := reservedHeaders[textproto.CanonicalMIMEHeaderKey(k)]; ok {
+			return fmt.Errorf("header %s cannot be set", k)
+		}
+	}
+	for _, a := range m.Attachments {
+		if a.Filename == "" && !a.Inline() {
+			return fmt.Errorf("attachment has no filename")
+		}
+		if strings.ContainsAny(a.ContentID, "<>\r\n") {
+			return fmt.Errorf("invalid content id %q", a.ContentID)
+		}
+	}
+	return nil
+}
+
+func (m *Message) Clone() *Message {
+	if m == nil {
+		return nil
+	}
+	m2 := *m
+	m2.To = slices.Clone(m.To)
+	m2.CC = slices.Clone(m.CC)
+	m2.BCC = slices.Clone(m.BCC)
+	m2.ReplyTo = slices.Clone(m.ReplyTo)
+	m2.Headers = maps.Clone(m.Headers)
+	if m.Attachments != nil {
+		m2.Attachments = make([]Attachment, 0, len(m.Attachments))
+		for _, a := range m.Attachments {
+			a.Data = slices.Clone(a.Data)
+			m2.Attachments = append(m2.Attachments, a)
+		}
+	}
+	return &m2
+}
+
+func newSimpleMessage(to []Contact, subject, plainContent, htmlContent string) *Message {
+	return &Message{
+		To:           to,
+		Subject:      subject,
+		PlainContent: plainContent,
+		HTMLContent:  htmlContent,
+	}
+}
+
+func (a Attachment) contentType() string {
+	if a.ContentType != "" {
+		return a.ContentType
+	}
+	if t := mime.TypeByExtension(filepath.Ext(a.Filename)); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
@@ -0,0 +1,99 @@
+package mailer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessage_Validate(t *testing.T) {
+	to := []Contact{{Email: "a@example.com"}}
+	tests := []struct {
+		name    string
+		msg     *Message
+		wantErr string
+	}{
+		{
+			name: "valid",
+			msg: &Message{
+				To:          to,
+				BCC:         []Contact{{Email: "b@example.com"}},
+				ReplyTo:     []Contact{{Email: "c@example.com"}},
+				Headers:     map[string]string{"List-Unsubscribe": "<mailto:u@example.com>"},
+				Attachments: []Attachment{{Filename: "a.pdf"}, {ContentID: "logo"}},
+			},
+		},
+		{
+			name:    "nil",
+			wantErr: "message is nil",
+		},
+		{
+			name:    "no recipients",
+			msg:     &Message{},
+			wantErr: "message has no recipients",
+		},
+		{
+			name:    "invalid cc",
+			msg:     &Message{To: to, CC: []Contact{{Email: "x"}}},
+			wantErr: "invalid email x",
+		},
+		{
+			name:    "header injection",
+			msg:     &Message{To: to, Headers: map[string]string{"X-A": "a\r\nBcc: x@example.com"}},
+			wantErr: `invalid header "X-A"`,
+		},
+		{
+			name:    "reserved header",
+			msg:     &Message{To: to, Headers: map[string]string{"from": "x@example.com"}},
+			wantErr: "header from cannot be set",
+		},
+		{
+			name:    "attachment without filename",
+			msg:     &Message{To: to, Attachments: []Attachment{{Data: []byte("a")}}},
+			wantErr: "attachment has no filename",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.msg.Validate()
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestMessage_Recipients(t *testing.T) {
+	m := &Message{
+		To:      []Contact{{Email: "a@example.com"}},
+		CC:      []Contact{{Email: "b@example.com"}},
+		BCC:     []Contact{{Email: "c@example.com"}},
+		ReplyTo: []Contact{{Email: "d@example.com"}},
+	}
+	assert.Equal(t, []Contact{{Email: "a@example.com"}, {Email: "b@example.com"}, {Email: "c@example.com"}}, m.Recipients())
+}
+
+func TestMessage_Clone(t *testing.T) {
+	m := &Message{
+		To:          []Contact{{Email: "a@example.com"}},
+		Attachments: []Attachment{{Filename: "a.txt", Data: []byte("a")}},
+		Headers:     map[string]string{"X-A": "a"},
+	}
+	m2 := m.Clone()
+	assert.Equal(t, m, m2)
+	m2.To[0].Email = "b@example.com"
+	m2.Attachments[0].Data[0] = 'b'
+	m2.Headers["X-A"] = "b"
+	assert.Equal(t, "a@example.com", m.To[0].Email)
+	assert.Equal(t, []byte("a"), m.Attachments[0].Data)
+	assert.Equal(t, "a", m.Headers["X-A"])
+	assert.Nil(t, (*Message)(nil).Clone())
+}
+
+func TestAttachment_contentType(t *testing.T) {
+	assert.Equal(t, "image/png", Attachment{Filename: "a.png"}.contentType())
+	assert.Equal(t, "text/csv", Attachment{Filename: "a.png", ContentType: "text/csv"}.contentType())
+	assert.Equal(t, "application/octet-stream", Attachment{Filename: "a"}.contentType())
+}
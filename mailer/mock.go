@@ -14,9 +14,14 @@ type Mock struct {
 
 type Mail struct {
 	To           []Contact
+	CC           []Contact
+	BCC          []Contact
+	ReplyTo      []Contact
 	Subject      string
 	PlainContent string
 	HTMLContent  string
+	Attachments  []Attachment
+	Headers      map[string]string
 }
 
 func NewMock() *Mock {
@@ -24,13 +29,23 @@ func NewMock() *Mock {
 }
 
 func (m *Mock) SendMail(ctx context.Context, to []Contact, subject, text, html string) error {
+	return m.Send(ctx, newSimpleMessage(append([]Contact{}, to...), subject, text, html))
+}
+
+func (m *Mock) Send(ctx context.Context, msg *Message) error {
+	msg = msg.Clone()
 	m.lock.Lock()
 	defer m.lock.Unlock()
 	m.mails = append(m.mails, Mail{
-		To:           append([]Contact{}, to...),
-		Subject:      subject,
-		PlainContent: text,
-		HTMLContent:  html,
+		To:           msg.To,
+		CC:           msg.CC,
+		BCC:          msg.BCC,
+		ReplyTo:      msg.ReplyTo,
+		Subject:      msg.Subject,
+		PlainContent: msg.PlainContent,
+		HTMLContent:  msg.HTMLContent,
+		Attachments:  msg.Attachments,
+		Headers:      msg.Headers,
 	})
 	return nil
 }
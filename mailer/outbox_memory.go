@@ -0,0 +1,95 @@
+package mailer
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+type OutboxMemory struct {
+	lock  sync.Mutex
+	mails []*OutboxMail
+}
+
+var _ Outbox = (*OutboxMemory)(nil)
+
+func NewOutboxMemory() *OutboxMemory {
+	return &OutboxMemory{}
+}
+
+func (o *OutboxMemory) Add(_ context.Context, m *OutboxMail) error {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	if m.IdempotencyKey != "" && slices.ContainsFunc(o.mails, func(n *OutboxMail) bool {
+		return n.IdempotencyKey == m.IdempotencyKey
+	}) {
+		return ErrDuplicateMail
+	}
+	o.mails = append(o.mails, cloneOutboxMail(m))
+	return nil
+}
+
+func (o *OutboxMemory) Acquire(_ context.Context, now, leaseUntil time.Time, limit int) ([]*OutboxMail, error) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	var due []*OutboxMail
+	for _, m := range o.mails {
+		if m.Status == OutboxStatusPending && !m.NextAttemptAt.After(now) {
+			due = append(due, m)
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool {
+		return due[i].NextAttemptAt.Before(due[j].NextAttemptAt)
+	})
+	if limit > 0 && len(due) > limit {
+		due = due[:limit]
+	}
+
+	res := make([]*OutboxMail, 0, len(due))
+	for _, m := range due {
+		m.NextAttemptAt = leaseUntil
+		res = append(res, cloneOutboxMail(m))
+	}
+	return res, nil
+}
+
+func (o *OutboxMemory) FindByStatus(_ context.Context, status OutboxStatus) ([]*OutboxMail, error) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	var res []*OutboxMail
+	for _, m := range o.mails {
+		if m.Status == status {
+			res = append(res, cloneOutboxMail(m))
+		}
+	}
+	return res, nil
+}
+
+func (o *OutboxMemory) Save(_ context.Context, m *OutboxMail) error {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	for i, n := range o.mails {
+		if n.ID == m.ID {
+			o.mails[i] = cloneOutboxMail(m)
+			return nil
+		}
+	}
+	o.mails = append(o.mails, cloneOutboxMail(m))
+	return nil
+}
+
+func cloneOutboxMail(m *OutboxMail) *OutboxMail {
+	m2 := *m
+	m2.Message = *m.Message.Clone()
+	if m.SentAt != nil {
+		t := *m.SentAt
+		m2.SentAt = &t
+	}
+	return &m2
+}
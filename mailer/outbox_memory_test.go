@@ -0,0 +1,54 @@
+package mailer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutboxMemory(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	o := NewOutboxMemory()
+
+	m1 := &OutboxMail{ID: "1", IdempotencyKey: "k", Status: OutboxStatusPending, NextAttemptAt: now.Add(-time.Minute)}
+	m2 := &OutboxMail{ID: "2", Status: OutboxStatusPending, NextAttemptAt: now.Add(-2 * time.Minute)}
+	m3 := &OutboxMail{ID: "3", Status: OutboxStatusPending, NextAttemptAt: now.Add(time.Minute)}
+	assert.NoError(t, o.Add(ctx, m1))
+	assert.Same(t, ErrDuplicateMail, o.Add(ctx, &OutboxMail{ID: "4", IdempotencyKey: "k"}))
+	assert.NoError(t, o.Add(ctx, m2))
+	assert.NoError(t, o.Add(ctx, m3))
+
+	got, err := o.Acquire(ctx, now, now.Add(time.Minute), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2"}, ids(got))
+
+	got, err = o.Acquire(ctx, now, now.Add(time.Minute), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1"}, ids(got))
+
+	// leased mails are not acquired again until the lease expires
+	got, err = o.Acquire(ctx, now, now.Add(time.Minute), 0)
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+
+	got, err = o.Acquire(ctx, now.Add(time.Minute), now.Add(2*time.Minute), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1", "2", "3"}, ids(got))
+
+	got[1].Status = OutboxStatusSent
+	assert.NoError(t, o.Save(ctx, got[1]))
+	got, err = o.FindByStatus(ctx, OutboxStatusSent)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2"}, ids(got))
+}
+
+func ids(mails []*OutboxMail) []string {
+	res := make([]string, 0, len(mails))
+	for _, m := range mails {
+		res = append(res, m.ID)
+	}
+	return res
+}
@@ -0,0 +1,216 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/reearth/reearthx/log"
+	"github.com/reearth/reearthx/mongox"
+	"github.com/reearth/reearthx/rerror"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type OutboxMongo struct {
+	client *mongox.Collection
+}
+
+var _ Outbox = (*OutboxMongo)(nil)
+
+func NewOutboxMongo(client *mongox.Collection) *OutboxMongo {
+	return &OutboxMongo{client: client}
+}
+
+func (o *OutboxMongo) Init(ctx context.Context) error {
+	res, err := o.client.Indexes2(
+		ctx,
+		mongox.IndexFromKey("id", true),
+		mongox.IndexFromKey("status,nextattemptat", false),
+		mongox.Index{
+			Name:   "re_idempotencykey",
+			Key:    bson.D{{Key: "idempotencykey", Value: 1}},
+			Unique: true,
+			Filter: bson.M{"idempotencykey": bson.M{"$type": "string"}},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	added, updated, deleted := res.AddedNames(), res.UpdatedNames(), res.DeletedNames()
+	if len(added) > 0 || len(updated) > 0 || len(deleted) > 0 {
+		log.Infofc(ctx, "mongo: mailOutbox: index: deleted: %v, updated: %v, created: %v", deleted, updated, added)
+	}
+	return nil
+}
+
+func (o *OutboxMongo) Add(ctx context.Context, m *OutboxMail) error {
+	doc, _ := newOutboxMongoDocument(m)
+	if _, err := o.client.Client().InsertOne(ctx, doc); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrDuplicateMail
+		}
+		return rerror.ErrInternalByWithContext(ctx, err)
+	}
+	return nil
+}
+
+func (o *OutboxMongo) Acquire(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*OutboxMail, error) {
+	var res []*OutboxMail
+	for limit <= 0 || len(res) < limit {
+		var doc outboxMongoDocument
+		err := o.client.Client().FindOneAndUpdate(
+			ctx,
+			bson.M{
+				"status":        string(OutboxStatusPending),
+				"nextattemptat": bson.M{"$lte": now},
+			},
+			bson.M{"$set": bson.M{"nextattemptat": leaseUntil}},
+			options.FindOneAndUpdate().
+				SetSort(bson.D{{Key: "nextattemptat", Value: 1}}).
+				SetReturnDocument(options.After),
+		).Decode(&doc)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			break
+		}
+		if err != nil {
+			return nil, rerror.ErrInternalByWithContext(ctx, err)
+		}
+		res = append(res, doc.Model())
+	}
+	return res, nil
+}
+
+func (o *OutboxMongo) FindByStatus(ctx context.Context, status OutboxStatus) ([]*OutboxMail, error) {
+	c := mongox.NewSliceFuncConsumer(func(d *outboxMongoDocument) (*OutboxMail, error) {
+		return d.Model(), nil
+	})
+	if err := o.client.Find(ctx, bson.M{"status": string(status)}, c); err != nil {
+		return nil, err
+	}
+	return c.Result, nil
+}
+
+func (o *OutboxMongo) Save(ctx context.Context, m *OutboxMail) error {
+	doc, id := newOutboxMongoDocument(m)
+	return o.client.SaveOne(ctx, id, doc)
+}
+
+type outboxMongoDocument struct {
+	ID             string                          `bson:"id"`
+	IdempotencyKey string                          `bson:"idempotencykey,omitempty"`
+	To             []outboxMongoContactDocument    `bson:"to"`
+	CC             []outboxMongoContactDocument    `bson:"cc,omitempty"`
+	BCC            []outboxMongoContactDocument    `bson:"bcc,omitempty"`
+	ReplyTo        []outboxMongoContactDocument    `bson:"replyto,omitempty"`
+	Subject        string                          `bson:"subject"`
+	PlainContent   string                          `bson:"plaincontent"`
+	HTMLContent    string                          `bson:"htmlcontent"`
+	Attachments    []outboxMongoAttachmentDocument `bson:"attachments,omitempty"`
+	Headers        map[string]string               `bson:"headers,omitempty"`
+	Status         string                          `bson:"status"`
+	Attempts       int                             `bson:"attempts"`
+	LastError      string                          `bson:"lasterror,omitempty"`
+	CreatedAt      time.Time                       `bson:"createdat"`
+	NextAttemptAt  time.Time                       `bson:"nextattemptat"`
+	SentAt         *time.Time                      `bson:"sentat,omitempty"`
+}
+
+type outboxMongoContactDocument struct {
+	Email string `bson:"email"`
+	Name  string `bson:"name,omitempty"`
+}
+
+type outboxMongoAttachmentDocument struct {
+	Filename    string `bson:"filename,omitempty"`
+	ContentType string `bson:"contenttype,omitempty"`
+	Data        []byte `bson:"data"`
+	ContentID   string `bson:"contentid,omitempty"`
+}
+
+func newOutboxMongoDocument(m *OutboxMail) (*outboxMongoDocument, string) {
+	var attachments []outboxMongoAttachmentDocument
+	for _, a := range m.Attachments {
+		attachments = append(attachments, outboxMongoAttachmentDocument{
+			Filename:    a.Filename,
+			ContentType: a.ContentType,
+			Data:        a.Data,
+			ContentID:   a.ContentID,
+		})
+	}
+	return &outboxMongoDocument{
+		ID:             m.ID,
+		IdempotencyKey: m.IdempotencyKey,
+		To:             newOutboxMongoContactDocuments(m.To),
+		CC:             newOutboxMongoContactDocuments(m.CC),
+		BCC:            newOutboxMongoContactDocuments(m.BCC),
+		ReplyTo:        newOutboxMongoContactDocuments(m.ReplyTo),
+		Subject:        m.Subject,
+		PlainContent:   m.PlainContent,
+		HTMLContent:    m.HTMLContent,
+		Attachments:    attachments,
+		Headers:        m.Headers,
+		Status:         string(m.Status),
+		Attempts:       m.Attempts,
+		LastError:      m.LastError,
+		CreatedAt:      m.CreatedAt,
+		NextAttemptAt:  m.NextAttemptAt,
+		SentAt:         m.SentAt,
+	}, m.ID
+}
+
+func newOutboxMongoContactDocuments(contacts []Contact) []outboxMongoContactDocument {
+	if contacts == nil {
+		return nil
+	}
+	res := make([]outboxMongoContactDocument, 0, len(contacts))
+	for _, c := range contacts {
+		res = append(res, outboxMongoContactDocument{Email: c.Email, Name: c.Name})
+	}
+	return res
+}
+
+func (d *outboxMongoDocument) Model() *OutboxMail {
+	var attachments []Attachment
+	for _, a := range d.Attachments {
+		attachments = append(attachments, Attachment{
+			Filename:    a.Filename,
+			ContentType: a.ContentType,
+			Data:        a.Data,
+			ContentID:   a.ContentID,
+		})
+	}
+	return &OutboxMail{
+		ID:             d.ID,
+		IdempotencyKey: d.IdempotencyKey,
+		Message: Message{
+			To:           contactsFromOutboxMongoDocuments(d.To),
+			CC:           contactsFromOutboxMongoDocuments(d.CC),
+			BCC:          contactsFromOutboxMongoDocuments(d.BCC),
+			ReplyTo:      contactsFromOutboxMongoDocuments(d.ReplyTo),
+			Subject:      d.Subject,
+			PlainContent: d.PlainContent,
+			HTMLContent:  d.HTMLContent,
+			Attachments:  attachments,
+			Headers:      d.Headers,
+		},
+		Status:        OutboxStatus(d.Status),
+		Attempts:      d.Attempts,
+		LastError:     d.LastError,
+		CreatedAt:     d.CreatedAt,
+		NextAttemptAt: d.NextAttemptAt,
+		SentAt:        d.SentAt,
+	}
+}
+
+func contactsFromOutboxMongoDocuments(docs []outboxMongoContactDocument) []Contact {
+	if docs == nil {
+		return nil
+	}
+	res := make([]Contact, 0, len(docs))
+	for _, c := range docs {
+		res = append(res, Contact{Email: c.Email, Name: c.Name})
+	}
+	return res
+}
@@ -0,0 +1,62 @@
+package mailer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/reearth/reearthx/mongox"
+	"github.com/reearth/reearthx/mongox/mongotest"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	mongotest.Env = "REEARTH_DB"
+}
+
+func TestOutboxMongo(t *testing.T) {
+	c := mongotest.Connect(t)(t)
+	o := NewOutboxMongo(mongox.NewCollection(c.Collection("mail_outbox")))
+	ctx := context.Background()
+	assert.NoError(t, o.Init(ctx))
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	m1 := &OutboxMail{
+		ID:             "1",
+		IdempotencyKey: "k",
+		Message: Message{
+			To:           []Contact{{Email: "a@example.com", Name: "A"}},
+			CC:           []Contact{{Email: "b@example.com"}},
+			Subject:      "s",
+			PlainContent: "p",
+			HTMLContent:  "h",
+			Attachments:  []Attachment{{Filename: "a.txt", Data: []byte("a")}},
+			Headers:      map[string]string{"List-Unsubscribe": "<https://example.com>"},
+		},
+		Status:        OutboxStatusPending,
+		CreatedAt:     now,
+		NextAttemptAt: now.Add(-time.Minute),
+	}
+	m2 := &OutboxMail{ID: "2", Message: Message{To: []Contact{}}, Status: OutboxStatusPending, NextAttemptAt: now.Add(-2 * time.Minute)}
+	m3 := &OutboxMail{ID: "3", Message: Message{To: []Contact{}}, Status: OutboxStatusPending, NextAttemptAt: now.Add(time.Minute)}
+	assert.NoError(t, o.Add(ctx, m1))
+	assert.Same(t, ErrDuplicateMail, o.Add(ctx, &OutboxMail{ID: "4", IdempotencyKey: "k"}))
+	assert.NoError(t, o.Add(ctx, m2))
+	assert.NoError(t, o.Add(ctx, m3))
+
+	got, err := o.Acquire(ctx, now, now.Add(time.Minute), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2", "1"}, ids(got))
+	m1.NextAttemptAt = now.Add(time.Minute)
+	assert.Equal(t, m1, got[1])
+
+	got, err = o.Acquire(ctx, now, now.Add(time.Minute), 0)
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+
+	m3.Status = OutboxStatusDead
+	assert.NoError(t, o.Save(ctx, m3))
+	got, err = o.FindByStatus(ctx, OutboxStatusDead)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"3"}, ids(got))
+}
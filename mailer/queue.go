@@ -0,0 +1,98 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/reearth/reearthx/util"
+)
+
+var ErrDuplicateMail = errors.New("mail with the same idempotency key already exists")
+
+type OutboxStatus string
+
+const (
+	OutboxStatusPending OutboxStatus = "pending"
+	OutboxStatusSent    OutboxStatus = "sent"
+	// OutboxStatusDead is a mail that failed too many times. It is kept in the outbox for inspection and is not retried.
+	OutboxStatusDead OutboxStatus = "dead"
+)
+
+// OutboxMail is a mail waiting in the outbox to be delivered by a Worker.
+type OutboxMail struct {
+	ID             string
+	IdempotencyKey string
+	Message
+	Status        OutboxStatus
+	Attempts      int
+	LastError     string
+	CreatedAt     time.Time
+	NextAttemptAt time.Time
+	SentAt        *time.Time
+}
+
+// Outbox persists queued mails.
+type Outbox interface {
+	// Add stores a new mail. It returns ErrDuplicateMail if a mail with the same idempotency key is already stored.
+	Add(context.Context, *OutboxMail) error
+	// Acquire returns up to limit pending mails that are due at now.
+	// They are hidden from other calls of Acquire until leaseUntil so that only one worker delivers each mail.
+	Acquire(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*OutboxMail, error)
+	FindByStatus(context.Context, OutboxStatus) ([]*OutboxMail, error)
+	Save(context.Context, *OutboxMail) error
+}
+
+type idempotencyKeyKey struct{}
+
+// ContextWithIdempotencyKey sets the idempotency key of the mail sent with the context.
+// A queued mailer enqueues a mail only once per key. Other mailers ignore it.
+func ContextWithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyKey{}, key)
+}
+
+func GetIdempotencyKeyFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if key, ok := ctx.Value(idempotencyKeyKey{}).(string); ok {
+		return key
+	}
+	return ""
+}
+
+type queued struct {
+	outbox Outbox
+}
+
+// NewQueued returns a Mailer that stores mails in the outbox instead of sending them.
+// Run a Worker with the actual mailer to deliver them.
+func NewQueued(outbox Outbox) Mailer {
+	return &queued{outbox: outbox}
+}
+
+func (m *queued) SendMail(ctx context.Context, to []Contact, subject, plainContent, htmlContent string) error {
+	return m.Send(ctx, newSimpleMessage(to, subject, plainContent, htmlContent))
+}
+
+func (m *queued) Send(ctx context.Context, msg *Message) error {
+	if err := msg.Validate(); err != nil {
+		return err
+	}
+
+	now := util.Now()
+	err := m.outbox.Add(ctx, &OutboxMail{
+		ID:             uuid.NewString(),
+		IdempotencyKey: GetIdempotencyKeyFromContext(ctx),
+		Message:        *msg.Clone(),
+		Status:         OutboxStatusPending,
+		CreatedAt:      now,
+		NextAttemptAt:  now,
+	})
+	// the mail has already been queued
+	if errors.Is(err, ErrDuplicateMail) {
+		return nil
+	}
+	return err
+}
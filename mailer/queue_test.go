@@ -0,0 +1,159 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/reearth/reearthx/util"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueued_SendMail(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	defer util.MockNow(now)()
+
+	ctx := context.Background()
+	o := NewOutboxMemory()
+	m := NewQueued(o)
+	to := []Contact{{Email: "a@example.com", Name: "A"}}
+
+	assert.Error(t, m.SendMail(ctx, []Contact{{Email: "invalid"}}, "s", "p", "h"))
+
+	kctx := ContextWithIdempotencyKey(ctx, "key")
+	assert.NoError(t, m.SendMail(kctx, to, "s", "p", "h"))
+	assert.NoError(t, m.SendMail(kctx, to, "s", "p", "h"))
+	assert.NoError(t, m.SendMail(ctx, to, "s2", "p", "h"))
+
+	mails := lo.Must(o.FindByStatus(ctx, OutboxStatusPending))
+	assert.Len(t, mails, 2)
+	assert.NotEmpty(t, mails[0].ID)
+	assert.Equal(t, &OutboxMail{
+		ID:             mails[0].ID,
+		IdempotencyKey: "key",
+		Message: Message{
+			To:           to,
+			Subject:      "s",
+			PlainContent: "p",
+			HTMLContent:  "h",
+		},
+		Status:        OutboxStatusPending,
+		CreatedAt:     now,
+		NextAttemptAt: now,
+	}, mails[0])
+	assert.Equal(t, "", mails[1].IdempotencyKey)
+}
+
+type failingMailer struct {
+	fails int
+	sent  int
+}
+
+func (m *failingMailer) SendMail(ctx context.Context, to []Contact, subject, plainContent, htmlContent string) error {
+	return m.Send(ctx, newSimpleMessage(to, subject, plainContent, htmlContent))
+}
+
+func (m *failingMailer) Send(ctx context.Context, msg *Message) error {
+	if m.fails > 0 {
+		m.fails--
+		return errors.New("unavailable")
+	}
+	m.sent++
+	return nil
+}
+
+func TestWorker_Process(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	defer util.MockNow(now)()
+
+	ctx := context.Background()
+	o := NewOutboxMemory()
+	lo.Must0(NewQueued(o).SendMail(ctx, []Contact{{Email: "a@example.com"}}, "s", "p", "h"))
+
+	fm := &failingMailer{fails: 1}
+	w := NewWorker(o, fm, WorkerConfig{
+		MaxAttempts: 3,
+		BaseDelay:   time.Minute,
+	})
+
+	// first attempt fails and is retried later
+	sent, err := w.Process(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, sent)
+	m := lo.Must(o.FindByStatus(ctx, OutboxStatusPending))[0]
+	assert.Equal(t, 1, m.Attempts)
+	assert.Equal(t, "unavailable", m.LastError)
+	assert.Equal(t, now.Add(time.Minute), m.NextAttemptAt)
+
+	sent, err = w.Process(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, sent)
+
+	defer util.MockNow(now.Add(time.Minute))()
+	sent, err = w.Process(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	assert.Equal(t, 1, fm.sent)
+
+	m = lo.Must(o.FindByStatus(ctx, OutboxStatusSent))[0]
+	assert.Equal(t, 2, m.Attempts)
+	assert.Equal(t, "", m.LastError)
+	assert.Equal(t, now.Add(time.Minute), *m.SentAt)
+}
+
+func TestWorker_Process_DeadLetter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	defer util.MockNow(now)()
+
+	ctx := context.Background()
+	o := NewOutboxMemory()
+	lo.Must0(NewQueued(o).SendMail(ctx, []Contact{{Email: "a@example.com"}}, "s", "p", "h"))
+
+	w := NewWorker(o, &failingMailer{fails: 100}, WorkerConfig{
+		MaxAttempts: 3,
+		BaseDelay:   time.Minute,
+		MaxDelay:    90 * time.Second,
+	})
+
+	for _, d := range []time.Duration{0, time.Minute, 90 * time.Second} {
+		now = now.Add(d)
+		defer util.MockNow(now)()
+		_, err := w.Process(ctx)
+		assert.NoError(t, err)
+	}
+
+	assert.Empty(t, lo.Must(o.FindByStatus(ctx, OutboxStatusPending)))
+	dead := lo.Must(o.FindByStatus(ctx, OutboxStatusDead))
+	assert.Len(t, dead, 1)
+	assert.Equal(t, 3, dead[0].Attempts)
+}
+
+func TestWorker_Run(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	o := NewOutboxMemory()
+	lo.Must0(NewQueued(o).SendMail(ctx, []Contact{{Email: "a@example.com"}}, "s", "p", "h"))
+
+	m := NewMock()
+	done := make(chan struct{})
+	go func() {
+		NewWorker(o, m, WorkerConfig{Interval: time.Millisecond}).Run(ctx)
+		close(done)
+	}()
+
+	assert.Eventually(t, func() bool {
+		return len(lo.Must(o.FindByStatus(ctx, OutboxStatusSent))) == 1
+	}, time.Second, time.Millisecond)
+	cancel()
+	<-done
+	assert.Len(t, m.Mails(), 1)
+}
+
+func TestWorkerConfig_retryDelay(t *testing.T) {
+	c := WorkerConfig{}.normalize()
+	assert.Equal(t, 30*time.Second, c.retryDelay(1))
+	assert.Equal(t, time.Minute, c.retryDelay(2))
+	assert.Equal(t, 2*time.Minute, c.retryDelay(3))
+	assert.Equal(t, time.Hour, c.retryDelay(20))
+}
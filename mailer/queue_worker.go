@@ -0,0 +1,134 @@
+package mailer
+
+import (
+	"context"
+	"time"
+
+	"github.com/reearth/reearthx/log"
+	"github.com/reearth/reearthx/util"
+)
+
+type WorkerConfig struct {
+	// Interval is how often the outbox is polled. Defaults to 5 seconds.
+	Interval time.Duration
+	// BatchSize is the max number of mails delivered in one poll. Defaults to 10.
+	BatchSize int
+	// Lease is how long a mail is hidden from other workers while it is being delivered. Defaults to 1 minute.
+	Lease time.Duration
+	// MaxAttempts is the number of attempts before a mail is dead-lettered. Defaults to 8.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry. It doubles on every retry up to MaxDelay. Defaults to 30 seconds.
+	BaseDelay time.Duration
+	// MaxDelay defaults to 1 hour.
+	MaxDelay time.Duration
+}
+
+func (c WorkerConfig) normalize() WorkerConfig {
+	if c.Interval <= 0 {
+		c.Interval = 5 * time.Second
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = 10
+	}
+	if c.Lease <= 0 {
+		c.Lease = time.Minute
+	}
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = 8
+	}
+	if c.BaseDelay <= 0 {
+		c.BaseDelay = 30 * time.Second
+	}
+	if c.MaxDelay <= 0 {
+		c.MaxDelay = time.Hour
+	}
+	return c
+}
+
+func (c WorkerConfig) retryDelay(attempts int) time.Duration {
+	d := c.BaseDelay
+	for i := 1; i < attempts; i++ {
+		d *= 2
+		if d >= c.MaxDelay {
+			return c.MaxDelay
+		}
+	}
+	return d
+}
+
+// Worker delivers mails in the outbox with the mailer.
+type Worker struct {
+	outbox Outbox
+	mailer Mailer
+	config WorkerConfig
+}
+
+func NewWorker(outbox Outbox, mailer Mailer, config WorkerConfig) *Worker {
+	return &Worker{
+		outbox: outbox,
+		mailer: mailer,
+		config: config.normalize(),
+	}
+}
+
+// Run delivers mails until the context is canceled.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := w.Process(ctx); err != nil {
+			log.Errorfc(ctx, "mailer: failed to process the outbox: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Process delivers due mails once and returns the number of mails sent.
+func (w *Worker) Process(ctx context.Context) (int, error) {
+	now := util.Now()
+	mails, err := w.outbox.Acquire(ctx, now, now.Add(w.config.Lease), w.config.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, m := range mails {
+		if w.deliver(ctx, m) {
+			sent++
+		}
+		if err := w.outbox.Save(ctx, m); err != nil {
+			return sent, err
+		}
+	}
+	return sent, nil
+}
+
+func (w *Worker) deliver(ctx context.Context, m *OutboxMail) bool {
+	err := w.mailer.Send(ctx, &m.Message)
+	now := util.Now()
+	m.Attempts++
+
+	if err == nil {
+		m.Status = OutboxStatusSent
+		m.SentAt = &now
+		m.LastError = ""
+		return true
+	}
+
+	m.LastError = err.Error()
+	if m.Attempts >= w.config.MaxAttempts {
+		m.Status = OutboxStatusDead
+		log.Errorfc(ctx, "mailer: mail %s is dead-lettered after %d attempts: %v", m.ID, m.Attempts, err)
+		return false
+	}
+
+	m.NextAttemptAt = now.Add(w.config.retryDelay(m.Attempts))
+	log.Warnfc(ctx, "mailer: failed to send mail %s (attempt %d), retrying at %s: %v", m.ID, m.Attempts, m.NextAttemptAt.Format(time.RFC3339), err)
+	return false
+}
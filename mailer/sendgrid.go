@@ -2,6 +2,7 @@ package mailer
 
 import (
 	"context"
+	"encoding/base64"
 
 	"github.com/sendgrid/sendgrid-go"
 	"github.com/sendgrid/sendgrid-go/helpers/mail"
@@ -23,15 +24,67 @@ func NewSendGrid(senderName, senderEmail, api string) Mailer {
 
 func (m *sendgridMailer) SendMail(ctx context.Context, to []Contact, subject, plainContent, htmlContent string) error {
 	for _, t := range to {
-		sender := mail.NewEmail(m.name, m.email)
-		receiver := mail.NewEmail(t.Name, t.Email)
-		message := mail.NewSingleEmail(sender, subject, receiver, plainContent, htmlContent)
-		_, err := m.client.Send(message)
-		if err != nil {
+		if err := m.Send(ctx, newSimpleMessage([]Contact{t}, subject, plainContent, htmlContent)); err != nil {
 			return err
 		}
 	}
+	return nil
+}
 
-	logMail(ctx, to, subject)
+func (m *sendgridMailer) Send(ctx context.Context, msg *Message) error {
+	if err := msg.Validate(); err != nil {
+		return err
+	}
+
+	if _, err := m.client.Send(m.message(msg)); err != nil {
+		return err
+	}
+
+	logMail(ctx, msg.To, msg.Subject)
 	return nil
 }
+
+func (m *sendgridMailer) message(msg *Message) *mail.SGMailV3 {
+	p := mail.NewPersonalization()
+	p.AddTos(sendgridEmails(msg.To)...)
+	p.AddCCs(sendgridEmails(msg.CC)...)
+	p.AddBCCs(sendgridEmails(msg.BCC)...)
+
+	res := mail.NewV3Mail().
+		SetFrom(mail.NewEmail(m.name, m.email)).
+		AddPersonalizations(p)
+	res.Subject = msg.Subject
+	if msg.PlainContent != "" {
+		res.AddContent(mail.NewContent("text/plain", msg.PlainContent))
+	}
+	if msg.HTMLContent != "" {
+		res.AddContent(mail.NewContent("text/html", msg.HTMLContent))
+	}
+	if len(msg.ReplyTo) > 0 {
+		res.SetReplyToList(sendgridEmails(msg.ReplyTo))
+	}
+	for k, v := range msg.Headers {
+		res.SetHeader(k, v)
+	}
+	for _, a := range msg.Attachments {
+		att := mail.NewAttachment().
+			SetContent(base64.StdEncoding.EncodeToString(a.Data)).
+			SetType(a.contentType()).
+			SetFilename(a.Filename)
+		if a.Inline() {
+			att.SetDisposition("inline").SetContentID(a.ContentID)
+		} else {
+			att.SetDisposition("attachment")
+		}
+		res.AddAttachment(att)
+	}
+	return res
+}
+
+func sendgridEmails(contacts []Contact) []*mail.Email {
+	res := make([]*mail.Email, 0, len(contacts))
+	for _, c := range contacts {
+		res = append(res, mail.NewEmail(c.Name, c.Email))
+	}
+	return res
+}
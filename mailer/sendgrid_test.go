@@ -0,0 +1,40 @@
+package mailer
+
+import (
+	"testing"
+
+	"github.com/sendgrid/sendgrid-go/helpers/mail"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSendGrid_message(t *testing.T) {
+	m := &sendgridMailer{name: "Re:Earth", email: "noreply@example.com"}
+	got := m.message(&Message{
+		To:           []Contact{{Email: "a@example.com", Name: "A"}},
+		CC:           []Contact{{Email: "b@example.com"}},
+		BCC:          []Contact{{Email: "c@example.com"}},
+		ReplyTo:      []Contact{{Email: "d@example.com"}},
+		Subject:      "s",
+		PlainContent: "p",
+		HTMLContent:  "h",
+		Headers:      map[string]string{"List-Unsubscribe": "<https://example.com/u>"},
+		Attachments: []Attachment{
+			{Filename: "a.txt", Data: []byte("text")},
+			{ContentID: "logo", ContentType: "image/png", Data: []byte("png")},
+		},
+	})
+
+	assert.Equal(t, mail.NewEmail("Re:Earth", "noreply@example.com"), got.From)
+	assert.Equal(t, "s", got.Subject)
+	assert.Len(t, got.Personalizations, 1)
+	assert.Equal(t, []*mail.Email{mail.NewEmail("A", "a@example.com")}, got.Personalizations[0].To)
+	assert.Equal(t, []*mail.Email{mail.NewEmail("", "b@example.com")}, got.Personalizations[0].CC)
+	assert.Equal(t, []*mail.Email{mail.NewEmail("", "c@example.com")}, got.Personalizations[0].BCC)
+	assert.Equal(t, []*mail.Email{mail.NewEmail("", "d@example.com")}, got.ReplyToList)
+	assert.Equal(t, []*mail.Content{mail.NewContent("text/plain", "p"), mail.NewContent("text/html", "h")}, got.Content)
+	assert.Equal(t, map[string]string{"List-Unsubscribe": "<https://example.com/u>"}, got.Headers)
+	assert.Equal(t, []*mail.Attachment{
+		{Content: "dGV4dA==", Type: "text/plain; charset=utf-8", Filename: "a.txt", Disposition: "attachment"},
+		{Content: "cG5n", Type: "image/png", Disposition: "inline", ContentID: "logo"},
+	}, got.Attachments)
+}
@@ -27,20 +27,21 @@ func NewSMTP(host, port, username, email, password string) Mailer {
 }
 
 func (m *smtpMailer) SendMail(ctx context.Context, to []Contact, subject, plainContent, htmlContent string) error {
-	emails, err := verifyEmails(to)
-	if err != nil {
+	return m.Send(ctx, newSimpleMessage(to, subject, plainContent, htmlContent))
+}
+
+func (m *smtpMailer) Send(ctx context.Context, msg *Message) error {
+	if err := msg.Validate(); err != nil {
 		return err
 	}
 
-	msg := &message{
-		to:           emails,
-		from:         m.email,
-		subject:      subject,
-		plainContent: plainContent,
-		htmlContent:  htmlContent,
+	// BCC recipients receive the mail without appearing in the headers
+	emails, err := verifyEmails(msg.Recipients())
+	if err != nil {
+		return err
 	}
 
-	encodedMsg, err := msg.encodeMessage()
+	encodedMsg, err := newMessage(m.email, msg).encodeMessage()
 	if err != nil {
 		return err
 	}
@@ -54,6 +55,6 @@ func (m *smtpMailer) SendMail(ctx context.Context, to []Contact, subject, plainC
 		return err
 	}
 
-	logMail(ctx, to, subject)
+	logMail(ctx, msg.To, msg.Subject)
 	return nil
 }
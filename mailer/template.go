@@ -0,0 +1,265 @@
+package mailer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	htmlTmpl "html/template"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	textTmpl "text/template"
+
+	"github.com/reearth/reearthx/i18n"
+	"golang.org/x/text/language"
+)
+
+var ErrTemplateNotFound = errors.New("mail template not found")
+
+const (
+	templateExt        = ".tmpl"
+	subjectTemplateExt = ".subject" + templateExt
+	textTemplateExt    = ".txt" + templateExt
+	htmlTemplateExt    = ".html" + templateExt
+)
+
+// Templates renders localized mails from templates.
+//
+// A mail named "foo" consists of "foo.subject.tmpl" and "foo.txt.tmpl" (text/template) and an optional "foo.html.tmpl" (html/template).
+// Files whose names start with "_" are shared by all mails, e.g. a layout that calls blocks defined in each mail.
+// Templates translate messages of the bundle with `{{ t "id" }}` or `{{ t "id" . }}` and get the language with `{{ lang }}`.
+type Templates struct {
+	bundle    *i18n.Bundle
+	templates map[string]*mailTemplate
+}
+
+type mailTemplate struct {
+	subject *textTmpl.Template
+	text    *textTmpl.Template
+	html    *htmlTmpl.Template
+}
+
+// NewTemplates loads templates in the root of fsys and validates them:
+// every template must be renderable, and every message it uses must be translated to all languages of the bundle.
+func NewTemplates(fsys fs.FS, bundle *i18n.Bundle) (*Templates, error) {
+	files, err := fs.Glob(fsys, "*"+templateExt)
+	if err != nil {
+		return nil, err
+	}
+
+	funcs := templateFuncs(nil, language.Und, false)
+	textBase := textTmpl.New("").Funcs(textTmpl.FuncMap(funcs))
+	htmlBase := htmlTmpl.New("").Funcs(htmlTmpl.FuncMap(funcs))
+	names := map[string]struct{}{}
+	for _, f := range files {
+		if !strings.HasPrefix(f, "_") {
+			if name, ok := strings.CutSuffix(f, subjectTemplateExt); ok {
+				names[name] = struct{}{}
+			}
+			continue
+		}
+
+		b, err := fs.ReadFile(fsys, f)
+		if err != nil {
+			return nil, err
+		}
+		if strings.HasSuffix(f, htmlTemplateExt) {
+			_, err = htmlBase.New(f).Parse(string(b))
+		} else {
+			_, err = textBase.New(f).Parse(string(b))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("mail template %s: %w", f, err)
+		}
+	}
+
+	t := &Templates{
+		bundle:    bundle,
+		templates: make(map[string]*mailTemplate, len(names)),
+	}
+	for name := range names {
+		mt := &mailTemplate{}
+		if mt.subject, err = parseTextTemplate(fsys, textBase, name+subjectTemplateExt); err != nil {
+			return nil, err
+		}
+		if mt.text, err = parseTextTemplate(fsys, textBase, name+textTemplateExt); err != nil {
+			return nil, err
+		}
+		if mt.html, err = parseHTMLTemplate(fsys, htmlBase, name+htmlTemplateExt); err != nil {
+			return nil, err
+		}
+		t.templates[name] = mt
+	}
+
+	if err := t.validate(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func MustNewTemplates(fsys fs.FS, bundle *i18n.Bundle) *Templates {
+	t, err := NewTemplates(fsys, bundle)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// Names returns the sorted names of the mails.
+func (t *Templates) Names() []string {
+	res := make([]string, 0, len(t.templates))
+	for name := range t.templates {
+		res = append(res, name)
+	}
+	slices.Sort(res)
+	return res
+}
+
+// Render renders the mail in lang. Messages that are not translated to lang fall back to the default language of the bundle.
+// Recipients of the returned message are not set.
+func (t *Templates) Render(name string, lang language.Tag, data any) (*Message, error) {
+	return t.render(name, lang, data, false)
+}
+
+func (t *Templates) validate() error {
+	for _, name := range t.Names() {
+		for _, lang := range t.bundle.LanguageTags() {
+			if _, err := t.render(name, lang, nil, true); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (t *Templates) render(name string, lang language.Tag, data any, strict bool) (*Message, error) {
+	mt, ok := t.templates[name]
+	if !ok {
+		return nil, ErrTemplateNotFound
+	}
+
+	funcs := templateFuncs(t.bundle, lang, strict)
+	subject, err := executeTextTemplate(mt.subject, funcs, data)
+	if err != nil {
+		return nil, fmt.Errorf("mail template %s (%s): %w", name, lang, err)
+	}
+	text, err := executeTextTemplate(mt.text, funcs, data)
+	if err != nil {
+		return nil, fmt.Errorf("mail template %s (%s): %w", name, lang, err)
+	}
+	html, err := executeHTMLTemplate(mt.html, funcs, data)
+	if err != nil {
+		return nil, fmt.Errorf("mail template %s (%s): %w", name, lang, err)
+	}
+
+	return &Message{
+		// subjects must be a single line
+		Subject:      strings.Join(strings.Fields(subject), " "),
+		PlainContent: text,
+		HTMLContent:  html,
+	}, nil
+}
+
+func templateFuncs(bundle *i18n.Bundle, lang language.Tag, strict bool) map[string]any {
+	var localizer *i18n.Localizer
+	if bundle != nil {
+		var langs []string
+		if lang != language.Und {
+			langs = append(langs, lang.String())
+		}
+		localizer = i18n.NewLocalizer(bundle, langs...)
+	}
+
+	return map[string]any{
+		"t": func(id string, data ...any) (string, error) {
+			if localizer == nil {
+				return "", errors.New("no localizer")
+			}
+			var templateData any
+			if len(data) > 0 {
+				templateData = data[0]
+			}
+			s, tag, err := localizer.LocalizeWithTag(&i18n.LocalizeConfig{
+				MessageID:    id,
+				TemplateData: templateData,
+			})
+			// the message in the default language is returned with MessageNotFoundErr when it is not translated to lang
+			if nf := (*i18n.MessageNotFoundErr)(nil); errors.As(err, &nf) && !strict && tag != language.Und {
+				err = nil
+			}
+			if err != nil {
+				return "", err
+			}
+			return s, nil
+		},
+		"lang": func() string {
+			return lang.String()
+		},
+	}
+}
+
+func parseTextTemplate(fsys fs.FS, base *textTmpl.Template, name string) (*textTmpl.Template, error) {
+	b, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, fmt.Errorf("mail template %s: %w", name, err)
+	}
+	t, err := base.Clone()
+	if err != nil {
+		return nil, err
+	}
+	if t, err = t.New(path.Base(name)).Parse(string(b)); err != nil {
+		return nil, fmt.Errorf("mail template %s: %w", name, err)
+	}
+	return t, nil
+}
+
+func parseHTMLTemplate(fsys fs.FS, base *htmlTmpl.Template, name string) (*htmlTmpl.Template, error) {
+	b, err := fs.ReadFile(fsys, name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("mail template %s: %w", name, err)
+	}
+	t, err := base.Clone()
+	if err != nil {
+		return nil, err
+	}
+	if t, err = t.New(path.Base(name)).Parse(string(b)); err != nil {
+		return nil, fmt.Errorf("mail template %s: %w", name, err)
+	}
+	return t, nil
+}
+
+// templates are cloned before execution so that functions can be bound to the language of each mail
+
+func executeTextTemplate(t *textTmpl.Template, funcs map[string]any, data any) (string, error) {
+	if t == nil {
+		return "", nil
+	}
+	c, err := t.Clone()
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := c.Funcs(textTmpl.FuncMap(funcs)).Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func executeHTMLTemplate(t *htmlTmpl.Template, funcs map[string]any, data any) (string, error) {
+	if t == nil {
+		return "", nil
+	}
+	c, err := t.Clone()
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := c.Funcs(htmlTmpl.FuncMap(funcs)).Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
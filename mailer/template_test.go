@@ -0,0 +1,90 @@
+package mailer
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/reearth/reearthx/i18n"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/language"
+)
+
+func testTemplatesFS() fstest.MapFS {
+	return fstest.MapFS{
+		"_layout.txt.tmpl":    {Data: []byte(`{{ t "greeting" . }} {{ template "body" . }}`)},
+		"_layout.html.tmpl":   {Data: []byte(`<p lang="{{ lang }}">{{ t "greeting" . }}</p>{{ template "body" . }}`)},
+		"hello.subject.tmpl":  {Data: []byte("{{ t \"subject\" }}\n")},
+		"hello.txt.tmpl":      {Data: []byte(`{{ define "body" }}{{ .URL }}{{ end }}{{ template "_layout.txt.tmpl" . }}`)},
+		"hello.html.tmpl":     {Data: []byte(`{{ define "body" }}<a href="{{ .URL }}">{{ .URL }}</a>{{ end }}{{ template "_layout.html.tmpl" . }}`)},
+		"plain.subject.tmpl":  {Data: []byte(`Plain`)},
+		"plain.txt.tmpl":      {Data: []byte(`plain {{ .URL }}`)},
+		"locales/unused.tmpl": {Data: []byte(`{{`)},
+	}
+}
+
+func testTemplatesBundle(t *testing.T) *i18n.Bundle {
+	t.Helper()
+	b := i18n.NewBundle(language.English)
+	assert.NoError(t, b.LoadBytes([]byte("subject: Hello\ngreeting: \"Hi {{ .Name }},\""), "en.yml"))
+	assert.NoError(t, b.LoadBytes([]byte("subject: こんにちは\ngreeting: \"{{ .Name }} 様\""), "ja.yml"))
+	return b
+}
+
+func TestTemplates_Render(t *testing.T) {
+	tm, err := NewTemplates(testTemplatesFS(), testTemplatesBundle(t))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"hello", "plain"}, tm.Names())
+
+	data := map[string]any{"Name": "<A>", "URL": "https://example.com/?a=b&c=d"}
+
+	got, err := tm.Render("hello", language.Japanese, data)
+	assert.NoError(t, err)
+	assert.Equal(t, &Message{
+		Subject:      "こんにちは",
+		PlainContent: "<A> 様 https://example.com/?a=b&c=d",
+		HTMLContent:  `<p lang="ja">&lt;A&gt; 様</p><a href="https://example.com/?a=b&amp;c=d">https://example.com/?a=b&amp;c=d</a>`,
+	}, got)
+
+	// falls back to the default language
+	got, err = tm.Render("hello", language.French, data)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello", got.Subject)
+	assert.Equal(t, "Hi <A>, https://example.com/?a=b&c=d", got.PlainContent)
+
+	got, err = tm.Render("hello", language.Und, data)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello", got.Subject)
+
+	got, err = tm.Render("plain", language.English, data)
+	assert.NoError(t, err)
+	assert.Equal(t, &Message{Subject: "Plain", PlainContent: "plain https://example.com/?a=b&c=d"}, got)
+
+	_, err = tm.Render("unknown", language.English, nil)
+	assert.Same(t, ErrTemplateNotFound, err)
+}
+
+func TestNewTemplates_Validation(t *testing.T) {
+	// missing translation
+	b := testTemplatesBundle(t)
+	assert.NoError(t, b.LoadBytes([]byte("subject: Bonjour"), "fr.yml"))
+	_, err := NewTemplates(testTemplatesFS(), b)
+	assert.EqualError(t, err, `mail template hello (fr): template: _layout.txt.tmpl:1:3: executing "_layout.txt.tmpl" at <t "greeting" .>: error calling t: message "greeting" not found in language "fr"`)
+
+	// missing plain text template
+	fsys := testTemplatesFS()
+	delete(fsys, "plain.txt.tmpl")
+	_, err = NewTemplates(fsys, testTemplatesBundle(t))
+	assert.ErrorContains(t, err, "mail template plain.txt.tmpl:")
+
+	// syntax error
+	fsys = testTemplatesFS()
+	fsys["_layout.txt.tmpl"] = &fstest.MapFile{Data: []byte(`{{ if }}`)}
+	_, err = NewTemplates(fsys, testTemplatesBundle(t))
+	assert.ErrorContains(t, err, "mail template _layout.txt.tmpl:")
+
+	// unknown message
+	fsys = testTemplatesFS()
+	fsys["plain.subject.tmpl"] = &fstest.MapFile{Data: []byte(`{{ t "unknown" }}`)}
+	_, err = NewTemplates(fsys, testTemplatesBundle(t))
+	assert.ErrorContains(t, err, `"unknown" not found`)
+}
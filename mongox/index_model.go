@@ -53,6 +53,16 @@ func CaseInsensitiveIndexFromKey(key string, unique bool) Index {
 	}
 }
 
+// GeoIndexFromKey returns a 2dsphere index for GeoJSON values stored in the given keys.
+func GeoIndexFromKey(key string) Index {
+	return Index{
+		Name: prefix + key,
+		Key: lo.Map(toKeyBSON(key), func(e bson.E, _ int) bson.E {
+			return bson.E{Key: e.Key, Value: "2dsphere"}
+		}),
+	}
+}
+
 func toKeyBSON(key string) bson.D {
 	return lo.Map(
 		strings.Split(key, ","),
@@ -39,6 +39,19 @@ func TestCaseInsensitiveIndexFromKey(t *testing.T) {
 	}, CaseInsensitiveIndexFromKey("a,b.c", false))
 }
 
+func TestGeoIndexFromKey(t *testing.T) {
+	assert.Equal(t, Index{
+		Name: "re_geo.g",
+		Key: bson.D{
+			{Key: "geo.g", Value: "2dsphere"},
+		},
+	}, GeoIndexFromKey("geo.g"))
+	assert.Equal(t, Index{
+		Name: "re_geo.g",
+		Key:  bson.D{{Key: "geo.g", Value: "2dsphere"}},
+	}, GeoIndexFromKey("geo.g").Normalize())
+}
+
 func TestToKeyBSON(t *testing.T) {
 	assert.Equal(
 		t,
@@ -0,0 +1,86 @@
+package mongox
+
+import (
+	"encoding/base64"
+	"slices"
+	"strings"
+
+	"github.com/reearth/reearthx/usecasex"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+// keysetCursorPrefix distinguishes keyset cursors from plain ID cursors.
+const keysetCursorPrefix = "~"
+
+// ErrInvalidCursor is the same error as the one PaginateSlice of usecasex returns.
+var ErrInvalidCursor = usecasex.ErrInvalidCursor
+
+// keysetCursor holds the values of the sort fields of an item so that the next page can be found without reading the item again.
+// It is encoded opaquely and is used for compound sorts. Single key sorts keep using IDs as cursors.
+type keysetCursor struct {
+	Keys   []string        `bson:"k"`
+	Values []bson.RawValue `bson:"v"`
+}
+
+func cursorFunc(s *usecasex.Sort) func(bson.Raw) (*usecasex.Cursor, error) {
+	if !s.IsCompound() {
+		return getCursor
+	}
+	fields := sortFields(s)
+	return func(raw bson.Raw) (*usecasex.Cursor, error) {
+		return encodeKeysetCursor(raw, fields)
+	}
+}
+
+func encodeKeysetCursor(raw bson.Raw, fields []sortField) (*usecasex.Cursor, error) {
+	b, err := bson.Marshal(keysetCursor{
+		Keys:   sortKeys(fields),
+		Values: lookupSortValues(raw, fields),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return usecasex.Cursor(keysetCursorPrefix + base64.RawURLEncoding.EncodeToString(b)).Ref(), nil
+}
+
+func isKeysetCursor(c usecasex.Cursor) bool {
+	return strings.HasPrefix(string(c), keysetCursorPrefix)
+}
+
+func decodeKeysetCursor(c usecasex.Cursor, fields []sortField) ([]bson.RawValue, error) {
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(string(c), keysetCursorPrefix))
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var kc keysetCursor
+	if err := bson.Unmarshal(b, &kc); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	// the cursor was issued for another sort
+	if !slices.Equal(kc.Keys, sortKeys(fields)) || len(kc.Values) != len(fields) {
+		return nil, ErrInvalidCursor
+	}
+	return kc.Values, nil
+}
+
+func sortKeys(fields []sortField) []string {
+	res := make([]string, 0, len(fields))
+	for _, f := range fields {
+		res = append(res, f.key)
+	}
+	return res
+}
+
+// lookupSortValues returns the values of the fields in the document. Missing values are null as MongoDB sorts them.
+func lookupSortValues(raw bson.Raw, fields []sortField) []bson.RawValue {
+	res := make([]bson.RawValue, 0, len(fields))
+	for _, f := range fields {
+		v, err := raw.LookupErr(strings.Split(f.key, ".")...)
+		if err != nil {
+			v = bson.RawValue{Type: bsontype.Null}
+		}
+		res = append(res, v)
+	}
+	return res
+}
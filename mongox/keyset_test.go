@@ -0,0 +1,127 @@
+package mongox
+
+import (
+	"context"
+	"testing"
+
+	"github.com/reearth/reearthx/mongox/mongotest"
+	"github.com/reearth/reearthx/usecasex"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestSortDocument(t *testing.T) {
+	first := usecasex.CursorPagination{First: lo.ToPtr(int64(1))}.Wrap()
+	last := usecasex.CursorPagination{Last: lo.ToPtr(int64(1))}.Wrap()
+	s := usecasex.NewSort(
+		usecasex.SortKey{Key: "status", Reverted: true},
+		usecasex.SortKey{Key: "updatedAt"},
+		usecasex.SortKey{Key: "id"},
+	)
+
+	assert.Equal(t, bson.D{{Key: "id", Value: 1}}, sortDocument(*first, nil))
+	assert.Equal(t, bson.D{{Key: "id", Value: -1}}, sortDocument(*last, nil))
+	assert.Equal(t, bson.D{{Key: "id", Value: -1}}, sortDocument(*first, &usecasex.Sort{Key: "id", Reverted: true}))
+	assert.Equal(t, bson.D{
+		{Key: "status", Value: -1},
+		{Key: "updatedAt", Value: 1},
+		{Key: "id", Value: -1},
+	}, sortDocument(*first, s))
+	assert.Equal(t, bson.D{
+		{Key: "status", Value: 1},
+		{Key: "updatedAt", Value: -1},
+		{Key: "id", Value: 1},
+	}, sortDocument(*last, s))
+}
+
+func TestKeysetCursor(t *testing.T) {
+	fields := sortFields(usecasex.NewSort(usecasex.SortKey{Key: "a.b"}, usecasex.SortKey{Key: "c"}))
+	raw := lo.Must(bson.Marshal(bson.M{"id": "x", "a": bson.M{"b": int32(1)}}))
+
+	cursor, err := encodeKeysetCursor(raw, fields)
+	assert.NoError(t, err)
+	assert.True(t, isKeysetCursor(*cursor))
+
+	values, err := decodeKeysetCursor(*cursor, fields)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), values[0].Int32())
+	assert.Equal(t, bson.TypeNull, values[1].Type)
+	assert.Equal(t, "x", values[2].StringValue())
+
+	_, err = decodeKeysetCursor(*cursor, sortFields(usecasex.NewSort(usecasex.SortKey{Key: "c"}, usecasex.SortKey{Key: "a.b"})))
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+	_, err = decodeKeysetCursor(usecasex.Cursor("~!!"), fields)
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+func TestClientCollection_PaginateCompoundSort(t *testing.T) {
+	ctx := context.Background()
+	initDB := mongotest.Connect(t)
+	c := NewCollection(initDB(t).Collection("test"))
+
+	_, _ = c.Client().InsertMany(ctx, []any{
+		bson.M{"id": "a", "status": "open", "updatedAt": 2},
+		bson.M{"id": "b", "status": "closed", "updatedAt": 1},
+		bson.M{"id": "c", "status": "open", "updatedAt": 1},
+		bson.M{"id": "d", "status": "open", "updatedAt": 2},
+		bson.M{"id": "e", "status": "closed", "updatedAt": 3},
+	})
+
+	// status desc, updatedAt asc and then id desc as status
+	s := usecasex.NewSort(
+		usecasex.SortKey{Key: "status", Reverted: true},
+		usecasex.SortKey{Key: "updatedAt"},
+	)
+	expected := []usecasex.Cursor{"c", "d", "a", "b", "e"}
+
+	paginate := map[string]func(*usecasex.Pagination, Consumer) (*usecasex.PageInfo, error){
+		"find": func(p *usecasex.Pagination, con Consumer) (*usecasex.PageInfo, error) {
+			return c.Paginate(ctx, bson.M{}, s, p, con)
+		},
+		"aggregation": func(p *usecasex.Pagination, con Consumer) (*usecasex.PageInfo, error) {
+			return c.PaginateAggregation(ctx, []any{}, s, p, con)
+		},
+	}
+
+	for name, paginate := range paginate {
+		t.Run(name, func(t *testing.T) {
+			// forward
+			var got []usecasex.Cursor
+			var after *usecasex.Cursor
+			for {
+				con := &consumer{}
+				info, err := paginate(usecasex.CursorPagination{First: lo.ToPtr(int64(2)), After: after}.Wrap(), con)
+				assert.NoError(t, err)
+				got = append(got, con.Cursors...)
+				if !info.HasNextPage {
+					break
+				}
+				assert.True(t, isKeysetCursor(*info.EndCursor))
+				after = info.EndCursor
+			}
+			assert.Equal(t, expected, got)
+
+			// backward
+			got = nil
+			var before *usecasex.Cursor
+			for {
+				con := &consumer{}
+				info, err := paginate(usecasex.CursorPagination{Last: lo.ToPtr(int64(2)), Before: before}.Wrap(), con)
+				assert.NoError(t, err)
+				got = append(con.Cursors, got...)
+				if !info.HasPreviousPage {
+					break
+				}
+				before = info.StartCursor
+			}
+			assert.Equal(t, expected, got)
+
+			// an ID can be used as a cursor as well
+			con := &consumer{}
+			_, err := paginate(usecasex.CursorPagination{First: lo.ToPtr(int64(2)), After: usecasex.Cursor("d").Ref()}.Wrap(), con)
+			assert.NoError(t, err)
+			assert.Equal(t, []usecasex.Cursor{"a", "b"}, con.Cursors)
+		})
+	}
+}
@@ -50,18 +50,14 @@ func (c *Collection) PaginateAggregation(ctx context.Context, pipeline []any, s
 		_ = cursor.Close(ctx)
 	}()
 
-	count, err := c.CountAggregation(ctx, pipeline)
-	if err != nil {
-		return nil, rerror.ErrInternalByWithContext(ctx, fmt.Errorf("failed to count: %w", err))
-	}
-
-	items, startCursor, endCursor, hasMore, err := consume(ctx, cursor, limit(*p))
+	items, startCursor, endCursor, hasMore, err := consume(ctx, cursor, limit(*p), cursorFunc(s))
 	if err != nil {
 		return nil, err
 	}
 
 	if p.Cursor != nil && p.Cursor.Last != nil {
 		reverse(items)
+		startCursor, endCursor = endCursor, startCursor
 	}
 
 	for _, item := range items {
@@ -72,7 +68,13 @@ func (c *Collection) PaginateAggregation(ctx context.Context, pipeline []any, s
 
 	hasNextPage, hasPreviousPage := pageInfo(p, hasMore)
 
-	return usecasex.NewPageInfo(count, startCursor, endCursor, hasNextPage, hasPreviousPage), nil
+	res := usecasex.NewPageInfo(0, startCursor, endCursor, hasNextPage, hasPreviousPage)
+	if err := c.countTotal(ctx, p, res, func(ctx context.Context) (int64, error) {
+		return c.CountAggregation(ctx, pipeline)
+	}); err != nil {
+		return nil, err
+	}
+	return res, nil
 }
 
 func pageInfo(p *usecasex.Pagination, hasMore bool) (bool, bool) {
@@ -85,7 +87,7 @@ func pageInfo(p *usecasex.Pagination, hasMore bool) (bool, bool) {
 	return hasNextPage, hasPreviousPage
 }
 
-func consume(ctx context.Context, cursor *mongo.Cursor, limit int64) ([]bson.Raw, *usecasex.Cursor, *usecasex.Cursor, bool, error) {
+func consume(ctx context.Context, cursor *mongo.Cursor, limit int64, cursorOf func(bson.Raw) (*usecasex.Cursor, error)) ([]bson.Raw, *usecasex.Cursor, *usecasex.Cursor, bool, error) {
 	i := int64(0)
 	var startCursor, endCursor *usecasex.Cursor
 	var items []bson.Raw
@@ -97,7 +99,7 @@ func consume(ctx context.Context, cursor *mongo.Cursor, limit int64) ([]bson.Raw
 				return nil, nil, nil, false, rerror.ErrInternalByWithContext(ctx, fmt.Errorf("failed to decode item: %w", err))
 			}
 
-			cur, err := getCursor(item)
+			cur, err := cursorOf(item)
 			if err != nil {
 				return nil, nil, nil, false, rerror.ErrInternalByWithContext(ctx, fmt.Errorf("failed to get cursor: %w", err))
 			}
@@ -130,7 +132,7 @@ func (c *Collection) aggregateFilter(ctx context.Context, p usecasex.Pagination,
 		return nil, nil, errors.New("invalid pagination")
 	}
 
-	stages := []any{bson.M{"$sort": sortFilter(p, s)}}
+	stages := []any{bson.M{"$sort": sortDocument(p, s)}}
 
 	if p.Offset != nil {
 		stages = append(stages, bson.M{"$skip": p.Offset.Offset})
@@ -159,80 +161,105 @@ func (c *Collection) pageFilter(ctx context.Context, p usecasex.Pagination, s *u
 		return nil, nil
 	}
 
-	var filter bson.M
-	sortKey := idKey
-	sortOrder := 1
-
-	if s != nil && s.Key != "" {
-		sortKey = s.Key
-		if s.Reverted {
-			sortOrder = -1
-		}
-	}
-
 	var cursor *usecasex.Cursor
-	var op string
-
+	after := true
 	if p.Cursor.After != nil {
 		cursor = p.Cursor.After
-		op = "$gt"
 	} else if p.Cursor.Before != nil {
 		cursor = p.Cursor.Before
-		op = "$lt"
+		after = false
+	}
+	if cursor == nil {
+		return nil, nil
 	}
 
-	if cursor != nil {
-		cursorDoc, err := c.getCursorDocument(ctx, *cursor)
-		if err != nil {
-			return nil, err
-		}
+	fields := sortFields(s)
+	values, err := c.cursorValues(ctx, *cursor, fields)
+	if err != nil {
+		return nil, err
+	}
 
-		filter = bson.M{
-			"$or": []bson.M{
-				{sortKey: bson.M{op: cursorDoc[sortKey]}},
-				{
-					sortKey: cursorDoc[sortKey],
-					idKey:   bson.M{op: cursorDoc[idKey]},
-				},
-			},
+	// keyset filter: (k1 > v1) or (k1 = v1 and k2 > v2) or ... (k1 = v1 and ... and id > id1)
+	conds := make([]bson.M, 0, len(fields))
+	for i, f := range fields {
+		op := "$gt"
+		if (f.order == 1) != after {
+			op = "$lt"
 		}
-
-		if sortOrder == -1 {
-			if op == "$gt" {
-				op = "$lt"
-			} else {
-				op = "$gt"
-			}
-			filter = bson.M{
-				"$or": []bson.M{
-					{sortKey: bson.M{op: cursorDoc[sortKey]}},
-					{
-						sortKey: cursorDoc[sortKey],
-						idKey:   bson.M{op: cursorDoc[idKey]},
-					},
-				},
-			}
+		cond := bson.M{}
+		for j := 0; j < i; j++ {
+			cond[fields[j].key] = values[j]
 		}
+		cond[f.key] = bson.M{op: values[i]}
+		conds = append(conds, cond)
+	}
+	return bson.M{"$or": conds}, nil
+}
+
+// cursorValues returns the values of the sort fields of the item pointed by the cursor.
+func (c *Collection) cursorValues(ctx context.Context, cursor usecasex.Cursor, fields []sortField) ([]bson.RawValue, error) {
+	if isKeysetCursor(cursor) {
+		return decodeKeysetCursor(cursor, fields)
 	}
 
-	return filter, nil
+	cursorDoc, err := c.getCursorDocument(ctx, cursor)
+	if err != nil {
+		return nil, err
+	}
+	return lookupSortValues(cursorDoc, fields), nil
 }
 
-func (c *Collection) getCursorDocument(ctx context.Context, cursor usecasex.Cursor) (bson.M, error) {
-	var cursorDoc bson.M
-	err := c.collection.FindOne(ctx, bson.M{idKey: cursor}).Decode(&cursorDoc)
+func (c *Collection) getCursorDocument(ctx context.Context, cursor usecasex.Cursor) (bson.Raw, error) {
+	cursorDoc, err := c.collection.FindOne(ctx, bson.M{idKey: cursor}).Raw()
 	if err != nil {
 		return nil, fmt.Errorf("failed to find cursor element: %w", err)
 	}
 	return cursorDoc, nil
 }
 
-func sortFilter(p usecasex.Pagination, s *usecasex.Sort) bson.D {
-	var sortOptions bson.D
-	if s != nil && s.Key != "" && s.Key != idKey {
-		sortOptions = append(sortOptions, bson.E{Key: s.Key, Value: sortDirection(p, s)})
+type sortField struct {
+	key string
+	// order is 1 (ascending) or -1 (descending) regardless of the pagination direction.
+	order int
+}
+
+// sortFields returns the fields to sort by. The id is always the last field so that the order is stable,
+// and it is ordered in the direction of the first key.
+func sortFields(s *usecasex.Sort) []sortField {
+	keys := s.Keys()
+	res := make([]sortField, 0, len(keys)+1)
+	for _, k := range keys {
+		if k.Key == idKey {
+			continue
+		}
+		res = append(res, sortField{key: k.Key, order: order(k.Reverted)})
+	}
+	idOrder := 1
+	if len(keys) > 0 {
+		idOrder = order(keys[0].Reverted)
 	}
-	return append(sortOptions, bson.E{Key: idKey, Value: sortDirection(p, s)})
+	return append(res, sortField{key: idKey, order: idOrder})
+}
+
+func order(reverted bool) int {
+	if reverted {
+		return -1
+	}
+	return 1
+}
+
+func sortDocument(p usecasex.Pagination, s *usecasex.Sort) bson.D {
+	// the order is reversed to read the last items first
+	dir := 1
+	if p.Cursor != nil && p.Cursor.Last != nil {
+		dir = -1
+	}
+	fields := sortFields(s)
+	res := make(bson.D, 0, len(fields))
+	for _, f := range fields {
+		res = append(res, bson.E{Key: f.key, Value: f.order * dir})
+	}
+	return res
 }
 
 func limit(p usecasex.Pagination) int64 {
@@ -257,20 +284,6 @@ func limit(p usecasex.Pagination) int64 {
 	return defaultLimit + 1
 }
 
-func sortDirection(p usecasex.Pagination, s *usecasex.Sort) int {
-	reverted := false
-	if s != nil {
-		reverted = s.Reverted
-	}
-
-	reverted = reverted || (p.Cursor != nil && p.Cursor.Last != nil)
-
-	if reverted {
-		return -1
-	}
-	return 1
-}
-
 func (c *Collection) PaginateProject(ctx context.Context, rawFilter any, s *usecasex.Sort, p *usecasex.Pagination, consumer Consumer, opts ...*options.FindOptions) (*usecasex.PageInfo, error) {
 	if p == nil || (p.Cursor == nil && p.Offset == nil) {
 		return nil, nil
@@ -291,24 +304,7 @@ func (c *Collection) PaginateProject(ctx context.Context, rawFilter any, s *usec
 }
 
 func (c *Collection) paginate(ctx context.Context, rawFilter any, s *usecasex.Sort, p *usecasex.Pagination, filter any, consumer Consumer, opts []*options.FindOptions) (*usecasex.PageInfo, error) {
-
-	sortKey := idKey
-	sortOrder := 1
-	if s != nil && s.Key != "" {
-		sortKey = s.Key
-		if s.Reverted {
-			sortOrder = -1
-		}
-	}
-
-	if p.Cursor != nil && p.Cursor.Last != nil {
-		sortOrder *= -1
-	}
-
-	sort := bson.D{{Key: sortKey, Value: sortOrder}}
-	if sortKey != idKey {
-		sort = append(sort, bson.E{Key: idKey, Value: sortOrder})
-	}
+	sort := sortDocument(*p, s)
 
 	findOpts := options.Find().
 		SetSort(sort).
@@ -326,12 +322,7 @@ func (c *Collection) paginate(ctx context.Context, rawFilter any, s *usecasex.So
 		_ = cursor.Close(ctx)
 	}()
 
-	count, err := c.collection.CountDocuments(ctx, rawFilter)
-	if err != nil {
-		return nil, rerror.ErrInternalByWithContext(ctx, fmt.Errorf("failed to count: %w", err))
-	}
-
-	items, startCursor, endCursor, hasMore, err := consume(ctx, cursor, limit(*p))
+	items, startCursor, endCursor, hasMore, err := consume(ctx, cursor, limit(*p), cursorFunc(s))
 	if err != nil {
 		return nil, err
 	}
@@ -349,5 +340,47 @@ func (c *Collection) paginate(ctx context.Context, rawFilter any, s *usecasex.So
 
 	hasNextPage, hasPreviousPage := pageInfo(p, hasMore)
 
-	return usecasex.NewPageInfo(count, startCursor, endCursor, hasNextPage, hasPreviousPage), nil
+	res := usecasex.NewPageInfo(0, startCursor, endCursor, hasNextPage, hasPreviousPage)
+	if err := c.countTotal(ctx, p, res, func(ctx context.Context) (int64, error) {
+		return c.collection.CountDocuments(ctx, rawFilter)
+	}); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// countTotal sets the total count to the page info in the mode of the pagination.
+func (c *Collection) countTotal(ctx context.Context, p *usecasex.Pagination, info *usecasex.PageInfo, count func(context.Context) (int64, error)) error {
+	count = countWithError(count)
+	switch p.Count {
+	case usecasex.TotalCountNone:
+	case usecasex.TotalCountEstimated:
+		n, err := countWithError(func(ctx context.Context) (int64, error) {
+			return c.collection.EstimatedDocumentCount(ctx)
+		})(ctx)
+		if err != nil {
+			return err
+		}
+		info.TotalCount = n
+	case usecasex.TotalCountLazy:
+		info.SetTotalCountLoader(count)
+	default:
+		n, err := count(ctx)
+		if err != nil {
+			return err
+		}
+		info.TotalCount = n
+	}
+	info.TotalCountMode = p.Count
+	return nil
+}
+
+func countWithError(count func(context.Context) (int64, error)) func(context.Context) (int64, error) {
+	return func(ctx context.Context) (int64, error) {
+		n, err := count(ctx)
+		if err != nil {
+			return 0, rerror.ErrInternalByWithContext(ctx, fmt.Errorf("failed to count: %w", err))
+		}
+		return n, nil
+	}
 }
@@ -491,3 +491,54 @@ func TestPaginate_SortLogic(t *testing.T) {
 		})
 	}
 }
+
+func TestClientCollection_PaginateTotalCount(t *testing.T) {
+	ctx := context.Background()
+	initDB := mongotest.Connect(t)
+	c := NewCollection(initDB(t).Collection("test"))
+
+	_, _ = c.Client().InsertMany(ctx, []any{
+		bson.M{"id": "a", "n": 1},
+		bson.M{"id": "b", "n": 2},
+		bson.M{"id": "c", "n": 2},
+	})
+
+	filter := bson.M{"n": 2}
+	pipeline := []any{bson.M{"$match": filter}}
+	p := usecasex.CursorPagination{First: lo.ToPtr(int64(1))}.Wrap()
+
+	paginate := map[string]func(*usecasex.Pagination) (*usecasex.PageInfo, error){
+		"find": func(p *usecasex.Pagination) (*usecasex.PageInfo, error) {
+			return c.Paginate(ctx, filter, nil, p, &consumer{})
+		},
+		"aggregation": func(p *usecasex.Pagination) (*usecasex.PageInfo, error) {
+			return c.PaginateAggregation(ctx, pipeline, nil, p, &consumer{})
+		},
+	}
+
+	for name, paginate := range paginate {
+		t.Run(name, func(t *testing.T) {
+			got, err := paginate(p)
+			assert.NoError(t, err)
+			assert.Equal(t, int64(2), got.TotalCount)
+			assert.Equal(t, usecasex.TotalCountExact, got.TotalCountMode)
+
+			got, err = paginate(p.WithCount(usecasex.TotalCountNone))
+			assert.NoError(t, err)
+			assert.Equal(t, int64(0), got.TotalCount)
+			_, err = got.LoadTotalCount(ctx)
+			assert.Same(t, usecasex.ErrTotalCountUnavailable, err)
+
+			got, err = paginate(p.WithCount(usecasex.TotalCountEstimated))
+			assert.NoError(t, err)
+			assert.Equal(t, int64(3), got.TotalCount)
+
+			got, err = paginate(p.WithCount(usecasex.TotalCountLazy))
+			assert.NoError(t, err)
+			assert.Equal(t, int64(0), got.TotalCount)
+			count, err := got.LoadTotalCount(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, int64(2), count)
+		})
+	}
+}
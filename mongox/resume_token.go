@@ -0,0 +1,78 @@
+package mongox
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/reearth/reearthx/rerror"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// ResumeTokenStore persists resume tokens of change stream subscriptions.
+type ResumeTokenStore interface {
+	// LoadResumeToken returns nil if no token is stored for the name.
+	LoadResumeToken(ctx context.Context, name string) (bson.Raw, error)
+	SaveResumeToken(ctx context.Context, name string, token bson.Raw) error
+}
+
+type resumeTokenDocument struct {
+	ID    string   `bson:"id"`
+	Token bson.Raw `bson:"token"`
+}
+
+// MongoResumeTokenStore stores resume tokens in a collection.
+type MongoResumeTokenStore struct {
+	c *Collection
+}
+
+var _ ResumeTokenStore = (*MongoResumeTokenStore)(nil)
+
+func NewMongoResumeTokenStore(c *Collection) *MongoResumeTokenStore {
+	return &MongoResumeTokenStore{c: c}
+}
+
+func (s *MongoResumeTokenStore) Init(ctx context.Context) error {
+	_, err := s.c.Indexes2(ctx, IndexFromKey(idKey, true))
+	return err
+}
+
+func (s *MongoResumeTokenStore) LoadResumeToken(ctx context.Context, name string) (bson.Raw, error) {
+	c := &SliceConsumer[resumeTokenDocument]{}
+	if err := s.c.FindOne(ctx, bson.M{idKey: name}, c); err != nil {
+		if errors.Is(err, rerror.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return c.Result[0].Token, nil
+}
+
+func (s *MongoResumeTokenStore) SaveResumeToken(ctx context.Context, name string, token bson.Raw) error {
+	return s.c.SaveOne(ctx, name, resumeTokenDocument{ID: name, Token: token})
+}
+
+// MemoryResumeTokenStore stores resume tokens in memory. It is useful for tests.
+type MemoryResumeTokenStore struct {
+	lock   sync.Mutex
+	tokens map[string]bson.Raw
+}
+
+var _ ResumeTokenStore = (*MemoryResumeTokenStore)(nil)
+
+func NewMemoryResumeTokenStore() *MemoryResumeTokenStore {
+	return &MemoryResumeTokenStore{tokens: map[string]bson.Raw{}}
+}
+
+func (s *MemoryResumeTokenStore) LoadResumeToken(_ context.Context, name string) (bson.Raw, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.tokens[name], nil
+}
+
+func (s *MemoryResumeTokenStore) SaveResumeToken(_ context.Context, name string, token bson.Raw) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.tokens[name] = append(bson.Raw{}, token...)
+	return nil
+}
@@ -0,0 +1,320 @@
+package mongox
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/reearth/reearthx/log"
+	"github.com/reearth/reearthx/rerror"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	ChangeOperationInsert  = "insert"
+	ChangeOperationUpdate  = "update"
+	ChangeOperationReplace = "replace"
+	ChangeOperationDelete  = "delete"
+
+	// changeStreamUnsupportedCode is returned by servers that are not replica sets nor sharded clusters.
+	changeStreamUnsupportedCode = 40573
+	pollTokenKey                = "poll"
+	defaultPollInterval         = 5 * time.Second
+	defaultPollBatchSize        = 100
+)
+
+// ChangeEvent is a change event of a change stream. T is the type of the document.
+type ChangeEvent[T any] struct {
+	// Token is the resume token of the event.
+	Token         bson.Raw            `bson:"_id"`
+	OperationType string              `bson:"operationType"`
+	DocumentKey   bson.Raw            `bson:"documentKey"`
+	ClusterTime   primitive.Timestamp `bson:"clusterTime"`
+	// FullDocument is set on insert and replace events, and on update events if WatchOptions.FullDocument is true.
+	FullDocument *T `bson:"fullDocument"`
+}
+
+// ChangeConsumer is a Consumer that decodes change events.
+type ChangeConsumer[T any] func(ChangeEvent[T]) error
+
+func (c ChangeConsumer[T]) Consume(raw bson.Raw) error {
+	if raw == nil {
+		return nil
+	}
+	var e ChangeEvent[T]
+	if err := bson.Unmarshal(raw, &e); err != nil {
+		return err
+	}
+	return c(e)
+}
+
+type WatchOptions struct {
+	// Name identifies the subscription. The resume token is stored in TokenStore with it.
+	Name string
+	// TokenStore persists resume tokens so that the subscription restarts where it left off. If nil, it starts from now.
+	TokenStore ResumeTokenStore
+	// Pipeline filters and transforms change events, e.g. []any{bson.M{"$match": bson.M{"operationType": "insert"}}}.
+	Pipeline []any
+	// FullDocument makes update events carry the current version of the document.
+	FullDocument bool
+	BatchSize    int32
+
+	// PollField enables the fallback poller for deployments without replica sets.
+	// It must be a field that increases on every write, e.g. the update time. Deleted documents cannot be detected by the poller.
+	PollField string
+	// PollFilter filters documents found by the poller because Pipeline is not applied to them.
+	PollFilter any
+	// PollInterval defaults to 5 seconds.
+	PollInterval time.Duration
+}
+
+type watcher interface {
+	Watch(context.Context, any, ...*options.ChangeStreamOptions) (*mongo.ChangeStream, error)
+}
+
+type mongoCollectionWatcher struct{ c *mongo.Collection }
+
+func (w mongoCollectionWatcher) Watch(ctx context.Context, pipeline any, opts ...*options.ChangeStreamOptions) (*mongo.ChangeStream, error) {
+	return w.c.Watch(ctx, pipeline, opts...)
+}
+
+type mongoDatabaseWatcher struct{ db *mongo.Database }
+
+func (w mongoDatabaseWatcher) Watch(ctx context.Context, pipeline any, opts ...*options.ChangeStreamOptions) (*mongo.ChangeStream, error) {
+	return w.db.Watch(ctx, pipeline, opts...)
+}
+
+// Watch delivers change events of the collection to the consumer until ctx is done or the consumer returns an error.
+// A resume token is saved after each event is consumed successfully, so events are delivered at least once.
+// If change streams are not supported and opts.PollField is set, the collection is polled instead.
+// Polled events are replace events with the full document.
+func (c *Collection) Watch(ctx context.Context, consumer Consumer, opts WatchOptions) error {
+	token, err := loadResumeToken(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	// a subscription that has been polled keeps being polled to deliver the changes since the last poll
+	if !isPollToken(token) {
+		err = watch(ctx, mongoCollectionWatcher{c.collection}, consumer, opts, token)
+		if !isChangeStreamUnsupported(err) || opts.PollField == "" {
+			return err
+		}
+		log.Warnfc(ctx, "mongo: change streams are not supported, %s is polled instead", c.collection.Name())
+		token = nil
+	}
+
+	return c.poll(ctx, consumer, opts, token)
+}
+
+// Watch delivers change events of all collections in the database to the consumer. See Collection.Watch.
+// The fallback poller is not available.
+func (c *Client) Watch(ctx context.Context, consumer Consumer, opts WatchOptions) error {
+	token, err := loadResumeToken(ctx, opts)
+	if err != nil {
+		return err
+	}
+	if isPollToken(token) {
+		token = nil
+	}
+	return watch(ctx, mongoDatabaseWatcher{c.db}, consumer, opts, token)
+}
+
+func watch(ctx context.Context, w watcher, consumer Consumer, opts WatchOptions, token bson.Raw) error {
+	o := options.ChangeStream()
+	if opts.FullDocument {
+		o.SetFullDocument(options.UpdateLookup)
+	}
+	if opts.BatchSize > 0 {
+		o.SetBatchSize(opts.BatchSize)
+	}
+	if token != nil {
+		o.SetResumeAfter(token)
+	}
+
+	pipeline := opts.Pipeline
+	if pipeline == nil {
+		pipeline = []any{}
+	}
+
+	stream, err := w.Watch(ctx, pipeline, o)
+	if err != nil {
+		if isChangeStreamUnsupported(err) {
+			return err
+		}
+		return wrapError(ctx, err)
+	}
+	defer func() {
+		_ = stream.Close(context.Background())
+	}()
+
+	for stream.Next(ctx) {
+		if err := consumer.Consume(stream.Current); err != nil {
+			return err
+		}
+		if err := saveResumeToken(ctx, opts, stream.ResumeToken()); err != nil {
+			return err
+		}
+	}
+
+	if ctx.Err() != nil {
+		return nil
+	}
+	return wrapError(ctx, stream.Err())
+}
+
+type pollToken struct {
+	Value bson.RawValue `bson:"v"`
+	ID    string        `bson:"id"`
+}
+
+func (c *Collection) poll(ctx context.Context, consumer Consumer, opts WatchOptions, token bson.Raw) error {
+	var last *pollToken
+	if token != nil {
+		last = &pollToken{}
+		if err := token.Lookup(pollTokenKey).Unmarshal(last); err != nil {
+			return rerror.ErrInternalByWithContext(ctx, err)
+		}
+	} else {
+		// start from now like change streams
+		var err error
+		if last, err = c.latestPollToken(ctx, opts.PollField); err != nil {
+			return err
+		}
+	}
+
+	interval := opts.PollInterval
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	limit := int64(opts.BatchSize)
+	if limit <= 0 {
+		limit = defaultPollBatchSize
+	}
+	findOpts := options.Find().
+		SetSort(bson.D{{Key: opts.PollField, Value: 1}, {Key: idKey, Value: 1}}).
+		SetLimit(limit)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		n := int64(0)
+		err := c.Find(ctx, pollFilter(opts, last), FuncConsumer(func(raw bson.Raw) error {
+			if raw == nil {
+				return nil
+			}
+			n++
+
+			event, next, err := pollEvent(raw, opts.PollField)
+			if err != nil {
+				return err
+			}
+			if err := consumer.Consume(event); err != nil {
+				return err
+			}
+			last = next
+			return saveResumeToken(ctx, opts, event.Lookup("_id").Document())
+		}), findOpts)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		// there may be more documents to deliver
+		if n >= limit {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *Collection) latestPollToken(ctx context.Context, field string) (*pollToken, error) {
+	var last *pollToken
+	err := c.FindOne(ctx, bson.M{field: bson.M{"$exists": true}}, FuncConsumer(func(raw bson.Raw) error {
+		_, t, err := pollEvent(raw, field)
+		last = t
+		return err
+	}), options.FindOne().SetSort(bson.D{{Key: field, Value: -1}, {Key: idKey, Value: -1}}))
+	if errors.Is(err, rerror.ErrNotFound) {
+		return nil, nil
+	}
+	return last, err
+}
+
+func pollFilter(opts WatchOptions, last *pollToken) any {
+	var filters []any
+	if opts.PollFilter != nil {
+		filters = append(filters, opts.PollFilter)
+	}
+	if last != nil {
+		filters = append(filters, bson.M{"$or": []bson.M{
+			{opts.PollField: bson.M{"$gt": last.Value}},
+			{opts.PollField: last.Value, idKey: bson.M{"$gt": last.ID}},
+		}})
+	}
+	switch len(filters) {
+	case 0:
+		return bson.M{}
+	case 1:
+		return filters[0]
+	}
+	return bson.M{"$and": filters}
+}
+
+func pollEvent(raw bson.Raw, field string) (bson.Raw, *pollToken, error) {
+	value, err := raw.LookupErr(field)
+	if err != nil {
+		return nil, nil, err
+	}
+	id, _ := raw.Lookup(idKey).StringValueOK()
+	next := &pollToken{Value: value, ID: id}
+
+	event, err := bson.Marshal(bson.D{
+		{Key: "_id", Value: bson.D{{Key: pollTokenKey, Value: next}}},
+		{Key: "operationType", Value: ChangeOperationReplace},
+		{Key: "documentKey", Value: bson.D{{Key: "_id", Value: raw.Lookup("_id")}}},
+		{Key: "fullDocument", Value: raw},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return event, next, nil
+}
+
+func isPollToken(token bson.Raw) bool {
+	if token == nil {
+		return false
+	}
+	_, err := token.LookupErr(pollTokenKey)
+	return err == nil
+}
+
+func isChangeStreamUnsupported(err error) bool {
+	var se mongo.ServerError
+	return errors.As(err, &se) && se.HasErrorCode(changeStreamUnsupportedCode)
+}
+
+func loadResumeToken(ctx context.Context, opts WatchOptions) (bson.Raw, error) {
+	if opts.TokenStore == nil || opts.Name == "" {
+		return nil, nil
+	}
+	return opts.TokenStore.LoadResumeToken(ctx, opts.Name)
+}
+
+func saveResumeToken(ctx context.Context, opts WatchOptions, token bson.Raw) error {
+	if opts.TokenStore == nil || opts.Name == "" || token == nil {
+		return nil
+	}
+	return opts.TokenStore.SaveResumeToken(ctx, opts.Name, token)
+}
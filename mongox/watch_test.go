@@ -0,0 +1,213 @@
+package mongox
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/reearth/reearthx/mongox/mongotest"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type watchTestDoc struct {
+	ID string `bson:"id"`
+	U  int    `bson:"u"`
+}
+
+func TestChangeConsumer(t *testing.T) {
+	var got []ChangeEvent[watchTestDoc]
+	c := ChangeConsumer[watchTestDoc](func(e ChangeEvent[watchTestDoc]) error {
+		got = append(got, e)
+		return nil
+	})
+
+	raw := lo.Must(bson.Marshal(bson.M{
+		"_id":           bson.M{"_data": "x"},
+		"operationType": ChangeOperationInsert,
+		"fullDocument":  bson.M{"id": "a", "u": 1},
+	}))
+	assert.NoError(t, c.Consume(raw))
+	assert.NoError(t, c.Consume(nil))
+	assert.Len(t, got, 1)
+	assert.Equal(t, ChangeOperationInsert, got[0].OperationType)
+	assert.Equal(t, &watchTestDoc{ID: "a", U: 1}, got[0].FullDocument)
+	assert.Equal(t, "x", got[0].Token.Lookup("_data").StringValue())
+}
+
+func TestPollEvent(t *testing.T) {
+	raw := lo.Must(bson.Marshal(bson.M{"_id": "oid", "id": "a", "u": 2}))
+	event, token, err := pollEvent(raw, "u")
+	assert.NoError(t, err)
+	assert.Equal(t, "a", token.ID)
+	assert.Equal(t, int32(2), token.Value.Int32())
+
+	var e ChangeEvent[watchTestDoc]
+	assert.NoError(t, bson.Unmarshal(event, &e))
+	assert.Equal(t, ChangeOperationReplace, e.OperationType)
+	assert.Equal(t, &watchTestDoc{ID: "a", U: 2}, e.FullDocument)
+	assert.Equal(t, "oid", e.DocumentKey.Lookup("_id").StringValue())
+	assert.True(t, isPollToken(e.Token))
+	assert.False(t, isPollToken(lo.Must(bson.Marshal(bson.M{"_data": "x"}))))
+
+	_, _, err = pollEvent(raw, "x")
+	assert.Error(t, err)
+}
+
+func TestPollFilter(t *testing.T) {
+	assert.Equal(t, bson.M{}, pollFilter(WatchOptions{PollField: "u"}, nil))
+	assert.Equal(t, bson.M{"x": 1}, pollFilter(WatchOptions{PollField: "u", PollFilter: bson.M{"x": 1}}, nil))
+
+	last := &pollToken{ID: "a"}
+	assert.Equal(t, bson.M{"$and": []any{
+		bson.M{"x": 1},
+		bson.M{"$or": []bson.M{
+			{"u": bson.M{"$gt": last.Value}},
+			{"u": last.Value, "id": bson.M{"$gt": "a"}},
+		}},
+	}}, pollFilter(WatchOptions{PollField: "u", PollFilter: bson.M{"x": 1}}, last))
+}
+
+func TestMemoryResumeTokenStore(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryResumeTokenStore()
+	got, err := s.LoadResumeToken(ctx, "a")
+	assert.NoError(t, err)
+	assert.Nil(t, got)
+
+	token := lo.Must(bson.Marshal(bson.M{"_data": "x"}))
+	assert.NoError(t, s.SaveResumeToken(ctx, "a", token))
+	got, err = s.LoadResumeToken(ctx, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, bson.Raw(token), got)
+}
+
+func TestMongoResumeTokenStore(t *testing.T) {
+	ctx := context.Background()
+	s := NewMongoResumeTokenStore(NewCollection(mongotest.Connect(t)(t).Collection("tokens")))
+	assert.NoError(t, s.Init(ctx))
+
+	got, err := s.LoadResumeToken(ctx, "a")
+	assert.NoError(t, err)
+	assert.Nil(t, got)
+
+	token := lo.Must(bson.Marshal(bson.M{"_data": "x"}))
+	assert.NoError(t, s.SaveResumeToken(ctx, "a", token))
+	got, err = s.LoadResumeToken(ctx, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, bson.Raw(token), got)
+}
+
+func TestCollection_Watch(t *testing.T) {
+	db := mongotest.Connect(t)(t)
+	c := NewCollection(db.Collection("test"))
+	store := NewMemoryResumeTokenStore()
+	opts := WatchOptions{
+		Name:       "test",
+		TokenStore: store,
+		Pipeline:   []any{bson.M{"$match": bson.M{"operationType": ChangeOperationInsert}}},
+	}
+
+	events := make(chan ChangeEvent[watchTestDoc], 10)
+	consumer := ChangeConsumer[watchTestDoc](func(e ChangeEvent[watchTestDoc]) error {
+		events <- e
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- c.Watch(ctx, consumer, opts) }()
+
+	// wait for the change stream to open
+	time.Sleep(500 * time.Millisecond)
+	select {
+	case err := <-done:
+		cancel()
+		if isChangeStreamUnsupported(err) {
+			t.Skip("change streams are not supported")
+		}
+		t.Fatal(err)
+	default:
+	}
+
+	_ = lo.Must(c.Client().InsertOne(context.Background(), bson.M{"id": "a", "u": 1}))
+	_ = lo.Must(c.Client().UpdateOne(context.Background(), bson.M{"id": "a"}, bson.M{"$set": bson.M{"u": 2}}))
+	_ = lo.Must(c.Client().InsertOne(context.Background(), bson.M{"id": "b", "u": 3}))
+
+	for _, id := range []string{"a", "b"} {
+		select {
+		case e := <-events:
+			assert.Equal(t, ChangeOperationInsert, e.OperationType)
+			assert.Equal(t, id, e.FullDocument.ID)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout")
+		}
+	}
+	cancel()
+	assert.NoError(t, <-done)
+	assert.NotNil(t, lo.Must(store.LoadResumeToken(context.Background(), "test")))
+}
+
+func TestCollection_poll(t *testing.T) {
+	db := mongotest.Connect(t)(t)
+	c := NewCollection(db.Collection("test"))
+	bg := context.Background()
+	_ = lo.Must(c.Client().InsertOne(bg, bson.M{"id": "a", "u": 1, "x": true}))
+
+	store := NewMemoryResumeTokenStore()
+	opts := WatchOptions{
+		Name:         "test",
+		TokenStore:   store,
+		PollField:    "u",
+		PollFilter:   bson.M{"x": true},
+		PollInterval: 10 * time.Millisecond,
+		BatchSize:    1,
+	}
+
+	events := make(chan string, 10)
+	consumer := ChangeConsumer[watchTestDoc](func(e ChangeEvent[watchTestDoc]) error {
+		events <- e.FullDocument.ID
+		return nil
+	})
+	receive := func(n int) []string {
+		var res []string
+		for i := 0; i < n; i++ {
+			select {
+			case id := <-events:
+				res = append(res, id)
+			case <-time.After(5 * time.Second):
+				t.Fatal("timeout")
+			}
+		}
+		return res
+	}
+
+	ctx, cancel := context.WithCancel(bg)
+	done := make(chan error, 1)
+	go func() { done <- c.poll(ctx, consumer, opts, nil) }()
+
+	// existing documents are not delivered
+	time.Sleep(100 * time.Millisecond)
+	_ = lo.Must(c.Client().InsertMany(bg, []any{
+		bson.M{"id": "c", "u": 2, "x": true},
+		bson.M{"id": "b", "u": 2, "x": true},
+		bson.M{"id": "d", "u": 3, "x": false},
+	}))
+	_ = lo.Must(c.Client().UpdateOne(bg, bson.M{"id": "a"}, bson.M{"$set": bson.M{"u": 4}}))
+
+	assert.Equal(t, []string{"b", "c", "a"}, receive(3))
+	cancel()
+	assert.NoError(t, <-done)
+
+	// resume from the stored token
+	token := lo.Must(store.LoadResumeToken(bg, "test"))
+	assert.True(t, isPollToken(token))
+	_ = lo.Must(c.Client().InsertOne(bg, bson.M{"id": "e", "u": 5, "x": true}))
+
+	ctx, cancel = context.WithCancel(bg)
+	go func() { done <- c.poll(ctx, consumer, opts, token) }()
+	assert.Equal(t, []string{"e"}, receive(1))
+	cancel()
+	assert.NoError(t, <-done)
+}
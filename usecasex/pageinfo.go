@@ -1,11 +1,23 @@
 package usecasex
 
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var ErrTotalCountUnavailable = errors.New("total count is not available")
+
 type PageInfo struct {
 	TotalCount      int64
 	StartCursor     *Cursor
 	EndCursor       *Cursor
 	HasNextPage     bool
 	HasPreviousPage bool
+	// TotalCountMode tells how TotalCount was computed.
+	TotalCountMode TotalCountMode
+	// totalCountLoader counts items for TotalCountLazy.
+	totalCountLoader func(context.Context) (int64, error)
 }
 
 func NewPageInfo(totalCount int64, startCursor, endCursor *Cursor, hasNextPage, hasPreviousPage bool) *PageInfo {
@@ -42,10 +54,51 @@ func (p *PageInfo) Clone() *PageInfo {
 	}
 
 	return &PageInfo{
-		TotalCount:      p.TotalCount,
-		StartCursor:     p.StartCursor.CopyRef(),
-		EndCursor:       p.EndCursor.CopyRef(),
-		HasNextPage:     p.HasNextPage,
-		HasPreviousPage: p.HasPreviousPage,
+		TotalCount:       p.TotalCount,
+		StartCursor:      p.StartCursor.CopyRef(),
+		EndCursor:        p.EndCursor.CopyRef(),
+		HasNextPage:      p.HasNextPage,
+		HasPreviousPage:  p.HasPreviousPage,
+		TotalCountMode:   p.TotalCountMode,
+		totalCountLoader: p.totalCountLoader,
+	}
+}
+
+// SetTotalCountLoader makes the total count be computed by f on the first call of LoadTotalCount.
+func (p *PageInfo) SetTotalCountLoader(f func(context.Context) (int64, error)) {
+	var lock sync.Mutex
+	var count *int64
+	p.TotalCountMode = TotalCountLazy
+	p.totalCountLoader = func(ctx context.Context) (int64, error) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		if count != nil {
+			return *count, nil
+		}
+		c, err := f(ctx)
+		if err != nil {
+			return 0, err
+		}
+		count = &c
+		return c, nil
+	}
+}
+
+// LoadTotalCount returns the total count, computing it if it is lazy.
+// ErrTotalCountUnavailable is returned if items were not counted.
+func (p *PageInfo) LoadTotalCount(ctx context.Context) (int64, error) {
+	if p == nil {
+		return 0, nil
+	}
+	switch p.TotalCountMode {
+	case TotalCountNone:
+		return 0, ErrTotalCountUnavailable
+	case TotalCountLazy:
+		if p.totalCountLoader == nil {
+			return 0, ErrTotalCountUnavailable
+		}
+		return p.totalCountLoader(ctx)
 	}
+	return p.TotalCount, nil
 }
@@ -1,6 +1,8 @@
 package usecasex
 
 import (
+	"context"
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -45,3 +47,43 @@ func TestPageInfo_Clone(t *testing.T) {
 	assert.NotSame(t, p, got)
 	assert.Nil(t, (*PageInfo)(nil).Clone())
 }
+
+func TestPageInfo_LoadTotalCount(t *testing.T) {
+	ctx := context.Background()
+
+	got, err := (&PageInfo{TotalCount: 10}).LoadTotalCount(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), got)
+
+	got, err = (&PageInfo{TotalCount: 10, TotalCountMode: TotalCountEstimated}).LoadTotalCount(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), got)
+
+	_, err = (&PageInfo{TotalCountMode: TotalCountNone}).LoadTotalCount(ctx)
+	assert.Same(t, ErrTotalCountUnavailable, err)
+
+	_, err = (&PageInfo{TotalCountMode: TotalCountLazy}).LoadTotalCount(ctx)
+	assert.Same(t, ErrTotalCountUnavailable, err)
+
+	calls := 0
+	wantErr := errors.New("failed")
+	p := &PageInfo{}
+	p.SetTotalCountLoader(func(context.Context) (int64, error) {
+		calls++
+		if calls == 1 {
+			return 0, wantErr
+		}
+		return 5, nil
+	})
+	assert.Equal(t, TotalCountLazy, p.TotalCountMode)
+
+	_, err = p.LoadTotalCount(ctx)
+	assert.Same(t, wantErr, err)
+	got, err = p.LoadTotalCount(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), got)
+	got, err = p.Clone().LoadTotalCount(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), got)
+	assert.Equal(t, 2, calls)
+}
@@ -28,6 +28,7 @@ func TestPagination_Clone(t *testing.T) {
 			Last:   lo.ToPtr(int64(10)),
 		},
 		Offset: &OffsetPagination{Offset: 100, Limit: 10},
+		Count:  TotalCountLazy,
 	}
 	got := target.Clone()
 
@@ -35,3 +36,11 @@ func TestPagination_Clone(t *testing.T) {
 	assert.NotSame(t, got, target)
 	assert.Nil(t, (*Pagination)(nil).Clone())
 }
+
+func TestPagination_WithCount(t *testing.T) {
+	p := OffsetPagination{Offset: 1, Limit: 2}.Wrap()
+	got := p.WithCount(TotalCountNone)
+	assert.Equal(t, &Pagination{Offset: &OffsetPagination{Offset: 1, Limit: 2}, Count: TotalCountNone}, got)
+	assert.Equal(t, TotalCountExact, p.Count)
+	assert.Nil(t, (*Pagination)(nil).WithCount(TotalCountNone))
+}
@@ -44,6 +44,32 @@ func (p OffsetPagination) Wrap() *Pagination {
 type Pagination struct {
 	Cursor *CursorPagination
 	Offset *OffsetPagination
+	// Count specifies how PageInfo.TotalCount is computed. Counting can be much slower than finding a page.
+	Count TotalCountMode
+}
+
+// TotalCountMode specifies how PageInfo.TotalCount is computed.
+type TotalCountMode int
+
+const (
+	// TotalCountExact counts all matching items. This is the default.
+	TotalCountExact TotalCountMode = iota
+	// TotalCountNone does not count items. TotalCount is always zero.
+	TotalCountNone
+	// TotalCountEstimated uses the number of all items of the collection taken from its stats, ignoring filters.
+	TotalCountEstimated
+	// TotalCountLazy counts all matching items only when PageInfo.LoadTotalCount is called.
+	TotalCountLazy
+)
+
+// WithCount returns a copy of the pagination that computes the total count in the mode.
+func (p *Pagination) WithCount(m TotalCountMode) *Pagination {
+	if p == nil {
+		return nil
+	}
+	p2 := p.Clone()
+	p2.Count = m
+	return p2
 }
 
 func (p *Pagination) Clone() *Pagination {
@@ -54,10 +80,6 @@ func (p *Pagination) Clone() *Pagination {
 	return &Pagination{
 		Cursor: p.Cursor.Clone(),
 		Offset: util.CloneRef(p.Offset),
+		Count:  p.Count,
 	}
 }
-
-type Sort struct {
-	Key      string
-	Reverted bool
-}
@@ -0,0 +1,79 @@
+package usecasex
+
+import (
+	"errors"
+	"slices"
+)
+
+const defaultPageSize = 20
+
+// ErrInvalidCursor is returned when the item of a cursor is not found.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// PaginateSlice paginates sorted items in memory with the same page info as mongox.Collection.Paginate,
+// so that memory repositories can be tested against the behavior of the mongo ones:
+// hasPreviousPage is only reported for last, hasNextPage only for first and offsets,
+// after takes precedence over before, and a cursor of an item that is not in the slice is an error.
+// Cursors of items are returned by cursor. It returns all items and nil page info if p is nil.
+func PaginateSlice[T any](items []T, p *Pagination, cursor func(T) Cursor) ([]T, *PageInfo, error) {
+	if p == nil || (p.Cursor == nil && p.Offset == nil) {
+		return items, nil, nil
+	}
+
+	total := int64(len(items))
+	var hasNext, hasPrev bool
+
+	if p.Offset != nil {
+		limit := p.Offset.Limit
+		if limit <= 0 {
+			limit = defaultPageSize
+		}
+		start := min(max(p.Offset.Offset, 0), total)
+		end := min(start+limit, total)
+		hasNext = end < total
+		items = items[start:end]
+	} else {
+		c := p.Cursor
+		if c.After != nil {
+			i := slices.IndexFunc(items, func(t T) bool { return cursor(t) == *c.After })
+			if i < 0 {
+				return nil, nil, ErrInvalidCursor
+			}
+			items = items[i+1:]
+		} else if c.Before != nil {
+			i := slices.IndexFunc(items, func(t T) bool { return cursor(t) == *c.Before })
+			if i < 0 {
+				return nil, nil, ErrInvalidCursor
+			}
+			items = items[:i]
+		}
+
+		n := int64(len(items))
+		switch {
+		case c.First != nil:
+			limit := pageSize(*c.First)
+			hasNext = n > limit
+			items = items[:min(limit, n)]
+		case c.Last != nil:
+			limit := pageSize(*c.Last)
+			hasPrev = n > limit
+			items = items[n-min(limit, n):]
+		default:
+			items = items[:min(defaultPageSize, n)]
+		}
+	}
+
+	var start, end *Cursor
+	if len(items) > 0 {
+		start = cursor(items[0]).Ref()
+		end = cursor(items[len(items)-1]).Ref()
+	}
+	return items, NewPageInfo(total, start, end, hasNext, hasPrev), nil
+}
+
+func pageSize(limit int64) int64 {
+	if limit <= 0 {
+		return defaultPageSize
+	}
+	return limit
+}
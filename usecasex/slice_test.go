@@ -0,0 +1,93 @@
+package usecasex
+
+import (
+	"testing"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaginateSlice(t *testing.T) {
+	items := []string{"a", "b", "c", "d", "e"}
+	cursor := func(s string) Cursor { return Cursor(s) }
+
+	tests := []struct {
+		name     string
+		p        *Pagination
+		want     []string
+		wantInfo *PageInfo
+		wantErr  error
+	}{
+		{
+			name: "nil",
+			want: items,
+		},
+		{
+			name:     "first",
+			p:        CursorPagination{First: lo.ToPtr(int64(2))}.Wrap(),
+			want:     []string{"a", "b"},
+			wantInfo: NewPageInfo(5, Cursor("a").Ref(), Cursor("b").Ref(), true, false),
+		},
+		{
+			name:     "first after",
+			p:        CursorPagination{First: lo.ToPtr(int64(2)), After: Cursor("c").Ref()}.Wrap(),
+			want:     []string{"d", "e"},
+			wantInfo: NewPageInfo(5, Cursor("d").Ref(), Cursor("e").Ref(), false, false),
+		},
+		{
+			name:     "last",
+			p:        CursorPagination{Last: lo.ToPtr(int64(2))}.Wrap(),
+			want:     []string{"d", "e"},
+			wantInfo: NewPageInfo(5, Cursor("d").Ref(), Cursor("e").Ref(), false, true),
+		},
+		{
+			name:     "last before",
+			p:        CursorPagination{Last: lo.ToPtr(int64(3)), Before: Cursor("c").Ref()}.Wrap(),
+			want:     []string{"a", "b"},
+			wantInfo: NewPageInfo(5, Cursor("a").Ref(), Cursor("b").Ref(), false, false),
+		},
+		{
+			name:    "unknown after",
+			p:       CursorPagination{First: lo.ToPtr(int64(2)), After: Cursor("x").Ref()}.Wrap(),
+			wantErr: ErrInvalidCursor,
+		},
+		{
+			name:    "unknown before",
+			p:       CursorPagination{Last: lo.ToPtr(int64(2)), Before: Cursor("x").Ref()}.Wrap(),
+			wantErr: ErrInvalidCursor,
+		},
+		{
+			name:     "after takes precedence over before",
+			p:        CursorPagination{First: lo.ToPtr(int64(2)), After: Cursor("c").Ref(), Before: Cursor("x").Ref()}.Wrap(),
+			want:     []string{"d", "e"},
+			wantInfo: NewPageInfo(5, Cursor("d").Ref(), Cursor("e").Ref(), false, false),
+		},
+		{
+			name:     "offset",
+			p:        OffsetPagination{Offset: 1, Limit: 2}.Wrap(),
+			want:     []string{"b", "c"},
+			wantInfo: NewPageInfo(5, Cursor("b").Ref(), Cursor("c").Ref(), true, false),
+		},
+		{
+			name:     "offset zero",
+			p:        OffsetPagination{Offset: 0, Limit: 2}.Wrap(),
+			want:     []string{"a", "b"},
+			wantInfo: NewPageInfo(5, Cursor("a").Ref(), Cursor("b").Ref(), true, false),
+		},
+		{
+			name:     "offset out of range",
+			p:        OffsetPagination{Offset: 10, Limit: 2}.Wrap(),
+			want:     []string{},
+			wantInfo: NewPageInfo(5, nil, nil, false, false),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotInfo, err := PaginateSlice(items, tt.p, cursor)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantInfo, gotInfo)
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package usecasex
+
+import (
+	"cmp"
+	"strings"
+	"time"
+)
+
+type SortKey struct {
+	Key      string
+	Reverted bool
+}
+
+// Sort orders results by Key and then by each key of Then. Repositories finally order results by their IDs so that the order is stable.
+type Sort struct {
+	Key      string
+	Reverted bool
+	Then     []SortKey
+}
+
+func NewSort(keys ...SortKey) *Sort {
+	if len(keys) == 0 {
+		return nil
+	}
+	s := &Sort{
+		Key:      keys[0].Key,
+		Reverted: keys[0].Reverted,
+	}
+	if len(keys) > 1 {
+		s.Then = append([]SortKey{}, keys[1:]...)
+	}
+	return s
+}
+
+// Keys returns the non-empty sort keys in order of priority. Each key appears only once.
+func (s *Sort) Keys() []SortKey {
+	if s == nil {
+		return nil
+	}
+	res := make([]SortKey, 0, len(s.Then)+1)
+	seen := map[string]struct{}{}
+	for _, k := range append([]SortKey{{Key: s.Key, Reverted: s.Reverted}}, s.Then...) {
+		if k.Key == "" {
+			continue
+		}
+		if _, ok := seen[k.Key]; ok {
+			continue
+		}
+		seen[k.Key] = struct{}{}
+		res = append(res, k)
+	}
+	return res
+}
+
+// IsCompound returns true if the sort has more than one key.
+func (s *Sort) IsCompound() bool {
+	return len(s.Keys()) > 1
+}
+
+// SortFunc returns a comparison function for slices.SortFunc that orders elements by the keys of the sort.
+// value returns the value of the element for the key and id returns the ID of the element, which breaks ties.
+// The ID is ordered in the direction of the first key, which is the order the keyset cursors of mongox assume.
+func SortFunc[T any](s *Sort, value func(T, string) any, id func(T) string) func(a, b T) int {
+	keys := s.Keys()
+	return func(a, b T) int {
+		for _, k := range keys {
+			c := CompareSortValues(value(a, k.Key), value(b, k.Key))
+			if k.Reverted {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		c := strings.Compare(id(a), id(b))
+		if len(keys) > 0 && keys[0].Reverted {
+			c = -c
+		}
+		return c
+	}
+}
+
+// CompareSortValues compares values of the same type. nil is less than any other value like null in MongoDB.
+// Values of unsupported or different types are regarded as equal.
+func CompareSortValues(a, b any) int {
+	if a == nil || b == nil {
+		switch {
+		case a == nil && b == nil:
+			return 0
+		case a == nil:
+			return -1
+		default:
+			return 1
+		}
+	}
+
+	switch a := a.(type) {
+	case string:
+		if b, ok := b.(string); ok {
+			return cmp.Compare(a, b)
+		}
+	case int:
+		if b, ok := b.(int); ok {
+			return cmp.Compare(a, b)
+		}
+	case int64:
+		if b, ok := b.(int64); ok {
+			return cmp.Compare(a, b)
+		}
+	case float64:
+		if b, ok := b.(float64); ok {
+			return cmp.Compare(a, b)
+		}
+	case bool:
+		if b, ok := b.(bool); ok && a != b {
+			if a {
+				return 1
+			}
+			return -1
+		}
+	case time.Time:
+		if b, ok := b.(time.Time); ok {
+			return a.Compare(b)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,96 @@
+package usecasex
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSort(t *testing.T) {
+	assert.Nil(t, NewSort())
+	assert.Equal(t, &Sort{Key: "a", Reverted: true}, NewSort(SortKey{Key: "a", Reverted: true}))
+	assert.Equal(t, &Sort{
+		Key:  "a",
+		Then: []SortKey{{Key: "b", Reverted: true}},
+	}, NewSort(SortKey{Key: "a"}, SortKey{Key: "b", Reverted: true}))
+}
+
+func TestSort_Keys(t *testing.T) {
+	assert.Nil(t, (*Sort)(nil).Keys())
+	assert.Empty(t, (&Sort{}).Keys())
+	assert.Equal(t, []SortKey{{Key: "a"}}, (&Sort{Key: "a"}).Keys())
+	assert.Equal(t, []SortKey{{Key: "a", Reverted: true}, {Key: "b"}}, (&Sort{
+		Key:      "a",
+		Reverted: true,
+		Then:     []SortKey{{Key: ""}, {Key: "b"}, {Key: "a"}},
+	}).Keys())
+}
+
+func TestSort_IsCompound(t *testing.T) {
+	assert.False(t, (*Sort)(nil).IsCompound())
+	assert.False(t, (&Sort{Key: "a"}).IsCompound())
+	assert.False(t, (&Sort{Key: "a", Then: []SortKey{{Key: "a"}}}).IsCompound())
+	assert.True(t, (&Sort{Key: "a", Then: []SortKey{{Key: "b"}}}).IsCompound())
+}
+
+func TestSortFunc(t *testing.T) {
+	type elem struct {
+		id     string
+		status string
+		n      int
+	}
+	elems := []elem{
+		{id: "a", status: "open", n: 2},
+		{id: "b", status: "closed", n: 1},
+		{id: "c", status: "open", n: 1},
+		{id: "d", status: "open", n: 2},
+	}
+	value := func(e elem, key string) any {
+		switch key {
+		case "status":
+			return e.status
+		case "n":
+			return e.n
+		}
+		return nil
+	}
+	id := func(e elem) string { return e.id }
+	ids := func(s *Sort) []string {
+		res := slices.Clone(elems)
+		slices.SortFunc(res, SortFunc(s, value, id))
+		var ids []string
+		for _, e := range res {
+			ids = append(ids, e.id)
+		}
+		return ids
+	}
+
+	assert.Equal(t, []string{"a", "b", "c", "d"}, ids(nil))
+	assert.Equal(t, []string{"b", "c", "a", "d"}, ids(&Sort{Key: "n"}))
+	assert.Equal(t, []string{"d", "a", "c", "b"}, ids(&Sort{Key: "n", Reverted: true}))
+	assert.Equal(t, []string{"c", "d", "a", "b"}, ids(NewSort(
+		SortKey{Key: "status", Reverted: true},
+		SortKey{Key: "n"},
+	)))
+	assert.Equal(t, []string{"b", "a", "d", "c"}, ids(NewSort(
+		SortKey{Key: "status"},
+		SortKey{Key: "n", Reverted: true},
+	)))
+}
+
+func TestCompareSortValues(t *testing.T) {
+	now := time.Now()
+	assert.Equal(t, 0, CompareSortValues(nil, nil))
+	assert.Equal(t, -1, CompareSortValues(nil, "a"))
+	assert.Equal(t, 1, CompareSortValues(1, nil))
+	assert.Equal(t, -1, CompareSortValues("a", "b"))
+	assert.Equal(t, 1, CompareSortValues(2, 1))
+	assert.Equal(t, 1, CompareSortValues(int64(2), int64(1)))
+	assert.Equal(t, -1, CompareSortValues(1.5, 2.5))
+	assert.Equal(t, -1, CompareSortValues(false, true))
+	assert.Equal(t, 0, CompareSortValues(true, true))
+	assert.Equal(t, -1, CompareSortValues(now, now.Add(time.Second)))
+	assert.Equal(t, 0, CompareSortValues("a", 1))
+}
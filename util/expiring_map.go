@@ -0,0 +1,129 @@
+package util
+
+import (
+	"sync"
+	"time"
+)
+
+// minExpiringMapSweep is the number of entries below which an ExpiringMap is never swept.
+const minExpiringMapSweep = 64
+
+// ExpiringMap is a map whose entries expire at the time given when they are stored. The zero value is ready to use.
+// Expired entries are never returned, and they are removed when the map has doubled since the last sweep,
+// so that the map does not hold more than about twice the entries alive without a background goroutine.
+type ExpiringMap[K comparable, V any] struct {
+	lock    sync.Mutex
+	entries map[K]expiringEntry[V]
+	// next is the size of the map at which the next sweep happens
+	next int
+}
+
+type expiringEntry[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+func NewExpiringMap[K comparable, V any]() *ExpiringMap[K, V] {
+	return &ExpiringMap[K, V]{}
+}
+
+func (e expiringEntry[V]) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// Load returns the value of the key unless it has expired.
+func (m *ExpiringMap[K, V]) Load(key K) (res V, _ bool) {
+	if m == nil {
+		return
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	e, ok := m.entries[key]
+	if !ok {
+		return
+	}
+	if e.expired(Now()) {
+		delete(m.entries, key)
+		return
+	}
+	return e.value, true
+}
+
+// Store sets the value of the key until expiresAt. A zero expiresAt means that the entry never expires.
+func (m *ExpiringMap[K, V]) Store(key K, value V, expiresAt time.Time) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.entries == nil {
+		m.entries = map[K]expiringEntry[V]{}
+	}
+	if len(m.entries) >= max(m.next, minExpiringMapSweep) {
+		now := Now()
+		for k, e := range m.entries {
+			if e.expired(now) {
+				delete(m.entries, k)
+			}
+		}
+		m.next = 2 * len(m.entries)
+	}
+	m.entries[key] = expiringEntry[V]{value: value, expiresAt: expiresAt}
+}
+
+func (m *ExpiringMap[K, V]) Delete(key K) {
+	if m == nil {
+		return
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	delete(m.entries, key)
+}
+
+// DeleteFunc deletes the entries for which f returns true.
+func (m *ExpiringMap[K, V]) DeleteFunc(f func(K, V) bool) {
+	if m == nil {
+		return
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	for k, e := range m.entries {
+		if f(k, e.value) {
+			delete(m.entries, k)
+		}
+	}
+}
+
+// Range calls f for the entries that have not expired until f returns false. f must not modify the map.
+func (m *ExpiringMap[K, V]) Range(f func(K, V) bool) {
+	if m == nil {
+		return
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	now := Now()
+	for k, e := range m.entries {
+		if !e.expired(now) && !f(k, e.value) {
+			return
+		}
+	}
+}
+
+// Len returns the number of entries including the expired ones that have not been removed yet.
+func (m *ExpiringMap[K, V]) Len() int {
+	if m == nil {
+		return 0
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return len(m.entries)
+}
+
+func (m *ExpiringMap[K, V]) Clear() {
+	if m == nil {
+		return
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	clear(m.entries)
+	m.next = 0
+}
@@ -0,0 +1,96 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpiringMap_Load_Store(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	defer MockNow(now)()
+
+	m := NewExpiringMap[string, int]()
+	m.Store("a", 1, now.Add(time.Second))
+	m.Store("b", 2, now)
+	m.Store("c", 3, time.Time{})
+
+	got, ok := m.Load("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, got)
+
+	// expired entries are removed when they are loaded
+	got, ok = m.Load("b")
+	assert.False(t, ok)
+	assert.Zero(t, got)
+	assert.Equal(t, 2, m.Len())
+
+	defer MockNow(now.Add(time.Hour))()
+	_, ok = m.Load("a")
+	assert.False(t, ok)
+	got, ok = m.Load("c")
+	assert.True(t, ok)
+	assert.Equal(t, 3, got)
+
+	var nilMap *ExpiringMap[string, int]
+	_, ok = nilMap.Load("a")
+	assert.False(t, ok)
+	assert.Zero(t, nilMap.Len())
+}
+
+func TestExpiringMap_Store_Sweep(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	defer MockNow(now)()
+
+	m := &ExpiringMap[int, int]{}
+	for i := range minExpiringMapSweep {
+		m.Store(i, i, now.Add(time.Second))
+	}
+	m.Store(-1, -1, time.Time{})
+	assert.Equal(t, minExpiringMapSweep+1, m.Len())
+
+	// the map is swept when it has doubled since the last sweep
+	defer MockNow(now.Add(time.Minute))()
+	for i := range minExpiringMapSweep - 1 {
+		m.Store(100+i, i, now.Add(time.Hour))
+	}
+	assert.Equal(t, 2*minExpiringMapSweep, m.Len())
+	m.Store(1000, 0, now.Add(time.Hour))
+	assert.Equal(t, minExpiringMapSweep+1, m.Len())
+	_, ok := m.Load(-1)
+	assert.True(t, ok)
+}
+
+func TestExpiringMap_Range(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	defer MockNow(now)()
+
+	m := &ExpiringMap[string, int]{}
+	m.Store("a", 1, now.Add(time.Second))
+	m.Store("b", 2, now.Add(-time.Second))
+
+	var keys []string
+	m.Range(func(k string, _ int) bool {
+		keys = append(keys, k)
+		return true
+	})
+	assert.Equal(t, []string{"a"}, keys)
+}
+
+func TestExpiringMap_Delete(t *testing.T) {
+	m := &ExpiringMap[string, int]{}
+	m.Store("a", 1, time.Time{})
+	m.Store("b", 2, time.Time{})
+	m.Store("c", 3, time.Time{})
+
+	m.Delete("a")
+	_, ok := m.Load("a")
+	assert.False(t, ok)
+
+	m.DeleteFunc(func(_ string, v int) bool { return v == 2 })
+	assert.Equal(t, 1, m.Len())
+
+	m.Clear()
+	assert.Zero(t, m.Len())
+}